@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"go.k6.io/k6/lib"
+)
+
+// ExecMix contains the current weights of the exec functions from which a
+// scenario randomly picks the function for each iteration.
+type ExecMix struct {
+	Scenario string             `json:"-" yaml:"scenario"`
+	Weights  map[string]float64 `json:"weights" yaml:"weights"`
+}
+
+// NewExecMix returns the current state of the given executor ExecMix.
+func NewExecMix(scenario string, mix *lib.ExecMix) ExecMix {
+	return ExecMix{
+		Scenario: scenario,
+		Weights:  mix.GetWeights(),
+	}
+}
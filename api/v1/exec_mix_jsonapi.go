@@ -0,0 +1,64 @@
+package v1
+
+// ExecMixesJSONAPI is JSON API envelop for multiple exec mixes
+type ExecMixesJSONAPI struct {
+	Data []execMixData `json:"data"`
+}
+
+// ExecMixJSONAPI is JSON API envelop for a single exec mix
+type ExecMixJSONAPI struct {
+	Data execMixData `json:"data"`
+}
+
+type execMixData struct {
+	Type       string  `json:"type"`
+	ID         string  `json:"id"`
+	Attributes ExecMix `json:"attributes"`
+}
+
+// NewExecMixJSONAPI creates the JSON API exec mix envelop
+func NewExecMixJSONAPI(m ExecMix) ExecMixJSONAPI {
+	return ExecMixJSONAPI{
+		Data: newExecMixData(m),
+	}
+}
+
+func newExecMixesJSONAPI(list []ExecMix) ExecMixesJSONAPI {
+	mixes := make([]execMixData, 0, len(list))
+
+	for _, m := range list {
+		mixes = append(mixes, newExecMixData(m))
+	}
+
+	return ExecMixesJSONAPI{
+		Data: mixes,
+	}
+}
+
+func newExecMixData(m ExecMix) execMixData {
+	return execMixData{
+		Type:       "execMixes",
+		ID:         m.Scenario,
+		Attributes: m,
+	}
+}
+
+// ExecMix extracts the v1.ExecMix from the JSON API envelop
+func (m ExecMixJSONAPI) ExecMix() ExecMix {
+	mix := m.Data.Attributes
+	mix.Scenario = m.Data.ID
+	return mix
+}
+
+// ExecMixes extracts the []v1.ExecMix from the JSON API envelop
+func (m ExecMixesJSONAPI) ExecMixes() []ExecMix {
+	list := make([]ExecMix, 0, len(m.Data))
+
+	for _, data := range m.Data {
+		mix := data.Attributes
+		mix.Scenario = data.ID
+		list = append(list, mix)
+	}
+
+	return list
+}
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"go.k6.io/k6/api/common"
+	"go.k6.io/k6/lib"
+)
+
+// getExecMixes returns the exec mixes of all executors that have one, in the
+// order of the executors.
+func getExecMixes(execScheduler lib.ExecutionScheduler) []ExecMix {
+	var result []ExecMix
+	for _, e := range execScheduler.GetExecutors() {
+		mixExecutor, ok := e.(lib.ExecMixExecutor)
+		if !ok || mixExecutor.GetExecMix() == nil {
+			continue
+		}
+		result = append(result, NewExecMix(e.GetConfig().GetName(), mixExecutor.GetExecMix()))
+	}
+	return result
+}
+
+func getExecutorExecMix(execScheduler lib.ExecutionScheduler, scenario string) *lib.ExecMix {
+	for _, e := range execScheduler.GetExecutors() {
+		if e.GetConfig().GetName() != scenario {
+			continue
+		}
+		if mixExecutor, ok := e.(lib.ExecMixExecutor); ok {
+			return mixExecutor.GetExecMix()
+		}
+	}
+	return nil
+}
+
+func handleGetExecMixes(rw http.ResponseWriter, r *http.Request) {
+	engine := common.GetEngine(r.Context())
+
+	data, err := json.Marshal(newExecMixesJSONAPI(getExecMixes(engine.ExecutionScheduler)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+func handleGetExecMix(rw http.ResponseWriter, r *http.Request, scenario string) {
+	engine := common.GetEngine(r.Context())
+
+	mix := getExecutorExecMix(engine.ExecutionScheduler, scenario)
+	if mix == nil {
+		apiError(rw, "Not Found", "No scenario with an exec mix and that ID was found", http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(NewExecMixJSONAPI(NewExecMix(scenario, mix)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+func handlePatchExecMix(rw http.ResponseWriter, r *http.Request, scenario string) {
+	engine := common.GetEngine(r.Context())
+
+	mix := getExecutorExecMix(engine.ExecutionScheduler, scenario)
+	if mix == nil {
+		apiError(rw, "Not Found", "No scenario with an exec mix and that ID was found", http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var envelop ExecMixJSONAPI
+	if err = json.Unmarshal(body, &envelop); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = mix.SetWeights(envelop.ExecMix().Weights); err != nil {
+		apiError(rw, "Exec mix update error", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := json.Marshal(NewExecMixJSONAPI(NewExecMix(scenario, mix)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
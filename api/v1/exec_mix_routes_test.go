@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/core"
+	"go.k6.io/k6/core/local"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/metrics"
+)
+
+func TestExecMixRoutes(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+
+	var scenarios lib.ScenarioConfigs
+	err := json.Unmarshal([]byte(`{
+		"journeys": {"executor": "constant-vus", "vus": 1, "duration": "10s", "execMix": {"browse": 7, "buy": 3}},
+		"plain": {"executor": "constant-vus", "vus": 1, "duration": "10s"}
+	}`), &scenarios)
+	require.NoError(t, err)
+	options := lib.Options{Scenarios: scenarios}
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	execScheduler, err := local.NewExecutionScheduler(&minirunner.MiniRunner{Options: options}, builtinMetrics, logger)
+	require.NoError(t, err)
+	engine, err := core.NewEngine(execScheduler, options, lib.RuntimeOptions{}, nil, logger, registry)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rw, newRequestWithEngine(engine, http.MethodGet, "/v1/exec-mixes", nil))
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	var list ExecMixesJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &list))
+	assert.Equal(t, []ExecMix{
+		{Scenario: "journeys", Weights: map[string]float64{"browse": 7, "buy": 3}},
+	}, list.ExecMixes())
+
+	for _, path := range []string{"/v1/exec-mixes/plain", "/v1/exec-mixes/unknown"} {
+		rw = httptest.NewRecorder()
+		NewHandler().ServeHTTP(rw, newRequestWithEngine(engine, http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rw.Result().StatusCode, path)
+	}
+
+	patch := func(weights map[string]float64) *httptest.ResponseRecorder {
+		body, err := json.Marshal(NewExecMixJSONAPI(ExecMix{Scenario: "journeys", Weights: weights}))
+		require.NoError(t, err)
+		rw := httptest.NewRecorder()
+		NewHandler().ServeHTTP(rw, newRequestWithEngine(
+			engine, http.MethodPatch, "/v1/exec-mixes/journeys", bytes.NewReader(body),
+		))
+		return rw
+	}
+
+	rw = patch(map[string]float64{"buy": 9})
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	var mix ExecMixJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &mix))
+	assert.Equal(t, ExecMix{Scenario: "journeys", Weights: map[string]float64{"browse": 7, "buy": 9}}, mix.ExecMix())
+
+	assert.Equal(t, http.StatusBadRequest, patch(map[string]float64{"checkout": 1}).Result().StatusCode)
+	assert.Equal(t, http.StatusBadRequest, patch(map[string]float64{"browse": 0, "buy": 0}).Result().StatusCode)
+
+	rw = httptest.NewRecorder()
+	NewHandler().ServeHTTP(rw, newRequestWithEngine(engine, http.MethodGet, "/v1/exec-mixes/journeys", nil))
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &mix))
+	assert.Equal(t, map[string]float64{"browse": 7, "buy": 9}, mix.ExecMix().Weights)
+}
@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"strconv"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/output"
+)
+
+// Output contains the run-time state of an output, which can be paused and
+// resumed, or sampled, during the test run.
+type Output struct {
+	ID            string     `json:"-" yaml:"id"`
+	Description   string     `json:"description" yaml:"description"`
+	Paused        null.Bool  `json:"paused" yaml:"paused"`
+	SamplingRatio null.Float `json:"samplingRatio" yaml:"samplingRatio"`
+}
+
+// NewOutput returns the v1.Output representation of the given output status.
+func NewOutput(status output.Status) Output {
+	return Output{
+		ID:            strconv.Itoa(status.ID),
+		Description:   status.Description,
+		Paused:        null.BoolFrom(status.Paused),
+		SamplingRatio: null.FloatFrom(status.SamplingRatio),
+	}
+}
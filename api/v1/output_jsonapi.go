@@ -0,0 +1,64 @@
+package v1
+
+// OutputsJSONAPI is JSON API envelop for multiple outputs
+type OutputsJSONAPI struct {
+	Data []outputData `json:"data"`
+}
+
+// OutputJSONAPI is JSON API envelop for a single output
+type OutputJSONAPI struct {
+	Data outputData `json:"data"`
+}
+
+type outputData struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Attributes Output `json:"attributes"`
+}
+
+// NewOutputJSONAPI creates the JSON API output envelop
+func NewOutputJSONAPI(o Output) OutputJSONAPI {
+	return OutputJSONAPI{
+		Data: newOutputData(o),
+	}
+}
+
+func newOutputsJSONAPI(list []Output) OutputsJSONAPI {
+	outputs := make([]outputData, 0, len(list))
+
+	for _, o := range list {
+		outputs = append(outputs, newOutputData(o))
+	}
+
+	return OutputsJSONAPI{
+		Data: outputs,
+	}
+}
+
+func newOutputData(o Output) outputData {
+	return outputData{
+		Type:       "outputs",
+		ID:         o.ID,
+		Attributes: o,
+	}
+}
+
+// Output extracts the v1.Output from the JSON API envelop
+func (o OutputJSONAPI) Output() Output {
+	out := o.Data.Attributes
+	out.ID = o.Data.ID
+	return out
+}
+
+// Outputs extracts the []v1.Output from the JSON API envelop
+func (o OutputsJSONAPI) Outputs() []Output {
+	list := make([]Output, 0, len(o.Data))
+
+	for _, data := range o.Data {
+		out := data.Attributes
+		out.ID = data.ID
+		list = append(list, out)
+	}
+
+	return list
+}
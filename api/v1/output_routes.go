@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"go.k6.io/k6/api/common"
+)
+
+func handleGetOutputs(rw http.ResponseWriter, r *http.Request) {
+	engine := common.GetEngine(r.Context())
+
+	statuses := engine.OutputManager.GetStatuses()
+	list := make([]Output, 0, len(statuses))
+	for _, status := range statuses {
+		list = append(list, NewOutput(status))
+	}
+
+	data, err := json.Marshal(newOutputsJSONAPI(list))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+func handleGetOutput(rw http.ResponseWriter, r *http.Request, id string) {
+	engine := common.GetEngine(r.Context())
+
+	numID, err := strconv.Atoi(id)
+	if err != nil {
+		apiError(rw, "Not Found", "No output with that ID was found", http.StatusNotFound)
+		return
+	}
+	status, err := engine.OutputManager.GetStatus(numID)
+	if err != nil {
+		apiError(rw, "Not Found", err.Error(), http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(NewOutputJSONAPI(NewOutput(status)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+func handlePatchOutput(rw http.ResponseWriter, r *http.Request, id string) {
+	engine := common.GetEngine(r.Context())
+
+	numID, err := strconv.Atoi(id)
+	if err != nil {
+		apiError(rw, "Not Found", "No output with that ID was found", http.StatusNotFound)
+		return
+	}
+	if _, err = engine.OutputManager.GetStatus(numID); err != nil {
+		apiError(rw, "Not Found", err.Error(), http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var envelop OutputJSONAPI
+	if err = json.Unmarshal(body, &envelop); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out := envelop.Output()
+	status, err := engine.OutputManager.UpdateStatus(numID, out.Paused, out.SamplingRatio)
+	if err != nil {
+		apiError(rw, "Output update error", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := json.Marshal(NewOutputJSONAPI(NewOutput(status)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/core"
+	"go.k6.io/k6/core/local"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/lib/testutils/mockoutput"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+func TestOutputRoutes(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	execScheduler, err := local.NewExecutionScheduler(&minirunner.MiniRunner{}, builtinMetrics, logger)
+	require.NoError(t, err)
+	mockOut := mockoutput.New()
+	mockOut.DescFn = func() string { return "json (raw.json)" }
+	engine, err := core.NewEngine(
+		execScheduler, lib.Options{}, lib.RuntimeOptions{}, []output.Output{mockOut}, logger, registry,
+	)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rw, newRequestWithEngine(engine, http.MethodGet, "/v1/outputs", nil))
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	var list OutputsJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &list))
+	// the engine's own output for the thresholds and the summary isn't listed
+	assert.Equal(t, []Output{{
+		ID: "0", Description: "json (raw.json)", Paused: null.BoolFrom(false), SamplingRatio: null.FloatFrom(1),
+	}}, list.Outputs())
+
+	for _, path := range []string{"/v1/outputs/1", "/v1/outputs/foo"} {
+		rw = httptest.NewRecorder()
+		NewHandler().ServeHTTP(rw, newRequestWithEngine(engine, http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rw.Result().StatusCode, path)
+	}
+
+	patch := func(o Output) *httptest.ResponseRecorder {
+		body, err := json.Marshal(NewOutputJSONAPI(o))
+		require.NoError(t, err)
+		rw := httptest.NewRecorder()
+		NewHandler().ServeHTTP(rw, newRequestWithEngine(engine, http.MethodPatch, "/v1/outputs/0", bytes.NewReader(body)))
+		return rw
+	}
+
+	rw = patch(Output{ID: "0", Paused: null.BoolFrom(true)})
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	var out OutputJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &out))
+	assert.Equal(t, Output{
+		ID: "0", Description: "json (raw.json)", Paused: null.BoolFrom(true), SamplingRatio: null.FloatFrom(1),
+	}, out.Output())
+
+	rw = patch(Output{ID: "0", SamplingRatio: null.FloatFrom(0.1)})
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &out))
+	assert.Equal(t, null.BoolFrom(true), out.Output().Paused)
+	assert.Equal(t, null.FloatFrom(0.1), out.Output().SamplingRatio)
+
+	assert.Equal(t, http.StatusBadRequest, patch(Output{ID: "0", SamplingRatio: null.FloatFrom(2)}).Result().StatusCode)
+
+	engine.OutputManager.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{}})
+	assert.Empty(t, mockOut.SampleContainers)
+}
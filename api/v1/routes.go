@@ -76,6 +76,57 @@ func NewHandler() http.Handler {
 		handleGetGroup(rw, r, id)
 	})
 
+	mux.HandleFunc("/v1/exec-mixes", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleGetExecMixes(rw, r)
+	})
+
+	mux.HandleFunc("/v1/exec-mixes/", func(rw http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/v1/exec-mixes/"):]
+		switch r.Method {
+		case http.MethodGet:
+			handleGetExecMix(rw, r, id)
+		case http.MethodPatch:
+			handlePatchExecMix(rw, r, id)
+		default:
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/vus", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleGetVUs(rw, r)
+	})
+
+	mux.HandleFunc("/v1/outputs", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleGetOutputs(rw, r)
+	})
+
+	mux.HandleFunc("/v1/outputs/", func(rw http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/v1/outputs/"):]
+		switch r.Method {
+		case http.MethodGet:
+			handleGetOutput(rw, r, id)
+		case http.MethodPatch:
+			handlePatchOutput(rw, r, id)
+		default:
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/v1/setup", func(rw http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
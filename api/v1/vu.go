@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+)
+
+// VU contains what an initialized VU is currently doing.
+type VU struct {
+	ID           uint64         `json:"-" yaml:"id"`
+	Scenario     string         `json:"scenario" yaml:"scenario"`
+	Iteration    int64          `json:"iteration" yaml:"iteration"`
+	Group        string         `json:"group" yaml:"group"`
+	URL          string         `json:"url" yaml:"url"`
+	Status       lib.VUStatus   `json:"status" yaml:"status"`
+	TimeInStatus types.Duration `json:"time-in-status" yaml:"time-in-status"`
+}
+
+// NewVU returns the v1.VU for the given VU activity snapshot.
+func NewVU(s lib.VUActivitySnapshot, now time.Time) VU {
+	return VU{
+		ID:           s.ID,
+		Scenario:     s.Scenario,
+		Iteration:    s.Iteration,
+		Group:        s.Group,
+		URL:          s.URL,
+		Status:       s.Status,
+		TimeInStatus: types.Duration(s.TimeInStatus(now)),
+	}
+}
@@ -0,0 +1,43 @@
+package v1
+
+import "strconv"
+
+// VUsJSONAPI is JSON API envelop for multiple VUs
+type VUsJSONAPI struct {
+	Data []vuData `json:"data"`
+}
+
+type vuData struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Attributes VU     `json:"attributes"`
+}
+
+func newVUsJSONAPI(list []VU) VUsJSONAPI {
+	vus := make([]vuData, 0, len(list))
+
+	for _, vu := range list {
+		vus = append(vus, vuData{
+			Type:       "vus",
+			ID:         strconv.FormatUint(vu.ID, 10),
+			Attributes: vu,
+		})
+	}
+
+	return VUsJSONAPI{
+		Data: vus,
+	}
+}
+
+// VUs extracts the []v1.VU from the JSON API envelop
+func (v VUsJSONAPI) VUs() []VU {
+	list := make([]VU, 0, len(v.Data))
+
+	for _, data := range v.Data {
+		vu := data.Attributes
+		vu.ID, _ = strconv.ParseUint(data.ID, 10, 64)
+		list = append(list, vu)
+	}
+
+	return list
+}
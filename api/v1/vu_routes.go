@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.k6.io/k6/api/common"
+)
+
+func handleGetVUs(rw http.ResponseWriter, r *http.Request) {
+	engine := common.GetEngine(r.Context())
+
+	now := time.Now()
+	activities := engine.ExecutionScheduler.GetState().GetVUActivities()
+	vus := make([]VU, 0, len(activities))
+	for _, activity := range activities {
+		vus = append(vus, NewVU(activity, now))
+	}
+
+	data, err := json.Marshal(newVUsJSONAPI(vus))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
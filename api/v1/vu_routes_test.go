@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/core"
+	"go.k6.io/k6/core/local"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+type activityVU struct {
+	lib.InitializedVU
+	activity *lib.VUActivity
+}
+
+func (vu activityVU) GetID() uint64                { return vu.activity.Snapshot().ID }
+func (vu activityVU) GetActivity() *lib.VUActivity { return vu.activity }
+
+func TestGetVUs(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+	var scenarios lib.ScenarioConfigs
+	err := json.Unmarshal([]byte(`{"default": {"executor": "constant-vus", "vus": 3, "duration": "10s"}}`), &scenarios)
+	require.NoError(t, err)
+	options := lib.Options{Scenarios: scenarios}
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	execScheduler, err := local.NewExecutionScheduler(&minirunner.MiniRunner{Options: options}, builtinMetrics, logger)
+	require.NoError(t, err)
+	engine, err := core.NewEngine(execScheduler, options, lib.RuntimeOptions{}, nil, logger, registry)
+	require.NoError(t, err)
+
+	running, idle := lib.NewVUActivity(2), lib.NewVUActivity(1)
+	running.Activate("default")
+	running.StartIteration(5)
+	running.SetGroup("::login")
+	running.SetURL("https://test.k6.io/login")
+	idle.Activate("default")
+	es := execScheduler.GetState()
+	es.AddInitializedVU(activityVU{activity: running})
+	es.AddInitializedVU(activityVU{activity: idle})
+	es.AddInitializedVU(&minirunner.VU{ID: 3}) // doesn't report its activity
+
+	rw := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rw, newRequestWithEngine(engine, http.MethodGet, "/v1/vus", nil))
+	res := rw.Result()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var envelop VUsJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &envelop))
+	vus := envelop.VUs()
+	require.Len(t, vus, 2)
+	for i := range vus {
+		assert.GreaterOrEqual(t, vus[i].TimeInStatus, types.Duration(0))
+		vus[i].TimeInStatus = 0
+	}
+	assert.Equal(t, []VU{
+		{ID: 1, Scenario: "default", Iteration: -1, Status: lib.VUStatusIdle},
+		{
+			ID: 2, Scenario: "default", Iteration: 5, Group: "::login",
+			URL: "https://test.k6.io/login", Status: lib.VUStatusRunning,
+		},
+	}, vus)
+}
@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -39,6 +40,7 @@ import (
 	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/executor"
+	"go.k6.io/k6/lib/preflight"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
@@ -49,6 +51,10 @@ func configFlagSet() *pflag.FlagSet {
 	flags.SortFlags = false
 	flags.StringArrayP("out", "o", []string{}, "`uri` for an external metrics database")
 	flags.BoolP("linger", "l", false, "keep the API server alive past test end")
+	flags.Duration("abort-after-grace", 0, "forcefully abort the test if it hasn't stopped this long after a Ctrl+C, 0 to wait indefinitely") //nolint:lll
+	flags.Int64("gomaxprocs", 0, "maximum number of CPUs that execute the VUs at the same time, "+
+		"0 for the number of usable CPUs")
+	flags.String("cpu-affinity", "", "restrict k6 to a `list` of CPUs like 0-7,16-23, e.g. to a single NUMA node (Linux only)")
 	flags.Bool("no-usage-report", false, "don't send anonymous stats to the developers")
 	return flags
 }
@@ -57,9 +63,12 @@ func configFlagSet() *pflag.FlagSet {
 type Config struct {
 	lib.Options
 
-	Out           []string  `json:"out" envconfig:"K6_OUT"`
-	Linger        null.Bool `json:"linger" envconfig:"K6_LINGER"`
-	NoUsageReport null.Bool `json:"noUsageReport" envconfig:"K6_NO_USAGE_REPORT"`
+	Out             []string           `json:"out" envconfig:"K6_OUT"`
+	Linger          null.Bool          `json:"linger" envconfig:"K6_LINGER"`
+	AbortAfterGrace types.NullDuration `json:"abortAfterGrace" envconfig:"K6_ABORT_AFTER_GRACE"`
+	GOMAXPROCS      null.Int           `json:"gomaxprocs" envconfig:"K6_GOMAXPROCS"`
+	CPUAffinity     null.String        `json:"cpuAffinity" envconfig:"K6_CPU_AFFINITY"`
+	NoUsageReport   null.Bool          `json:"noUsageReport" envconfig:"K6_NO_USAGE_REPORT"`
 
 	// TODO: deprecate
 	Collectors map[string]json.RawMessage `json:"collectors"`
@@ -68,6 +77,14 @@ type Config struct {
 // Validate checks if all of the specified options make sense
 func (c Config) Validate() []error {
 	errors := c.Options.Validate()
+	if c.GOMAXPROCS.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("gomaxprocs can't be negative"))
+	}
+	if c.CPUAffinity.String != "" {
+		if _, err := preflight.ParseCPUList(c.CPUAffinity.String); err != nil {
+			errors = append(errors, fmt.Errorf("invalid cpuAffinity: %w", err))
+		}
+	}
 	// TODO: validate all of the other options... that we should have already been validating...
 	// TODO: maybe integrate an external validation lib: https://github.com/avelino/awesome-go#validation
 
@@ -83,6 +100,15 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.Linger.Valid {
 		c.Linger = cfg.Linger
 	}
+	if cfg.AbortAfterGrace.Valid {
+		c.AbortAfterGrace = cfg.AbortAfterGrace
+	}
+	if cfg.GOMAXPROCS.Valid {
+		c.GOMAXPROCS = cfg.GOMAXPROCS
+	}
+	if cfg.CPUAffinity.Valid {
+		c.CPUAffinity = cfg.CPUAffinity
+	}
 	if cfg.NoUsageReport.Valid {
 		c.NoUsageReport = cfg.NoUsageReport
 	}
@@ -113,10 +139,13 @@ func getConfig(flags *pflag.FlagSet) (Config, error) {
 		return Config{}, err
 	}
 	return Config{
-		Options:       opts,
-		Out:           out,
-		Linger:        getNullBool(flags, "linger"),
-		NoUsageReport: getNullBool(flags, "no-usage-report"),
+		Options:         opts,
+		Out:             out,
+		Linger:          getNullBool(flags, "linger"),
+		AbortAfterGrace: getNullDuration(flags, "abort-after-grace"),
+		GOMAXPROCS:      getNullInt64(flags, "gomaxprocs"),
+		CPUAffinity:     getNullString(flags, "cpu-affinity"),
+		NoUsageReport:   getNullBool(flags, "no-usage-report"),
 	}, nil
 }
 
@@ -239,6 +268,51 @@ func applyDefault(conf Config) Config {
 	return conf
 }
 
+// expandThresholdMacros resolves the environment variable macros in the
+// metric name expressions of the thresholds, e.g.
+// `http_req_duration{env:${ENVIRONMENT}}`, with the supplied script
+// environment. This allows sharing the same threshold definitions between
+// test runs in environments that tag their samples differently.
+func expandThresholdMacros(conf Config, env map[string]string) (Config, error) {
+	if len(conf.Thresholds) == 0 {
+		return conf, nil
+	}
+
+	thresholds, err := expandThresholdMacrosMap(conf.Thresholds, env)
+	if err != nil {
+		return conf, err
+	}
+	conf.Thresholds = thresholds
+
+	return conf, nil
+}
+
+// expandThresholdMacrosMap returns a copy of the thresholds map, with the
+// macros in its metric name expressions resolved with the supplied env.
+func expandThresholdMacrosMap(
+	thresholds map[string]metrics.Thresholds, env map[string]string,
+) (map[string]metrics.Thresholds, error) {
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	result := make(map[string]metrics.Thresholds, len(thresholds))
+	for name, ths := range thresholds {
+		expandedName, err := metrics.ExpandMetricNameMacros(name, lookup)
+		if err != nil {
+			return nil, errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+		}
+		if _, ok := result[expandedName]; ok {
+			return nil, errext.WithExitCodeIfNone(fmt.Errorf(
+				"threshold metric '%s' resolves to '%s', which is already defined", name, expandedName,
+			), exitcodes.InvalidConfig)
+		}
+		result[expandedName] = ths
+	}
+
+	return result, nil
+}
+
 func deriveAndValidateConfig(
 	conf Config, isExecutable func(string) bool, logger logrus.FieldLogger,
 ) (result Config, err error) {
@@ -276,6 +350,27 @@ func consolidateErrorMessage(errList []error, title string) error {
 }
 
 func validateScenarioConfig(conf lib.ExecutorConfig, isExecutable func(string) bool) error {
+	if journey, ok := conf.(executor.JourneyConfig); ok {
+		for _, state := range journey.GetStates() {
+			if !isExecutable(state) {
+				return fmt.Errorf("executor %s: journey state function '%s' not found in exports", conf.GetName(), state)
+			}
+		}
+		return nil
+	}
+	if execMix := conf.GetExecMix(); len(execMix) > 0 {
+		names := make([]string, 0, len(execMix))
+		for execFn := range execMix {
+			names = append(names, execFn)
+		}
+		sort.Strings(names)
+		for _, execFn := range names {
+			if !isExecutable(execFn) {
+				return fmt.Errorf("executor %s: execMix function '%s' not found in exports", conf.GetName(), execFn)
+			}
+		}
+		return nil
+	}
 	execFn := conf.GetExec()
 	if !isExecutable(execFn) {
 		return fmt.Errorf("executor %s: function '%s' not found in exports", conf.GetName(), execFn)
@@ -26,6 +26,7 @@ import (
 
 	"github.com/mstoykov/envconfig"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/errext"
@@ -33,6 +34,7 @@ import (
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/executor"
 	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
 )
 
 type testCmdData struct {
@@ -99,6 +101,18 @@ func TestConfigEnv(t *testing.T) {
 			"true":  func(c Config) { assert.Equal(t, null.BoolFrom(true), c.Linger) },
 			"false": func(c Config) { assert.Equal(t, null.BoolFrom(false), c.Linger) },
 		},
+		{"AbortAfterGrace", "K6_ABORT_AFTER_GRACE"}: {
+			"":    func(c Config) { assert.Equal(t, types.NullDuration{}, c.AbortAfterGrace) },
+			"30s": func(c Config) { assert.Equal(t, types.NullDurationFrom(30*time.Second), c.AbortAfterGrace) },
+		},
+		{"GOMAXPROCS", "K6_GOMAXPROCS"}: {
+			"":  func(c Config) { assert.Equal(t, null.Int{}, c.GOMAXPROCS) },
+			"4": func(c Config) { assert.Equal(t, null.IntFrom(4), c.GOMAXPROCS) },
+		},
+		{"CPUAffinity", "K6_CPU_AFFINITY"}: {
+			"":      func(c Config) { assert.Equal(t, null.String{}, c.CPUAffinity) },
+			"0-3,8": func(c Config) { assert.Equal(t, null.StringFrom("0-3,8"), c.CPUAffinity) },
+		},
 		{"NoUsageReport", "K6_NO_USAGE_REPORT"}: {
 			"":      func(c Config) { assert.Equal(t, null.Bool{}, c.NoUsageReport) },
 			"true":  func(c Config) { assert.Equal(t, null.BoolFrom(true), c.NoUsageReport) },
@@ -196,6 +210,36 @@ func TestDeriveAndValidateConfig(t *testing.T) {
 			false,
 			"executor per_vu_iters: function 'nonDefaultErr' not found in exports",
 		},
+		{
+			"execMixErr",
+			Config{Options: lib.Options{Scenarios: lib.ScenarioConfigs{
+				"per_vu_iters": executor.PerVUIterationsConfig{
+					BaseConfig: executor.BaseConfig{
+						Name: "per_vu_iters", Type: "per-vu-iterations",
+						ExecMix: map[string]float64{"browse": 1, "buy": 2},
+					},
+					VUs:         null.IntFrom(1),
+					Iterations:  null.IntFrom(1),
+					MaxDuration: types.NullDurationFrom(time.Second),
+				},
+			}}},
+			false,
+			"executor per_vu_iters: execMix function 'browse' not found in exports",
+		},
+		{
+			"journeyErr",
+			Config{Options: lib.Options{Scenarios: lib.ScenarioConfigs{
+				"journey": executor.JourneyConfig{
+					BaseConfig: executor.BaseConfig{Name: "journey", Type: "journey"},
+					VUs:        null.IntFrom(1),
+					Duration:   types.NullDurationFrom(time.Second),
+					Start:      null.StringFrom("home"),
+					States:     map[string]executor.JourneyState{"home": {}},
+				},
+			}}},
+			false,
+			"executor journey: journey state function 'home' not found in exports",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -215,3 +259,47 @@ func TestDeriveAndValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandThresholdMacros(t *testing.T) {
+	t.Parallel()
+
+	newThresholds := func(src string) metrics.Thresholds {
+		return metrics.NewThresholds([]string{src})
+	}
+
+	t.Run("resolved", func(t *testing.T) {
+		t.Parallel()
+		conf := Config{Options: lib.Options{Thresholds: map[string]metrics.Thresholds{
+			"http_req_duration{env:${ENVIRONMENT}}": newThresholds("p(95)<200"),
+			"http_req_failed":                       newThresholds("rate<0.01"),
+		}}}
+		original := conf.Thresholds
+
+		result, err := expandThresholdMacros(conf, map[string]string{"ENVIRONMENT": "staging"})
+		require.NoError(t, err)
+		assert.Contains(t, result.Thresholds, "http_req_duration{env:staging}")
+		assert.Contains(t, result.Thresholds, "http_req_failed")
+		assert.Len(t, result.Thresholds, 2)
+		assert.Contains(t, original, "http_req_duration{env:${ENVIRONMENT}}", "the original map was modified")
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		t.Parallel()
+		conf := Config{Options: lib.Options{Thresholds: map[string]metrics.Thresholds{
+			"http_req_duration{env:${ENVIRONMENT}}": newThresholds("p(95)<200"),
+		}}}
+		_, err := expandThresholdMacros(conf, nil)
+		require.ErrorIs(t, err, metrics.ErrMetricNameMacro)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		t.Parallel()
+		conf := Config{Options: lib.Options{Thresholds: map[string]metrics.Thresholds{
+			"http_req_duration{env:${ENVIRONMENT}}": newThresholds("p(95)<200"),
+			"http_req_duration{env:staging}":        newThresholds("p(95)<300"),
+		}}}
+		_, err := expandThresholdMacros(conf, map[string]string{"ENVIRONMENT": "staging"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already defined")
+	})
+}
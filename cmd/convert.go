@@ -43,6 +43,7 @@ func getCmdConvert(globalState *globalState) *cobra.Command {
 		enableChecks        bool
 		returnOnFailedCheck bool
 		correlate           bool
+		correlateWith       string
 		threshold           uint
 		nobatch             bool
 		only                []string
@@ -62,6 +63,9 @@ func getCmdConvert(globalState *globalState) *cobra.Command {
   # Convert a HAR file. Batching requests together as long as idle time between requests <800ms
   k6 convert --batch-threshold 800 session.har
 
+  # Convert a HAR file, extracting the values that differ from a second recording of the same flow.
+  k6 convert --no-batch --correlate-with session2.har session.har
+
   # Run the k6 script.
   k6 run har-session.js`[1:],
 		Args: cobra.ExactArgs(1),
@@ -79,6 +83,22 @@ func getCmdConvert(globalState *globalState) *cobra.Command {
 				return err
 			}
 
+			var correlationHAR *har.HAR
+			if correlateWith != "" {
+				cr, err := globalState.fs.Open(correlateWith)
+				if err != nil {
+					return err
+				}
+				ch, err := har.Decode(cr)
+				if err != nil {
+					return err
+				}
+				if err = cr.Close(); err != nil {
+					return err
+				}
+				correlationHAR = &ch
+			}
+
 			// recordings include redirections as separate requests, and we dont want to trigger them twice
 			options := lib.Options{MaxRedirects: null.IntFrom(0)}
 
@@ -96,7 +116,7 @@ func getCmdConvert(globalState *globalState) *cobra.Command {
 
 			// TODO: refactor...
 			script, err := har.Convert(h, options, minSleep, maxSleep, enableChecks,
-				returnOnFailedCheck, threshold, nobatch, correlate, only, skip)
+				returnOnFailedCheck, threshold, nobatch, correlate, correlationHAR, only, skip)
 			if err != nil {
 				return err
 			}
@@ -141,6 +161,7 @@ func getCmdConvert(globalState *globalState) *cobra.Command {
 	convertCmd.Flags().BoolVarP(&enableChecks, "enable-status-code-checks", "", false, "add a status code check for each HTTP response")                                                                          //nolint:lll
 	convertCmd.Flags().BoolVarP(&returnOnFailedCheck, "return-on-failed-check", "", false, "return from iteration if we get an unexpected response status code")                                                  //nolint:lll
 	convertCmd.Flags().BoolVarP(&correlate, "correlate", "", false, "detect values in responses being used in subsequent requests and try adapt the script accordingly (only redirects and JSON values for now)") //nolint:lll
+	convertCmd.Flags().StringVarP(&correlateWith, "correlate-with", "", "", "a second recording of the same flow, values that differ between the recordings are extracted from previous responses")               //nolint:lll
 	convertCmd.Flags().UintVarP(&minSleep, "min-sleep", "", 20, "the minimum amount of seconds to sleep after each iteration")                                                                                    //nolint:lll
 	convertCmd.Flags().UintVarP(&maxSleep, "max-sleep", "", 40, "the maximum amount of seconds to sleep after each iteration")                                                                                    //nolint:lll
 	return convertCmd
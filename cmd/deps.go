@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.k6.io/k6/loader"
+)
+
+// getCmdDeps returns the `k6 deps` sub-command, together with its children.
+func getCmdDeps(gs *globalState) *cobra.Command {
+	depsCmd := &cobra.Command{
+		Use:   "deps",
+		Short: "Manage the remote modules of a script",
+		Long:  `Manage the remote modules of a script.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+	depsCmd.AddCommand(getCmdDepsVendor(gs))
+
+	return depsCmd
+}
+
+func getCmdDepsVendor(gs *globalState) *cobra.Command {
+	vendorCmd := &cobra.Command{
+		Use:   "vendor [file]",
+		Short: "Vendor the remote modules of a script",
+		Long: fmt.Sprintf(`Vendor the remote modules of a script.
+
+All of the remote modules imported by the script, e.g. from jslib.k6.io, are
+stored in the '%[1]s' directory next to it, together with a '%[2]s' manifest
+with their integrity hashes. When the script is executed afterwards, the vendored
+copies are used instead of fetching the modules again, after verifying that they
+weren't modified. This makes the tests reproducible and allows running them
+without internet access.
+
+The modules are vendored at the exact URLs that the script imports, so they
+should contain a version, e.g. https://jslib.k6.io/k6-utils/1.4.0/index.js. To
+update a module, change its URL in the script and vendor the modules again. The
+existing vendored copies are never reused, every module is fetched again.`,
+			loader.VendorDir, loader.VendorManifestFile),
+		Example: `
+  # Vendor the remote modules of script.js in the ./vendor directory.
+  k6 deps vendor script.js
+
+  # Run the script with the vendored modules.
+  k6 run script.js`[1:],
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the existing vendored modules are ignored, so the current
+			// versions of the remote modules are vendored
+			test, err := loadTest(gs, cmd, args, nil, false)
+			if err != nil {
+				return err
+			}
+
+			manifest, err := loader.VendorModules(gs.fs, test.source.URL, test.fileSystems["https"])
+			if err != nil {
+				return err
+			}
+
+			var report strings.Builder
+			for _, modulePath := range manifest.Paths() {
+				fmt.Fprintf(&report, "%s %s\n", modulePath, manifest.Modules[modulePath].Integrity)
+			}
+			fmt.Fprintf(&report, "Vendored %d remote modules in '%s'\n", len(manifest.Modules),
+				filepath.Join(filepath.Dir(test.sourceRootPath), loader.VendorDir))
+			printToStdout(gs, report.String())
+			return nil
+		},
+	}
+
+	vendorCmd.Flags().SortFlags = false
+	vendorCmd.Flags().AddFlagSet(runtimeOptionFlagSet(false))
+
+	return vendorCmd
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/preflight"
+)
+
+func getCmdDoctor(gs *globalState) *cobra.Command {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor [file]",
+		Short: "Check whether the environment is ready for a test run",
+		Long: `Check whether the environment is ready for a test run.
+
+Reports the properties of the environment that are relevant for running many
+VUs, like the OS limits for open files and ephemeral ports, the connection
+tracking table size, the available memory, the number of usable CPUs and
+whether the system clock is synchronized. If a script or an archive is
+specified, they are compared with what its maximum number of VUs would
+probably need.
+
+The connectivity and the latency to the outputs with an URL and to the
+specified targets are checked as well.`,
+		Example: `
+  # Check the environment
+  k6 doctor
+
+  # Check whether the environment is ready for a script and its outputs
+  k6 doctor --out influxdb=http://localhost:8086/k6 script.js
+
+  # Check the connectivity to the system under test
+  k6 doctor --target https://test.k6.io --target 10.0.0.1:8080`[1:],
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var maxVUs uint64
+			outputs, err := cmd.Flags().GetStringArray("out")
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				test, err := loadAndConfigureTest(gs, cmd, args, getDoctorConfig)
+				if err != nil {
+					return err
+				}
+				if maxVUs, err = getMaxPlannedVUs(test); err != nil {
+					return err
+				}
+				outputs = test.derivedConfig.Out
+			}
+
+			targets, err := cmd.Flags().GetStringArray("target")
+			if err != nil {
+				return err
+			}
+			addresses, err := getDoctorAddresses(outputs, targets)
+			if err != nil {
+				return err
+			}
+
+			env, err := preflight.GetEnvironment()
+			if err != nil {
+				return fmt.Errorf("couldn't get the environment properties: %w", err)
+			}
+			diagnostics := preflight.CheckEnvironment(env, maxVUs)
+			diagnostics = append(diagnostics, preflight.CheckCPUs(
+				preflight.UsableCPUs(runtime.GOMAXPROCS(0), env.ContainerCPUs), maxVUs))
+			diagnostics = append(diagnostics, preflight.CheckConnectivity(
+				gs.ctx, addresses, doctorConnectTimeout)...)
+			printToStdout(gs, formatDiagnostics(diagnostics))
+			return nil
+		},
+	}
+
+	doctorCmd.Flags().SortFlags = false
+	doctorCmd.Flags().AddFlagSet(optionFlagSet())
+	doctorCmd.Flags().AddFlagSet(runtimeOptionFlagSet(false))
+	doctorCmd.Flags().StringArrayP("out", "o", []string{}, "`uri` for an external metrics database to check")
+	doctorCmd.Flags().StringArray("target", []string{},
+		"`url or host:port` of a system under test to check, can be used multiple times")
+
+	return doctorCmd
+}
+
+func getMaxPlannedVUs(test *loadedTest) (uint64, error) {
+	et, err := lib.NewExecutionTuple(test.derivedConfig.ExecutionSegment, test.derivedConfig.ExecutionSegmentSequence)
+	if err != nil {
+		return 0, err
+	}
+	return lib.GetMaxPossibleVUs(test.derivedConfig.Scenarios.GetFullExecutionRequirements(et)), nil
+}
+
+// getDoctorConfig is like getPartialConfig, but includes the outputs too.
+func getDoctorConfig(flags *pflag.FlagSet) (Config, error) {
+	conf, err := getPartialConfig(flags)
+	if err != nil {
+		return Config{}, err
+	}
+	if conf.Out, err = flags.GetStringArray("out"); err != nil {
+		return Config{}, err
+	}
+	return conf, nil
+}
+
+// doctorConnectTimeout is how long k6 doctor waits for each connection.
+const doctorConnectTimeout = 5 * time.Second
+
+// getDoctorAddresses returns the host:port addresses of the outputs which have
+// an URL argument and of the targets, without duplicates.
+func getDoctorAddresses(outputs, targets []string) ([]string, error) {
+	var addresses []string
+	seen := make(map[string]bool)
+	add := func(address string) {
+		if address != "" && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	for _, out := range outputs {
+		_, arg := parseOutputArgument(out)
+		if !strings.Contains(arg, "://") {
+			continue // e.g. a file name or the default address of the output
+		}
+		address, err := getAddress(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output '%s': %w", out, err)
+		}
+		add(address)
+	}
+	for _, target := range targets {
+		address, err := getAddress(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target '%s': %w", target, err)
+		}
+		add(address)
+	}
+	return addresses, nil
+}
+
+// getAddress returns the host:port address of an URL or the value itself, if
+// it's already in the host:port format.
+func getAddress(s string) (string, error) {
+	if !strings.Contains(s, "://") {
+		if _, _, err := net.SplitHostPort(s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if u.Hostname() == "" {
+		return "", errors.New("the URL doesn't have a host")
+	}
+	if u.Port() != "" {
+		return u.Host, nil
+	}
+	switch u.Scheme {
+	case "http", "ws":
+		return net.JoinHostPort(u.Hostname(), "80"), nil
+	case "https", "wss":
+		return net.JoinHostPort(u.Hostname(), "443"), nil
+	default:
+		return "", fmt.Errorf("the port for the '%s' scheme should be specified", u.Scheme)
+	}
+}
+
+func formatDiagnostics(diagnostics []preflight.Diagnostic) string {
+	var sb strings.Builder
+	for _, d := range diagnostics {
+		status := "OK"
+		if d.Warning != "" {
+			status = "WARN"
+		}
+		fmt.Fprintf(&sb, "%-4s %s: %s\n", status, d.Name, d.Value)
+		if d.Warning != "" {
+			fmt.Fprintf(&sb, "     %s\n", d.Warning)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/lib/preflight"
+)
+
+// warnAboutOSLimits logs the preflight warnings about OS limits which are
+// probably too low for the planned number of VUs.
+func warnAboutOSLimits(gs *globalState, maxVUs uint64) {
+	limits, err := preflight.GetLimits()
+	if err != nil {
+		gs.logger.WithError(err).Debug("Couldn't get the OS limits")
+		return
+	}
+	containerCPUs, _ := preflight.GetContainerLimits()
+	cpus := preflight.UsableCPUs(runtime.GOMAXPROCS(0), containerCPUs)
+	diagnostics := append(preflight.CheckLimits(limits, maxVUs), preflight.CheckCPUs(cpus, maxVUs))
+	for _, d := range diagnostics {
+		if d.Warning != "" {
+			gs.logger.Warnf("The %s is %s, but %s; run `k6 doctor` for more details", d.Name, d.Value, d.Warning)
+		}
+	}
+}
+
+// tuneCPUs restricts k6 to the CPUs of the cpuAffinity option and sets how
+// many of them can execute the VUs at the same time. Without an explicit
+// gomaxprocs, all of the CPUs in the affinity are used, since the Go runtime
+// determined its default before the affinity was changed, but no more than
+// the CPU quota of the container. The returned function restores the previous
+// settings.
+func tuneCPUs(gs *globalState, conf Config) (func(), error) {
+	procs := int(conf.GOMAXPROCS.Int64)
+	defaultProcs := runtime.GOMAXPROCS(0)
+	restoreAffinity := func() {}
+	if conf.CPUAffinity.String != "" {
+		cpus, err := preflight.ParseCPUList(conf.CPUAffinity.String)
+		if err != nil {
+			return nil, err
+		}
+		previous, err := preflight.GetCPUAffinity()
+		if err != nil {
+			return nil, err
+		}
+		if err = preflight.SetCPUAffinity(cpus); err != nil {
+			return nil, err
+		}
+		gs.logger.Debugf("Restricted k6 to the CPUs %v", cpus)
+		restoreAffinity = func() {
+			if err := preflight.SetCPUAffinity(previous); err != nil {
+				gs.logger.WithError(err).Debug("Couldn't restore the CPU affinity")
+			}
+		}
+		defaultProcs = len(cpus)
+	}
+
+	if procs == 0 {
+		containerCPUs, _ := preflight.GetContainerLimits()
+		procs = preflight.UsableCPUs(defaultProcs, containerCPUs)
+	}
+	if procs == runtime.GOMAXPROCS(0) {
+		return restoreAffinity, nil
+	}
+	previousProcs := runtime.GOMAXPROCS(procs)
+	gs.logger.Debugf("Set GOMAXPROCS to %d", procs)
+	return func() {
+		runtime.GOMAXPROCS(previousProcs)
+		restoreAffinity()
+	}, nil
+}
+
+// getRunEnvironment describes the k6 build and the machine for the summary
+// and the outputs. It should be called after tuneCPUs().
+func getRunEnvironment(gs *globalState) lib.RunEnvironment {
+	runEnv := lib.RunEnvironment{
+		K6Version:  consts.Version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CPUs:       runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		runEnv.Hostname = hostname
+	}
+	// only the properties that are needed are read, so e.g. the unknown
+	// ephemeral ports don't hide the container limits
+	if limits, err := preflight.GetLimits(); err == nil {
+		runEnv.OpenFilesLimit = limits.OpenFiles
+	} else {
+		gs.logger.WithError(err).Debug("Couldn't get the OS limits")
+	}
+	runEnv.ContainerCPULimit, runEnv.ContainerMemoryLimit = preflight.GetContainerLimits()
+	return runEnv
+}
@@ -3,18 +3,25 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"net/url"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/lib/consts"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/loader"
+	"go.k6.io/k6/metrics"
 )
 
 const (
@@ -308,7 +315,7 @@ func TestExecutionTestOptionsDefaultValues(t *testing.T) {
 	loglines := ts.loggerHook.Drain()
 	require.Len(t, loglines, 1)
 
-	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"noConnectionReuse":null,"noVUConnectionReuse":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"systemTags":["check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"discardResponseBodies":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null}},"localIPs":null}`
+	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"preflight":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"latency":null,"noConnectionReuse":null,"noVUConnectionReuse":null,"minIterationDuration":null,"cost":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"summaryTopSubmetrics":null,"summaryTransactions":null,"systemTags":["check","connect_to","error","error_code","expected_response","group","method","name","proto","scenario","service","sni","status","subproto","tls_version","url"],"tags":null,"metadata":null,"disabledMetrics":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"discardResponseBodies":null,"iterationBodyBytesBudget":null,"iterationBreakdown":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null,"execMix":null,"requestTimeout":null}},"localIPs":null}`
 	assert.JSONEq(t, expected, loglines[0].Message)
 }
 
@@ -342,3 +349,353 @@ func TestSubMetricThresholdNoData(t *testing.T) {
        { tag:xyz }........: 0   0/s
      two..................: 42`)
 }
+
+func TestDisabledMetrics(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "--iterations", "2", "--disabled-metrics", "vus_max,iteration_duration", "-"}
+	ts.stdIn = bytes.NewBufferString(noopDefaultFunc)
+	newRootCommand(ts.globalState).execute()
+
+	stdOut := ts.stdOut.String()
+	assert.Contains(t, stdOut, "iterations")
+	assert.NotContains(t, stdOut, "iteration_duration")
+	assert.NotContains(t, stdOut, "vus_max")
+
+	ts = newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "--disabled-metrics", "iteration_duration", "-"}
+	ts.stdIn = bytes.NewBufferString(`
+		export const options = { thresholds: { 'iteration_duration{scenario:default}': ['p(95)<100'] } };
+		export default function() {};
+	`)
+	ts.expectedExitCode = int(exitcodes.InvalidConfig)
+	newRootCommand(ts.globalState).execute()
+	assert.True(t, testutils.LogContains(ts.loggerHook.Drain(), logrus.ErrorLevel,
+		"the metric 'iteration_duration' is disabled, so it can't have thresholds"))
+
+	ts = newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "--disabled-metrics", "http_reqs", "-"}
+	ts.stdIn = bytes.NewBufferString(`
+		export const options = { cost: { perRequest: 0.01 } };
+		export default function() {};
+	`)
+	ts.expectedExitCode = int(exitcodes.InvalidConfig)
+	newRootCommand(ts.globalState).execute()
+	assert.True(t, testutils.LogContains(ts.loggerHook.Drain(), logrus.ErrorLevel,
+		"the metric 'http_reqs' is disabled, but the cost estimation is based on it"))
+}
+
+func TestIterationBreakdown(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "--iterations", "2", "-"}
+	ts.stdIn = bytes.NewBufferString(noopDefaultFunc)
+	newRootCommand(ts.globalState).execute()
+	assert.NotContains(t, ts.stdOut.String(), "iteration_script_duration")
+
+	ts = newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "--iterations", "2", "--iteration-breakdown", "-"}
+	ts.stdIn = bytes.NewBufferString(noopDefaultFunc)
+	newRootCommand(ts.globalState).execute()
+	stdOut := ts.stdOut.String()
+	assert.Contains(t, stdOut, "iteration_protocol_duration")
+	assert.Contains(t, stdOut, "iteration_script_duration")
+	assert.Contains(t, stdOut, "iteration_sleep_duration")
+
+	ts = newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "-"}
+	ts.stdIn = bytes.NewBufferString(`
+		export const options = { thresholds: { 'iteration_sleep_duration': ['p(95)<100'] } };
+		export default function() {};
+	`)
+	ts.expectedExitCode = int(exitcodes.InvalidConfig)
+	newRootCommand(ts.globalState).execute()
+	assert.True(t, testutils.LogContains(ts.loggerHook.Drain(), logrus.ErrorLevel,
+		"the metric 'iteration_sleep_duration' is only emitted with the iterationBreakdown option"))
+}
+
+func TestMetricsQuerySubmetrics(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "--iterations", "2", "-"}
+	ts.stdIn = bytes.NewBufferString(`
+		import { sleep } from 'k6';
+		import metrics, { Counter } from 'k6/metrics';
+		const counter = new Counter('my_counter');
+		metrics.addSubmetric('my_counter', 'kind:defined');
+		export default function() {
+			counter.add(1, { kind: 'defined' });
+			counter.add(1, { kind: 'undefined' });
+		};
+		export function teardown() {
+			sleep(0.5); // for the samples to be aggregated
+			console.log('defined=' + metrics.query('my_counter{kind:defined}', 'count'));
+			try {
+				metrics.query('my_counter{kind:undefined}', 'count');
+			} catch (e) {
+				console.log('undefined=' + e);
+			}
+		};
+	`)
+	newRootCommand(ts.globalState).execute()
+
+	logs := ts.loggerHook.Drain()
+	assert.True(t, testutils.LogContains(logs, logrus.InfoLevel, "defined=2"))
+	assert.True(t, testutils.LogContains(logs, logrus.InfoLevel,
+		"undefined=the sub-metric was created by the query, so it doesn't have the earlier samples of 'my_counter'"))
+}
+
+func TestSummaryMetadata(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "--metadata", "build=1234", "--metadata", "team=perf", "-"}
+	ts.stdIn = bytes.NewBufferString(`
+		export default function() {};
+		export function handleSummary(data) {
+			return { stdout: JSON.stringify([data.metadata.build, data.metadata.team, data.environment.k6_version]) };
+		}
+	`)
+	newRootCommand(ts.globalState).execute()
+	assert.True(t, strings.HasSuffix(ts.stdOut.String(), `["1234","perf","`+consts.Version+`"]`))
+}
+
+func TestSummaryMetadataFromEnv(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "-"}
+	ts.envVars = map[string]string{"K6_METADATA": "build=1234,team=perf"}
+	ts.stdIn = bytes.NewBufferString(`
+		export default function() {};
+		export function handleSummary(data) {
+			return { stdout: JSON.stringify([data.metadata.build, data.metadata.team]) };
+		}
+	`)
+	newRootCommand(ts.globalState).execute()
+	assert.True(t, strings.HasSuffix(ts.stdOut.String(), `["1234","perf"]`))
+}
+
+func TestInvalidCPUSettings(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	ts.args = []string{"k6", "run", "--gomaxprocs", "-1", "--cpu-affinity", "3-1", "-"}
+	ts.stdIn = bytes.NewBufferString(noopDefaultFunc)
+	ts.expectedExitCode = int(exitcodes.InvalidConfig)
+	newRootCommand(ts.globalState).execute()
+
+	logs := ts.loggerHook.Drain()
+	assert.True(t, testutils.LogContains(logs, logrus.ErrorLevel, "gomaxprocs can't be negative"))
+	assert.True(t, testutils.LogContains(logs, logrus.ErrorLevel, "invalid cpuAffinity: invalid CPU range '3-1' in '3-1'"))
+}
+
+func TestResultsExport(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	ts.args = []string{
+		"k6", "run", "--quiet", "--iterations", "2", "--metadata", "build=1234", "--out", "proto=results.pb", "-",
+	}
+	ts.stdIn = bytes.NewBufferString(noopDefaultFunc)
+	newRootCommand(ts.globalState).execute()
+
+	ts.args = []string{"k6", "results", "export", "--to", "json", "-O", "results.json", "results.pb"}
+	newRootCommand(ts.globalState).execute()
+
+	data, err := afero.ReadFile(ts.fs, "results.json")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var metadata struct {
+		Type string `json:"type"`
+		Data struct {
+			Environment struct {
+				K6Version string `json:"k6_version"`
+			} `json:"environment"`
+			Metadata map[string]string `json:"metadata"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &metadata), lines[0])
+	assert.Equal(t, "Metadata", metadata.Type)
+	assert.Equal(t, consts.Version, metadata.Data.Environment.K6Version)
+	assert.Equal(t, map[string]string{"build": "1234"}, metadata.Data.Metadata)
+
+	var metricLines, iterations int
+	for _, line := range lines[1:] {
+		var envelope struct {
+			Type   string `json:"type"`
+			Metric string `json:"metric"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &envelope), line)
+		if envelope.Type == "Metric" {
+			metricLines++
+		} else if envelope.Metric == metrics.IterationsName {
+			iterations++
+		}
+	}
+	assert.Positive(t, metricLines)
+	assert.Equal(t, 2, iterations)
+
+	ts = newGlobalTestState(t)
+	ts.args = []string{"k6", "results", "export", "--to", "csv", "results.pb"}
+	ts.expectedExitCode = -1
+	newRootCommand(ts.globalState).execute()
+	assert.True(t, testutils.LogContains(ts.loggerHook.Drain(), logrus.ErrorLevel,
+		"unsupported results format 'csv', only 'json' is supported"))
+}
+
+func TestDoctor(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	ts.args = []string{"k6", "doctor"}
+	newRootCommand(ts.globalState).execute()
+	stdOut := ts.stdOut.String()
+	assert.Contains(t, stdOut, "open files limit: ")
+	assert.Contains(t, stdOut, "ephemeral ports: ")
+	assert.Contains(t, stdOut, "available memory: ")
+	assert.Contains(t, stdOut, "system clock: ")
+	assert.NotContains(t, stdOut, "WARN open files limit")
+
+	ts = newGlobalTestState(t)
+	ts.args = []string{"k6", "doctor", "--vus", "100000000", "--duration", "10s", "-"}
+	ts.stdIn = bytes.NewBufferString(noopDefaultFunc)
+	newRootCommand(ts.globalState).execute()
+	if runtime.GOOS != "windows" {
+		assert.Contains(t, ts.stdOut.String(), "WARN open files limit: ")
+		assert.Contains(t, ts.stdOut.String(), "100000000 VUs may need around 200000100 open files")
+	}
+}
+
+func TestDoctorConnectivity(t *testing.T) {
+	t.Parallel()
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	ts := newGlobalTestState(t)
+	ts.args = []string{
+		"k6", "doctor", "--out", "json=results.json", "--out", "influxdb=" + tb.ServerHTTP.URL + "/k6",
+		"--target", tb.Replacer.Replace("HTTPBIN_IP:HTTPBIN_PORT"), "--target", tb.ServerHTTPS.URL,
+	}
+	newRootCommand(ts.globalState).execute()
+	stdOut := ts.stdOut.String()
+	assert.Contains(t, stdOut, "OK   connection to "+strings.TrimPrefix(tb.ServerHTTP.URL, "http://")+": ")
+	assert.Contains(t, stdOut, "OK   connection to "+strings.TrimPrefix(tb.ServerHTTPS.URL, "https://")+": ")
+	assert.Equal(t, 2, strings.Count(stdOut, "connection to "), "duplicate addresses should be checked once")
+
+	ts = newGlobalTestState(t)
+	ts.args = []string{"k6", "doctor", "--target", "ftp://example.com"}
+	ts.expectedExitCode = -1
+	newRootCommand(ts.globalState).execute()
+	assert.True(t, testutils.LogContains(ts.loggerHook.Drain(), logrus.ErrorLevel,
+		"the port for the 'ftp' scheme should be specified"))
+}
+
+func TestVendoredModules(t *testing.T) {
+	t.Parallel()
+
+	ts := newGlobalTestState(t)
+	script := `
+		import { word } from "https://vendored.k6.invalid/lib/1.0.0/index.js";
+		export default function () { console.log(word) };
+	`
+	module := `export var word = "vendored";`
+	require.NoError(t, afero.WriteFile(ts.fs, filepath.Join(ts.cwd, "script.js"), []byte(script), 0o644))
+
+	httpsFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(httpsFS, "/vendored.k6.invalid/lib/1.0.0/index.js", []byte(module), 0o644))
+	scriptURL := &url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(ts.cwd, "script.js"))}
+	_, err := loader.VendorModules(ts.fs, scriptURL, httpsFS)
+	require.NoError(t, err)
+
+	ts.args = []string{"k6", "run", "--quiet", "--no-summary", "script.js"}
+	newRootCommand(ts.globalState).execute()
+	assert.True(t, testutils.LogContains(ts.loggerHook.Drain(), logrus.InfoLevel, "vendored"))
+
+	// vendoring again fetches the module instead of reusing the vendored copy,
+	// the command exit handler cancels the context, so a new state is needed
+	vendorTS := newGlobalTestState(t)
+	vendorTS.fs = ts.fs
+	vendorTS.args = []string{"k6", "deps", "vendor", "script.js"}
+	vendorTS.expectedExitCode = int(exitcodes.ScriptException)
+	newRootCommand(vendorTS.globalState).execute()
+	assert.True(t, testutils.LogContains(vendorTS.loggerHook.Drain(), logrus.ErrorLevel,
+		"https://vendored.k6.invalid/lib/1.0.0/index.js"))
+	assert.NotContains(t, vendorTS.stdOut.String(), "Vendored")
+}
+
+// sendSignalsAfterLog mocks the signal handling of ts and sends the given
+// signals, one after the other, once msg has been logged.
+func sendSignalsAfterLog(t *testing.T, ts *globalTestState, msg string, signals ...os.Signal) {
+	t.Helper()
+	sigReceivers := make(chan chan<- os.Signal, 1)
+	ts.signalNotify = func(c chan<- os.Signal, _ ...os.Signal) { sigReceivers <- c }
+	ts.signalStop = func(chan<- os.Signal) {}
+
+	go func() {
+		sigC := <-sigReceivers
+		for !testutils.LogContains(ts.loggerHook.Drain(), logrus.InfoLevel, msg) {
+			time.Sleep(50 * time.Millisecond)
+		}
+		for _, sig := range signals {
+			sigC <- sig
+			time.Sleep(500 * time.Millisecond)
+		}
+	}()
+}
+
+func TestAbortSummary(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { sleep } from 'k6';
+		export const options = { duration: '1m' };
+		export default function () { console.log('running'); sleep(0.1); };
+		export function teardown() { console.log('teardown'); sleep(2); };
+		export function handleSummary(data) {
+			return { stdout: JSON.stringify({ reason: data.abort.reason, truncated: data.abort.truncated }) };
+		};
+	`
+	testCases := []struct {
+		name, args, expSummary string
+		signals                []os.Signal
+		expExitCode            int
+	}{
+		{
+			name: "graceful stop", signals: []os.Signal{os.Interrupt},
+			expSummary: `{"reason":"the test run was stopped by the interrupt signal","truncated":false}`,
+		},
+		{
+			name: "second signal", signals: []os.Signal{os.Interrupt, os.Interrupt},
+			expSummary:  `{"reason":"a second interrupt signal was received","truncated":true}`,
+			expExitCode: int(exitcodes.ExternalAbort),
+		},
+		{
+			name: "timed abort", args: "--abort-after-grace 500ms", signals: []os.Signal{os.Interrupt},
+			expSummary:  `{"reason":"the test run didn't stop in 500ms after the interrupt signal","truncated":true}`,
+			expExitCode: int(exitcodes.ExternalAbort),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newGlobalTestState(t)
+			ts.args = append(append([]string{"k6", "run"}, strings.Fields(tc.args)...), "-")
+			ts.stdIn = bytes.NewBufferString(script)
+			// the mocked os exit doesn't stop k6, so the teardown finishes and
+			// the test run ends normally afterwards, without another summary
+			var exitCode int64
+			ts.osExit = func(code int) { atomic.StoreInt64(&exitCode, int64(code)) }
+			sendSignalsAfterLog(t, ts, "running", tc.signals...)
+
+			newRootCommand(ts.globalState).execute()
+
+			assert.Equal(t, int64(tc.expExitCode), atomic.LoadInt64(&exitCode))
+			assert.Contains(t, ts.stdOut.String(), tc.expSummary)
+		})
+	}
+}
@@ -63,6 +63,8 @@ func optionFlagSet() *pflag.FlagSet {
 	flags.Bool("insecure-skip-tls-verify", false, "skip verification of TLS certificates")
 	flags.Bool("no-connection-reuse", false, "disable keep-alive connections")
 	flags.Bool("no-vu-connection-reuse", false, "don't reuse connections between iterations")
+	flags.Bool("preflight", false, "check that the init code of the first and last possible VUs of every scenario "+
+		"can be executed and exports the scenario functions, before initializing the rest of them")
 	flags.Duration("min-iteration-duration", 0, "minimum amount of time k6 will take executing a single iteration")
 	flags.BoolP("throw", "w", false, "throw warnings (like failed http requests) as errors")
 	flags.StringSlice("blacklist-ip", nil, "blacklist an `ip range` from being called")
@@ -76,7 +78,13 @@ func optionFlagSet() *pflag.FlagSet {
 		strings.Join(lib.DefaultSummaryTrendStats, ","),
 	)
 	flags.StringSlice("summary-trend-stats", nil, sumTrendStatsHelp)
-	flags.String("summary-time-unit", "", "define the time unit used to display the trend stats. Possible units are: 's', 'ms' and 'us'") //nolint:lll
+	flags.String("summary-time-unit", "", "define the time unit used to display the trend stats. Possible units are: 's', 'ms', 'us' and 'ns'") //nolint:lll
+	flags.Int64("summary-top-submetrics", 0, "show only this many of the worst sub-metrics of every metric in the "+
+		"summary, e.g. the URLs with the highest p(95), 0 shows all of them. The rates are ranked by the values "+
+		"their rate thresholds limit, e.g. the true ones for rate<0.01, and by the false values without any, "+
+		"except for http_req_failed")
+	flags.Bool("summary-transactions", false, "show all groups in the SLA table of the summary, not only the ones "+
+		"with group_duration thresholds")
 	// system-tags must have a default value, but we can't specify it here, otherwiese, it will always override others.
 	// set it to nil here, and add the default in applyDefault() instead.
 	systemTagsCliHelpText := fmt.Sprintf(
@@ -85,8 +93,17 @@ func optionFlagSet() *pflag.FlagSet {
 	)
 	flags.StringSlice("system-tags", nil, systemTagsCliHelpText)
 	flags.StringSlice("tag", nil, "add a `tag` to be applied to all samples, as `[name]=[value]`")
+	flags.StringSlice("metadata", nil, "add a `key=value` pair describing the test run to the summary and the outputs")
+	flags.StringSlice("disabled-metrics", nil, "don't emit any samples for these built-in `metrics`, "+
+		"e.g. 'http_req_blocked,http_req_tls_handshaking,vus_max'")
 	flags.String("console-output", "", "redirects the console logging to the provided output file")
+	flags.String("script-log-output", "", "writes the structured k6/execution script logs to the provided "+
+		"output file as JSON")
 	flags.Bool("discard-response-bodies", false, "Read but don't process or save HTTP response bodies")
+	flags.Int64("iteration-body-bytes-budget", 0, "warn about iterations that allocate more than this number of "+
+		"response body bytes, 0 disables it")
+	flags.Bool("iteration-breakdown", false, "emit the time every iteration spent in protocol calls, in sleep() "+
+		"and in the script itself as the iteration_*_duration metrics")
 	flags.String("local-ips", "", "Client IP Ranges and/or CIDRs from which each VU will be making requests, "+
 		"e.g. '192.168.220.1,192.168.0.10-192.168.0.25', 'fd:1::0/120', etc.")
 	flags.String("dns", types.DefaultDNSConfig().String(), "DNS resolver configuration. Possible ttl values are: 'inf' "+
@@ -100,25 +117,30 @@ func optionFlagSet() *pflag.FlagSet {
 //nolint:funlen,gocognit,cyclop // this needs breaking up but probably should wait for croconf
 func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 	opts := lib.Options{
-		VUs:                     getNullInt64(flags, "vus"),
-		Duration:                getNullDuration(flags, "duration"),
-		Iterations:              getNullInt64(flags, "iterations"),
-		Paused:                  getNullBool(flags, "paused"),
-		NoSetup:                 getNullBool(flags, "no-setup"),
-		NoTeardown:              getNullBool(flags, "no-teardown"),
-		MaxRedirects:            getNullInt64(flags, "max-redirects"),
-		Batch:                   getNullInt64(flags, "batch"),
-		BatchPerHost:            getNullInt64(flags, "batch-per-host"),
-		RPS:                     getNullInt64(flags, "rps"),
-		UserAgent:               getNullString(flags, "user-agent"),
-		HTTPDebug:               getNullString(flags, "http-debug"),
-		InsecureSkipTLSVerify:   getNullBool(flags, "insecure-skip-tls-verify"),
-		NoConnectionReuse:       getNullBool(flags, "no-connection-reuse"),
-		NoVUConnectionReuse:     getNullBool(flags, "no-vu-connection-reuse"),
-		MinIterationDuration:    getNullDuration(flags, "min-iteration-duration"),
-		Throw:                   getNullBool(flags, "throw"),
-		DiscardResponseBodies:   getNullBool(flags, "discard-response-bodies"),
-		MetricSamplesBufferSize: null.NewInt(1000, false),
+		VUs:                      getNullInt64(flags, "vus"),
+		Duration:                 getNullDuration(flags, "duration"),
+		Iterations:               getNullInt64(flags, "iterations"),
+		Paused:                   getNullBool(flags, "paused"),
+		NoSetup:                  getNullBool(flags, "no-setup"),
+		NoTeardown:               getNullBool(flags, "no-teardown"),
+		MaxRedirects:             getNullInt64(flags, "max-redirects"),
+		Batch:                    getNullInt64(flags, "batch"),
+		BatchPerHost:             getNullInt64(flags, "batch-per-host"),
+		RPS:                      getNullInt64(flags, "rps"),
+		UserAgent:                getNullString(flags, "user-agent"),
+		HTTPDebug:                getNullString(flags, "http-debug"),
+		InsecureSkipTLSVerify:    getNullBool(flags, "insecure-skip-tls-verify"),
+		NoConnectionReuse:        getNullBool(flags, "no-connection-reuse"),
+		NoVUConnectionReuse:      getNullBool(flags, "no-vu-connection-reuse"),
+		Preflight:                getNullBool(flags, "preflight"),
+		MinIterationDuration:     getNullDuration(flags, "min-iteration-duration"),
+		Throw:                    getNullBool(flags, "throw"),
+		DiscardResponseBodies:    getNullBool(flags, "discard-response-bodies"),
+		IterationBodyBytesBudget: getNullInt64(flags, "iteration-body-bytes-budget"),
+		IterationBreakdown:       getNullBool(flags, "iteration-breakdown"),
+		SummaryTopSubmetrics:     getNullInt64(flags, "summary-top-submetrics"),
+		SummaryTransactions:      getNullBool(flags, "summary-transactions"),
+		MetricSamplesBufferSize:  null.NewInt(1000, false),
 	}
 
 	// Using Changed() because GetStringSlice() doesn't differentiate between empty and no value
@@ -174,6 +196,14 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		opts.SystemTags = metrics.ToSystemTagSet(systemTagList)
 	}
 
+	if flags.Changed("disabled-metrics") {
+		disabledMetrics, err := flags.GetStringSlice("disabled-metrics")
+		if err != nil {
+			return opts, err
+		}
+		opts.DisabledMetrics = disabledMetrics
+	}
+
 	blacklistIPStrings, err := flags.GetStringSlice("blacklist-ip")
 	if err != nil {
 		return opts, err
@@ -224,8 +254,10 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		return opts, err
 	}
 	if summaryTimeUnit != "" {
-		if summaryTimeUnit != "s" && summaryTimeUnit != "ms" && summaryTimeUnit != "us" {
-			return opts, fmt.Errorf("invalid summary time unit '%s', use 's', 'ms' or 'us'", summaryTimeUnit)
+		switch summaryTimeUnit {
+		case "s", "ms", "us", "ns":
+		default:
+			return opts, fmt.Errorf("invalid summary time unit '%s', use 's', 'ms', 'us' or 'ns'", summaryTimeUnit)
 		}
 		opts.SummaryTimeUnit = null.StringFrom(summaryTimeUnit)
 	}
@@ -248,6 +280,22 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		opts.RunTags = metrics.IntoSampleTags(&parsedRunTags)
 	}
 
+	metadata, err := flags.GetStringSlice("metadata")
+	if err != nil {
+		return opts, err
+	}
+	if len(metadata) > 0 {
+		opts.Metadata = make(lib.Metadata, len(metadata))
+		for _, s := range metadata {
+			var name, value string
+			name, value, err = parseTagNameValue(s)
+			if err != nil {
+				return opts, fmt.Errorf("error parsing metadata '%s': %w", s, err)
+			}
+			opts.Metadata[name] = value
+		}
+	}
+
 	redirectConFile, err := flags.GetString("console-output")
 	if err != nil {
 		return opts, err
@@ -257,6 +305,15 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		opts.ConsoleOutput = null.StringFrom(redirectConFile)
 	}
 
+	scriptLogFile, err := flags.GetString("script-log-output")
+	if err != nil {
+		return opts, err
+	}
+
+	if scriptLogFile != "" {
+		opts.ScriptLogOutput = null.StringFrom(scriptLogFile)
+	}
+
 	if dns, err := flags.GetString("dns"); err != nil {
 		return opts, err
 	} else if dns != "" {
@@ -32,6 +32,7 @@ import (
 	"go.k6.io/k6/output/csv"
 	"go.k6.io/k6/output/influxdb"
 	"go.k6.io/k6/output/json"
+	"go.k6.io/k6/output/proto"
 	"go.k6.io/k6/output/statsd"
 )
 
@@ -51,7 +52,8 @@ func getAllOutputConstructors() (map[string]func(output.Params) (output.Output,
 			return nil, errors.New("the datadog output was deprecated in k6 v0.32.0 and removed in k6 v0.34.0, " +
 				"please use the statsd output with env. variable K6_STATSD_ENABLE_TAGS=true instead")
 		},
-		"csv": csv.New,
+		"csv":   csv.New,
+		"proto": proto.New,
 	}
 
 	exts := output.GetExtensions()
@@ -77,7 +79,9 @@ func getPossibleIDList(constrs map[string]func(output.Params) (output.Output, er
 	return strings.Join(res, ", ")
 }
 
-func createOutputs(gs *globalState, test *loadedTest, executionPlan []lib.ExecutionStep) ([]output.Output, error) {
+func createOutputs(
+	gs *globalState, test *loadedTest, executionPlan []lib.ExecutionStep, runEnv lib.RunEnvironment,
+) ([]output.Output, error) {
 	outputConstructors, err := getAllOutputConstructors()
 	if err != nil {
 		return nil, err
@@ -92,6 +96,7 @@ func createOutputs(gs *globalState, test *loadedTest, executionPlan []lib.Execut
 		ScriptOptions:  test.derivedConfig.Options,
 		RuntimeOptions: test.runtimeOptions,
 		ExecutionPlan:  executionPlan,
+		RunEnvironment: runEnv,
 	}
 	result := make([]output.Output, 0, len(test.derivedConfig.Out))
 
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/spf13/cobra"
+
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output/json"
+	"go.k6.io/k6/output/proto"
+)
+
+func getCmdResults(gs *globalState) *cobra.Command {
+	resultsCmd := &cobra.Command{
+		Use:   "results",
+		Short: "Work with results files",
+		Long:  "Work with the results files written by the file outputs.",
+	}
+	resultsCmd.AddCommand(getCmdResultsExport(gs))
+	return resultsCmd
+}
+
+func getCmdResultsExport(gs *globalState) *cobra.Command {
+	var to, exportOutput string
+
+	exportCmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Convert a results file of the proto output to another format",
+		Long: `Convert a results file of the proto output to another format.
+
+At the moment, the only supported format is the JSON lines format of the json
+output, so existing post-processing tools can be used with it. The metadata of
+the test run is converted as well, if the results file contains it.`,
+		Example: `
+  # Convert the results of 'k6 run --out proto=results.pb' to JSON
+  k6 results export --to json -O results.json results.pb`[1:],
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to != "json" {
+				return fmt.Errorf("unsupported results format '%s', only 'json' is supported", to)
+			}
+
+			in, err := gs.fs.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer func() { _ = in.Close() }()
+			var r io.Reader = in
+			if strings.HasSuffix(args[0], ".gz") {
+				gzipReader, gzErr := gzip.NewReader(in)
+				if gzErr != nil {
+					return gzErr
+				}
+				r = gzipReader
+			}
+
+			if exportOutput == "" || exportOutput == "-" {
+				w := bufio.NewWriter(gs.stdOut)
+				if err = exportResults(r, w); err != nil {
+					return err
+				}
+				return w.Flush()
+			}
+
+			f, err := gs.fs.Create(exportOutput)
+			if err != nil {
+				return err
+			}
+			w := bufio.NewWriter(f)
+			if err = exportResults(r, w); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err = w.Flush(); err != nil {
+				_ = f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}
+
+	exportCmd.Flags().SortFlags = false
+	exportCmd.Flags().StringVar(&to, "to", "json", "the format to convert the results to")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "O", "", "output filename (stdout by default)")
+
+	return exportCmd
+}
+
+// exportResults converts the samples of a proto results file to JSON lines.
+func exportResults(r io.Reader, w io.Writer) error {
+	decoder, err := proto.NewDecoder(r)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	if md, ok := decoder.Metadata(); ok {
+		if err = encoder.EncodeMetadata(md.Environment, md.Metadata); err != nil {
+			return err
+		}
+	}
+
+	const batchSize = 1000
+	batch := make([]metrics.Sample, 0, batchSize)
+	for {
+		sample, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if batch = append(batch, sample); len(batch) == batchSize {
+			if err = encoder.Encode(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	return encoder.Encode(batch)
+}
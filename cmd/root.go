@@ -236,9 +236,9 @@ func newRootCommand(gs *globalState) *rootCommand {
 	rootCmd.SetIn(gs.stdIn)
 
 	subCommands := []func(*globalState) *cobra.Command{
-		getCmdArchive, getCmdCloud, getCmdConvert, getCmdInspect,
-		getCmdLogin, getCmdPause, getCmdResume, getCmdScale, getCmdRun,
-		getCmdStats, getCmdStatus, getCmdVersion,
+		getCmdArchive, getCmdCloud, getCmdConvert, getCmdDeps, getCmdDoctor, getCmdInspect,
+		getCmdLogin, getCmdPause, getCmdResults, getCmdResume, getCmdScale, getCmdRun,
+		getCmdStats, getCmdStatus, getCmdThresholds, getCmdVersion,
 	}
 
 	for _, sc := range subCommands {
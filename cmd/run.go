@@ -31,6 +31,7 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/afero"
@@ -45,9 +46,14 @@ import (
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/lib/preflight"
 	"go.k6.io/k6/ui/pb"
 )
 
+// partialSummaryTimeout limits how long k6 waits for the truncated summary when
+// the test run is forcefully aborted.
+const partialSummaryTimeout = 5 * time.Second
+
 // cmdRun handles the `k6 run` sub-command
 type cmdRun struct {
 	gs *globalState
@@ -114,7 +120,19 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) error {
 
 	// Create all outputs.
 	executionPlan := execScheduler.GetExecutionPlan()
-	outputs, err := createOutputs(c.gs, test, executionPlan)
+
+	// Check the OS limits before starting the test and tune what we can
+	restoreCPUs, err := tuneCPUs(c.gs, test.derivedConfig)
+	if err != nil {
+		return err
+	}
+	defer restoreCPUs()
+	// Tune first, so the warnings are about the limits the test will run with
+	defer preflight.Tune(logger)()
+	warnAboutOSLimits(c.gs, lib.GetMaxPossibleVUs(executionPlan))
+
+	runEnv := getRunEnvironment(c.gs)
+	outputs, err := createOutputs(c.gs, test, executionPlan, runEnv)
 	if err != nil {
 		return err
 	}
@@ -164,14 +182,88 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) error {
 		c.gs, "local", args[0], "", conf, execScheduler.GetState().ExecutionTuple, executionPlan, outputs,
 	)
 
+	// The end-of-test summary is generated only once, either normally after
+	// the test run has finished or, if k6 is forcefully aborted, as a partial
+	// summary right before it exits.
+	var summaryStarted uint32
+	handleSummary := func(ctx context.Context, abort *lib.SummaryAbort) {
+		if test.runtimeOptions.NoSummary.Bool || !atomic.CompareAndSwapUint32(&summaryStarted, 0, 1) {
+			return
+		}
+		var throughputSearches []lib.ThroughputSearchResult
+		for _, e := range execScheduler.GetExecutors() {
+			if tse, ok := e.(lib.ThroughputSearchExecutor); ok {
+				throughputSearches = append(throughputSearches, tse.GetThroughputSearchResult())
+			}
+		}
+		// TODO: refactor so the lock is not needed
+		if !lockWithContext(ctx, &engine.MetricsEngine.MetricsLock) {
+			logger.Error("Skipping the end-of-test summary, the metrics weren't released in time")
+			return
+		}
+		summaryResult, err := test.initRunner.HandleSummary(ctx, &lib.Summary{
+			Metrics:         engine.MetricsEngine.ObservedMetrics,
+			RootGroup:       execScheduler.GetRunner().GetDefaultGroup(),
+			TestRunDuration: execScheduler.GetState().GetCurrentTestRunDuration(),
+			NoColor:         c.gs.flags.noColor,
+			Transactions:    engine.MetricsEngine.GetTransactions(),
+			Abort:           abort,
+			Environment:     runEnv,
+			UIState: lib.UIState{
+				IsStdOutTTY: c.gs.stdOut.isTTY,
+				IsStdErrTTY: c.gs.stdErr.isTTY,
+			},
+			ThroughputSearches: throughputSearches,
+		})
+		engine.MetricsEngine.MetricsLock.Unlock()
+		if err == nil {
+			err = handleSummaryResult(c.gs.fs, c.gs.stdOut, c.gs.stdErr, summaryResult)
+		}
+		if err != nil {
+			logger.WithError(err).Error("failed to handle the end-of-test summary")
+		}
+	}
+
+	// The reason for a graceful stop is recorded in the summary, while a
+	// forceful abort produces a truncated summary with its own reason.
+	var (
+		abortReasonMx sync.Mutex
+		abortReason   string
+	)
+	setAbortReason := func(reason string) {
+		abortReasonMx.Lock()
+		defer abortReasonMx.Unlock()
+		if abortReason == "" {
+			abortReason = reason
+		}
+	}
+	hardStop := func(reason string) {
+		logger.Errorf("Aborting k6, %s", reason)
+		ctx, cancel := context.WithTimeout(c.gs.ctx, partialSummaryTimeout)
+		defer cancel()
+		handleSummary(ctx, &lib.SummaryAbort{Reason: reason, Truncated: true})
+	}
+	runDone := make(chan struct{})
+	defer close(runDone)
+
 	// Trap Interrupts, SIGINTs and SIGTERMs.
 	gracefulStop := func(sig os.Signal) {
 		logger.WithField("sig", sig).Debug("Stopping k6 in response to signal...")
+		setAbortReason(fmt.Sprintf("the test run was stopped by the %s signal", sig))
+		if grace := time.Duration(conf.AbortAfterGrace.Duration); grace > 0 {
+			go func() {
+				select {
+				case <-time.After(grace):
+					hardStop(fmt.Sprintf("the test run didn't stop in %s after the %s signal", grace, sig))
+					c.gs.osExit(int(exitcodes.ExternalAbort))
+				case <-runDone:
+				}
+			}()
+		}
 		lingerCancel() // stop the test run, metric processing is cancelled below
 	}
 	onHardStop := func(sig os.Signal) {
-		logger.WithField("sig", sig).Error("Aborting k6 in response to signal")
-		globalCancel() // not that it matters, given the following command...
+		hardStop(fmt.Sprintf("a second %s signal was received", sig))
 	}
 	stopSignalHandling := handleTestAbortSignals(c.gs, gracefulStop, onHardStop)
 	defer stopSignalHandling()
@@ -230,26 +322,16 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Handle the end-of-test summary.
-	if !test.runtimeOptions.NoSummary.Bool {
-		engine.MetricsEngine.MetricsLock.Lock() // TODO: refactor so this is not needed
-		summaryResult, err := test.initRunner.HandleSummary(globalCtx, &lib.Summary{
-			Metrics:         engine.MetricsEngine.ObservedMetrics,
-			RootGroup:       execScheduler.GetRunner().GetDefaultGroup(),
-			TestRunDuration: executionState.GetCurrentTestRunDuration(),
-			NoColor:         c.gs.flags.noColor,
-			UIState: lib.UIState{
-				IsStdOutTTY: c.gs.stdOut.isTTY,
-				IsStdErrTTY: c.gs.stdErr.isTTY,
-			},
-		})
-		engine.MetricsEngine.MetricsLock.Unlock()
-		if err == nil {
-			err = handleSummaryResult(c.gs.fs, c.gs.stdOut, c.gs.stdErr, summaryResult)
-		}
-		if err != nil {
-			logger.WithError(err).Error("failed to handle the end-of-test summary")
-		}
+	if interrupt != nil {
+		setAbortReason(interrupt.Error())
+	}
+	var abort *lib.SummaryAbort
+	abortReasonMx.Lock()
+	if abortReason != "" {
+		abort = &lib.SummaryAbort{Reason: abortReason}
 	}
+	abortReasonMx.Unlock()
+	handleSummary(globalCtx, abort)
 
 	if conf.Linger.Bool {
 		select {
@@ -362,6 +444,27 @@ func reportUsage(execScheduler *local.ExecutionScheduler) error {
 	return err
 }
 
+// lockWithContext takes the lock, unless the context is done first, e.g. when
+// the metrics are still being processed after the forceful abort of the test
+// run. If it returns false, the lock is released as soon as it's taken.
+func lockWithContext(ctx context.Context, l sync.Locker) bool {
+	locked := make(chan struct{})
+	go func() {
+		l.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		return true
+	case <-ctx.Done():
+		go func() {
+			<-locked
+			l.Unlock()
+		}()
+		return false
+	}
+}
+
 func handleSummaryResult(fs afero.Fs, stdOut, stdErr io.Writer, result map[string]io.Reader) error {
 	var errs []error
 
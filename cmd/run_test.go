@@ -22,6 +22,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -30,7 +31,9 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
@@ -133,6 +136,23 @@ func TestHandleSummaryResultError(t *testing.T) {
 	assertEqual(t, "file summary 2", files[filePath2])
 }
 
+func TestLockWithContext(t *testing.T) {
+	t.Parallel()
+
+	var l sync.Mutex
+	require.True(t, lockWithContext(context.Background(), &l))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.False(t, lockWithContext(ctx, &l))
+
+	// the lock that wasn't taken in time is released as soon as it's taken
+	l.Unlock()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.True(t, lockWithContext(ctx, &l))
+}
+
 func TestRunScriptErrorsAndAbort(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
@@ -206,6 +226,17 @@ func TestRunScriptErrorsAndAbort(t *testing.T) {
 			name:         "run should succeed on a threshold applying an unsupported aggregation method to a metric with the --no-thresholds flag set",
 			extraArgs:    []string{"--no-thresholds"},
 		},
+		{
+			testFilename: "thresholds/undefined_macro.js",
+			name:         "run should fail with exit status 104 on a threshold referencing an undefined environment variable",
+			expErr:       "undefined environment variable",
+			expExitCode:  exitcodes.InvalidConfig,
+		},
+		{
+			testFilename: "thresholds/undefined_macro.js",
+			name:         "run should succeed on a threshold referencing an undefined environment variable with the --no-thresholds flag set",
+			extraArgs:    []string{"--no-thresholds"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/pflag"
 	"gopkg.in/guregu/null.v3"
@@ -49,6 +50,8 @@ extended: base + Babel with parts of ES2015 preset
 `)
 	flags.StringP("type", "t", "", "override test type, \"js\" or \"archive\"")
 	flags.StringArrayP("env", "e", nil, "add/override environment variable with `VAR=value`")
+	flags.StringSlice("enable-experimental", nil,
+		"allow the script to import the experimental JS `module`, can be specified multiple times")
 	flags.Bool("no-thresholds", false, "don't run thresholds")
 	flags.Bool("no-summary", false, "don't show the summary at the end of the test")
 	flags.String(
@@ -117,6 +120,20 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		}
 	}
 
+	enableExperimental, err := flags.GetStringSlice("enable-experimental")
+	if err != nil {
+		return opts, err
+	}
+	if envVar, ok := environment["K6_ENABLE_EXPERIMENTAL"]; ok && !flags.Changed("enable-experimental") {
+		// Only override if not explicitly set via the CLI flag
+		enableExperimental = strings.Split(envVar, ",")
+	}
+	for _, name := range enableExperimental {
+		if name = strings.TrimSpace(name); name != "" {
+			opts.EnableExperimental = append(opts.EnableExperimental, name)
+		}
+	}
+
 	if envVar, ok := environment["SSLKEYLOGFILE"]; ok {
 		if !opts.KeyWriter.Valid {
 			opts.KeyWriter = null.StringFrom(envVar)
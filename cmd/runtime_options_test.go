@@ -346,6 +346,27 @@ func TestRuntimeOptions(t *testing.T) {
 				SummaryExport:        null.NewString("bar", true),
 			},
 		},
+		"experimental modules from env": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_ENABLE_EXPERIMENTAL": "k6/x/foo, k6/x/bar,"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				EnableExperimental:   []string{"k6/x/foo", "k6/x/bar"},
+			},
+		},
+		"experimental modules from env overwritten by CLI": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_ENABLE_EXPERIMENTAL": "k6/x/foo"},
+			cliFlags:  []string{"--enable-experimental", "k6/x/bar", "--enable-experimental=k6/x/baz"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				EnableExperimental:   []string{"k6/x/bar", "k6/x/baz"},
+			},
+		},
 		"env var error detected even when CLI flags overwrite 1": {
 			useSysEnv: false,
 			systemEnv: map[string]string{"K6_NO_THRESHOLDS": "boo"},
@@ -49,6 +49,16 @@ func loadAndConfigureTest(
 	gs *globalState, cmd *cobra.Command, args []string,
 	// supply this if you want the test config consolidated and validated
 	cliConfigGetter func(flags *pflag.FlagSet) (Config, error), // TODO: obviate
+) (*loadedTest, error) {
+	return loadTest(gs, cmd, args, cliConfigGetter, true)
+}
+
+// loadTest is like loadAndConfigureTest, but the vendored modules of the
+// script are ignored if useVendoredModules is false, so all remote modules
+// are fetched, e.g. when they are vendored again.
+func loadTest(
+	gs *globalState, cmd *cobra.Command, args []string,
+	cliConfigGetter func(flags *pflag.FlagSet) (Config, error), useVendoredModules bool,
 ) (*loadedTest, error) {
 	if len(args) < 1 {
 		return nil, fmt.Errorf("k6 needs at least one argument to load the test")
@@ -56,7 +66,7 @@ func loadAndConfigureTest(
 
 	sourceRootPath := args[0]
 	gs.logger.Debugf("Resolving and reading test '%s'...", sourceRootPath)
-	src, fileSystems, pwd, err := readSource(gs, sourceRootPath)
+	src, fileSystems, pwd, err := readSource(gs, sourceRootPath, useVendoredModules)
 	if err != nil {
 		return nil, err
 	}
@@ -164,8 +174,11 @@ func (lt *loadedTest) initializeFirstRunner(gs *globalState) error {
 }
 
 // readSource is a small wrapper around loader.ReadSource returning
-// result of the load and filesystems map
-func readSource(globalState *globalState, filename string) (*loader.SourceData, map[string]afero.Fs, string, error) {
+// result of the load and filesystems map, with the vendored modules of the
+// script already loaded if useVendoredModules is true.
+func readSource(
+	globalState *globalState, filename string, useVendoredModules bool,
+) (*loader.SourceData, map[string]afero.Fs, string, error) {
 	pwd, err := globalState.getwd()
 	if err != nil {
 		return nil, nil, "", err
@@ -173,6 +186,10 @@ func readSource(globalState *globalState, filename string) (*loader.SourceData,
 
 	filesystems := loader.CreateFilesystems(globalState.fs)
 	src, err := loader.ReadSource(globalState.logger, filename, pwd, filesystems, globalState.stdIn)
+	if err != nil || !useVendoredModules {
+		return src, filesystems, pwd, err
+	}
+	_, err = loader.LoadVendoredModules(globalState.logger, globalState.fs, src.URL, filesystems["https"])
 	return src, filesystems, pwd, err
 }
 
@@ -204,10 +221,15 @@ func (lt *loadedTest) consolidateDeriveAndValidateConfig(
 	}
 
 	gs.logger.Debug("Parsing thresholds and validating config...")
-	// Parse the thresholds, only if the --no-threshold flag is not set.
-	// If parsing the threshold expressions failed, consider it as an
+	// Expand and parse the thresholds, only if the --no-threshold flag is not
+	// set. If parsing the threshold expressions failed, consider it as an
 	// invalid configuration error.
 	if !lt.runtimeOptions.NoThresholds.Bool {
+		consolidatedConfig, err = expandThresholdMacros(consolidatedConfig, lt.runtimeOptions.Env)
+		if err != nil {
+			return err
+		}
+
 		for metricName, thresholdsDefinition := range consolidatedConfig.Options.Thresholds {
 			err = thresholdsDefinition.Parse()
 			if err != nil {
@@ -226,12 +248,69 @@ func (lt *loadedTest) consolidateDeriveAndValidateConfig(
 		return err
 	}
 
+	if err = lt.disableMetrics(derivedConfig.Options); err != nil {
+		return errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+	}
+
 	lt.consolidatedConfig = consolidatedConfig
 	lt.derivedConfig = derivedConfig
 
 	return nil
 }
 
+// disableMetrics stops the emission of the built-in metrics from the
+// disabledMetrics option, which can't have any thresholds, nor be needed by
+// the prices of the cost model. The metrics of the iteration breakdown are
+// disabled as well, unless the iterationBreakdown option is enabled.
+func (lt *loadedTest) disableMetrics(opts lib.Options) error {
+	var breakdownMetrics []string
+	if !opts.IterationBreakdown.Bool {
+		breakdownMetrics = []string{
+			metrics.IterationProtocolDurationName,
+			metrics.IterationScriptDurationName,
+			metrics.IterationSleepDurationName,
+		}
+	}
+	if !lt.runtimeOptions.NoThresholds.Bool {
+		for thresholdsName := range opts.Thresholds {
+			metricName, _, err := metrics.ParseMetricName(thresholdsName)
+			if err != nil {
+				continue // already validated with the thresholds
+			}
+			for _, name := range opts.DisabledMetrics {
+				if metricName == name {
+					return fmt.Errorf("the metric '%s' is disabled, so it can't have thresholds", name)
+				}
+			}
+			for _, name := range breakdownMetrics {
+				if metricName == name {
+					return fmt.Errorf(
+						"the metric '%s' is only emitted with the iterationBreakdown option, "+
+							"so it can't have thresholds without it", name,
+					)
+				}
+			}
+		}
+	}
+	if cost := opts.Cost; cost != nil {
+		pricedMetrics := map[string]bool{
+			metrics.HTTPReqsName:        cost.PerRequest.Valid,
+			metrics.GRPCReqDurationName: cost.PerRequest.Valid,
+			metrics.DataReceivedName:    cost.PerGBEgress.Valid,
+			metrics.VUsName:             cost.PerVUMinute.Valid,
+		}
+		for _, name := range opts.DisabledMetrics {
+			if pricedMetrics[name] {
+				return fmt.Errorf("the metric '%s' is disabled, but the cost estimation is based on it", name)
+			}
+		}
+	}
+	if err := lt.builtInMetrics.Disable(opts.DisabledMetrics...); err != nil {
+		return err
+	}
+	return lt.builtInMetrics.Disable(breakdownMetrics...)
+}
+
 type syncWriter struct {
 	w io.Writer
 	m sync.Mutex
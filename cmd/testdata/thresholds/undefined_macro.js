@@ -0,0 +1,13 @@
+export const options = {
+	thresholds: {
+		// UNDEFINED_ENV_VAR isn't passed with --env, so the macro can't be
+		// expanded. k6 should catch that.
+		"http_req_duration{env:${UNDEFINED_ENV_VAR}}": ["p(95)<100"],
+	},
+};
+
+export default function () {
+	console.log(
+		"asserting that a threshold with an undefined macro fails with exit code 104 (Invalid config)"
+	);
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/metrics"
+)
+
+// getCmdThresholds returns the `k6 thresholds` sub-command, together with its children.
+func getCmdThresholds(gs *globalState) *cobra.Command {
+	thresholdsCmd := &cobra.Command{
+		Use:   "thresholds",
+		Short: "Work with threshold definitions",
+		Long:  `Work with threshold definitions.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+	thresholdsCmd.AddCommand(getCmdThresholdsEval(gs))
+
+	return thresholdsCmd
+}
+
+func getCmdThresholdsEval(gs *globalState) *cobra.Command {
+	var summaryPath, thresholdsPath string
+
+	evalCmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate thresholds against the summary of a previous test run",
+		Long: `Evaluate thresholds against the summary of a previous test run.
+
+The summary can be either a file produced by --summary-export, or the JSON
+serialization of the handleSummary() data. The thresholds file contains an
+object with the same format as the thresholds option, optionally nested in a
+"thresholds" key, as in a JSON config file. This allows validating changes to
+the thresholds against historical data before adopting them.
+
+Note that only the aggregated values stored in the summary can be used, so
+thresholds on percentiles which weren't included in summaryTrendStats, or on
+sub-metrics which aren't present in the summary, can't be evaluated.`,
+		Example: `
+  # Evaluate the thresholds in new-thresholds.json against the results of a previous run.
+  k6 run --summary-export old.json script.js
+  k6 thresholds eval --summary old.json --thresholds new-thresholds.json`[1:],
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			summaryData, err := afero.ReadFile(gs.fs, summaryPath)
+			if err != nil {
+				return err
+			}
+			values, err := parseSummaryMetricValues(summaryData)
+			if err != nil {
+				return errext.WithExitCodeIfNone(
+					fmt.Errorf("could not parse the summary file '%s': %w", summaryPath, err), exitcodes.InvalidConfig,
+				)
+			}
+
+			thresholdsData, err := afero.ReadFile(gs.fs, thresholdsPath)
+			if err != nil {
+				return err
+			}
+			thresholds, err := parseThresholdsFile(thresholdsData)
+			if err != nil {
+				return errext.WithExitCodeIfNone(
+					fmt.Errorf("could not parse the thresholds file '%s': %w", thresholdsPath, err), exitcodes.InvalidConfig,
+				)
+			}
+			thresholds, err = expandThresholdMacrosMap(thresholds, gs.envVars)
+			if err != nil {
+				return err
+			}
+
+			report, failed := evaluateThresholds(thresholds, values, gs.flags.noColor || !gs.stdOut.isTTY)
+			printToStdout(gs, report)
+			if failed {
+				return errext.WithExitCodeIfNone(errors.New("some thresholds have failed"), exitcodes.ThresholdsHaveFailed)
+			}
+			return nil
+		},
+	}
+
+	evalCmd.Flags().SortFlags = false
+	evalCmd.Flags().StringVar(&summaryPath, "summary", "", "path to the JSON summary of a previous test run")
+	evalCmd.Flags().StringVar(&thresholdsPath, "thresholds", "", "path to the JSON file with the thresholds to evaluate")
+	_ = evalCmd.MarkFlagRequired("summary")
+	_ = evalCmd.MarkFlagRequired("thresholds")
+
+	return evalCmd
+}
+
+// parseSummaryMetricValues returns the aggregated values of all metrics in
+// either the --summary-export or the handleSummary() data format.
+func parseSummaryMetricValues(data []byte) (map[string]map[string]float64, error) {
+	var summary struct {
+		Metrics map[string]json.RawMessage `json:"metrics"`
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+	if summary.Metrics == nil {
+		return nil, errors.New("the 'metrics' property is missing")
+	}
+
+	result := make(map[string]map[string]float64, len(summary.Metrics))
+	for name, rawMetric := range summary.Metrics {
+		// The handleSummary() data format has the values in a nested object
+		var metric struct {
+			Values map[string]float64 `json:"values"`
+		}
+		if err := json.Unmarshal(rawMetric, &metric); err == nil && metric.Values != nil {
+			result[name] = metric.Values
+			continue
+		}
+
+		// The --summary-export format has the values at the top level, mixed
+		// with the thresholds results
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(rawMetric, &fields); err != nil {
+			return nil, fmt.Errorf("invalid metric '%s': %w", name, err)
+		}
+		values := make(map[string]float64, len(fields))
+		for key, rawValue := range fields {
+			var value float64
+			if err := json.Unmarshal(rawValue, &value); err == nil {
+				values[key] = value
+			}
+		}
+		// Rate metrics have their rate exported as "value" in this format
+		_, hasPasses := values["passes"]
+		_, hasFails := values["fails"]
+		if v, hasValue := values["value"]; hasValue && hasPasses && hasFails {
+			values["rate"] = v
+			delete(values, "value")
+		}
+		result[name] = values
+	}
+
+	return result, nil
+}
+
+// parseThresholdsFile parses a JSON object with the same format as the
+// thresholds option, or a JSON config with a thresholds key.
+func parseThresholdsFile(data []byte) (map[string]metrics.Thresholds, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if nested, ok := fields["thresholds"]; ok && bytes.HasPrefix(bytes.TrimSpace(nested), []byte("{")) {
+		data = nested
+	}
+
+	var thresholds map[string]metrics.Thresholds
+	if err := json.Unmarshal(data, &thresholds); err != nil {
+		return nil, err
+	}
+	for name, ths := range thresholds {
+		ths := ths
+		if err := ths.Parse(); err != nil {
+			return nil, fmt.Errorf("invalid thresholds for metric '%s': %w", name, err)
+		}
+	}
+	return thresholds, nil
+}
+
+// evaluateThresholds returns a human-readable report of the thresholds
+// results and whether any of them failed.
+func evaluateThresholds(
+	thresholds map[string]metrics.Thresholds, values map[string]map[string]float64, noColor bool,
+) (string, bool) {
+	names := make([]string, 0, len(thresholds))
+	for name := range thresholds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	successMark := getColor(noColor, color.FgGreen).Sprint("✓")
+	failMark := getColor(noColor, color.FgRed).Sprint("✗")
+	unknownMark := getColor(noColor, color.FgYellow).Sprint("?")
+
+	var buf bytes.Buffer
+	var passed, failed, skipped int
+	for _, name := range names {
+		metricValues, ok := values[name]
+		for _, threshold := range thresholds[name].Thresholds {
+			if !ok {
+				skipped++
+				fmt.Fprintf(&buf, "%s %s: %s - metric not found in the summary\n", unknownMark, name, threshold.Source)
+				continue
+			}
+			passes, value, err := threshold.Evaluate(metricValues)
+			switch {
+			case err != nil:
+				skipped++
+				fmt.Fprintf(&buf, "%s %s: %s - %s\n", unknownMark, name, threshold.Source, err)
+			case passes:
+				passed++
+				fmt.Fprintf(&buf, "%s %s: %s (%g)\n", successMark, name, threshold.Source, value)
+			default:
+				failed++
+				fmt.Fprintf(&buf, "%s %s: %s (%g)\n", failMark, name, threshold.Source, value)
+			}
+		}
+	}
+	fmt.Fprintf(&buf, "\nthresholds: %d passed, %d failed, %d couldn't be evaluated\n", passed, failed, skipped)
+
+	return buf.String(), failed > 0
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/errext/exitcodes"
+)
+
+const testSummaryExport = `{
+    "metrics": {
+        "http_req_duration": {
+            "avg": 120.5,
+            "min": 10,
+            "med": 100,
+            "max": 900,
+            "p(90)": 300,
+            "p(95)": 450,
+            "thresholds": {
+                "p(95)<500": false
+            }
+        },
+        "http_req_duration{env:staging}": {
+            "avg": 100,
+            "min": 10,
+            "med": 90,
+            "max": 800,
+            "p(90)": 250,
+            "p(95)": 400
+        },
+        "http_req_failed": {
+            "passes": 2,
+            "fails": 98,
+            "value": 0.02
+        }
+    }
+}`
+
+const testHandleSummaryData = `{
+    "metrics": {
+        "http_req_duration": {
+            "type": "trend",
+            "contains": "time",
+            "values": {"avg": 120.5, "p(95)": 450}
+        },
+        "http_req_failed": {
+            "type": "rate",
+            "contains": "default",
+            "values": {"rate": 0.02, "passes": 2, "fails": 98}
+        }
+    }
+}`
+
+func TestParseSummaryMetricValues(t *testing.T) {
+	t.Parallel()
+
+	for name, data := range map[string]string{"summaryExport": testSummaryExport, "handleSummary": testHandleSummaryData} {
+		data := data
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			values, err := parseSummaryMetricValues([]byte(data))
+			require.NoError(t, err)
+			assert.Equal(t, 450.0, values["http_req_duration"]["p(95)"])
+			assert.Equal(t, 0.02, values["http_req_failed"]["rate"])
+			assert.NotContains(t, values["http_req_failed"], "value")
+		})
+	}
+
+	_, err := parseSummaryMetricValues([]byte(`{"root_group": {}}`))
+	assert.Error(t, err)
+}
+
+func TestThresholdsEvalCmd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passing", func(t *testing.T) {
+		t.Parallel()
+		ts := newGlobalTestState(t)
+		ts.envVars["ENVIRONMENT"] = "staging"
+		require.NoError(t, afero.WriteFile(ts.fs, "old.json", []byte(testSummaryExport), 0o644))
+		require.NoError(t, afero.WriteFile(ts.fs, "new.json", []byte(`{"thresholds": {
+			"http_req_duration": ["p(95)<500", {"threshold": "avg<200", "abortOnFail": true}],
+			"http_req_duration{env:${ENVIRONMENT}}": ["p(95)<450"],
+			"http_req_failed": ["rate<0.05"]
+		}}`), 0o644))
+		ts.args = []string{"k6", "thresholds", "eval", "--summary", "old.json", "--thresholds", "new.json"}
+
+		newRootCommand(ts.globalState).execute()
+
+		stdOut := ts.stdOut.String()
+		assert.Contains(t, stdOut, "✓ http_req_duration: p(95)<500 (450)\n")
+		assert.Contains(t, stdOut, "✓ http_req_duration: avg<200 (120.5)\n")
+		assert.Contains(t, stdOut, "✓ http_req_duration{env:staging}: p(95)<450 (400)\n")
+		assert.Contains(t, stdOut, "✓ http_req_failed: rate<0.05 (0.02)\n")
+		assert.Contains(t, stdOut, "thresholds: 4 passed, 0 failed, 0 couldn't be evaluated\n")
+	})
+
+	t.Run("failing", func(t *testing.T) {
+		t.Parallel()
+		ts := newGlobalTestState(t)
+		require.NoError(t, afero.WriteFile(ts.fs, "old.json", []byte(testHandleSummaryData), 0o644))
+		require.NoError(t, afero.WriteFile(ts.fs, "new.json", []byte(`{
+			"http_req_duration": ["p(95)<400", "p(99)<1000"],
+			"http_req_failed": ["rate<0.01"],
+			"checks": ["rate>0.99"]
+		}`), 0o644))
+		ts.args = []string{"k6", "thresholds", "eval", "--summary", "old.json", "--thresholds", "new.json"}
+		ts.expectedExitCode = int(exitcodes.ThresholdsHaveFailed)
+
+		newRootCommand(ts.globalState).execute()
+
+		stdOut := ts.stdOut.String()
+		assert.Contains(t, stdOut, "✗ http_req_duration: p(95)<400 (450)\n")
+		assert.Contains(t, stdOut, "? http_req_duration: p(99)<1000 - no p(99) value found")
+		assert.Contains(t, stdOut, "✗ http_req_failed: rate<0.01 (0.02)\n")
+		assert.Contains(t, stdOut, "? checks: rate>0.99 - metric not found in the summary\n")
+		assert.Contains(t, stdOut, "thresholds: 0 passed, 2 failed, 2 couldn't be evaluated\n")
+	})
+
+	t.Run("invalid thresholds", func(t *testing.T) {
+		t.Parallel()
+		ts := newGlobalTestState(t)
+		require.NoError(t, afero.WriteFile(ts.fs, "old.json", []byte(testHandleSummaryData), 0o644))
+		require.NoError(t, afero.WriteFile(ts.fs, "new.json", []byte(`{"http_req_duration": ["foo<400"]}`), 0o644))
+		ts.args = []string{"k6", "thresholds", "eval", "--summary", "old.json", "--thresholds", "new.json"}
+		ts.expectedExitCode = int(exitcodes.InvalidConfig)
+
+		newRootCommand(ts.globalState).execute()
+	})
+}
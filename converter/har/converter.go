@@ -59,7 +59,11 @@ func fprintf(w io.Writer, format string, a ...interface{}) int {
 }
 
 // TODO: refactor this to have fewer parameters... or just refactor in general...
-func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks bool, returnOnFailedCheck bool, batchTime uint, nobatch bool, correlate bool, only, skip []string) (result string, convertErr error) {
+//
+// If correlateWith is specified, it should be a second recording of the
+// same flow. Values that differ between the two recordings are extracted
+// from the previous responses and substituted in the subsequent requests.
+func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks bool, returnOnFailedCheck bool, batchTime uint, nobatch bool, correlate bool, correlateWith *HAR, only, skip []string) (result string, convertErr error) {
 	var b bytes.Buffer
 	w := bufio.NewWriter(&b)
 
@@ -67,7 +71,7 @@ func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks b
 		return "", fmt.Errorf("return on failed check requires --enable-status-code-checks")
 	}
 
-	if correlate && !nobatch {
+	if (correlate || correlateWith != nil) && !nobatch {
 		return "", fmt.Errorf("correlation requires --no-batch")
 	}
 
@@ -75,6 +79,26 @@ func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks b
 		return "", fmt.Errorf("invalid HAR file supplied, the 'log' property is missing")
 	}
 
+	pages, pageEntries, err := groupEntriesByPage(h, only, skip)
+	if err != nil {
+		return "", err
+	}
+
+	var corr *correlation
+	if correlateWith != nil {
+		if correlateWith.Log == nil {
+			return "", fmt.Errorf("invalid correlation HAR file supplied, the 'log' property is missing")
+		}
+		otherPages, otherPageEntries, err := groupEntriesByPage(*correlateWith, only, skip)
+		if err != nil {
+			return "", err
+		}
+		corr = newCorrelation(
+			orderedEntries(pages, pageEntries), orderedEntries(otherPages, otherPageEntries),
+		)
+	}
+	var defined []*dynamicValue
+
 	if enableChecks {
 		fprint(w, "import { group, check, sleep } from 'k6';\n")
 	} else {
@@ -104,44 +128,21 @@ func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks b
 	}
 	fprint(w, "};\n\n")
 
-	fprint(w, "export default function() {\n\n")
-
-	pages := h.Log.Pages
-	sort.Sort(PageByStarted(pages))
-
-	// Hack to handle HAR files without a pages array
-	// Temporary fix for https://github.com/k6io/k6/issues/793
-	if len(pages) == 0 {
-		pages = []Page{{
-			ID:      "", // The Pageref property of all Entries will be an empty string
-			Title:   "Global",
-			Comment: "Placeholder page since there were no pages specified in the HAR file",
-		}}
+	if corr != nil && corr.usesHelper {
+		fprint(w, extractBetweenJS, "\n")
 	}
 
-	// Grouping by page and URL filtering
-	pageEntries := make(map[string][]*Entry)
-	for _, e := range h.Log.Entries {
-
-		// URL filtering
-		u, err := url.Parse(e.Request.URL)
-		if err != nil {
-			return "", err
-		}
-		if !IsAllowedURL(u.Host, only, skip) {
-			continue
-		}
+	fprint(w, "export default function() {\n\n")
 
-		// Avoid multipart/form-data requests until k6 scripts can support binary data
-		if e.Request.PostData != nil && strings.HasPrefix(e.Request.PostData.MimeType, "multipart/form-data") {
-			continue
+	if corr != nil && len(corr.values) > 0 {
+		varNames := make([]string, 0, len(corr.values))
+		for _, dv := range corr.values {
+			if dv.source != nil {
+				varNames = append(varNames, dv.varName)
+			}
 		}
-
-		// Create new group o adding page to a existing one
-		if _, ok := pageEntries[e.Pageref]; !ok {
-			pageEntries[e.Pageref] = append([]*Entry{}, e)
-		} else {
-			pageEntries[e.Pageref] = append(pageEntries[e.Pageref], e)
+		if len(varNames) > 0 {
+			fprintf(w, "\tlet %s;\n\n", strings.Join(varNames, ", "))
 		}
 	}
 
@@ -157,8 +158,6 @@ func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks b
 		}
 		fprintf(w, "\tgroup(%q, function() {\n", scriptGroupName)
 
-		sort.Sort(EntryByStarted(entries))
-
 		if nobatch {
 			var recordedRedirectURL string
 			previousResponse := map[string]interface{}{}
@@ -172,19 +171,25 @@ func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks b
 				var body string
 
 				fprintf(w, "\t\t// Request #%d\n", entryIndex)
+				if corr != nil {
+					for _, dv := range corr.unresolved[e] {
+						fprintf(w, "\t\t// TODO: the value %q of the %s differs between the recordings, "+
+							"but it couldn't be found in any of the previous responses\n", dv.value, dv.location)
+					}
+				}
 
 				if e.Request.PostData != nil {
 					body = e.Request.PostData.Text
 				}
 
 				for _, c := range e.Request.Cookies {
-					cookies = append(cookies, fmt.Sprintf(`%q: %q`, c.Name, c.Value))
+					cookies = append(cookies, fmt.Sprintf(`%q: %s`, c.Name, jsString(c.Value, defined)))
 				}
 				if len(cookies) > 0 {
 					params = append(params, fmt.Sprintf("\"cookies\": {\n\t\t\t\t%s\n\t\t\t}", strings.Join(cookies, ",\n\t\t\t\t\t")))
 				}
 
-				if headers := buildK6Headers(e.Request.Headers); len(headers) > 0 {
+				if headers := buildCorrelatedK6Headers(e.Request.Headers, defined); len(headers) > 0 {
 					params = append(params, fmt.Sprintf("\"headers\": {\n\t\t\t\t\t%s\n\t\t\t\t}", strings.Join(headers, ",\n\t\t\t\t\t")))
 				}
 
@@ -200,7 +205,7 @@ func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks b
 					fprintf(w, "redirectUrl")
 					recordedRedirectURL = ""
 				} else {
-					fprintf(w, "%q", e.Request.URL)
+					fprint(w, jsString(e.Request.URL, defined))
 				}
 
 				if e.Request.Method != "GET" {
@@ -219,13 +224,14 @@ func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks b
 						requestText, err := json.Marshal(requestMap)
 						if err == nil {
 							prettyJSONString := string(pretty.PrettyOptions(requestText, &pretty.Options{Width: 999999, Prefix: "\t\t\t", Indent: "\t", SortKeys: true})[:])
+							prettyJSONString, _ = substituteValues(prettyJSONString, defined, func(s string) string { return s })
 							fprintf(w, ",\n\t\t\t`%s`", strings.TrimSpace(prettyJSONString))
 						} else {
 							return "", err
 						}
 
 					} else {
-						fprintf(w, ",\n\t\t%q", body)
+						fprintf(w, ",\n\t\t%s", jsString(body, defined))
 					}
 				}
 
@@ -267,6 +273,13 @@ func Convert(h HAR, options lib.Options, minSleep, maxSleep uint, enableChecks b
 						fprint(w, "\t\tjson = JSON.parse(res.body);\n")
 					}
 				}
+
+				if corr != nil {
+					for _, dv := range corr.bySource[e] {
+						fprintf(w, "\t\t%s = %s;\n", dv.varName, dv.extractor)
+						defined = append(defined, dv)
+					}
+				}
 			}
 		} else {
 			batches := SplitEntriesInBatches(entries, batchTime)
@@ -391,7 +404,72 @@ func buildK6RequestObject(req *Request) (string, error) {
 	return buffer.String(), nil
 }
 
+// groupEntriesByPage returns the sorted pages of the HAR file, together with
+// their sorted entries which are allowed by the only and skip filters.
+func groupEntriesByPage(h HAR, only, skip []string) ([]Page, map[string][]*Entry, error) {
+	pages := h.Log.Pages
+	sort.Sort(PageByStarted(pages))
+
+	// Hack to handle HAR files without a pages array
+	// Temporary fix for https://github.com/k6io/k6/issues/793
+	if len(pages) == 0 {
+		pages = []Page{{
+			ID:      "", // The Pageref property of all Entries will be an empty string
+			Title:   "Global",
+			Comment: "Placeholder page since there were no pages specified in the HAR file",
+		}}
+	}
+
+	// Grouping by page and URL filtering
+	pageEntries := make(map[string][]*Entry)
+	for _, e := range h.Log.Entries {
+
+		// URL filtering
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !IsAllowedURL(u.Host, only, skip) {
+			continue
+		}
+
+		// Avoid multipart/form-data requests until k6 scripts can support binary data
+		if e.Request.PostData != nil && strings.HasPrefix(e.Request.PostData.MimeType, "multipart/form-data") {
+			continue
+		}
+
+		// Create new group o adding page to a existing one
+		if _, ok := pageEntries[e.Pageref]; !ok {
+			pageEntries[e.Pageref] = append([]*Entry{}, e)
+		} else {
+			pageEntries[e.Pageref] = append(pageEntries[e.Pageref], e)
+		}
+	}
+
+	for _, entries := range pageEntries {
+		sort.Sort(EntryByStarted(entries))
+	}
+
+	return pages, pageEntries, nil
+}
+
+// orderedEntries returns the entries in the order they are emitted in the
+// converted script.
+func orderedEntries(pages []Page, pageEntries map[string][]*Entry) []*Entry {
+	var result []*Entry
+	for _, page := range pages {
+		result = append(result, pageEntries[page.ID]...)
+	}
+	return result
+}
+
 func buildK6Headers(headers []Header) []string {
+	return buildCorrelatedK6Headers(headers, nil)
+}
+
+// buildCorrelatedK6Headers is like buildK6Headers, but substitutes the
+// defined dynamic values in the header values.
+func buildCorrelatedK6Headers(headers []Header, defined []*dynamicValue) []string {
 	var h []string
 	if len(headers) > 0 {
 		ignored := map[string]bool{"cookie": true, "content-length": true}
@@ -401,7 +479,7 @@ func buildK6Headers(headers []Header) []string {
 			// Avoid SPDY's, duplicated or ignored headers
 			if !isIgnored && name[0] != ':' {
 				ignored[name] = true
-				h = append(h, fmt.Sprintf("%q: %q", header.Name, header.Value))
+				h = append(h, fmt.Sprintf("%q: %s", header.Name, jsString(header.Value, defined)))
 			}
 		}
 	}
@@ -0,0 +1,446 @@
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Values shorter than this are too likely to be coincidental differences
+	// between the recordings (e.g. page numbers) to be worth correlating.
+	minDynamicValueLength = 4
+	// How far apart the same request is allowed to be in the two recordings.
+	maxAlignmentLookahead = 5
+)
+
+// Request headers whose values are either managed by k6 or are derived from
+// other values, so they're not considered when looking for dynamic values.
+var ignoredCorrelationHeaders = map[string]bool{ //nolint:gochecknoglobals
+	"cookie":            true,
+	"content-length":    true,
+	"referer":           true,
+	"if-modified-since": true,
+	"if-none-match":     true,
+}
+
+// dynamicValue is a value that differs between two recordings of the same
+// flow, e.g. a session token or a generated ID, together with the way it can
+// be extracted from a previous response of the converted script.
+type dynamicValue struct {
+	varName  string
+	value    string
+	location string
+
+	// source is the entry whose response contains the value and extractor is
+	// the JS expression that extracts it from that response. Both are empty
+	// if the value couldn't be found in any of the previous responses.
+	source    *Entry
+	extractor string
+}
+
+// correlation contains all of the dynamic values found by comparing two
+// recordings, indexed by the entries at which they should be handled.
+type correlation struct {
+	values     []*dynamicValue
+	bySource   map[*Entry][]*dynamicValue
+	unresolved map[*Entry][]*dynamicValue
+	usesHelper bool
+}
+
+// newCorrelation compares the requests of the primary recording with the
+// matching requests in the secondary one and returns a correlation for all
+// of the values that differed between them. Both entry slices are expected
+// to be in the order in which their requests are going to be emitted.
+func newCorrelation(primary, secondary []*Entry) *correlation {
+	c := &correlation{
+		bySource:   make(map[*Entry][]*dynamicValue),
+		unresolved: make(map[*Entry][]*dynamicValue),
+	}
+
+	seen := make(map[string]bool)
+	usedNames := make(map[string]bool)
+	for _, pair := range alignEntries(primary, secondary) {
+		primaryFields, secondaryFields := requestFields(pair.primary.Request), requestFields(pair.secondary.Request)
+
+		// Shorter values are handled first, so that values which just contain
+		// an already found dynamic value (e.g. "Bearer <token>") are skipped.
+		locations := make([]string, 0, len(primaryFields))
+		for location := range primaryFields {
+			locations = append(locations, location)
+		}
+		sort.Slice(locations, func(i, j int) bool {
+			li, lj := len(primaryFields[locations[i]]), len(primaryFields[locations[j]])
+			if li != lj {
+				return li < lj
+			}
+			return locations[i] < locations[j]
+		})
+
+		for _, location := range locations {
+			value := primaryFields[location]
+			otherValue, ok := secondaryFields[location]
+			if !ok || value == otherValue || seen[value] || !isCorrelatable(value) || c.contains(value) {
+				continue
+			}
+			seen[value] = true
+
+			dv := &dynamicValue{
+				varName:  uniqueVarName(location, usedNames),
+				value:    value,
+				location: location,
+			}
+			c.values = append(c.values, dv)
+			if c.findSource(dv, primary[:pair.index]) {
+				c.bySource[dv.source] = append(c.bySource[dv.source], dv)
+			} else {
+				c.unresolved[pair.primary] = append(c.unresolved[pair.primary], dv)
+			}
+		}
+	}
+
+	return c
+}
+
+// contains returns whether the value contains any of the already found
+// dynamic values.
+func (c *correlation) contains(value string) bool {
+	for _, dv := range c.values {
+		if strings.Contains(value, dv.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCorrelatable returns whether the value can be safely substituted with a
+// variable in the generated JS code.
+func isCorrelatable(value string) bool {
+	return len(value) >= minDynamicValueLength && !strings.ContainsAny(value, "`$\\\n")
+}
+
+type alignedPair struct {
+	index              int
+	primary, secondary *Entry
+}
+
+// alignEntries pairs the requests of the two recordings, allowing for a few
+// extra or missing requests (e.g. ones served from the browser cache) in
+// either of them.
+func alignEntries(primary, secondary []*Entry) []alignedPair {
+	var pairs []alignedPair
+	j := 0
+	for i, e := range primary {
+		for k := j; k < len(secondary) && k <= j+maxAlignmentLookahead; k++ {
+			if isSameRequest(e.Request, secondary[k].Request) {
+				pairs = append(pairs, alignedPair{index: i, primary: e, secondary: secondary[k]})
+				j = k + 1
+				break
+			}
+		}
+	}
+	return pairs
+}
+
+// isSameRequest returns whether both requests have the same method, host
+// and number of path segments, since dynamic path segments are expected.
+func isSameRequest(a, b *Request) bool {
+	if a.Method != b.Method {
+		return false
+	}
+	ua, errA := url.Parse(a.URL)
+	ub, errB := url.Parse(b.URL)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return ua.Host == ub.Host && strings.Count(ua.Path, "/") == strings.Count(ub.Path, "/")
+}
+
+// requestFields returns all of the values sent with the request, keyed by a
+// human-readable description of where they were sent.
+func requestFields(req *Request) map[string]string {
+	fields := make(map[string]string)
+
+	if u, err := url.Parse(req.URL); err == nil {
+		for i, segment := range strings.Split(strings.Trim(u.Path, "/"), "/") {
+			if segment != "" {
+				fields[fmt.Sprintf("path segment %d", i+1)] = segment
+			}
+		}
+		for name, values := range u.Query() {
+			if len(values) > 0 {
+				fields[fmt.Sprintf("query parameter %s", name)] = values[0]
+			}
+		}
+	}
+
+	for _, h := range req.Headers {
+		name := strings.ToLower(h.Name)
+		if ignoredCorrelationHeaders[name] || name == "" || name[0] == ':' {
+			continue
+		}
+		fields[fmt.Sprintf("header %s", name)] = h.Value
+	}
+
+	for _, c := range req.Cookies {
+		fields[fmt.Sprintf("cookie %s", c.Name)] = c.Value
+	}
+
+	if req.PostData == nil {
+		return fields
+	}
+	if req.PostData.MimeType == "application/x-www-form-urlencoded" {
+		if values, err := url.ParseQuery(req.PostData.Text); err == nil {
+			for name, v := range values {
+				if len(v) > 0 {
+					fields[fmt.Sprintf("form field %s", name)] = v[0]
+				}
+			}
+		}
+	} else if strings.Contains(req.PostData.MimeType, "json") {
+		var body interface{}
+		if err := json.Unmarshal([]byte(req.PostData.Text), &body); err == nil {
+			for path, value := range flattenJSON(body) {
+				fields[fmt.Sprintf("JSON field %s", path)] = value
+			}
+		}
+	}
+
+	return fields
+}
+
+// flattenJSON returns all of the string and number leaves of the given JSON
+// value, keyed by their gjson path. Leaves whose keys can't be expressed
+// without escaping are skipped.
+func flattenJSON(v interface{}) map[string]string {
+	result := make(map[string]string)
+	var walk func(path string, v interface{})
+	walk = func(path string, v interface{}) {
+		join := func(key string) string {
+			if path == "" {
+				return key
+			}
+			return path + "." + key
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			for k, child := range val {
+				if k == "" || strings.ContainsAny(k, ".*?|#@\\") {
+					continue
+				}
+				walk(join(k), child)
+			}
+		case []interface{}:
+			for i, child := range val {
+				walk(join(strconv.Itoa(i)), child)
+			}
+		case string:
+			result[path] = val
+		case float64:
+			result[path] = strconv.FormatFloat(val, 'f', -1, 64)
+		}
+	}
+	walk("", v)
+	return result
+}
+
+// findSource looks for the dynamic value in the responses of the given
+// entries, starting with the most recent one, and sets the extractor for the
+// first response which contains it.
+func (c *correlation) findSource(dv *dynamicValue, previous []*Entry) bool {
+	for i := len(previous) - 1; i >= 0; i-- {
+		resp := previous[i].Response
+		if resp == nil {
+			continue
+		}
+
+		for _, cookie := range resp.Cookies {
+			if cookie.Value == dv.value {
+				dv.source, dv.extractor = previous[i], fmt.Sprintf("res.cookies[%q][0].value", cookie.Name)
+				return true
+			}
+		}
+
+		for _, h := range resp.Headers {
+			if strings.EqualFold(h.Name, "set-cookie") {
+				continue
+			}
+			header := fmt.Sprintf("res.headers[%q]", http.CanonicalHeaderKey(h.Name))
+			if h.Value == dv.value {
+				dv.source, dv.extractor = previous[i], header
+				return true
+			}
+			if extractor, ok := betweenExtractor(header, h.Value, dv.value); ok {
+				dv.source, dv.extractor, c.usesHelper = previous[i], extractor, true
+				return true
+			}
+		}
+
+		if resp.Content == nil || !strings.Contains(resp.Content.Text, dv.value) {
+			continue
+		}
+		if strings.Contains(resp.Content.MimeType, "json") {
+			var body interface{}
+			if err := json.Unmarshal([]byte(resp.Content.Text), &body); err == nil {
+				if path, ok := findJSONPath(body, dv.value); ok {
+					dv.source, dv.extractor = previous[i], fmt.Sprintf("res.json(%q)", path)
+					return true
+				}
+			}
+		}
+		if extractor, ok := betweenExtractor("res.body", resp.Content.Text, dv.value); ok {
+			dv.source, dv.extractor, c.usesHelper = previous[i], extractor, true
+			return true
+		}
+	}
+	return false
+}
+
+// findJSONPath returns the lexicographically first gjson path of a leaf with
+// the given value, so the generated scripts are deterministic.
+func findJSONPath(body interface{}, value string) (string, bool) {
+	var paths []string
+	for path, v := range flattenJSON(body) {
+		if v == value {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return "", false
+	}
+	sort.Strings(paths)
+	return paths[0], true
+}
+
+// betweenExtractor returns an extractBetween() call that extracts the value
+// from the text, using the text immediately surrounding its first occurrence
+// as boundaries. The left boundary is widened until it identifies the right
+// occurrence of the value.
+func betweenExtractor(textExpr, text, value string) (string, bool) {
+	pos := strings.Index(text, value)
+	if pos == -1 {
+		return "", false
+	}
+	end := pos + len(value)
+	var right string
+	if end < len(text) {
+		right = text[end : end+1]
+	}
+
+	for _, width := range []int{16, 32, 64} {
+		start := pos - width
+		if start < 0 {
+			start = 0
+		}
+		left := text[start:pos]
+		if nl := strings.LastIndexByte(left, '\n'); nl != -1 {
+			left = left[nl+1:]
+		}
+		if extractBetween(text, left, right) == value {
+			return fmt.Sprintf("extractBetween(%s, %q, %q)", textExpr, left, right), true
+		}
+	}
+	return "", false
+}
+
+// extractBetween mirrors the JS helper that is added to converted scripts.
+func extractBetween(text, left, right string) string {
+	start := strings.Index(text, left)
+	if start == -1 {
+		return ""
+	}
+	from := start + len(left)
+	end := len(text)
+	if right != "" {
+		if i := strings.Index(text[from:], right); i != -1 {
+			end = from + i
+		}
+	}
+	return text[from:end]
+}
+
+const extractBetweenJS = `function extractBetween(str, left, right) {
+	const start = str.indexOf(left);
+	if (start === -1) {
+		return undefined;
+	}
+	const from = start + left.length;
+	const end = right === "" ? -1 : str.indexOf(right, from);
+	return str.substring(from, end === -1 ? str.length : end);
+}
+`
+
+// uniqueVarName returns a valid JS identifier based on where the value was
+// sent, that wasn't used yet.
+func uniqueVarName(location string, used map[string]bool) string {
+	parts := strings.Fields(location)
+	name := parts[len(parts)-1]
+	if strings.HasPrefix(location, "path segment") {
+		name = "path_" + name
+	}
+
+	var b strings.Builder
+	b.WriteString("correlated_")
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+
+	varName := b.String()
+	for i := 2; used[varName]; i++ {
+		varName = fmt.Sprintf("%s_%d", b.String(), i)
+	}
+	used[varName] = true
+	return varName
+}
+
+// jsString returns the given string as a JS string literal, replacing the
+// occurrences of any of the already extracted dynamic values with their
+// variables in a template literal.
+func jsString(s string, defined []*dynamicValue) string {
+	if replaced, ok := substituteValues(s, defined, escapeTemplateLiteral); ok {
+		return "`" + replaced + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// substituteValues replaces all of the occurrences of the defined dynamic
+// values in the string with `${varName}` placeholders, escaping the rest of
+// the string with the supplied function. The second return value is false if
+// there was nothing to replace.
+func substituteValues(s string, defined []*dynamicValue, escape func(string) string) (string, bool) {
+	var b strings.Builder
+	replaced := false
+	for {
+		var match *dynamicValue
+		matchPos := -1
+		for _, dv := range defined {
+			pos := strings.Index(s, dv.value)
+			if pos == -1 {
+				continue
+			}
+			if matchPos == -1 || pos < matchPos || (pos == matchPos && len(dv.value) > len(match.value)) {
+				match, matchPos = dv, pos
+			}
+		}
+		if match == nil {
+			b.WriteString(escape(s))
+			return b.String(), replaced
+		}
+		replaced = true
+		b.WriteString(escape(s[:matchPos]))
+		b.WriteString("${" + match.varName + "}")
+		s = s[matchPos+len(match.value):]
+	}
+}
+
+func escapeTemplateLiteral(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(s)
+}
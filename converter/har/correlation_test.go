@@ -0,0 +1,131 @@
+package har
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/loader"
+	"go.k6.io/k6/metrics"
+)
+
+func newCorrelationTestHAR(token, csrf, nonce string) HAR {
+	started := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	return HAR{Log: &Log{
+		Version: "1.2",
+		Creator: &Creator{Name: "test"},
+		Entries: []*Entry{
+			{
+				StartedDateTime: started,
+				Request:         &Request{Method: "GET", URL: "https://example.com/login"},
+				Response: &Response{
+					Status: 200,
+					Content: &Content{
+						MimeType: "application/json",
+						Text:     fmt.Sprintf(`{"user":{"token":%q}}`, token),
+					},
+				},
+			},
+			{
+				StartedDateTime: started.Add(time.Second),
+				Request: &Request{
+					Method:  "GET",
+					URL:     "https://example.com/form?token=" + token,
+					Headers: []Header{{"Authorization", "Bearer " + token}},
+				},
+				Response: &Response{
+					Status: 200,
+					Content: &Content{
+						MimeType: "text/html",
+						Text:     fmt.Sprintf(`<form><input name="csrf" value="%s"></form>`, csrf),
+					},
+				},
+			},
+			{
+				StartedDateTime: started.Add(2 * time.Second),
+				Request: &Request{
+					Method: "POST",
+					URL:    "https://example.com/submit?nonce=" + nonce,
+					PostData: &PostData{
+						MimeType: "application/x-www-form-urlencoded",
+						Text:     "csrf=" + csrf + "&name=same",
+					},
+				},
+				Response: &Response{Status: 200, Content: &Content{MimeType: "text/plain", Text: "ok"}},
+			},
+		},
+	}}
+}
+
+func TestConvertCorrelateWith(t *testing.T) {
+	t.Parallel()
+
+	primary := newCorrelationTestHAR("abcd1234token", "c5rf1111", "n0nce111")
+	secondary := newCorrelationTestHAR("wxyz9876token", "c5rf2222", "n0nce222")
+
+	script, err := Convert(primary, lib.Options{}, 1, 2, false, false, 0, true, false, &secondary, nil, nil)
+	require.NoError(t, err)
+
+	assert.Contains(t, script, "function extractBetween(str, left, right) {")
+	assert.Contains(t, script, "\tlet correlated_token, correlated_csrf;\n")
+	assert.Contains(t, script, "\t\tcorrelated_token = res.json(\"user.token\");\n")
+	assert.Contains(t, script, "`https://example.com/form?token=${correlated_token}`")
+	assert.Contains(t, script, "\"Authorization\": `Bearer ${correlated_token}`")
+	assert.Contains(t, script, "\t\tcorrelated_csrf = extractBetween(res.body, \"e=\\\"csrf\\\" value=\\\"\", \"\\\"\");\n")
+	assert.Contains(t, script, "`csrf=${correlated_csrf}&name=same`")
+	assert.Contains(t, script, `// TODO: the value "n0nce111" of the query parameter nonce differs between the recordings`)
+	assert.NotContains(t, script, "abcd1234token\"")
+
+	registry := metrics.NewRegistry()
+	_, err = js.New(
+		&lib.RuntimeState{
+			Logger:         testutils.NewLogger(t),
+			BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+			Registry:       registry,
+		}, &loader.SourceData{
+			URL:  &url.URL{Path: "/script.js"},
+			Data: []byte(script),
+		}, nil)
+	require.NoError(t, err)
+}
+
+func TestConvertCorrelateWithRequiresNoBatch(t *testing.T) {
+	t.Parallel()
+
+	primary := newCorrelationTestHAR("abcd1234token", "c5rf1111", "n0nce111")
+	_, err := Convert(primary, lib.Options{}, 1, 2, false, false, 0, false, false, &primary, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "correlation requires --no-batch")
+}
+
+func TestBetweenExtractor(t *testing.T) {
+	t.Parallel()
+
+	text := `<a data-id="first">x</a><a data-id="second">y</a>`
+	extractor, ok := betweenExtractor("res.body", text, "second")
+	require.True(t, ok)
+	assert.Equal(t, `extractBetween(res.body, "</a><a data-id=\"", "\"")`, extractor)
+	assert.Equal(t, "second", extractBetween(text, "</a><a data-id=\"", "\""))
+
+	_, ok = betweenExtractor("res.body", text, "missing")
+	assert.False(t, ok)
+}
+
+func TestJSString(t *testing.T) {
+	t.Parallel()
+
+	defined := []*dynamicValue{
+		{varName: "correlated_id", value: "1234"},
+		{varName: "correlated_long_id", value: "123456"},
+	}
+	assert.Equal(t, `"no values"`, jsString("no values", defined))
+	assert.Equal(t, "`/items/${correlated_long_id}/${correlated_id}?a=\\`b\\``",
+		jsString("/items/123456/1234?a=`b`", defined))
+}
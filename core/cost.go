@@ -0,0 +1,101 @@
+package core
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+const bytesPerGB = 1e9
+
+// costAccountant estimates the cost of a test run in real time, based on the
+// prices in the cost model and the metric samples that are being emitted. The
+// estimations are emitted as estimated_cost samples, tagged with the cost_type
+// they are for, so they can be used in thresholds and outputs.
+//
+// It isn't safe for concurrent use, it's only used by the engine's metrics
+// processing goroutine.
+type costAccountant struct {
+	model   lib.CostModel
+	runTags *metrics.SampleTags
+
+	costMetric, vusMetric, reqsMetric, grpcMetric, dataMetric *metrics.Metric
+
+	lastVUsTime time.Time
+	lastVUs     float64
+}
+
+// newCostAccountant returns nil if the cost model isn't enabled, or if the
+// estimated_cost metric is disabled.
+func newCostAccountant(registry *metrics.Registry, opts lib.Options) *costAccountant {
+	if opts.Cost == nil || !opts.Cost.IsEnabled() {
+		return nil
+	}
+	costMetric := registry.Get(metrics.EstimatedCostName)
+	if costMetric == nil || costMetric.Disabled {
+		return nil
+	}
+	return &costAccountant{
+		model:      *opts.Cost,
+		runTags:    opts.RunTags,
+		costMetric: costMetric,
+		vusMetric:  registry.Get(metrics.VUsName),
+		reqsMetric: registry.Get(metrics.HTTPReqsName),
+		grpcMetric: registry.Get(metrics.GRPCReqDurationName),
+		dataMetric: registry.Get(metrics.DataReceivedName),
+	}
+}
+
+// process returns the estimated_cost samples for the given sample containers.
+func (ca *costAccountant) process(sampleContainers []metrics.SampleContainer) metrics.SampleContainer {
+	var requests, bytes, vuMinutes float64
+	var lastTime time.Time
+	for _, sc := range sampleContainers {
+		for _, s := range sc.GetSamples() {
+			switch s.Metric {
+			case nil:
+				continue
+			case ca.reqsMetric:
+				requests += s.Value
+			case ca.grpcMetric:
+				requests++
+			case ca.dataMetric:
+				bytes += s.Value
+			case ca.vusMetric:
+				if !ca.lastVUsTime.IsZero() && s.Time.After(ca.lastVUsTime) {
+					vuMinutes += ca.lastVUs * s.Time.Sub(ca.lastVUsTime).Minutes()
+				}
+				ca.lastVUsTime, ca.lastVUs = s.Time, s.Value
+			}
+			if s.Time.After(lastTime) {
+				lastTime = s.Time
+			}
+		}
+	}
+
+	var samples []metrics.Sample
+	add := func(costType string, price null.Float, amount float64) {
+		if !price.Valid || amount <= 0 || price.Float64 == 0 {
+			return
+		}
+		tags := ca.runTags.CloneTags()
+		tags["cost_type"] = costType
+		samples = append(samples, metrics.Sample{
+			Time:   lastTime,
+			Metric: ca.costMetric,
+			Tags:   metrics.IntoSampleTags(&tags),
+			Value:  price.Float64 * amount,
+		})
+	}
+	add("requests", ca.model.PerRequest, requests)
+	add("egress", ca.model.PerGBEgress, bytes/bytesPerGB)
+	add("vu_time", ca.model.PerVUMinute, vuMinutes)
+
+	if len(samples) == 0 {
+		return nil
+	}
+	return metrics.Samples(samples)
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestCostAccountant(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	assert.Nil(t, newCostAccountant(registry, lib.Options{}))
+	assert.Nil(t, newCostAccountant(registry, lib.Options{Cost: &lib.CostModel{}}))
+
+	disabledRegistry := metrics.NewRegistry()
+	require.NoError(t, metrics.RegisterBuiltinMetrics(disabledRegistry).Disable(metrics.EstimatedCostName))
+	assert.Nil(t, newCostAccountant(disabledRegistry, lib.Options{Cost: &lib.CostModel{PerRequest: null.FloatFrom(1)}}))
+
+	ca := newCostAccountant(registry, lib.Options{
+		Cost: &lib.CostModel{
+			PerRequest:  null.FloatFrom(0.01),
+			PerGBEgress: null.FloatFrom(2),
+			PerVUMinute: null.FloatFrom(0.5),
+		},
+		RunTags: metrics.IntoSampleTags(&map[string]string{"env": "staging"}),
+	})
+	require.NotNil(t, ca)
+
+	start := time.Now()
+	vus := func(offset time.Duration, value float64) metrics.Sample {
+		return metrics.Sample{Metric: builtinMetrics.VUs, Time: start.Add(offset), Value: value}
+	}
+	getCosts := func(sc metrics.SampleContainer) map[string]float64 {
+		if sc == nil {
+			return nil
+		}
+		result := make(map[string]float64)
+		for _, s := range sc.GetSamples() {
+			assert.Equal(t, builtinMetrics.EstimatedCost, s.Metric)
+			assert.Equal(t, map[string]string{"env": "staging", "cost_type": s.Tags.CloneTags()["cost_type"]},
+				s.Tags.CloneTags())
+			result[s.Tags.CloneTags()["cost_type"]] += s.Value
+		}
+		return result
+	}
+
+	assert.Nil(t, ca.process([]metrics.SampleContainer{vus(0, 10)}), "the first VUs sample has no duration")
+
+	costs := getCosts(ca.process([]metrics.SampleContainer{
+		metrics.ConnectedSamples{Samples: []metrics.Sample{
+			{Metric: builtinMetrics.HTTPReqs, Time: start, Value: 1},
+			{Metric: builtinMetrics.DataReceived, Time: start, Value: 250e6},
+			{Metric: builtinMetrics.DataSent, Time: start, Value: 1e9},
+		}},
+		metrics.Sample{Metric: builtinMetrics.GRPCReqDuration, Time: start, Value: 123},
+		vus(time.Minute, 20),
+	}))
+	assert.InDeltaMapValues(t, map[string]float64{"requests": 0.02, "egress": 0.5, "vu_time": 5}, costs, 1e-9)
+
+	costs = getCosts(ca.process([]metrics.SampleContainer{vus(90*time.Second, 0)}))
+	assert.InDeltaMapValues(t, map[string]float64{"vu_time": 5}, costs, 1e-9)
+}
@@ -60,6 +60,7 @@ type Engine struct {
 	runtimeOptions lib.RuntimeOptions
 
 	ingester output.Output
+	costs    *costAccountant // nil if there is no cost model
 
 	logger   *logrus.Entry
 	stopOnce sync.Once
@@ -88,6 +89,7 @@ func NewEngine(
 		Samples:        make(chan metrics.SampleContainer, opts.MetricSamplesBufferSize.Int64),
 		stopChan:       make(chan struct{}),
 		logger:         logger.WithField("component", "engine"),
+		costs:          newCostAccountant(registry, opts),
 	}
 
 	me, err := engine.NewMetricsEngine(registry, ex.GetState(), opts, rtOpts, logger)
@@ -99,6 +101,7 @@ func NewEngine(
 	if !(rtOpts.NoSummary.Bool && rtOpts.NoThresholds.Bool) {
 		e.ingester = me.GetIngester()
 		outputs = append(outputs, e.ingester)
+		ex.GetState().SetMetricsQuerier(me)
 	}
 
 	e.OutputManager = output.NewManager(outputs, logger, func(err error) {
@@ -256,7 +259,7 @@ func (e *Engine) processMetrics(globalCtx context.Context, processMetricsAfterRu
 		for sc := range e.Samples {
 			sampleContainers = append(sampleContainers, sc)
 		}
-		e.OutputManager.AddMetricSamples(sampleContainers)
+		e.OutputManager.AddMetricSamples(e.withCosts(sampleContainers))
 
 		if !e.runtimeOptions.NoThresholds.Bool {
 			// Process the thresholds one final time
@@ -273,7 +276,7 @@ func (e *Engine) processMetrics(globalCtx context.Context, processMetricsAfterRu
 	e.logger.Debug("Metrics processing started...")
 	processSamples := func() {
 		if len(sampleContainers) > 0 {
-			e.OutputManager.AddMetricSamples(sampleContainers)
+			e.OutputManager.AddMetricSamples(e.withCosts(sampleContainers))
 			// Make the new container with the same size as the previous
 			// one, assuming that we produce roughly the same amount of
 			// metrics data between ticks...
@@ -314,6 +317,18 @@ func (e *Engine) processMetrics(globalCtx context.Context, processMetricsAfterRu
 	}
 }
 
+// withCosts appends the estimated cost of the given samples to them, if there
+// is a cost model.
+func (e *Engine) withCosts(sampleContainers []metrics.SampleContainer) []metrics.SampleContainer {
+	if e.costs == nil {
+		return sampleContainers
+	}
+	if costs := e.costs.process(sampleContainers); costs != nil {
+		sampleContainers = append(sampleContainers, costs)
+	}
+	return sampleContainers
+}
+
 func (e *Engine) IsTainted() bool {
 	e.thresholdsTaintedLock.Lock()
 	defer e.thresholdsTaintedLock.Unlock()
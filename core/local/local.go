@@ -24,6 +24,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -279,12 +280,52 @@ func (e *ExecutionScheduler) emitVUsAndVUsMax(ctx context.Context, out chan<- me
 	}()
 }
 
+// preflight checks the init code of the first and last VUs that could be
+// initialized, before spending the time to initialize all of the planned VUs.
+// Some executors initialize the VUs after the planned ones during the test,
+// so the first of those is checked as well, and so are the last VUs that each
+// of the scenarios could need. The runner reports all of the problems of these
+// VUs together.
+func (e *ExecutionScheduler) preflight(ctx context.Context, logger *logrus.Entry) error {
+	runner, ok := e.runner.(lib.PreflightRunner)
+	if !ok {
+		logger.Warn("The preflight check isn't supported for this test, skipping it")
+		return nil
+	}
+	if e.maxPossibleVUs == 0 {
+		return nil
+	}
+
+	maxPlannedVUs := lib.GetMaxPlannedVUs(e.executionPlan)
+	candidates := []uint64{1, maxPlannedVUs, maxPlannedVUs + 1, e.maxPossibleVUs}
+	for _, cfg := range e.options.Scenarios {
+		reqs := cfg.GetExecutionRequirements(e.state.ExecutionTuple)
+		candidates = append(candidates, lib.GetMaxPlannedVUs(reqs), lib.GetMaxPossibleVUs(reqs))
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	vuIDs := []uint64{}
+	for _, id := range candidates {
+		if id > 0 && id <= e.maxPossibleVUs && (len(vuIDs) == 0 || id > vuIDs[len(vuIDs)-1]) {
+			vuIDs = append(vuIDs, id)
+		}
+	}
+	logger.WithField("vuIDs", vuIDs).Debugf("Running the preflight check...")
+	return runner.Preflight(ctx, vuIDs)
+}
+
 // Init concurrently initializes all of the planned VUs and then sequentially
 // initializes all of the configured executors.
 func (e *ExecutionScheduler) Init(ctx context.Context, samplesOut chan<- metrics.SampleContainer) error {
 	e.emitVUsAndVUsMax(ctx, samplesOut)
 
 	logger := e.logger.WithField("phase", "local-execution-scheduler-init")
+	if e.options.Preflight.Bool {
+		if err := e.preflight(ctx, logger); err != nil {
+			return err
+		}
+	}
+
 	vusToInitialize := lib.GetMaxPlannedVUs(e.executionPlan)
 	logger.WithFields(logrus.Fields{
 		"neededVUs":      vusToInitialize,
@@ -457,6 +498,8 @@ func (e *ExecutionScheduler) Run(globalCtx, runCtx context.Context, engineOut ch
 	//
 	// This is for addressing test.abort().
 	execCtx := executor.Context(runSubCtx)
+	circuitBreakersCtx, stopCircuitBreakers := context.WithCancel(execCtx)
+	circuitBreakersDone := e.runCircuitBreakers(circuitBreakersCtx, logger)
 	for _, exec := range e.executors {
 		go e.runExecutor(execCtx, runResults, engineOut, exec)
 	}
@@ -471,6 +514,8 @@ func (e *ExecutionScheduler) Run(globalCtx, runCtx context.Context, engineOut ch
 			cancel()
 		}
 	}
+	stopCircuitBreakers()
+	<-circuitBreakersDone
 
 	// Run teardown() after all executors are done, if it's not disabled
 	if !e.options.NoTeardown.Bool {
@@ -479,8 +524,10 @@ func (e *ExecutionScheduler) Run(globalCtx, runCtx context.Context, engineOut ch
 		e.initProgress.Modify(pb.WithConstProgress(1, "teardown()"))
 
 		// We run teardown() with the global context, so it isn't interrupted by
-		// aborts caused by thresholds or even Ctrl+C (unless used twice).
-		if err := e.runner.Teardown(globalCtx, engineOut); err != nil {
+		// aborts caused by thresholds or even Ctrl+C (unless used twice). It
+		// still needs the execution state, e.g. for the metric queries.
+		teardownCtx := lib.WithExecutionState(globalCtx, e.state)
+		if err := e.runner.Teardown(teardownCtx, engineOut); err != nil {
 			logger.WithField("error", err).Debug("teardown() aborted by error")
 			return err
 		}
@@ -492,6 +539,34 @@ func (e *ExecutionScheduler) Run(globalCtx, runCtx context.Context, engineOut ch
 	return firstErr
 }
 
+// runCircuitBreakers evaluates the circuit breakers of the script in the
+// background, while the executors are running, and aborts the test if an
+// abort circuit breaker is opened. The returned channel is closed when the
+// evaluation stops after the context is done.
+func (e *ExecutionScheduler) runCircuitBreakers(ctx context.Context, logger *logrus.Entry) <-chan struct{} {
+	done := make(chan struct{})
+	runner, ok := e.runner.(lib.CircuitBreakerRunner)
+	if !ok {
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+		abort, err := runner.GetCircuitBreakers().Run(ctx, e.state.GetMetricsQuerier(), e.logger)
+		if err != nil {
+			logger.WithError(err).Error("The circuit breakers won't be evaluated")
+			return
+		}
+		if abort != nil {
+			executor.CancelExecutorContext(ctx, &common.InterruptError{
+				Reason: fmt.Sprintf("%s by the circuit breaker '%s'", common.AbortTest, abort.Name),
+			})
+		}
+	}()
+	return done
+}
+
 // SetPaused pauses a test, if called with true. And if called with false, tries
 // to start/resume it. See the lib.ExecutionScheduler interface documentation of
 // the methods for the various caveats about its usage.
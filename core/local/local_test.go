@@ -40,6 +40,7 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/js"
+	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/executor"
 	"go.k6.io/k6/lib/netext"
@@ -1468,3 +1469,132 @@ func TestNewExecutionSchedulerHasWork(t *testing.T) {
 	assert.Len(t, execScheduler.executors, 2)
 	assert.Len(t, execScheduler.executorConfigs, 3)
 }
+
+type preflightRunner struct {
+	*minirunner.MiniRunner
+	vuIDs []uint64
+	err   error
+}
+
+func (r *preflightRunner) Preflight(_ context.Context, vuIDs []uint64) error {
+	r.vuIDs = vuIDs
+	return r.err
+}
+
+func TestExecutionSchedulerPreflight(t *testing.T) {
+	t.Parallel()
+
+	newRunner := func(err error) *preflightRunner {
+		return &preflightRunner{MiniRunner: &minirunner.MiniRunner{}, err: err}
+	}
+	getOptions := func(preflight bool) lib.Options {
+		config := executor.NewConstantArrivalRateConfig("arrival")
+		config.Rate = null.IntFrom(10)
+		config.Duration = types.NullDurationFrom(time.Second)
+		config.PreAllocatedVUs = null.IntFrom(3)
+		config.MaxVUs = null.IntFrom(10)
+		return lib.Options{
+			Preflight: null.BoolFrom(preflight),
+			Scenarios: lib.ScenarioConfigs{"arrival": config},
+		}
+	}
+
+	t.Run("checks the first and last VUs", func(t *testing.T) {
+		t.Parallel()
+		runner := newRunner(nil)
+		_, cancel, _, _ := newTestExecutionScheduler(t, runner, nil, getOptions(true))
+		defer cancel()
+		assert.Equal(t, []uint64{1, 3, 4, 10}, runner.vuIDs)
+	})
+
+	t.Run("checks the last VUs of every scenario", func(t *testing.T) {
+		t.Parallel()
+		options := getOptions(true)
+		config := executor.NewConstantVUsConfig("constant")
+		config.VUs = null.IntFrom(2)
+		config.Duration = types.NullDurationFrom(time.Second)
+		options.Scenarios["constant"] = config
+		runner := newRunner(nil)
+		_, cancel, _, _ := newTestExecutionScheduler(t, runner, nil, options)
+		defer cancel()
+		assert.Equal(t, []uint64{1, 2, 3, 5, 6, 10, 12}, runner.vuIDs)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		runner := newRunner(nil)
+		_, cancel, _, _ := newTestExecutionScheduler(t, runner, nil, getOptions(false))
+		defer cancel()
+		assert.Nil(t, runner.vuIDs)
+	})
+
+	t.Run("fails the init", func(t *testing.T) {
+		t.Parallel()
+		runner := newRunner(errors.New("preflight error"))
+		require.NoError(t, runner.SetOptions(runner.GetOptions().Apply(getOptions(true))))
+		registry := metrics.NewRegistry()
+		builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+		execScheduler, err := NewExecutionScheduler(runner, builtinMetrics, testutils.NewLogger(t))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		err = execScheduler.Init(ctx, make(chan metrics.SampleContainer, 1000))
+		require.EqualError(t, err, "preflight error")
+	})
+}
+
+type circuitBreakerRunner struct {
+	*minirunner.MiniRunner
+	circuitBreakers *lib.CircuitBreakers
+}
+
+func (r *circuitBreakerRunner) GetCircuitBreakers() *lib.CircuitBreakers {
+	return r.circuitBreakers
+}
+
+type fixedMetricsQuerier float64
+
+func (q fixedMetricsQuerier) QueryMetric(string, string, time.Duration) (float64, error) {
+	return float64(q), nil
+}
+
+func (q fixedMetricsQuerier) QueryMetricRange(context.Context, string, string, time.Time, time.Time) (float64, error) {
+	return float64(q), nil
+}
+
+func (q fixedMetricsQuerier) CountSamples(string, time.Duration) (uint64, error) {
+	return uint64(q), nil
+}
+
+func TestExecutionSchedulerCircuitBreakerAbort(t *testing.T) {
+	t.Parallel()
+
+	runner := &circuitBreakerRunner{
+		MiniRunner: &minirunner.MiniRunner{
+			Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+				<-ctx.Done()
+				return nil
+			},
+		},
+		circuitBreakers: lib.NewCircuitBreakers(),
+	}
+	_, err := runner.circuitBreakers.Add("errors", lib.CircuitBreakerConfig{
+		Metric: "http_req_failed", Threshold: "rate<0.1", Interval: 10 * time.Millisecond, Action: lib.CircuitBreakerAbort,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel, execScheduler, samples := newTestExecutionScheduler(t, runner, nil, lib.Options{
+		VUs:      null.IntFrom(1),
+		Duration: types.NullDurationFrom(time.Minute),
+	})
+	defer cancel()
+	execScheduler.GetState().SetMetricsQuerier(fixedMetricsQuerier(100))
+
+	start := time.Now()
+	err = execScheduler.Run(ctx, ctx, samples)
+	require.Error(t, err)
+	assert.True(t, common.IsInterruptError(err))
+	assert.Contains(t, err.Error(), "test aborted by the circuit breaker 'errors'")
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
@@ -56,6 +56,7 @@ type Bundle struct {
 	RuntimeOptions    lib.RuntimeOptions
 	CompatibilityMode lib.CompatibilityMode // parsed value
 	registry          *metrics.Registry
+	circuitBreakers   *lib.CircuitBreakers
 
 	exports map[string]goja.Callable
 }
@@ -95,18 +96,21 @@ func NewBundle(
 	}
 	// Make a bundle, instantiate it into a throwaway VM to populate caches.
 	rt := goja.New()
+	initctx := NewInitContext(logger, rt, c, compatMode, new(context.Context),
+		filesystems, loader.Dir(src.URL))
+	initctx.moduleGate = newModuleGate(logger, rtOpts.EnableExperimental)
 	bundle := Bundle{
-		Filename: src.URL,
-		Source:   code,
-		Program:  pgm,
-		BaseInitContext: NewInitContext(logger, rt, c, compatMode, new(context.Context),
-			filesystems, loader.Dir(src.URL)),
+		Filename:          src.URL,
+		Source:            code,
+		Program:           pgm,
+		BaseInitContext:   initctx,
 		RuntimeOptions:    rtOpts,
 		CompatibilityMode: compatMode,
 		exports:           make(map[string]goja.Callable),
 		registry:          registry,
+		circuitBreakers:   lib.NewCircuitBreakers(),
 	}
-	if err = bundle.instantiate(logger, rt, bundle.BaseInitContext, 0); err != nil {
+	if err = bundle.instantiate(logger, rt, bundle.BaseInitContext, 0, nil); err != nil {
 		return nil, err
 	}
 
@@ -149,6 +153,7 @@ func NewBundleFromArchive(
 	rt := goja.New()
 	initctx := NewInitContext(logger, rt, c, compatMode,
 		new(context.Context), arc.Filesystems, arc.PwdURL)
+	initctx.moduleGate = newModuleGate(logger, rtOpts.EnableExperimental)
 
 	env := arc.Env
 	if env == nil {
@@ -170,9 +175,10 @@ func NewBundleFromArchive(
 		CompatibilityMode: compatMode,
 		exports:           make(map[string]goja.Callable),
 		registry:          registry,
+		circuitBreakers:   lib.NewCircuitBreakers(),
 	}
 
-	if err = bundle.instantiate(logger, rt, bundle.BaseInitContext, 0); err != nil {
+	if err = bundle.instantiate(logger, rt, bundle.BaseInitContext, 0, nil); err != nil {
 		return nil, err
 	}
 
@@ -260,7 +266,7 @@ func (b *Bundle) Instantiate(
 	// runtime, but no state, to allow module-provided types to function within the init context.
 	vuImpl.runtime = goja.New()
 	init := newBoundInitContext(b.BaseInitContext, vuImpl)
-	if err := b.instantiate(logger, vuImpl.runtime, init, vuID); err != nil {
+	if err := b.instantiate(logger, vuImpl.runtime, init, vuID, nil); err != nil {
 		return nil, err
 	}
 
@@ -299,7 +305,11 @@ func (b *Bundle) Instantiate(
 
 // Instantiates the bundle into an existing runtime. Not public because it also messes with a bunch
 // of other things, will potentially thrash data and makes a mess in it if the operation fails.
-func (b *Bundle) instantiate(logger logrus.FieldLogger, rt *goja.Runtime, init *InitContext, vuID uint64) (err error) {
+// If missingEnv isn't nil, the __ENV keys that the init code reads, but which aren't set, are
+// added to it.
+func (b *Bundle) instantiate(
+	logger logrus.FieldLogger, rt *goja.Runtime, init *InitContext, vuID uint64, missingEnv map[string]bool,
+) (err error) {
 	rt.SetFieldNameMapper(common.FieldNameMapper{})
 	rt.SetRandSource(common.NewRandSource())
 
@@ -313,7 +323,11 @@ func (b *Bundle) instantiate(logger logrus.FieldLogger, rt *goja.Runtime, init *
 	for key, value := range b.RuntimeOptions.Env {
 		env[key] = value
 	}
-	rt.Set("__ENV", env)
+	if missingEnv != nil {
+		rt.Set("__ENV", newMissingEnvRecorder(rt, env, missingEnv))
+	} else {
+		rt.Set("__ENV", env)
+	}
 	rt.Set("__VU", vuID)
 	_ = rt.Set("console", newConsole(logger))
 
@@ -324,10 +338,14 @@ func (b *Bundle) instantiate(logger logrus.FieldLogger, rt *goja.Runtime, init *
 	// TODO: get rid of the unused ctxPtr, use a real external context (so we
 	// can interrupt), build the common.InitEnvironment earlier and reuse it
 	initenv := &common.InitEnvironment{
-		Logger:      logger,
-		FileSystems: init.filesystems,
-		CWD:         init.pwd,
-		Registry:    b.registry,
+		Logger:          logger,
+		FileSystems:     init.filesystems,
+		CWD:             init.pwd,
+		Registry:        b.registry,
+		CircuitBreakers: b.circuitBreakers,
+	}
+	if init.moduleGate != nil {
+		initenv.ModuleGates = init.moduleGate.getModuleGates()
 	}
 	init.moduleVUImpl.initEnv = initenv
 	init.moduleVUImpl.ctx = context.Background()
@@ -390,3 +408,22 @@ func generateSourceMapLoader(logger logrus.FieldLogger, filesystems map[string]a
 		return data.Data, nil
 	}
 }
+
+// newMissingEnvRecorder returns a proxy of the __ENV object, which adds the
+// keys that are read from it, but which aren't set, to missing. The lookups of
+// the Object.prototype methods, of toJSON by JSON.stringify() and of symbols
+// aren't reads of env variables, so they aren't recorded.
+func newMissingEnvRecorder(rt *goja.Runtime, env map[string]string, missing map[string]bool) goja.Proxy {
+	objectPrototype := rt.Get("Object").ToObject(rt).Get("prototype").ToObject(rt)
+	return rt.NewProxy(rt.ToValue(env).ToObject(rt), &goja.ProxyTrapConfig{
+		Get: func(target *goja.Object, property string, receiver goja.Value) goja.Value {
+			if _, ok := env[property]; !ok && property != "toJSON" && objectPrototype.Get(property) == nil {
+				missing[property] = true
+			}
+			return target.Get(property)
+		},
+		GetSym: func(target *goja.Object, property *goja.Symbol, receiver goja.Value) goja.Value {
+			return target.GetSymbol(property)
+		},
+	})
+}
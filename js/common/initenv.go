@@ -26,6 +26,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/metrics"
 )
 
@@ -36,11 +37,24 @@ type InitEnvironment struct {
 	FileSystems map[string]afero.Fs
 	CWD         *url.URL
 	Registry    *metrics.Registry
+	ModuleGates map[string]ModuleGate
+	// The circuit breakers of the test run, which are defined in the init
+	// context and shared between all VUs.
+	CircuitBreakers *lib.CircuitBreakers
 	// TODO: add RuntimeOptions and other properties, goja sources, etc.
 	// ideally, we should leave this as the only data structure necessary for
 	// executing the init context for all JS modules
 }
 
+// ModuleGate is the state of an experimental or deprecated module for the
+// current test run.
+type ModuleGate struct {
+	Experimental   bool
+	Enabled        bool
+	Deprecated     bool
+	RemovalVersion string
+}
+
 // GetAbsFilePath should be used to access the FileSystems, since afero has a
 // bug when opening files with relative paths - it caches them from the FS root,
 // not the current working directory... So, if necessary, this method will
@@ -53,6 +53,22 @@ func newFileConsole(filepath string, formatter logrus.Formatter) (*console, erro
 	return &console{l}, nil
 }
 
+// Creates a logger for the structured script logs, writing them as JSON to the
+// file at the provided `filepath`.
+func newFileScriptLogger(filepath string) (*logrus.Logger, error) {
+	f, err := os.OpenFile(filepath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	l := logrus.New()
+	l.SetOutput(f)
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.SetLevel(logrus.DebugLevel)
+
+	return l, nil
+}
+
 func (c console) log(level logrus.Level, args ...goja.Value) {
 	var strs strings.Builder
 	for i := 0; i < len(args); i++ {
@@ -2,10 +2,12 @@ package js
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dop251/goja"
@@ -412,3 +414,38 @@ func TestFileConsole(t *testing.T) {
 		})
 	}
 }
+
+func TestScriptLogOutput(t *testing.T) {
+	t.Parallel()
+	logFilename := filepath.Join(t.TempDir(), "script.log")
+
+	r, err := getSimpleRunner(t, "/script", `
+		var exec = require("k6/execution");
+		var group = require("k6").group;
+		exports.default = function() {
+			group("checkout", function() {
+				exec.logger.debug("paid", {amount: 42});
+			});
+		}
+	`)
+	require.NoError(t, err)
+	require.NoError(t, r.SetOptions(lib.Options{ScriptLogOutput: null.StringFrom(logFilename)}))
+
+	initVU, err := r.newVU(1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, initVU.Activate(&lib.VUActivationParams{RunContext: ctx}).RunOnce())
+
+	fileContent, err := ioutil.ReadFile(logFilename) //nolint:gosec
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(fileContent, &entry))
+	assert.Equal(t, "paid", entry["msg"])
+	assert.Equal(t, "debug", entry["level"])
+	assert.Equal(t, "script", entry["source"])
+	assert.Equal(t, "::checkout", entry["group"])
+	assert.Equal(t, float64(42), entry["amount"])
+	assert.Equal(t, float64(1), entry["vu"])
+	assert.Equal(t, float64(0), entry["iter"])
+}
@@ -43,6 +43,7 @@ import (
 	"go.k6.io/k6/js/modules/k6/data"
 	"go.k6.io/k6/js/modules/k6/encoding"
 	"go.k6.io/k6/js/modules/k6/execution"
+	"go.k6.io/k6/js/modules/k6/experimental"
 	"go.k6.io/k6/js/modules/k6/grpc"
 	"go.k6.io/k6/js/modules/k6/html"
 	"go.k6.io/k6/js/modules/k6/http"
@@ -82,7 +83,8 @@ type InitContext struct {
 
 	logger logrus.FieldLogger
 
-	modules map[string]interface{}
+	modules    map[string]interface{}
+	moduleGate *moduleGate
 }
 
 // NewInitContext creates a new initcontext with the provided arguments
@@ -125,6 +127,7 @@ func newBoundInitContext(base *InitContext, vuImpl *moduleVUImpl) *InitContext {
 		compatibilityMode: base.compatibilityMode,
 		logger:            base.logger,
 		modules:           base.modules,
+		moduleGate:        base.moduleGate,
 		moduleVUImpl:      vuImpl,
 	}
 }
@@ -232,6 +235,11 @@ func (i *InitContext) requireModule(name string) (goja.Value, error) {
 	if !ok {
 		return nil, fmt.Errorf("unknown module: %s", name)
 	}
+	if i.moduleGate != nil {
+		if err := i.moduleGate.check(name); err != nil {
+			return nil, err
+		}
+	}
 	if m, ok := mod.(modules.Module); ok {
 		instance := m.NewModuleInstance(i.moduleVUImpl)
 		return i.moduleVUImpl.runtime.ToValue(toESModuleExports(instance.Exports())), nil
@@ -375,17 +383,26 @@ func (i *InitContext) allowOnlyOpenedFiles() {
 
 func getInternalJSModules() map[string]interface{} {
 	return map[string]interface{}{
-		"k6":             k6.New(),
-		"k6/crypto":      crypto.New(),
-		"k6/crypto/x509": x509.New(),
-		"k6/data":        data.New(),
-		"k6/encoding":    encoding.New(),
-		"k6/execution":   execution.New(),
-		"k6/net/grpc":    grpc.New(),
-		"k6/html":        html.New(),
-		"k6/http":        http.New(),
-		"k6/metrics":     metrics.New(),
-		"k6/ws":          ws.New(),
+		"k6":              k6.New(),
+		"k6/crypto":       crypto.New(),
+		"k6/crypto/x509":  x509.New(),
+		"k6/data":         data.New(),
+		"k6/encoding":     encoding.New(),
+		"k6/execution":    execution.New(),
+		"k6/experimental": experimental.New(),
+		"k6/net/grpc":     grpc.New(),
+		"k6/html":         html.New(),
+		"k6/http":         http.New(),
+		"k6/metrics":      metrics.New(),
+		"k6/ws":           ws.New(),
+	}
+}
+
+// getInternalJSModuleStatuses returns the statuses of the internal modules
+// which are experimental or deprecated.
+func getInternalJSModuleStatuses() map[string]modules.Status {
+	return map[string]modules.Status{
+		"k6/experimental": {Experimental: true},
 	}
 }
 
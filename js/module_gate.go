@@ -0,0 +1,103 @@
+package js
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// moduleGate decides which of the experimental modules may be imported and
+// warns about the use of deprecated ones. It's shared between all of the init
+// contexts of a bundle, so every deprecation warning is logged only once.
+type moduleGate struct {
+	logger   logrus.FieldLogger
+	statuses map[string]modules.Status
+	enabled  map[string]bool
+
+	warned   map[string]bool
+	warnedMx sync.Mutex
+}
+
+func newModuleGate(logger logrus.FieldLogger, enableExperimental []string) *moduleGate {
+	statuses := getInternalJSModuleStatuses()
+	for name, status := range modules.GetStatuses() {
+		statuses[name] = status
+	}
+
+	g := &moduleGate{
+		logger:   logger,
+		statuses: statuses,
+		enabled:  make(map[string]bool, len(enableExperimental)),
+		warned:   make(map[string]bool),
+	}
+
+	var unknown []string
+	for _, name := range enableExperimental {
+		if !g.statuses[name].Experimental {
+			unknown = append(unknown, name)
+		}
+		g.enabled[name] = true
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		logger.Warnf("--enable-experimental was used for '%s', which isn't an experimental module",
+			strings.Join(unknown, "', '"))
+	}
+
+	return g
+}
+
+// check returns an error if the module with the given name is experimental
+// and wasn't enabled, and logs a warning the first time a deprecated module
+// is imported.
+func (g *moduleGate) check(name string) error {
+	status, ok := g.statuses[name]
+	if !ok {
+		return nil
+	}
+	if status.Experimental && !g.enabled[name] {
+		return fmt.Errorf("the module '%s' is experimental and has to be explicitly enabled with "+
+			"--enable-experimental=%s", name, name)
+	}
+	if !status.Deprecated {
+		return nil
+	}
+
+	g.warnedMx.Lock()
+	defer g.warnedMx.Unlock()
+	if g.warned[name] {
+		return nil
+	}
+	g.warned[name] = true
+
+	msg := fmt.Sprintf("The module '%s' is deprecated", name)
+	if status.RemovalVersion != "" {
+		msg += fmt.Sprintf(" and will be removed in k6 %s", status.RemovalVersion)
+	}
+	if status.Alternative != "" {
+		msg += fmt.Sprintf(", please use '%s' instead", status.Alternative)
+	}
+	g.logger.Warn(msg)
+	return nil
+}
+
+// getModuleGates returns the gate state of all experimental and deprecated
+// modules, so it can be exposed to the scripts.
+func (g *moduleGate) getModuleGates() map[string]common.ModuleGate {
+	result := make(map[string]common.ModuleGate, len(g.statuses))
+	for name, status := range g.statuses {
+		result[name] = common.ModuleGate{
+			Experimental:   status.Experimental,
+			Enabled:        !status.Experimental || g.enabled[name],
+			Deprecated:     status.Deprecated,
+			RemovalVersion: status.RemovalVersion,
+		}
+	}
+	return result
+}
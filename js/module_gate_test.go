@@ -0,0 +1,127 @@
+package js
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+var uniqueGatedModuleNumber int64 //nolint:gochecknoglobals
+
+func registerGatedModules(t *testing.T) (experimental, deprecated string) {
+	t.Helper()
+	n := atomic.AddInt64(&uniqueGatedModuleNumber, 1)
+	experimental = fmt.Sprintf("k6/x/experimental-%d", n)
+	deprecated = fmt.Sprintf("k6/x/deprecated-%d", n)
+	modules.Register(experimental, map[string]interface{}{"value": "experimental"})
+	modules.SetStatus(experimental, modules.Status{Experimental: true})
+	modules.Register(deprecated, map[string]interface{}{"value": "deprecated"})
+	modules.SetStatus(deprecated, modules.Status{
+		Deprecated: true, RemovalVersion: "v1.0.0", Alternative: "k6/x/something-else",
+	})
+	return experimental, deprecated
+}
+
+func TestModuleGateExperimental(t *testing.T) {
+	t.Parallel()
+	experimental, _ := registerGatedModules(t)
+	script := fmt.Sprintf(`
+		var mod = require("%s");
+		exports.default = function() {};
+	`, experimental)
+
+	_, err := getSimpleRunner(t, "/script.js", script)
+	require.ErrorContains(t, err, fmt.Sprintf("--enable-experimental=%s", experimental))
+
+	_, err = getSimpleRunner(t, "/script.js", script, lib.RuntimeOptions{
+		CompatibilityMode:  null.StringFrom("base"),
+		EnableExperimental: []string{experimental},
+	})
+	require.NoError(t, err)
+}
+
+func TestModuleGateInternalExperimental(t *testing.T) {
+	t.Parallel()
+	script := `
+		var experimental = require("k6/experimental");
+		exports.default = function() {};
+	`
+
+	_, err := getSimpleRunner(t, "/script.js", script)
+	require.ErrorContains(t, err, "--enable-experimental=k6/experimental")
+
+	_, err = getSimpleRunner(t, "/script.js", script, lib.RuntimeOptions{
+		CompatibilityMode:  null.StringFrom("base"),
+		EnableExperimental: []string{"k6/experimental"},
+	})
+	require.NoError(t, err)
+}
+
+func TestModuleGateDeprecated(t *testing.T) {
+	t.Parallel()
+	experimental, deprecated := registerGatedModules(t)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	logger.Out = ioutil.Discard
+	hook := testutils.SimpleLogrusHook{HookedLevels: []logrus.Level{logrus.WarnLevel}}
+	logger.AddHook(&hook)
+
+	r, err := getSimpleRunner(t, "/script.js", fmt.Sprintf(`
+		var mod = require("%s");
+		var exec = require("k6/execution");
+		exports.default = function() {
+			var gates = exec.test.modules;
+			var exp = gates["%s"], dep = gates["%s"];
+			if (!exp.experimental || exp.enabled || exp.deprecated) {
+				throw new Error("unexpected experimental gate " + JSON.stringify(exp));
+			}
+			if (dep.experimental || !dep.enabled || !dep.deprecated || dep.removalVersion != "v1.0.0") {
+				throw new Error("unexpected deprecated gate " + JSON.stringify(dep));
+			}
+		};
+	`, deprecated, experimental, deprecated), logger)
+	require.NoError(t, err)
+
+	vu, err := r.NewVU(1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, vu.Activate(&lib.VUActivationParams{RunContext: ctx}).RunOnce())
+
+	entries := hook.Drain()
+	require.Len(t, entries, 1, "the deprecation warning should be logged only once")
+	assert.Equal(t, fmt.Sprintf(
+		"The module '%s' is deprecated and will be removed in k6 v1.0.0, please use 'k6/x/something-else' instead",
+		deprecated,
+	), entries[0].Message)
+}
+
+func TestModuleGateUnknown(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	logger.Out = ioutil.Discard
+	hook := testutils.SimpleLogrusHook{HookedLevels: []logrus.Level{logrus.WarnLevel}}
+	logger.AddHook(&hook)
+
+	gate := newModuleGate(logger, []string{"k6/x/not-experimental", "k6/http"})
+	entries := hook.Drain()
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Message, "'k6/http', 'k6/x/not-experimental'")
+	assert.NoError(t, gate.check("k6/http"))
+}
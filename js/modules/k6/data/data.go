@@ -34,13 +34,15 @@ type (
 	// RootModule is the global module instance that will create module
 	// instances for each VU.
 	RootModule struct {
-		shared sharedArrays
+		shared        sharedArrays
+		identityPools identityPools
 	}
 
 	// Data represents an instance of the data module.
 	Data struct {
-		vu     modules.VU
-		shared *sharedArrays
+		vu            modules.VU
+		shared        *sharedArrays
+		identityPools *identityPools
 	}
 
 	sharedArrays struct {
@@ -60,6 +62,9 @@ func New() *RootModule {
 		shared: sharedArrays{
 			data: make(map[string]sharedArray),
 		},
+		identityPools: identityPools{
+			data: make(map[string]*identityPool),
+		},
 	}
 }
 
@@ -67,8 +72,9 @@ func New() *RootModule {
 // a new instance for each VU.
 func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	return &Data{
-		vu:     vu,
-		shared: &rm.shared,
+		vu:            vu,
+		shared:        &rm.shared,
+		identityPools: &rm.identityPools,
 	}
 }
 
@@ -76,7 +82,8 @@ func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 func (d *Data) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
-			"SharedArray": d.sharedArray,
+			"SharedArray":  d.sharedArray,
+			"IdentityPool": d.identityPool,
 		},
 	}
 }
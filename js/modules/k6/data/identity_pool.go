@@ -0,0 +1,290 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	identityPoolWaitingName = "identity_pool_waiting"
+	identityPoolStarvedName = "identity_pool_starved"
+)
+
+type identityPools struct {
+	data map[string]*identityPool
+	mu   sync.RWMutex
+}
+
+// identityPool is shared between all VUs and contains the identities that
+// can be exclusively checked out by them. Only the identities which belong to
+// the execution segment of the current k6 instance are made available, so
+// multiple instances running parts of the same test never use the same one.
+type identityPool struct {
+	name       string
+	identities sharedArray
+
+	initOnce sync.Once
+	initErr  error
+	free     chan int
+	size     int
+
+	waiting, starved *metrics.Metric
+}
+
+// init distributes the identities of the pool between the execution segments
+// in the same striped way the VUs and iterations are distributed.
+func (p *identityPool) init(state *lib.State) error {
+	p.initOnce.Do(func() {
+		et, err := lib.NewExecutionTuple(state.Options.ExecutionSegment, state.Options.ExecutionSegmentSequence)
+		if err != nil {
+			p.initErr = err
+			return
+		}
+		total := int64(len(p.identities.arr))
+		p.free = make(chan int, total)
+		segIdx := lib.NewSegmentedIndex(et)
+		for _, unscaled := segIdx.Next(); unscaled <= total; _, unscaled = segIdx.Next() {
+			p.free <- int(unscaled - 1)
+			p.size++
+		}
+		if p.size == 0 {
+			p.initErr = fmt.Errorf(
+				"the identity pool '%s' has no identities for execution segment %s", p.name, et,
+			)
+		}
+	})
+	return p.initErr
+}
+
+// checkout returns the index of a free identity, waiting for one to be
+// returned to the pool if all of them are currently checked out.
+func (p *identityPool) checkout(ctx context.Context, state *lib.State) (int, error) {
+	if err := p.init(state); err != nil {
+		return -1, err
+	}
+
+	tags := state.CloneTags()
+	tags["identity_pool"] = p.name
+	sampleTags := metrics.IntoSampleTags(&tags)
+
+	start := time.Now()
+	select {
+	case idx := <-p.free:
+		metrics.PushIfNotDone(ctx, state.Samples, metrics.Sample{
+			Metric: p.waiting, Time: start, Tags: sampleTags, Value: 0,
+		})
+		return idx, nil
+	default:
+	}
+
+	metrics.PushIfNotDone(ctx, state.Samples, metrics.Sample{
+		Metric: p.starved, Time: start, Tags: sampleTags, Value: 1,
+	})
+	select {
+	case idx := <-p.free:
+		now := time.Now()
+		metrics.PushIfNotDone(ctx, state.Samples, metrics.Sample{
+			Metric: p.waiting, Time: now, Tags: sampleTags, Value: metrics.D(now.Sub(start)),
+		})
+		return idx, nil
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+}
+
+// checkedOutIdentity is a single checkout of an identity by a VU. It's
+// returned to the pool either explicitly, or automatically at the end of the
+// iteration, whichever happens first.
+type checkedOutIdentity struct {
+	pool     *identityPool
+	idx      int
+	returned uint32
+}
+
+func (c *checkedOutIdentity) giveBack() bool {
+	if !atomic.CompareAndSwapUint32(&c.returned, 0, 1) {
+		return false
+	}
+	c.pool.free <- c.idx
+	return true
+}
+
+// vuIdentityPool is the per-VU JS wrapper of an identityPool, which keeps
+// track of the identities the VU has checked out.
+type vuIdentityPool struct {
+	d    *Data
+	pool *identityPool
+
+	held    map[*goja.Object]*checkedOutIdentity
+	heldMux sync.Mutex
+}
+
+// identityPool is a constructor returning an identity pool identified by the
+// name, containing the elements of the array the function returns.
+func (d *Data) identityPool(call goja.ConstructorCall) *goja.Object {
+	rt := d.vu.Runtime()
+
+	if d.vu.State() != nil {
+		common.Throw(rt, errors.New("new IdentityPool must be called in the init context"))
+	}
+
+	name := call.Argument(0).String()
+	if name == "" {
+		common.Throw(rt, errors.New("empty name provided to IdentityPool's constructor"))
+	}
+
+	fn, ok := goja.AssertFunction(call.Argument(1))
+	if !ok {
+		common.Throw(rt, errors.New("a function is expected as the second argument of IdentityPool's constructor"))
+	}
+
+	pool, err := d.identityPools.get(rt, d.vu.InitEnv(), name, fn)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	vuPool := &vuIdentityPool{d: d, pool: pool, held: make(map[*goja.Object]*checkedOutIdentity)}
+	obj := rt.NewObject()
+	for key, method := range map[string]interface{}{
+		"checkout": vuPool.checkout,
+		"release":  vuPool.release,
+		"size":     vuPool.size,
+	} {
+		if err := obj.Set(key, method); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+	return obj
+}
+
+func (s *identityPools) get(
+	rt *goja.Runtime, initEnv *common.InitEnvironment, name string, call goja.Callable,
+) (*identityPool, error) {
+	s.mu.RLock()
+	pool, ok := s.data[name]
+	s.mu.RUnlock()
+	if ok {
+		return pool, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pool, ok = s.data[name]; ok {
+		return pool, nil
+	}
+
+	if initEnv == nil || initEnv.Registry == nil {
+		return nil, errors.New("the metrics registry isn't available")
+	}
+	waiting, err := initEnv.Registry.NewMetric(identityPoolWaitingName, metrics.Trend, metrics.Time)
+	if err != nil {
+		return nil, err
+	}
+	starved, err := initEnv.Registry.NewMetric(identityPoolStarvedName, metrics.Counter)
+	if err != nil {
+		return nil, err
+	}
+
+	pool = &identityPool{
+		name:       name,
+		identities: getShareArrayFromCall(rt, call),
+		waiting:    waiting,
+		starved:    starved,
+	}
+	s.data[name] = pool
+	return pool, nil
+}
+
+// checkout exclusively checks out an identity from the pool, waiting for one
+// to become available if needed. By default, the identity is automatically
+// returned at the end of the current iteration, but with the session scope,
+// it's kept by the VU until it's explicitly released.
+func (p *vuIdentityPool) checkout(options goja.Value) goja.Value {
+	rt := p.d.vu.Runtime()
+	state := p.d.vu.State()
+	if state == nil {
+		common.Throw(rt, errors.New("identities can't be checked out in the init context"))
+	}
+
+	sessionScope := false
+	if options != nil && !goja.IsUndefined(options) && !goja.IsNull(options) {
+		scope := options.ToObject(rt).Get("scope")
+		if scope != nil && !goja.IsUndefined(scope) {
+			switch scope.String() {
+			case "iteration":
+			case "session":
+				sessionScope = true
+			default:
+				common.Throw(rt, fmt.Errorf("invalid identity checkout scope '%s'", scope.String()))
+			}
+		}
+	}
+
+	if !sessionScope && state.IterationCleanups == nil {
+		common.Throw(rt, errors.New("identities with the iteration scope can't be checked out here"))
+	}
+
+	idx, err := p.pool.checkout(p.d.vu.Context(), state)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	checkedOut := &checkedOutIdentity{pool: p.pool, idx: idx}
+
+	identity := p.pool.identities.wrap(rt).ToObject(rt).Get(fmt.Sprint(idx)).ToObject(rt)
+	p.heldMux.Lock()
+	p.held[identity] = checkedOut
+	p.heldMux.Unlock()
+
+	if !sessionScope {
+		state.IterationCleanups.Add(func() {
+			p.heldMux.Lock()
+			delete(p.held, identity)
+			p.heldMux.Unlock()
+			checkedOut.giveBack()
+		})
+	}
+
+	return identity
+}
+
+// release returns a checked out identity to the pool.
+func (p *vuIdentityPool) release(identity goja.Value) {
+	rt := p.d.vu.Runtime()
+	var obj *goja.Object
+	if identity != nil && !goja.IsUndefined(identity) && !goja.IsNull(identity) {
+		obj = identity.ToObject(rt)
+	}
+
+	p.heldMux.Lock()
+	checkedOut, ok := p.held[obj]
+	delete(p.held, obj)
+	p.heldMux.Unlock()
+
+	if !ok || !checkedOut.giveBack() {
+		common.Throw(rt, errors.New("the identity isn't currently checked out from this pool"))
+	}
+}
+
+// size returns the number of identities available to this instance, i.e.
+// the ones in its execution segment.
+func (p *vuIdentityPool) size() int {
+	rt := p.d.vu.Runtime()
+	state := p.d.vu.State()
+	if state == nil {
+		return len(p.pool.identities.arr)
+	}
+	if err := p.pool.init(state); err != nil {
+		common.Throw(rt, err)
+	}
+	return p.pool.size
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+const makeIdentityPoolScript = `
+var pool = new data.IdentityPool("users", function() {
+	var users = [];
+	for (var i = 0; i < 3; i++) {
+		users.push({username: "user" + i, password: "secret" + i});
+	}
+	return users;
+});
+`
+
+func newIdentityPoolTestRuntime(t *testing.T) (*goja.Runtime, *modulestest.VU, chan metrics.SampleContainer) {
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	vu := &modulestest.VU{
+		RuntimeField: rt,
+		InitEnvField: &common.InitEnvironment{Registry: metrics.NewRegistry()},
+		CtxField:     context.Background(),
+	}
+	m, ok := New().NewModuleInstance(vu).(*Data)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("data", m.Exports().Named))
+	_, err := rt.RunString(makeIdentityPoolScript)
+	require.NoError(t, err)
+
+	samples := make(chan metrics.SampleContainer, 100)
+	vu.StateField = &lib.State{
+		Options:           lib.Options{},
+		Samples:           samples,
+		Tags:              lib.NewTagMap(nil),
+		IterationCleanups: &lib.IterationCleanups{},
+	}
+	return rt, vu, samples
+}
+
+func TestIdentityPoolCheckout(t *testing.T) {
+	t.Parallel()
+	rt, vu, samples := newIdentityPoolTestRuntime(t)
+
+	_, err := rt.RunString(`
+		var session = pool.checkout({scope: "session"});
+		var first = pool.checkout();
+		var second = pool.checkout();
+		var names = [session.username, first.username, second.username].sort().join(",");
+		if (names !== "user0,user1,user2") {
+			throw new Error("unexpected identities " + names);
+		}
+		if (pool.size() !== 3) {
+			throw new Error("unexpected size " + pool.size());
+		}
+		pool.release(first);
+	`)
+	require.NoError(t, err)
+
+	_, err = rt.RunString(`pool.release(first);`)
+	require.ErrorContains(t, err, "isn't currently checked out")
+	_, err = rt.RunString(`pool.checkout({scope: "forever"});`)
+	require.ErrorContains(t, err, "invalid identity checkout scope")
+
+	// The second identity is returned as soon as the iteration ends, so the
+	// next one doesn't have to wait for it
+	vu.StateField.IterationCleanups.Run()
+	_, err = rt.RunString(`
+		var third = pool.checkout();
+		var fourth = pool.checkout();
+		if (third.username === session.username || fourth.username === session.username) {
+			throw new Error("the session identity was returned at the end of the iteration");
+		}
+		pool.release(third);
+	`)
+	require.NoError(t, err)
+	vu.StateField.IterationCleanups.Run()
+	_, err = rt.RunString(`pool.release(fourth);`)
+	require.ErrorContains(t, err, "isn't currently checked out")
+
+	var waits, starvations int
+	for _, sc := range metrics.GetBufferedSamples(samples) {
+		for _, s := range sc.GetSamples() {
+			name, _ := s.Tags.Get("identity_pool")
+			assert.Equal(t, "users", name)
+			switch s.Metric.Name {
+			case identityPoolWaitingName:
+				waits++
+			case identityPoolStarvedName:
+				starvations++
+			}
+		}
+	}
+	assert.Equal(t, 5, waits)
+	assert.Equal(t, 0, starvations)
+}
+
+func TestIdentityPoolCheckoutWithoutIterationCleanups(t *testing.T) {
+	t.Parallel()
+	rt, vu, _ := newIdentityPoolTestRuntime(t)
+	vu.StateField.IterationCleanups = nil
+
+	_, err := rt.RunString(`pool.checkout();`)
+	require.ErrorContains(t, err, "iteration scope can't be checked out")
+	_, err = rt.RunString(`pool.checkout({scope: "session"});`)
+	require.NoError(t, err)
+}
+
+func TestIdentityPoolCheckoutInterrupted(t *testing.T) {
+	t.Parallel()
+	rt, vu, _ := newIdentityPoolTestRuntime(t)
+
+	_, err := rt.RunString(`pool.checkout({scope: "session"}); pool.checkout({scope: "session"}); pool.checkout({scope: "session"});`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	vu.CtxField = ctx
+	_, err = rt.RunString(`pool.checkout();`)
+	require.ErrorContains(t, err, context.DeadlineExceeded.Error())
+}
+
+func TestIdentityPoolExecutionSegments(t *testing.T) {
+	t.Parallel()
+
+	seq, err := lib.NewExecutionSegmentSequenceFromString("0,1/3,2/3,1")
+	require.NoError(t, err)
+
+	seen := make(map[int]bool)
+	for _, segment := range seq {
+		pool := &identityPool{name: "users", identities: sharedArray{arr: make([]string, 10)}}
+		require.NoError(t, pool.init(&lib.State{
+			Options: lib.Options{ExecutionSegment: segment, ExecutionSegmentSequence: &seq},
+		}))
+		assert.InDelta(t, 10.0/3, pool.size, 1)
+		for i := 0; i < pool.size; i++ {
+			idx := <-pool.free
+			assert.False(t, seen[idx], "identity %d is used by multiple segments", idx)
+			seen[idx] = true
+		}
+	}
+	assert.Len(t, seen, 10)
+
+	pool := &identityPool{name: "users", identities: sharedArray{arr: make([]string, 1)}}
+	segment, err := lib.NewExecutionSegmentFromString("1/3:2/3")
+	require.NoError(t, err)
+	err = pool.init(&lib.State{Options: lib.Options{ExecutionSegment: segment, ExecutionSegmentSequence: &seq}})
+	require.ErrorContains(t, err, "has no identities for execution segment")
+}
@@ -37,12 +37,19 @@ import (
 type (
 	// RootModule is the global module instance that will create module
 	// instances for each VU.
-	RootModule struct{}
+	RootModule struct {
+		logRateLimiters *logRateLimiters
+	}
 
 	// ModuleInstance represents an instance of the execution module.
 	ModuleInstance struct {
-		vu  modules.VU
-		obj *goja.Object
+		root *RootModule
+		vu   modules.VU
+		obj  *goja.Object
+
+		// the state of the experimental and deprecated modules, it's only
+		// available in the init context so it's copied here
+		moduleGates map[string]common.ModuleGate
 	}
 )
 
@@ -53,13 +60,18 @@ var (
 
 // New returns a pointer to a new RootModule instance.
 func New() *RootModule {
-	return &RootModule{}
+	return &RootModule{
+		logRateLimiters: &logRateLimiters{data: make(map[string]*logRateLimiter)},
+	}
 }
 
 // NewModuleInstance implements the modules.Module interface to return
 // a new instance for each VU.
-func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
-	mi := &ModuleInstance{vu: vu}
+func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	mi := &ModuleInstance{root: r, vu: vu}
+	if initEnv := vu.InitEnv(); initEnv != nil {
+		mi.moduleGates = initEnv.ModuleGates
+	}
 	rt := vu.Runtime()
 	o := rt.NewObject()
 	defProp := func(name string, newInfo func() (*goja.Object, error)) {
@@ -79,6 +91,14 @@ func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	defProp("test", mi.newTestInfo)
 	defProp("vu", mi.newVUInfo)
 
+	logger, err := mi.newLogger(nil)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	if err = o.DefineDataProperty("logger", logger, goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE); err != nil {
+		common.Throw(rt, err)
+	}
+
 	mi.obj = o
 
 	return mi
@@ -160,6 +180,23 @@ func (mi *ModuleInstance) newInstanceInfo() (*goja.Object, error) {
 		"vusInitialized": func() interface{} {
 			return es.GetInitializedVUsCount()
 		},
+		"vus": func() interface{} {
+			now := time.Now()
+			activities := es.GetVUActivities()
+			vus := make([]map[string]interface{}, 0, len(activities))
+			for _, a := range activities {
+				vus = append(vus, map[string]interface{}{
+					"id":           a.ID,
+					"scenario":     a.Scenario,
+					"iteration":    a.Iteration,
+					"group":        a.Group,
+					"url":          a.URL,
+					"status":       string(a.Status),
+					"timeInStatus": float64(a.TimeInStatus(now)) / float64(time.Millisecond),
+				})
+			}
+			return vus
+		},
 	}
 
 	return newInfoObj(rt, ti)
@@ -193,6 +230,19 @@ func (mi *ModuleInstance) newTestInfo() (*goja.Object, error) {
 			}
 			return optionsObject
 		},
+		// the experimental and deprecated modules and whether they are enabled
+		"modules": func() interface{} {
+			gates := make(map[string]interface{}, len(mi.moduleGates))
+			for name, gate := range mi.moduleGates {
+				gates[name] = map[string]interface{}{
+					"experimental":   gate.Experimental,
+					"enabled":        gate.Enabled,
+					"deprecated":     gate.Deprecated,
+					"removalVersion": gate.RemovalVersion,
+				}
+			}
+			return rt.ToValue(gates)
+		},
 	}
 
 	return newInfoObj(rt, ti)
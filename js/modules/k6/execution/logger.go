@@ -0,0 +1,172 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+)
+
+// logRateLimiters contains the named rate limiters of the script logger,
+// they are shared between all VUs so the limits are for the whole instance.
+type logRateLimiters struct {
+	data map[string]*logRateLimiter
+	mu   sync.Mutex
+}
+
+type logRateLimiter struct {
+	limiter *rate.Limiter
+	dropped uint64
+}
+
+func (s *logRateLimiters) get(name string, entriesPerSecond float64) *logRateLimiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, ok := s.data[name]; ok {
+		return l
+	}
+	burst := int(entriesPerSecond)
+	if burst < 1 {
+		burst = 1
+	}
+	l := &logRateLimiter{limiter: rate.NewLimiter(rate.Limit(entriesPerSecond), burst)}
+	s.data[name] = l
+	return l
+}
+
+// allow reports whether an entry can be logged now and how many entries were
+// dropped since the last logged one.
+func (l *logRateLimiter) allow() (bool, uint64) {
+	if !l.limiter.Allow() {
+		atomic.AddUint64(&l.dropped, 1)
+		return false, 0
+	}
+	return true, atomic.SwapUint64(&l.dropped, 0)
+}
+
+// scriptLogger writes structured log entries from the script, automatically
+// annotated with the current scenario, VU, iteration, group and URL.
+type scriptLogger struct {
+	mi      *ModuleInstance
+	limiter *logRateLimiter // nil if the logger isn't rate-limited
+}
+
+// newLogger returns a goja.Object with the logging methods of the logger.
+func (mi *ModuleInstance) newLogger(limiter *logRateLimiter) (*goja.Object, error) {
+	rt := mi.vu.Runtime()
+	l := &scriptLogger{mi: mi, limiter: limiter}
+
+	o := rt.NewObject()
+	for key, method := range map[string]interface{}{
+		"debug":         l.levelFunc(logrus.DebugLevel),
+		"info":          l.levelFunc(logrus.InfoLevel),
+		"warn":          l.levelFunc(logrus.WarnLevel),
+		"error":         l.levelFunc(logrus.ErrorLevel),
+		"withRateLimit": l.withRateLimit,
+	} {
+		if err := o.Set(key, method); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+func (l *scriptLogger) levelFunc(level logrus.Level) func(goja.Value, goja.Value) {
+	return func(msg goja.Value, fields goja.Value) {
+		l.log(level, msg, fields)
+	}
+}
+
+// withRateLimit returns a logger which logs at most the given number of
+// entries per second. Loggers with the same name share the same limit,
+// across all VUs.
+func (l *scriptLogger) withRateLimit(name string, entriesPerSecond float64) *goja.Object {
+	rt := l.mi.vu.Runtime()
+	if name == "" {
+		common.Throw(rt, errors.New("the rate-limited logger needs a name"))
+	}
+	if entriesPerSecond <= 0 {
+		common.Throw(rt, fmt.Errorf("the rate limit of logger '%s' should be more than 0", name))
+	}
+
+	o, err := l.mi.newLogger(l.mi.root.logRateLimiters.get(name, entriesPerSecond))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return o
+}
+
+func (l *scriptLogger) log(level logrus.Level, msg goja.Value, fields goja.Value) {
+	rt := l.mi.vu.Runtime()
+	state := l.mi.vu.State()
+	if state == nil {
+		common.Throw(rt, errors.New("using the script logger in the init context is not supported"))
+	}
+
+	var logger logrus.FieldLogger = state.Logger
+	if state.ScriptLogger != nil {
+		logger = state.ScriptLogger
+	}
+	if logger == nil || !isLevelEnabled(logger, level) {
+		return
+	}
+
+	var dropped uint64
+	if l.limiter != nil {
+		var ok bool
+		if ok, dropped = l.limiter.allow(); !ok {
+			return
+		}
+	}
+
+	entryFields := logrus.Fields{}
+	if fields != nil && !goja.IsUndefined(fields) && !goja.IsNull(fields) {
+		obj := fields.ToObject(rt)
+		for _, key := range obj.Keys() {
+			entryFields[key] = obj.Get(key).Export()
+		}
+	}
+	// the automatic fields take precedence over the ones from the script
+	entryFields["source"] = "script"
+	entryFields["vu"] = state.VUID
+	entryFields["iter"] = state.Iteration
+	if ss := lib.GetScenarioState(l.mi.vu.Context()); ss != nil {
+		entryFields["scenario"] = ss.Name
+	}
+	if state.Group != nil && state.Group.Path != "" {
+		entryFields["group"] = state.Group.Path
+	}
+	if state.LastRequestURL != "" {
+		entryFields["url"] = state.LastRequestURL
+	}
+	if dropped > 0 {
+		entryFields["dropped"] = dropped
+	}
+
+	var msgStr string
+	if msg != nil && !goja.IsUndefined(msg) {
+		msgStr = msg.String()
+	}
+	logger.WithFields(entryFields).Log(level, msgStr)
+}
+
+// isLevelEnabled checks the level before the fields of an entry are built,
+// when the logger supports it.
+func isLevelEnabled(logger logrus.FieldLogger, level logrus.Level) bool {
+	switch l := logger.(type) {
+	case *logrus.Logger:
+		return l.IsLevelEnabled(level)
+	case *logrus.Entry:
+		return l.Logger.IsLevelEnabled(level)
+	default:
+		return true
+	}
+}
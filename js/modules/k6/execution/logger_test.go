@@ -0,0 +1,130 @@
+package execution
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+)
+
+func setupLoggerExecEnv(t *testing.T, state *lib.State) (*goja.Runtime, *testutils.SimpleLogrusHook) {
+	t.Helper()
+	logHook := &testutils.SimpleLogrusHook{HookedLevels: logrus.AllLevels}
+	testLog := logrus.New()
+	testLog.AddHook(logHook)
+	testLog.SetOutput(ioutil.Discard)
+	testLog.SetLevel(logrus.InfoLevel)
+
+	rt := goja.New()
+	ctx := lib.WithScenarioState(context.Background(), &lib.ScenarioState{Name: "checkout"})
+	vu := &modulestest.VU{
+		RuntimeField: rt,
+		InitEnvField: &common.InitEnvironment{},
+		CtxField:     ctx,
+	}
+	m, ok := New().NewModuleInstance(vu).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	if state != nil {
+		state.Logger = testLog
+		vu.StateField = state
+	}
+	return rt, logHook
+}
+
+func TestScriptLogger(t *testing.T) {
+	t.Parallel()
+
+	group, err := lib.NewGroup("", nil)
+	require.NoError(t, err)
+	group, err = group.Group("login")
+	require.NoError(t, err)
+
+	rt, hook := setupLoggerExecEnv(t, &lib.State{
+		VUID:           3,
+		Iteration:      7,
+		Group:          group,
+		LastRequestURL: "https://test.k6.io/login",
+	})
+	_, err = rt.RunString(`
+		exec.logger.debug("hidden");
+		exec.logger.info("logged in", {user: "admin", vu: "fake"});
+		exec.logger.error("no fields");
+	`)
+	require.NoError(t, err)
+
+	entries := hook.Drain()
+	require.Len(t, entries, 2)
+	assert.Equal(t, logrus.InfoLevel, entries[0].Level)
+	assert.Equal(t, "logged in", entries[0].Message)
+	assert.Equal(t, logrus.Fields{
+		"source":   "script",
+		"user":     "admin",
+		"vu":       uint64(3),
+		"iter":     int64(7),
+		"scenario": "checkout",
+		"group":    "::login",
+		"url":      "https://test.k6.io/login",
+	}, entries[0].Data)
+	assert.Equal(t, logrus.ErrorLevel, entries[1].Level)
+	assert.Equal(t, "no fields", entries[1].Message)
+}
+
+func TestScriptLoggerRateLimit(t *testing.T) {
+	t.Parallel()
+
+	rt, hook := setupLoggerExecEnv(t, &lib.State{})
+	_, err := rt.RunString(`
+		for (var i = 0; i < 10; i++) {
+			exec.logger.withRateLimit("noisy", 0.001).warn("message " + i);
+		}
+	`)
+	require.NoError(t, err)
+
+	entries := hook.Drain()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "message 0", entries[0].Message)
+	assert.NotContains(t, entries[0].Data, "dropped")
+
+	_, err = rt.RunString(`exec.logger.withRateLimit("noisy", 0);`)
+	require.ErrorContains(t, err, "should be more than 0")
+}
+
+func TestScriptLoggerInitContext(t *testing.T) {
+	t.Parallel()
+
+	rt, _ := setupLoggerExecEnv(t, nil)
+	_, err := rt.RunString(`exec.logger.info("init");`)
+	require.ErrorContains(t, err, "not supported")
+}
+
+func TestLogRateLimiterDropped(t *testing.T) {
+	t.Parallel()
+
+	limiters := &logRateLimiters{data: make(map[string]*logRateLimiter)}
+	l := limiters.get("test", 1)
+	assert.Same(t, l, limiters.get("test", 100))
+
+	ok, dropped := l.allow()
+	assert.True(t, ok)
+	assert.Zero(t, dropped)
+	for i := 0; i < 3; i++ {
+		ok, _ = l.allow()
+		assert.False(t, ok)
+	}
+	l.limiter.SetLimit(rate.Inf) // the next entry is allowed and reports the dropped ones
+	ok, dropped = l.allow()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), dropped)
+}
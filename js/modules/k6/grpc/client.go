@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"strings"
 	"time"
 
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext/grpcext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
@@ -20,6 +23,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -84,6 +88,12 @@ func (c *Client) Connect(addr string, params map[string]interface{}) (bool, erro
 	if err != nil {
 		return false, err
 	}
+	// Only the dns resolver returns multiple addresses that can be balanced
+	// between, with the default passthrough one round_robin is a no-op.
+	if p.LoadBalancingPolicy == "round_robin" && !strings.HasPrefix(addr, "dns:") {
+		return false, fmt.Errorf(
+			"the round_robin loadBalancingPolicy requires a dns target (e.g. \"dns:///%s\"), got %q", addr, addr)
+	}
 
 	opts := grpcext.DefaultOptions(c.vu)
 
@@ -102,10 +112,15 @@ func (c *Client) Connect(addr string, params map[string]interface{}) (bool, erro
 	if ua := state.Options.UserAgent; ua.Valid {
 		opts = append(opts, grpc.WithUserAgent(ua.ValueOrZero()))
 	}
+	opts = append(opts, p.dialOptions()...)
 
 	ctx, cancel := context.WithTimeout(c.vu.Context(), p.Timeout)
 	defer cancel()
 
+	// Both the dial and the reflection round-trip block the VU on the network.
+	start := time.Now()
+	defer func() { state.IterationTimings.AddProtocolTime(time.Since(start)) }()
+
 	c.addr = addr
 	c.conn, err = grpcext.Dial(ctx, addr, opts...)
 	if err != nil {
@@ -166,7 +181,8 @@ func (c *Client) Invoke(
 		md.Append(param, strval)
 	}
 
-	ctx, cancel := context.WithTimeout(c.vu.Context(), p.Timeout)
+	timeout := lib.GetTimeout(c.vu.Context(), state, p.Timeout, defaultInvokeTimeout)
+	ctx, cancel := context.WithTimeout(c.vu.Context(), timeout.Duration)
 	defer cancel()
 
 	tags := state.CloneTags()
@@ -189,14 +205,19 @@ func (c *Client) Invoke(
 	if _, ok := tags["name"]; !ok && state.Options.SystemTags.Has(metrics.TagName) {
 		tags["name"] = method
 	}
+	state.Options.Latency.SetRegionTag(tags, targetHostname(c.addr))
 
 	reqmsg := grpcext.Request{
 		MethodDescriptor: methodDesc,
 		Message:          b,
 		Tags:             tags,
+		Timeout:          timeout,
 	}
 
-	return c.conn.Invoke(ctx, method, md, reqmsg)
+	start := time.Now()
+	defer func() { state.IterationTimings.AddProtocolTime(time.Since(start)) }()
+
+	return c.conn.Invoke(ctx, method, md, reqmsg, p.callOptions()...)
 }
 
 // Close will close the client gRPC connection
@@ -262,16 +283,32 @@ func (c *Client) convertToMethodInfo(fdset *descriptorpb.FileDescriptorSet) ([]M
 	return rtn, nil
 }
 
+// defaultInvokeTimeout is used when neither the RPC, the group nor the
+// scenario specify a timeout.
+const defaultInvokeTimeout = time.Minute
+
 type params struct {
-	Metadata map[string]string
-	Tags     map[string]string
-	Timeout  time.Duration
+	Metadata       map[string]string
+	Tags           map[string]string
+	Timeout        types.NullDuration
+	MaxReceiveSize int64
+	MaxSendSize    int64
 }
 
-func (c *Client) parseParams(raw map[string]interface{}) (params, error) {
-	p := params{
-		Timeout: 1 * time.Minute,
+// callOptions returns the per-RPC options that override the connection ones.
+func (p params) callOptions() []grpc.CallOption {
+	var opts []grpc.CallOption
+	if p.MaxReceiveSize > 0 {
+		opts = append(opts, grpc.MaxCallRecvMsgSize(int(p.MaxReceiveSize)))
 	}
+	if p.MaxSendSize > 0 {
+		opts = append(opts, grpc.MaxCallSendMsgSize(int(p.MaxSendSize)))
+	}
+	return opts
+}
+
+func (c *Client) parseParams(raw map[string]interface{}) (params, error) {
+	p := params{}
 	for k, v := range raw {
 		switch k {
 		case "headers":
@@ -307,11 +344,23 @@ func (c *Client) parseParams(raw map[string]interface{}) (params, error) {
 				p.Tags[tk] = strVal
 			}
 		case "timeout":
-			var err error
-			p.Timeout, err = types.GetDurationValue(v)
+			timeout, err := types.GetDurationValue(v)
 			if err != nil {
 				return p, fmt.Errorf("invalid timeout value: %w", err)
 			}
+			p.Timeout = types.NullDurationFrom(timeout)
+		case "maxReceiveSize":
+			var err error
+			p.MaxReceiveSize, err = getPositiveInt(k, v)
+			if err != nil {
+				return p, err
+			}
+		case "maxSendSize":
+			var err error
+			p.MaxSendSize, err = getPositiveInt(k, v)
+			if err != nil {
+				return p, err
+			}
 		default:
 			return p, fmt.Errorf("unknown param: %q", k)
 		}
@@ -323,6 +372,93 @@ type connectParams struct {
 	IsPlaintext           bool
 	UseReflectionProtocol bool
 	Timeout               time.Duration
+	MaxReceiveSize        int64
+	MaxSendSize           int64
+	InitialWindowSize     int64
+	InitialConnWindowSize int64
+	Keepalive             *keepalive.ClientParameters
+	LoadBalancingPolicy   string
+}
+
+// minWindowSize is the smallest flow control window size gRPC allows, smaller
+// values are silently ignored by it.
+const minWindowSize = 64 * 1024
+
+// dialOptions returns the gRPC channel options for the configured params.
+func (p connectParams) dialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+	var callOpts []grpc.CallOption
+	if p.MaxReceiveSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(int(p.MaxReceiveSize)))
+	}
+	if p.MaxSendSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(int(p.MaxSendSize)))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+	if p.InitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(int32(p.InitialWindowSize)))
+	}
+	if p.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(int32(p.InitialConnWindowSize)))
+	}
+	if p.Keepalive != nil {
+		opts = append(opts, grpc.WithKeepaliveParams(*p.Keepalive))
+	}
+	if p.LoadBalancingPolicy != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(
+			fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, p.LoadBalancingPolicy),
+		))
+	}
+	return opts
+}
+
+func getPositiveInt(name string, v interface{}) (int64, error) {
+	var result int64
+	switch n := v.(type) {
+	case int64:
+		result = n
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("invalid %s value: '%#v', it needs to be an integer", name, v)
+		}
+		result = int64(n)
+	default:
+		return 0, fmt.Errorf("invalid %s value: '%#v', it needs to be an integer", name, v)
+	}
+	if result <= 0 || result > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid %s value: %d, it needs to be between 1 and %d", name, result, math.MaxInt32)
+	}
+	return result, nil
+}
+
+func parseKeepaliveParams(v interface{}) (*keepalive.ClientParameters, error) {
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid keepalive value: '%#v', it needs to be an object", v)
+	}
+	kp := &keepalive.ClientParameters{}
+	for k, v := range raw {
+		var err error
+		switch k {
+		case "time":
+			kp.Time, err = types.GetDurationValue(v)
+		case "timeout":
+			kp.Timeout, err = types.GetDurationValue(v)
+		case "permitWithoutStream":
+			var ok bool
+			if kp.PermitWithoutStream, ok = v.(bool); !ok {
+				err = fmt.Errorf("'%#v', it needs to be boolean", v)
+			}
+		default:
+			return nil, fmt.Errorf("unknown keepalive param: %q", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid keepalive %s value: %w", k, err)
+		}
+	}
+	return kp, nil
 }
 
 func (c *Client) parseConnectParams(raw map[string]interface{}) (connectParams, error) {
@@ -351,6 +487,42 @@ func (c *Client) parseConnectParams(raw map[string]interface{}) (connectParams,
 			if !ok {
 				return params, fmt.Errorf("invalid reflect value: '%#v', it needs to be boolean", v)
 			}
+		case "maxReceiveSize":
+			var err error
+			if params.MaxReceiveSize, err = getPositiveInt(k, v); err != nil {
+				return params, err
+			}
+		case "maxSendSize":
+			var err error
+			if params.MaxSendSize, err = getPositiveInt(k, v); err != nil {
+				return params, err
+			}
+		case "initialWindowSize", "initialConnWindowSize":
+			size, err := getPositiveInt(k, v)
+			if err != nil {
+				return params, err
+			}
+			if size < minWindowSize {
+				return params, fmt.Errorf("invalid %s value: %d, it needs to be at least %d", k, size, minWindowSize)
+			}
+			if k == "initialWindowSize" {
+				params.InitialWindowSize = size
+			} else {
+				params.InitialConnWindowSize = size
+			}
+		case "keepalive":
+			var err error
+			if params.Keepalive, err = parseKeepaliveParams(v); err != nil {
+				return params, err
+			}
+		case "loadBalancingPolicy":
+			policy, ok := v.(string)
+			if !ok || (policy != "pick_first" && policy != "round_robin") {
+				return params, fmt.Errorf(
+					"invalid loadBalancingPolicy value: '%#v', it needs to be \"pick_first\" or \"round_robin\"", v,
+				)
+			}
+			params.LoadBalancingPolicy = policy
 
 		default:
 			return params, fmt.Errorf("unknown connect param: %q", k)
@@ -375,3 +547,15 @@ func walkFileDescriptors(seen map[string]struct{}, fd *desc.FileDescriptor) []*d
 
 	return fds
 }
+
+// targetHostname returns the hostname of a gRPC target, which can have a
+// resolver scheme, e.g. "dns:///example.com:443".
+func targetHostname(target string) string {
+	if i := strings.LastIndexByte(target, '/'); i >= 0 {
+		target = target[i+1:]
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+	return target
+}
@@ -81,6 +81,8 @@ func TestClient(t *testing.T) {
 				SystemTags: metrics.NewSystemTagSet(
 					metrics.TagName,
 					metrics.TagURL,
+					metrics.TagBackend,
+					metrics.TagConnID,
 				),
 				UserAgent: null.StringFrom("k6-test"),
 			},
@@ -259,6 +261,122 @@ func TestClient(t *testing.T) {
 				client.load([], "../../../../vendor/google.golang.org/grpc/test/grpc_testing/test.proto");`},
 			vuString: codeBlock{code: `client.connect("GRPCBIN_ADDR", { timeout: 3456.3 });`},
 		},
+		{
+			name: "ConnectChannelOptions",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../vendor/google.golang.org/grpc/test/grpc_testing/test.proto");`},
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				tb.GRPCStub.EmptyCallFunc = func(context.Context, *grpc_testing.Empty) (*grpc_testing.Empty, error) {
+					return &grpc_testing.Empty{}, nil
+				}
+			},
+			vuString: codeBlock{
+				code: `
+				client.connect("dns:///HTTP2BIN_IP:HTTP2BIN_PORT", {
+					keepalive: { time: "30s", timeout: 5000, permitWithoutStream: true },
+					maxReceiveSize: 1048576,
+					maxSendSize: 1048576,
+					initialWindowSize: 131072,
+					initialConnWindowSize: 262144,
+					loadBalancingPolicy: "round_robin",
+				});
+				var resp = client.invoke("grpc.testing.TestService/EmptyCall", {})
+				if (resp.status !== grpc.StatusOK) {
+					throw new Error("unexpected error: " + JSON.stringify(resp.error) + "or status: " + resp.status)
+				}`,
+				asserts: func(t *testing.T, rb *httpmultibin.HTTPMultiBin, samples chan metrics.SampleContainer, _ error) {
+					backend := rb.Replacer.Replace("HTTP2BIN_IP:HTTP2BIN_PORT")
+					var found bool
+					for _, sc := range metrics.GetBufferedSamples(samples) {
+						for _, s := range sc.GetSamples() {
+							if s.Metric.Name != metrics.GRPCReqDurationName {
+								continue
+							}
+							found = true
+							tag, ok := s.Tags.Get("backend")
+							assert.True(t, ok)
+							assert.Equal(t, backend, tag)
+							tag, ok = s.Tags.Get("conn_id")
+							assert.True(t, ok)
+							assert.NotEmpty(t, tag)
+						}
+					}
+					assert.True(t, found, "expected a grpc_req_duration sample")
+				},
+			},
+		},
+		{
+			name: "ConnectRoundRobinWithoutDNSTarget",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../vendor/google.golang.org/grpc/test/grpc_testing/test.proto");`},
+			vuString: codeBlock{
+				code: `client.connect("GRPCBIN_ADDR", { loadBalancingPolicy: "round_robin" });`,
+				err:  "the round_robin loadBalancingPolicy requires a dns target",
+			},
+		},
+		{
+			name: "ConnectInvalidWindowSize",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../vendor/google.golang.org/grpc/test/grpc_testing/test.proto");`},
+			vuString: codeBlock{
+				code: `client.connect("GRPCBIN_ADDR", { initialWindowSize: 1024 });`,
+				err:  "invalid initialWindowSize value: 1024, it needs to be at least 65536",
+			},
+		},
+		{
+			name: "ConnectInvalidMaxReceiveSize",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../vendor/google.golang.org/grpc/test/grpc_testing/test.proto");`},
+			vuString: codeBlock{
+				code: `client.connect("GRPCBIN_ADDR", { maxReceiveSize: 10.5 });`,
+				err:  "invalid maxReceiveSize value: '10.5', it needs to be an integer",
+			},
+		},
+		{
+			name: "ConnectInvalidKeepalive",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../vendor/google.golang.org/grpc/test/grpc_testing/test.proto");`},
+			vuString: codeBlock{
+				code: `client.connect("GRPCBIN_ADDR", { keepalive: { interval: "10s" } });`,
+				err:  `unknown keepalive param: "interval"`,
+			},
+		},
+		{
+			name: "ConnectInvalidLoadBalancingPolicy",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../vendor/google.golang.org/grpc/test/grpc_testing/test.proto");`},
+			vuString: codeBlock{
+				code: `client.connect("GRPCBIN_ADDR", { loadBalancingPolicy: "random" });`,
+				err:  "invalid loadBalancingPolicy value",
+			},
+		},
+		{
+			name: "InvokeMaxReceiveSize",
+			initString: codeBlock{code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../vendor/google.golang.org/grpc/test/grpc_testing/test.proto");`},
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				tb.GRPCStub.UnaryCallFunc = func(context.Context, *grpc_testing.SimpleRequest) (*grpc_testing.SimpleResponse, error) {
+					return &grpc_testing.SimpleResponse{Payload: &grpc_testing.Payload{Body: make([]byte, 1024)}}, nil
+				}
+			},
+			vuString: codeBlock{code: `
+				client.connect("GRPCBIN_ADDR");
+				var resp = client.invoke("grpc.testing.TestService/UnaryCall", {}, { maxReceiveSize: 100 })
+				if (resp.status !== grpc.StatusResourceExhausted) {
+					throw new Error("unexpected status: " + resp.status)
+				}
+				resp = client.invoke("grpc.testing.TestService/UnaryCall", {})
+				if (resp.status !== grpc.StatusOK) {
+					throw new Error("unexpected error: " + JSON.stringify(resp.error) + "or status: " + resp.status)
+				}`},
+		},
 		{
 			name: "Connect",
 			initString: codeBlock{code: `
@@ -799,3 +917,16 @@ func TestClientInvokeHeadersDeprecated(t *testing.T) {
 	require.Len(t, entries, 1)
 	require.Contains(t, entries[0].Message, "headers property is deprecated")
 }
+
+func TestTargetHostname(t *testing.T) {
+	t.Parallel()
+
+	for target, hostname := range map[string]string{
+		"example.com:443":        "example.com",
+		"dns:///example.com:443": "example.com",
+		"dns://8.8.8.8/[::1]:80": "::1",
+		"example.com":            "example.com",
+	} {
+		assert.Equal(t, hostname, targetHostname(target), target)
+	}
+}
@@ -44,6 +44,7 @@ type ModuleInstance struct {
 	rootModule    *RootModule
 	defaultClient *Client
 	exports       *goja.Object
+	routes        httpext.RoutedTransports
 }
 
 var (
@@ -107,6 +108,7 @@ func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	mustExport("options", mi.defaultClient.getMethodClosure(http.MethodOptions))
 	mustExport("request", mi.defaultClient.Request)
 	mustExport("batch", mi.defaultClient.Batch)
+	mustExport("longPoll", mi.defaultClient.LongPoll)
 	mustExport("setResponseCallback", mi.defaultClient.SetResponseCallback)
 
 	mustExport("expectedStatuses", mi.expectedStatuses) // TODO: refactor?
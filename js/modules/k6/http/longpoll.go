@@ -0,0 +1,209 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext/httpext"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	defaultLongPollBackoff    = time.Second
+	defaultLongPollMaxBackoff = 30 * time.Second
+)
+
+// LongPollResult is returned by http.longPoll().
+type LongPollResult struct {
+	// Response is the response of the last poll.
+	Response *Response `js:"response"`
+	// Cycles is how many polls were made.
+	Cycles int64 `js:"cycles"`
+	// Done is whether the stop condition was met, instead of the long-poll
+	// stopping because of the maximum cycles or duration.
+	Done bool `js:"done"`
+}
+
+type longPollConfig struct {
+	method      string
+	body        interface{}
+	params      goja.Value
+	until       goja.Callable
+	maxCycles   int64
+	maxDuration time.Duration
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// LongPoll polls a long-polling endpoint until the `until` callback returns
+// true for a response, or until the maximum cycles or duration are reached,
+// e.g. http.longPoll(url, {until: (r) => r.json().ready, maxDuration: "2m"}).
+// After the failed polls, it waits for a backoff that is doubled every time,
+// up to maxBackoff, and is reset by a successful poll. Every poll emits the
+// http_longpoll_wait and http_longpoll_cycles metrics instead of the
+// http_req_* ones, so the long waits don't distort the aggregates of the
+// regular requests.
+func (c *Client) LongPoll(url goja.Value, options goja.Value) (*LongPollResult, error) {
+	state := c.moduleInstance.vu.State()
+	if state == nil {
+		return nil, ErrHTTPForbiddenInInitContext
+	}
+	config, err := c.parseLongPollConfig(options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid long-poll options: %w", err)
+	}
+
+	ctx := c.moduleInstance.vu.Context()
+	rt := c.moduleInstance.vu.Runtime()
+	start := time.Now()
+	backoff := config.backoff
+	result := &LongPollResult{}
+	for {
+		req, err := c.parseRequest(config.method, url, config.body, config.params)
+		if err != nil {
+			return nil, err
+		}
+		req.NoHTTPReqMetrics = true
+
+		pollStart := time.Now()
+		resp, err := c.makeRequest(state, req)
+		if err != nil {
+			return nil, err
+		}
+		c.pushLongPollSamples(state, req, resp, pollStart, time.Since(pollStart))
+		result.Response = resp
+		result.Cycles++
+
+		failed := resp.Error != "" || (req.ResponseCallback != nil && !req.ResponseCallback(resp.Status))
+		if !failed && config.until != nil {
+			done, err := config.until(goja.Undefined(), rt.ToValue(resp))
+			if err != nil {
+				return nil, err
+			}
+			if done.ToBoolean() {
+				result.Done = true
+				return result, nil
+			}
+		}
+
+		if config.maxCycles > 0 && result.Cycles >= config.maxCycles {
+			return result, nil
+		}
+		if config.maxDuration > 0 && time.Since(start) >= config.maxDuration {
+			return result, nil
+		}
+		if !failed {
+			backoff = config.backoff
+			if ctx.Err() != nil {
+				return result, nil
+			}
+			continue
+		}
+
+		sleepStart := time.Now()
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+		state.IterationTimings.AddSleepTime(time.Since(sleepStart))
+		if ctx.Err() != nil {
+			return result, nil
+		}
+		if backoff *= 2; backoff > config.maxBackoff {
+			backoff = config.maxBackoff
+		}
+	}
+}
+
+func (c *Client) pushLongPollSamples(
+	state *lib.State, req *httpext.ParsedHTTPRequest, resp *Response, pollStart time.Time, wait time.Duration,
+) {
+	tags := state.CloneTags()
+	for k, v := range req.Tags {
+		tags[k] = v
+	}
+	if _, ok := tags["name"]; !ok && state.Options.SystemTags.Has(metrics.TagName) {
+		tags["name"] = req.URL.Name
+	}
+	if state.Options.SystemTags.Has(metrics.TagMethod) {
+		tags["method"] = req.Req.Method
+	}
+	if state.Options.SystemTags.Has(metrics.TagStatus) {
+		tags["status"] = strconv.Itoa(resp.Status)
+	}
+	sampleTags := metrics.IntoSampleTags(&tags)
+
+	metrics.PushIfNotDone(c.moduleInstance.vu.Context(), state.Samples, metrics.ConnectedSamples{
+		Samples: []metrics.Sample{
+			{Metric: state.BuiltinMetrics.HTTPLongPollWait, Time: pollStart, Tags: sampleTags, Value: metrics.D(wait)},
+			{Metric: state.BuiltinMetrics.HTTPLongPollCycles, Time: pollStart, Tags: sampleTags, Value: 1},
+		},
+		Tags: sampleTags,
+		Time: pollStart,
+	})
+}
+
+//nolint:cyclop
+func (c *Client) parseLongPollConfig(v goja.Value) (longPollConfig, error) {
+	config := longPollConfig{
+		method:     http.MethodGet,
+		params:     goja.Undefined(),
+		backoff:    defaultLongPollBackoff,
+		maxBackoff: defaultLongPollMaxBackoff,
+	}
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return config, errors.New("at least one of 'until', 'maxCycles' or 'maxDuration' is required")
+	}
+	rt := c.moduleInstance.vu.Runtime()
+	params := v.ToObject(rt)
+	for _, k := range params.Keys() {
+		var err error
+		value := params.Get(k)
+		switch k {
+		case "method":
+			config.method = value.String()
+		case "body":
+			config.body = value.Export()
+		case "params":
+			config.params = value
+		case "until":
+			var isFunc bool
+			if config.until, isFunc = goja.AssertFunction(value); !isFunc {
+				err = errors.New("it should be a function")
+			}
+		case "maxCycles":
+			config.maxCycles = value.ToInteger()
+		case "maxDuration":
+			config.maxDuration, err = types.GetDurationValue(value.Export())
+		case "backoff":
+			config.backoff, err = types.GetDurationValue(value.Export())
+		case "maxBackoff":
+			config.maxBackoff, err = types.GetDurationValue(value.Export())
+		default:
+			err = errors.New("unknown option")
+		}
+		if err != nil {
+			return config, fmt.Errorf("invalid option '%s': %w", k, err)
+		}
+	}
+
+	if config.until == nil && config.maxCycles <= 0 && config.maxDuration <= 0 {
+		return config, errors.New("at least one of 'until', 'maxCycles' or 'maxDuration' is required")
+	}
+	if config.maxCycles < 0 || config.maxDuration < 0 || config.backoff < 0 || config.maxBackoff < 0 {
+		return config, errors.New("the maxCycles, maxDuration, backoff and maxBackoff can't be negative")
+	}
+	if config.maxBackoff < config.backoff {
+		config.maxBackoff = config.backoff
+	}
+	return config, nil
+}
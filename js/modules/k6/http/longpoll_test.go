@@ -0,0 +1,108 @@
+package http
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/metrics"
+)
+
+func TestLongPoll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("until", func(t *testing.T) {
+		t.Parallel()
+		tb, _, samples, rt, _ := newRuntime(t)
+
+		var polls int64
+		tb.Mux.HandleFunc("/longpoll", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt64(&polls, 1) < 3 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_, _ = w.Write([]byte(`{"ready": true}`))
+		}))
+
+		_, err := rt.RunString(tb.Replacer.Replace(`
+			var result = http.longPoll("HTTPBIN_URL/longpoll", {
+				until: (r) => r.status === 200 && r.json().ready,
+				maxCycles: 10,
+			});
+			if (!result.done || result.cycles !== 3 || result.response.status !== 200) {
+				throw new Error("unexpected result: " + JSON.stringify(result));
+			}
+		`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), atomic.LoadInt64(&polls))
+
+		var waits, cycles, reqs int
+		for _, container := range metrics.GetBufferedSamples(samples) {
+			for _, sample := range container.GetSamples() {
+				switch sample.Metric.Name {
+				case metrics.HTTPLongPollWaitName:
+					waits++
+					assert.Equal(t, "GET", sample.Tags.CloneTags()["method"])
+					assert.Equal(t, tb.Replacer.Replace("HTTPBIN_URL/longpoll"), sample.Tags.CloneTags()["name"])
+					assert.Contains(t, []string{"200", "204"}, sample.Tags.CloneTags()["status"])
+				case metrics.HTTPLongPollCyclesName:
+					cycles++
+					assert.Equal(t, 1.0, sample.Value)
+				case metrics.HTTPReqsName, metrics.HTTPReqDurationName, metrics.HTTPReqFailedName:
+					reqs++
+				}
+			}
+		}
+		assert.Equal(t, 3, waits)
+		assert.Equal(t, 3, cycles)
+		assert.Equal(t, 0, reqs)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		t.Parallel()
+		tb, state, _, rt, _ := newRuntime(t)
+		state.Options.Throw = null.BoolFrom(false)
+
+		var polls int64
+		tb.Mux.HandleFunc("/longpoll-fail", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&polls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		_, err := rt.RunString(tb.Replacer.Replace(`
+			var start = Date.now();
+			var result = http.longPoll("HTTPBIN_URL/longpoll-fail", {
+				until: () => true,
+				maxCycles: 3,
+				backoff: "100ms",
+			});
+			var elapsed = Date.now() - start;
+			if (result.done || result.cycles !== 3 || result.response.status !== 503) {
+				throw new Error("unexpected result: " + JSON.stringify(result));
+			}
+			// the backoffs after the first two polls are 100ms and 200ms
+			if (elapsed < 300) {
+				throw new Error("expected the backoff to be at least 300ms but it was " + elapsed);
+			}
+		`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), atomic.LoadInt64(&polls))
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		t.Parallel()
+		tb, _, _, rt, _ := newRuntime(t)
+
+		_, err := rt.RunString(tb.Replacer.Replace(`http.longPoll("HTTPBIN_URL/get")`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least one of 'until', 'maxCycles' or 'maxDuration' is required")
+
+		_, err = rt.RunString(tb.Replacer.Replace(`http.longPoll("HTTPBIN_URL/get", {maxCycles: 1, interval: "1s"})`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid option 'interval': unknown option")
+	})
+}
@@ -36,10 +36,15 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 )
 
+// defaultRequestTimeout is used when neither the request, the group nor the
+// scenario specify a timeout.
+const defaultRequestTimeout = 60 * time.Second
+
 // ErrHTTPForbiddenInInitContext is used when a http requests was made in the init context
 var ErrHTTPForbiddenInInitContext = common.NewInitContextError("Making http requests in the init context is not supported")
 
@@ -85,10 +90,20 @@ func (c *Client) Request(method string, url goja.Value, args ...goja.Value) (*Re
 		return &Response{Response: r, client: c}, nil
 	}
 
+	return c.makeRequest(state, req)
+}
+
+// makeRequest makes the already parsed request and accounts for its duration
+// in the iteration timings.
+func (c *Client) makeRequest(state *lib.State, req *httpext.ParsedHTTPRequest) (*Response, error) {
+	state.Activity.SetURL(req.URL.Clean())
+	start := time.Now()
 	resp, err := httpext.MakeRequest(c.moduleInstance.vu.Context(), state, req)
+	state.IterationTimings.AddProtocolTime(time.Since(start))
 	if err != nil {
 		return nil, err
 	}
+	state.LastRequestURL = req.URL.Clean()
 	c.processResponse(resp, req.ResponseType)
 	return c.responseFromHTTPext(resp), nil
 }
@@ -132,7 +147,6 @@ func (c *Client) parseRequest(
 			URL:    u.GetURL(),
 			Header: make(http.Header),
 		},
-		Timeout:          60 * time.Second,
 		Throw:            state.Options.Throw.Bool,
 		Redirects:        state.Options.MaxRedirects,
 		Cookies:          make(map[string]*httpext.HTTPRequestCookie),
@@ -249,6 +263,7 @@ func (c *Client) parseRequest(
 		result.ActiveJar = state.CookieJar
 	}
 
+	var requestTimeout types.NullDuration
 	// TODO: ditch goja.Value, reflections and Object and use a simple go map and type assertions?
 	if params != nil && !goja.IsUndefined(params) && !goja.IsNull(params) {
 		params := params.ToObject(rt)
@@ -341,12 +356,16 @@ func (c *Client) parseRequest(
 				}
 			case "auth":
 				result.Auth = params.Get(k).String()
+			case "sni":
+				result.Route.SNI = params.Get(k).String()
+			case "connectTo":
+				result.Route.ConnectTo = params.Get(k).String()
 			case "timeout":
 				t, err := types.GetDurationValue(params.Get(k).Export())
 				if err != nil {
 					return nil, fmt.Errorf("invalid timeout value: %w", err)
 				}
-				result.Timeout = t
+				requestTimeout = types.NullDurationFrom(t)
 			case "throw":
 				result.Throw = params.Get(k).ToBoolean()
 			case "responseType":
@@ -368,6 +387,15 @@ func (c *Client) parseRequest(
 		}
 	}
 
+	if !result.Route.IsZero() {
+		if result.Transport, err = c.moduleInstance.routes.Get(state, result.Route, result.Req.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	timeout := lib.GetTimeout(c.moduleInstance.vu.Context(), state, requestTimeout, defaultRequestTimeout)
+	result.Timeout, result.TimeoutSource = timeout.Duration, timeout.Source
+
 	if result.ActiveJar != nil {
 		httpext.SetRequestCookies(result.Req, result.ActiveJar, result.Cookies)
 	}
@@ -470,6 +498,7 @@ func (c *Client) Batch(reqsV goja.Value) (interface{}, error) {
 	}
 
 	reqCount := len(batchReqs)
+	start := time.Now()
 	errs := httpext.MakeBatchRequests(
 		c.moduleInstance.vu.Context(), state, batchReqs, reqCount,
 		int(state.Options.Batch.Int64), int(state.Options.BatchPerHost.Int64),
@@ -481,6 +510,7 @@ func (c *Client) Batch(reqsV goja.Value) (interface{}, error) {
 			err = e
 		}
 	}
+	state.IterationTimings.AddProtocolTime(time.Since(start))
 	return results, err
 }
 
@@ -52,6 +52,7 @@ import (
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -2146,6 +2147,34 @@ func TestResponseTimingsWhenTimeout(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRequestTimeoutTags(t *testing.T) {
+	t.Parallel()
+	tb, state, samples, rt, _ := newRuntime(t)
+	state.Options.Throw = null.BoolFrom(false)
+	state.GroupTimeout = types.NullDurationFrom(100 * time.Millisecond)
+
+	getTimeoutTags := func(script string) map[string]string {
+		_, err := rt.RunString(tb.Replacer.Replace(script))
+		require.NoError(t, err)
+		for _, sc := range metrics.GetBufferedSamples(samples) {
+			for _, s := range sc.GetSamples() {
+				if s.Metric.Name == metrics.HTTPReqsName {
+					tags := s.Tags.CloneTags()
+					return map[string]string{"timeout": tags["timeout"], "timeout_source": tags["timeout_source"]}
+				}
+			}
+		}
+		return nil
+	}
+
+	assert.Equal(t, map[string]string{"timeout": "100ms", "timeout_source": "group"},
+		getTimeoutTags(`http.get("HTTPBIN_URL/delay/10");`))
+	assert.Equal(t, map[string]string{"timeout": "200ms", "timeout_source": "request"},
+		getTimeoutTags(`http.get("HTTPBIN_URL/delay/10", { timeout: "200ms" });`))
+	assert.Equal(t, map[string]string{"timeout": "", "timeout_source": ""},
+		getTimeoutTags(`http.get("HTTPBIN_URL/get");`), "only the timed out requests should be tagged")
+}
+
 func TestNoResponseBodyMangling(t *testing.T) {
 	t.Parallel()
 	tb, state, _, rt, _ := newRuntime(t)
@@ -2416,3 +2445,82 @@ func TestBinaryResponseWithStatus0(t *testing.T) {
 	`)
 	require.NoError(t, err)
 }
+
+func TestRequestRoute(t *testing.T) {
+	t.Parallel()
+	tb, _, samples, rt, _ := newRuntime(t)
+	tb.Mux.HandleFunc("/route", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverName := ""
+		if r.TLS != nil {
+			serverName = r.TLS.ServerName
+		}
+		_, _ = fmt.Fprintf(w, "%s %s", r.Host, serverName)
+	}))
+
+	_, err := rt.RunString(tb.Replacer.Replace(`
+		var res = http.get("http://sut.test/route", { connectTo: "HTTPBIN_IP:HTTPBIN_PORT" });
+		if (res.body !== "sut.test ") { throw new Error("wrong body: " + res.body); }
+
+		res = http.get("https://sut.test/route", {
+			connectTo: "HTTPSBIN_IP:HTTPSBIN_PORT",
+			sni: "HTTPSBIN_DOMAIN",
+			headers: { Host: "vhost.test" },
+		});
+		if (res.body !== "vhost.test HTTPSBIN_DOMAIN") { throw new Error("wrong body: " + res.body); }
+	`))
+	require.NoError(t, err)
+
+	var tags []map[string]string
+	for _, container := range metrics.GetBufferedSamples(samples) {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == metrics.HTTPReqsName {
+				tags = append(tags, sample.Tags.CloneTags())
+			}
+		}
+	}
+	require.Len(t, tags, 2)
+	assert.Equal(t, tb.Replacer.Replace("HTTPBIN_IP:HTTPBIN_PORT"), tags[0][metrics.TagConnectTo.String()])
+	assert.NotContains(t, tags[0], metrics.TagSNI.String())
+	assert.Equal(t, "https://sut.test/route", tags[1]["url"])
+	assert.Equal(t, tb.Replacer.Replace("HTTPSBIN_DOMAIN"), tags[1][metrics.TagSNI.String()])
+
+	_, err = rt.RunString(tb.Replacer.Replace(`
+		http.get("https://sut.test/route", { connectTo: "HTTPSBIN_IP:HTTPSBIN_PORT" });
+	`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "x509: certificate is valid for")
+}
+
+func TestRequestConnTags(t *testing.T) {
+	t.Parallel()
+	tb, state, samples, rt, _ := newRuntime(t)
+	systemTags := metrics.TagURL | metrics.TagLocalPort | metrics.TagConnID | metrics.TagTLSSessionReused
+	state.Options.SystemTags = &systemTags
+
+	_, err := rt.RunString(tb.Replacer.Replace(`
+		http.get("HTTPSBIN_URL/get");
+		http.get("HTTPSBIN_URL/get");
+		http.get("HTTPBIN_URL/get");
+	`))
+	require.NoError(t, err)
+
+	var tags []map[string]string
+	for _, container := range metrics.GetBufferedSamples(samples) {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == metrics.HTTPReqsName {
+				tags = append(tags, sample.Tags.CloneTags())
+			}
+		}
+	}
+	require.Len(t, tags, 3)
+	for _, tag := range tags {
+		assert.NotEmpty(t, tag["conn_id"])
+		assert.NotEmpty(t, tag["local_port"])
+	}
+	// the ID of the TLS connection is found and it's reused by the second request
+	assert.Equal(t, tags[0]["conn_id"], tags[1]["conn_id"])
+	assert.Equal(t, tags[0]["local_port"], tags[1]["local_port"])
+	assert.Equal(t, "false", tags[0]["tls_session_reused"])
+	assert.NotEqual(t, tags[0]["conn_id"], tags[2]["conn_id"])
+	assert.NotContains(t, tags[2], "tls_session_reused")
+}
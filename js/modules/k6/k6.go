@@ -23,6 +23,7 @@ package k6
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
 	"sync/atomic"
 	"time"
@@ -31,6 +32,7 @@ import (
 
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -90,12 +92,16 @@ func (*K6) Fail(msg string) (goja.Value, error) {
 // Sleep waits the provided seconds before continuing the execution.
 func (mi *K6) Sleep(secs float64) {
 	ctx := mi.vu.Context()
+	start := time.Now()
 	timer := time.NewTimer(time.Duration(secs * float64(time.Second)))
 	select {
 	case <-timer.C:
 	case <-ctx.Done():
 		timer.Stop()
 	}
+	if state := mi.vu.State(); state != nil {
+		state.IterationTimings.AddSleepTime(time.Since(start))
+	}
 }
 
 // RandomSeed sets the seed to the random generator used for this VU.
@@ -105,7 +111,9 @@ func (mi *K6) RandomSeed(seed int64) {
 }
 
 // Group wraps a function call and executes it within the provided group name.
-func (mi *K6) Group(name string, fn goja.Callable) (goja.Value, error) {
+// The optional params can contain a timeout, which overrides the scenario one
+// for all requests in the group, including the nested groups.
+func (mi *K6) Group(name string, fn goja.Callable, params goja.Value) (goja.Value, error) {
 	state := mi.vu.State()
 	if state == nil {
 		return nil, ErrGroupInInitContext
@@ -115,20 +123,34 @@ func (mi *K6) Group(name string, fn goja.Callable) (goja.Value, error) {
 		return nil, errors.New("group() requires a callback as a second argument")
 	}
 
+	if state.CircuitBreakers.ShouldSkipGroup(name) {
+		return goja.Undefined(), nil
+	}
+
+	timeout, err := mi.parseGroupTimeout(params)
+	if err != nil {
+		return goja.Undefined(), err
+	}
+
 	g, err := state.Group.Group(name)
 	if err != nil {
 		return goja.Undefined(), err
 	}
 
-	old := state.Group
+	old, oldTimeout := state.Group, state.GroupTimeout
 	state.Group = g
+	state.Activity.SetGroup(g.Path)
+	if timeout.Valid {
+		state.GroupTimeout = timeout
+	}
 
 	shouldUpdateTag := state.Options.SystemTags.Has(metrics.TagGroup)
 	if shouldUpdateTag {
 		state.Tags.Set("group", g.Path)
 	}
 	defer func() {
-		state.Group = old
+		state.Group, state.GroupTimeout = old, oldTimeout
+		state.Activity.SetGroup(old.Path)
 		if shouldUpdateTag {
 			state.Tags.Set("group", old.Path)
 		}
@@ -151,6 +173,24 @@ func (mi *K6) Group(name string, fn goja.Callable) (goja.Value, error) {
 	return ret, err
 }
 
+func (mi *K6) parseGroupTimeout(params goja.Value) (types.NullDuration, error) {
+	if params == nil || goja.IsUndefined(params) || goja.IsNull(params) {
+		return types.NullDuration{}, nil
+	}
+	timeoutV := params.ToObject(mi.vu.Runtime()).Get("timeout")
+	if timeoutV == nil || goja.IsUndefined(timeoutV) || goja.IsNull(timeoutV) {
+		return types.NullDuration{}, nil
+	}
+	timeout, err := types.GetDurationValue(timeoutV.Export())
+	if err != nil {
+		return types.NullDuration{}, fmt.Errorf("invalid group timeout value: %w", err)
+	}
+	if timeout <= 0 {
+		return types.NullDuration{}, fmt.Errorf("the group timeout should be more than 0, but it's %s", timeout)
+	}
+	return types.NullDurationFrom(timeout), nil
+}
+
 // Check will emit check metrics for the provided checks.
 //nolint:cyclop
 func (mi *K6) Check(arg0, checks goja.Value, extras ...goja.Value) (bool, error) {
@@ -33,6 +33,7 @@ import (
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modulestest"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -206,6 +207,37 @@ func TestGroup(t *testing.T) {
 		_, err := rt.RunString(`k6.group("::", function() { throw new Error("nooo") })`)
 		assert.Contains(t, err.Error(), "group and check names may not contain '::'")
 	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+		rt, state, _ := setupGroupTest()
+		var timeouts []types.NullDuration
+		require.NoError(t, rt.Set("record", func() {
+			timeouts = append(timeouts, state.GroupTimeout)
+		}))
+		_, err := rt.RunString(`
+			k6.group("outer", function() {
+				record();
+				k6.group("inherited", record);
+				k6.group("overridden", record, {timeout: 500});
+				record();
+			}, {timeout: "5s"});
+			record();
+		`)
+		require.NoError(t, err)
+		assert.Equal(t, []types.NullDuration{
+			types.NullDurationFrom(5 * time.Second),
+			types.NullDurationFrom(5 * time.Second),
+			types.NullDurationFrom(500 * time.Millisecond),
+			types.NullDurationFrom(5 * time.Second),
+			{},
+		}, timeouts)
+
+		_, err = rt.RunString(`k6.group("invalid", function() {}, {timeout: "-1s"})`)
+		assert.ErrorContains(t, err, "the group timeout should be more than 0")
+		_, err = rt.RunString(`k6.group("invalid", function() {}, {timeout: "forever"})`)
+		assert.ErrorContains(t, err, "invalid group timeout value")
+	})
 }
 
 func checkTestRuntime(t testing.TB) (*goja.Runtime, chan metrics.SampleContainer, *metrics.BuiltinMetrics) {
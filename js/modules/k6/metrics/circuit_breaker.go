@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+)
+
+// CircuitBreaker defines a new circuit breaker, which is periodically
+// evaluated against the aggregated metrics during the test, e.g.
+// circuitBreaker("errors", {metric: "http_req_failed", threshold: "rate<0.1",
+// window: "30s", minSamples: 100, action: "abort"}). It opens when the
+// threshold fails and closes when it passes again, or when the window has
+// fewer than minSamples samples. It has to be defined in the init context and
+// all VUs share it.
+func (mi *ModuleInstance) CircuitBreaker(name string, options goja.Value) *goja.Object {
+	rt := mi.vu.Runtime()
+	initEnv := mi.vu.InitEnv()
+	if initEnv == nil {
+		common.Throw(rt, errors.New("circuit breakers must be defined in the init context"))
+	}
+	if initEnv.CircuitBreakers == nil {
+		common.Throw(rt, errors.New("circuit breakers are not supported"))
+	}
+
+	config, err := mi.parseCircuitBreakerConfig(options)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid circuit breaker '%s': %w", name, err))
+	}
+	cb, err := initEnv.CircuitBreakers.Add(name, config)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	o := rt.NewObject()
+	err = o.DefineDataProperty("name", rt.ToValue(cb.Name), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	if err = o.Set("isOpen", cb.IsOpen); err != nil {
+		common.Throw(rt, err)
+	}
+	return o
+}
+
+func (mi *ModuleInstance) parseCircuitBreakerConfig(v goja.Value) (lib.CircuitBreakerConfig, error) {
+	var config lib.CircuitBreakerConfig
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return config, errors.New("the options are required")
+	}
+	rt := mi.vu.Runtime()
+	params := v.ToObject(rt)
+	for _, k := range params.Keys() {
+		var err error
+		value := params.Get(k)
+		switch k {
+		case "metric":
+			config.Metric = value.String()
+		case "threshold":
+			config.Threshold = value.String()
+		case "action":
+			config.Action = lib.CircuitBreakerAction(value.String())
+		case "window":
+			config.Window, err = types.GetDurationValue(value.Export())
+		case "interval":
+			config.Interval, err = types.GetDurationValue(value.Export())
+		case "minSamples":
+			minSamples := value.ToInteger()
+			if minSamples < 0 {
+				err = errors.New("it can't be negative")
+			}
+			config.MinSamples = uint64(minSamples)
+		case "delay":
+			config.Delay, err = types.GetDurationValue(value.Export())
+		case "groups":
+			err = rt.ExportTo(value, &config.Groups)
+		case "scenarios":
+			err = rt.ExportTo(value, &config.Scenarios)
+		default:
+			err = errors.New("unknown option")
+		}
+		if err != nil {
+			return config, fmt.Errorf("invalid option '%s': %w", k, err)
+		}
+	}
+	return config, nil
+}
@@ -168,10 +168,13 @@ func New() *RootModule {
 func (mi *ModuleInstance) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
-			"Counter": mi.XCounter,
-			"Gauge":   mi.XGauge,
-			"Trend":   mi.XTrend,
-			"Rate":    mi.XRate,
+			"Counter":        mi.XCounter,
+			"Gauge":          mi.XGauge,
+			"Trend":          mi.XTrend,
+			"Rate":           mi.XRate,
+			"addSubmetric":   mi.AddSubmetric,
+			"query":          mi.Query,
+			"circuitBreaker": mi.CircuitBreaker,
 		},
 	}
 }
@@ -211,3 +214,19 @@ func (mi *ModuleInstance) XRate(call goja.ConstructorCall, rt *goja.Runtime) *go
 	}
 	return v
 }
+
+// AddSubmetric defines the sub-metric of the given metric for the samples
+// with the key:value tags, e.g. addSubmetric('http_req_duration',
+// 'endpoint:checkout'), so it's aggregated and shown in the end-of-test
+// summary without any thresholds. It returns the name of the sub-metric.
+func (mi *ModuleInstance) AddSubmetric(metricName, keyValues string) (string, error) {
+	initEnv := mi.vu.InitEnv()
+	if initEnv == nil {
+		return "", errors.New("sub-metrics must be defined in the init context")
+	}
+	sm, err := initEnv.Registry.GetOrAddSubmetric(metricName, keyValues)
+	if err != nil {
+		return "", err
+	}
+	return sm.Name, nil
+}
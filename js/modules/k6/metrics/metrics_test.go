@@ -252,3 +252,45 @@ func TestMetricDuplicates(t *testing.T) {
 
 	require.True(t, v.ToBoolean())
 }
+
+func TestAddSubmetric(t *testing.T) {
+	t.Parallel()
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	mii := &modulestest.VU{
+		RuntimeField: rt,
+		InitEnvField: &common.InitEnvironment{Registry: registry},
+		CtxField:     context.Background(),
+	}
+	m, ok := New().NewModuleInstance(mii).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("metrics", m.Exports().Named))
+
+	v, err := rt.RunString(`
+		["checkout", "login", "checkout"].map(function(endpoint) {
+			return metrics.addSubmetric("http_req_duration", "endpoint:" + endpoint);
+		}).join(" ")
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "http_req_duration{endpoint:checkout} http_req_duration{endpoint:login} "+
+		"http_req_duration{endpoint:checkout}", v.String())
+	require.Len(t, builtinMetrics.HTTPReqDuration.Submetrics, 2)
+	assert.Equal(t, map[string]string{"endpoint": "checkout"},
+		builtinMetrics.HTTPReqDuration.Submetrics[0].Tags.CloneTags())
+
+	_, err = rt.RunString(`metrics.addSubmetric("not_a_metric", "endpoint:checkout")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "metric 'not_a_metric' does not exist in the script")
+
+	_, err = rt.RunString(`metrics.addSubmetric("http_req_duration", "")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "submetric criteria for metric 'http_req_duration' cannot be empty")
+
+	mii.InitEnvField = nil
+	_, err = rt.RunString(`metrics.addSubmetric("http_req_duration", "endpoint:cart")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sub-metrics must be defined in the init context")
+}
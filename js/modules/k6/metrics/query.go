@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+)
+
+// ErrMetricsQueryInInitContext is returned when metrics are queried in the init context
+var ErrMetricsQueryInInitContext = common.NewInitContextError("Querying metrics in the init context is not supported")
+
+// queryOptions are the optional parameters of metrics.query()
+type queryOptions struct {
+	window time.Duration
+}
+
+func (mi *ModuleInstance) parseQueryOptions(v goja.Value) (queryOptions, error) {
+	var opts queryOptions
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return opts, nil
+	}
+	rt := mi.vu.Runtime()
+	params := v.ToObject(rt)
+	for _, k := range params.Keys() {
+		switch k {
+		case "window":
+			window, err := types.GetDurationValue(params.Get(k).Export())
+			if err != nil {
+				return opts, fmt.Errorf("invalid query window: %w", err)
+			}
+			if window <= 0 {
+				return opts, errors.New("the query window should be more than 0")
+			}
+			opts.window = window
+		default:
+			return opts, fmt.Errorf("unknown metric query option '%s'", k)
+		}
+	}
+	return opts, nil
+}
+
+// Query returns the current aggregated value of a metric or a sub-metric, as
+// seen by the metrics engine of this instance, e.g.
+// metrics.query('http_req_duration{scenario:main}', 'p(95)', {window: '30s'}).
+// The samples are aggregated periodically, so the most recent ones may not be
+// included yet. The queried sub-metrics should be defined in the init context
+// with metrics.addSubmetric() or have thresholds, and NaN is returned if the
+// window doesn't have any samples.
+func (mi *ModuleInstance) Query(name, aggregation string, options goja.Value) float64 {
+	rt := mi.vu.Runtime()
+	if mi.vu.State() == nil {
+		common.Throw(rt, ErrMetricsQueryInInitContext)
+	}
+	opts, err := mi.parseQueryOptions(options)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	var querier lib.MetricsQuerier
+	if es := lib.GetExecutionState(mi.vu.Context()); es != nil {
+		querier = es.GetMetricsQuerier()
+	}
+	if querier == nil {
+		common.Throw(rt, errors.New("metric queries are not available, since the metrics aren't aggregated locally"))
+	}
+
+	value, err := querier.QueryMetric(name, aggregation, opts.window)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	if opts.window > 0 {
+		samples, err := querier.CountSamples(name, opts.window)
+		if err != nil {
+			common.Throw(rt, err)
+		}
+		if samples == 0 {
+			return math.NaN()
+		}
+	}
+	return value
+}
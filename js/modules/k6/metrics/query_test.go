@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+type fakeMetricsQuerier struct {
+	name, aggregation string
+	window            time.Duration
+}
+
+func (q *fakeMetricsQuerier) QueryMetric(name, aggregation string, window time.Duration) (float64, error) {
+	switch name {
+	case "unknown":
+		return 0, errors.New("metric 'unknown' does not exist in the script")
+	case "http_req_duration{scenario:new}":
+		return 0, fmt.Errorf("%w, define it with metrics.addSubmetric()", lib.ErrNewQuerySubmetric)
+	}
+	q.name, q.aggregation, q.window = name, aggregation, window
+	return 42, nil
+}
+
+func (q *fakeMetricsQuerier) QueryMetricRange(
+	_ context.Context, name, aggregation string, _, _ time.Time,
+) (float64, error) {
+	return q.QueryMetric(name, aggregation, 0)
+}
+
+func (q *fakeMetricsQuerier) CountSamples(name string, _ time.Duration) (uint64, error) {
+	if name == "http_reqs{scenario:idle}" {
+		return 0, nil
+	}
+	return 10, nil
+}
+
+func TestMetricsQuery(t *testing.T) {
+	t.Parallel()
+
+	querier := &fakeMetricsQuerier{}
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, nil, 0, 0)
+	es.SetMetricsQuerier(querier)
+
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	vu := &modulestest.VU{
+		RuntimeField: rt,
+		InitEnvField: &common.InitEnvironment{Registry: metrics.NewRegistry()},
+		CtxField:     lib.WithExecutionState(context.Background(), es),
+	}
+	m, ok := New().NewModuleInstance(vu).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("metrics", m.Exports().Named))
+
+	_, err = rt.RunString(`metrics.query("http_req_duration", "p(95)")`)
+	require.ErrorContains(t, err, "Querying metrics in the init context is not supported")
+
+	vu.InitEnvField = nil
+	vu.StateField = &lib.State{}
+	v, err := rt.RunString(`metrics.query("http_req_duration{scenario:main}", "p(95)", {window: "30s"})`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), v.Export())
+	assert.Equal(t, fakeMetricsQuerier{"http_req_duration{scenario:main}", "p(95)", 30 * time.Second}, *querier)
+
+	_, err = rt.RunString(`metrics.query("http_reqs", "count", {window: 1500})`)
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, querier.window)
+	_, err = rt.RunString(`metrics.query("http_reqs", "count")`)
+	require.NoError(t, err)
+	assert.Zero(t, querier.window)
+
+	_, err = rt.RunString(`metrics.query("unknown", "count")`)
+	assert.ErrorContains(t, err, "metric 'unknown' does not exist in the script")
+	_, err = rt.RunString(`metrics.query("http_req_duration{scenario:new}", "p(95)")`)
+	assert.ErrorContains(t, err, "metrics.addSubmetric()")
+	v, err = rt.RunString(`metrics.query("http_reqs{scenario:idle}", "count", {window: "1m"})`)
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(v.ToFloat()))
+	_, err = rt.RunString(`metrics.query("http_reqs", "count", {window: -1})`)
+	assert.ErrorContains(t, err, "the query window should be more than 0")
+	_, err = rt.RunString(`metrics.query("http_reqs", "count", {interval: "1s"})`)
+	assert.ErrorContains(t, err, "unknown metric query option 'interval'")
+
+	es.SetMetricsQuerier(nil)
+	_, err = rt.RunString(`metrics.query("http_reqs", "count")`)
+	assert.ErrorContains(t, err, "metric queries are not available")
+}
@@ -28,6 +28,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	neturl "net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -39,9 +40,16 @@ import (
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	httpModule "go.k6.io/k6/js/modules/k6/http"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
+// defaultHandshakeTimeout is used when neither the connection, the group nor
+// the scenario specify a timeout.
+const defaultHandshakeTimeout = 60 * time.Second
+
 type (
 	// RootModule is the global module instance that will create module
 	// instances for each VU.
@@ -95,6 +103,10 @@ type Socket struct {
 	pingSendTimestamps map[string]time.Time
 	pingSendCounter    int
 
+	// The time spent in the JS callbacks, the rest of the session is
+	// accounted as protocol time of the iteration.
+	scriptTime time.Duration
+
 	sampleTags     *metrics.SampleTags
 	samplesOutput  chan<- metrics.SampleContainer
 	builtinMetrics *metrics.BuiltinMetrics
@@ -153,6 +165,7 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 	header.Set("User-Agent", state.Options.UserAgent.String)
 
 	enableCompression := false
+	var requestTimeout types.NullDuration
 
 	tags := state.CloneTags()
 	jar := state.CookieJar
@@ -210,6 +223,12 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 				}
 
 				enableCompression = true
+			case "timeout":
+				timeout, err := types.GetDurationValue(params.Get(k).Export())
+				if err != nil {
+					return nil, fmt.Errorf("invalid timeout value: %w", err)
+				}
+				requestTimeout = types.NullDurationFrom(timeout)
 			}
 		}
 
@@ -218,6 +237,9 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 	if state.Options.SystemTags.Has(metrics.TagURL) {
 		tags["url"] = url
 	}
+	if u, err := neturl.Parse(url); err == nil {
+		state.Options.Latency.SetRegionTag(tags, u.Hostname())
+	}
 
 	// Overriding the NextProtos to avoid talking http2
 	var tlsConfig *tls.Config
@@ -226,8 +248,9 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 		tlsConfig.NextProtos = []string{"http/1.1"}
 	}
 
+	timeout := lib.GetTimeout(ctx, state, requestTimeout, defaultHandshakeTimeout)
 	wsd := websocket.Dialer{
-		HandshakeTimeout: time.Second * 60, // TODO configurable
+		HandshakeTimeout: timeout.Duration,
 		// Pass a custom net.DialContext function to websocket.Dialer that will substitute
 		// the underlying net.Conn with our own tracked netext.Conn
 		NetDialContext:    state.Dialer.DialContext,
@@ -240,6 +263,7 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 		wsd.Jar = nil
 	}
 
+	state.Activity.SetURL(url)
 	start := time.Now()
 	conn, httpResponse, connErr := wsd.DialContext(ctx, url, header)
 	connectionEnd := time.Now()
@@ -250,6 +274,20 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 			tags["ip"] = ip
 		}
 	}
+	if conn != nil {
+		connID, _ := netext.GetConnID(conn.UnderlyingConn())
+		netext.SetConnTags(tags, state.Options.SystemTags, conn.LocalAddr(), connID)
+	}
+	if httpResponse != nil && httpResponse.TLS != nil && state.Options.SystemTags.Has(metrics.TagTLSSessionReused) {
+		tags[metrics.TagTLSSessionReused.String()] = strconv.FormatBool(httpResponse.TLS.DidResume)
+	}
+
+	var netErr net.Error
+	if errors.As(connErr, &netErr) && netErr.Timeout() {
+		for k, v := range timeout.Tags() {
+			tags[k] = v
+		}
+	}
 
 	if httpResponse != nil {
 		if state.Options.SystemTags.Has(metrics.TagStatus) {
@@ -283,6 +321,10 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 		Time: start,
 	})
 
+	defer func() {
+		state.IterationTimings.AddProtocolTime(time.Since(start) - socket.scriptTime)
+	}()
+
 	if connErr != nil {
 		// Pass the error to the user script before exiting immediately
 		socket.handleEvent("error", rt.ToValue(connErr))
@@ -291,7 +333,7 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 	}
 
 	// Run the user-provided set up function
-	if _, err := setupFn(goja.Undefined(), rt.ToValue(&socket)); err != nil {
+	if _, err := socket.call(setupFn, rt.ToValue(&socket)); err != nil {
 		_ = socket.closeConnection(websocket.CloseGoingAway)
 		return nil, err
 	}
@@ -381,7 +423,7 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*WSHTTPResponse, error) {
 			_ = socket.closeConnection(code)
 
 		case scheduledFn := <-socket.scheduled:
-			if _, err := scheduledFn(goja.Undefined()); err != nil {
+			if _, err := socket.call(scheduledFn); err != nil {
 				_ = socket.closeConnection(websocket.CloseGoingAway)
 				return nil, err
 			}
@@ -407,13 +449,20 @@ func (s *Socket) On(event string, handler goja.Value) {
 func (s *Socket) handleEvent(event string, args ...goja.Value) {
 	if handlers, ok := s.eventHandlers[event]; ok {
 		for _, handler := range handlers {
-			if _, err := handler(goja.Undefined(), args...); err != nil {
+			if _, err := s.call(handler, args...); err != nil {
 				common.Throw(s.rt, err)
 			}
 		}
 	}
 }
 
+// call runs a JS callback of the socket, keeping track of the time spent in it.
+func (s *Socket) call(fn goja.Callable, args ...goja.Value) (goja.Value, error) {
+	start := time.Now()
+	defer func() { s.scriptTime += time.Since(start) }()
+	return fn(goja.Undefined(), args...)
+}
+
 // Send writes the given string message to the connection.
 func (s *Socket) Send(message string) {
 	if err := s.conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
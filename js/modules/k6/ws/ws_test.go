@@ -1288,3 +1288,27 @@ func TestCookieJar(t *testing.T) {
 
 	assertSessionMetricsEmitted(t, metrics.GetBufferedSamples(ts.samples), "", sr("WSBIN_URL/ws-echo-someheader"), statusProtocolSwitch, "")
 }
+
+func TestSimulatedRegion(t *testing.T) {
+	t.Parallel()
+	ts := newTestState(t)
+	sr := ts.tb.Replacer.Replace
+	ts.state.Options.Latency = lib.HostLatencies{sr("HTTPBIN_DOMAIN"): {Region: "eu-west"}}
+
+	_, err := ts.rt.RunString(sr(`
+		var res = ws.connect("WSBIN_URL/ws-echo", function(socket){
+			socket.close()
+		});
+		`))
+	require.NoError(t, err)
+
+	containers := metrics.GetBufferedSamples(ts.samples)
+	require.NotEmpty(t, containers)
+	for _, sc := range containers {
+		for _, s := range sc.GetSamples() {
+			region, ok := s.Tags.Get(lib.SimulatedRegionTag)
+			assert.True(t, ok, s.Metric.Name)
+			assert.Equal(t, "eu-west", region)
+		}
+	}
+}
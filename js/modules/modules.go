@@ -35,8 +35,9 @@ const extPrefix string = "k6/x/"
 
 //nolint:gochecknoglobals
 var (
-	modules = make(map[string]interface{})
-	mx      sync.RWMutex
+	modules  = make(map[string]interface{})
+	statuses = make(map[string]Status)
+	mx       sync.RWMutex
 )
 
 // Register the given mod as an external JavaScript module that can be imported
@@ -56,6 +57,42 @@ func Register(name string, mod interface{}) {
 	modules[name] = mod
 }
 
+// Status describes the stability of a module.
+type Status struct {
+	// Experimental modules can only be imported if they are explicitly
+	// enabled with --enable-experimental=<module>.
+	Experimental bool
+
+	// Deprecated modules log a warning when they are imported, announcing
+	// the k6 version in which they will be removed and what to use instead.
+	Deprecated     bool
+	RemovalVersion string
+	Alternative    string
+}
+
+// SetStatus marks the module with the given name as experimental and/or
+// deprecated. Unlike Register, it can be used for both internal and external
+// modules, and it can be called before or after the module is registered.
+func SetStatus(name string, status Status) {
+	mx.Lock()
+	defer mx.Unlock()
+
+	statuses[name] = status
+}
+
+// GetStatuses returns the statuses of all modules which have one set.
+func GetStatuses() map[string]Status {
+	mx.RLock()
+	defer mx.RUnlock()
+	result := make(map[string]Status, len(statuses))
+
+	for name, status := range statuses {
+		result[name] = status
+	}
+
+	return result
+}
+
 // Module is the interface js modules should implement in order to get access to the VU
 type Module interface {
 	// NewModuleInstance will get modules.VU that should provide the module with a way to interact with the VU
@@ -31,6 +31,7 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -77,8 +78,9 @@ type Runner struct {
 	ActualResolver netext.MultiResolver
 	RPSLimit       *rate.Limiter
 
-	console   *console
-	setupData []byte
+	console      *console
+	scriptLogger logrus.FieldLogger
+	setupData    []byte
 
 	keylogger io.Writer
 }
@@ -122,7 +124,8 @@ func NewFromBundle(rs *lib.RuntimeState, b *Bundle) (*Runner, error) {
 			KeepAlive: 30 * time.Second,
 			DualStack: true,
 		},
-		console: newConsole(rs.Logger),
+		console:      newConsole(rs.Logger),
+		scriptLogger: rs.Logger,
 		Resolver: netext.NewResolver(
 			net.LookupIP, 0, defDNS.Select.DNSSelect, defDNS.Policy.DNSPolicy),
 		ActualResolver: net.LookupIP,
@@ -149,6 +152,87 @@ func (r *Runner) NewVU(idLocal, idGlobal uint64, samplesOut chan<- metrics.Sampl
 	return lib.InitializedVU(vu), nil
 }
 
+var _ lib.CircuitBreakerRunner = &Runner{}
+
+// GetCircuitBreakers returns the circuit breakers defined by the script.
+func (r *Runner) GetCircuitBreakers() *lib.CircuitBreakers {
+	return r.Bundle.circuitBreakers
+}
+
+var _ lib.PreflightRunner = &Runner{}
+
+// Preflight executes the init code of throwaway VUs with the given IDs, so
+// problems like files that are opened only by some of the VUs, or the exec
+// functions of scenarios that only some of them export, are found before the
+// test is started. The problems of all of the VUs and scenarios are returned
+// together, with the __ENV keys that the init code read but which weren't
+// set. If those keys are the only problem, they're just logged as a warning,
+// since the scripts can have default values for them.
+func (r *Runner) Preflight(ctx context.Context, vuIDs []uint64) error {
+	var problems []string
+	seen := make(map[string]bool)
+	addProblem := func(vuID uint64, msg string) {
+		if !seen[msg] {
+			seen[msg] = true
+			problems = append(problems, fmt.Sprintf("VU #%d: %s", vuID, msg))
+		}
+	}
+
+	scenarios := make([]string, 0, len(r.Bundle.Options.Scenarios))
+	for name := range r.Bundle.Options.Scenarios {
+		scenarios = append(scenarios, name)
+	}
+	sort.Strings(scenarios)
+
+	missingEnv := make(map[string]bool)
+	for _, vuID := range vuIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rt := goja.New()
+		vuImpl := newModuleVUImpl()
+		vuImpl.runtime = rt
+		init := newBoundInitContext(r.Bundle.BaseInitContext, vuImpl)
+		if err := r.Bundle.instantiate(r.Logger, rt, init, vuID, missingEnv); err != nil {
+			addProblem(vuID, err.Error())
+			continue
+		}
+
+		exports := rt.Get("exports").ToObject(rt)
+		for _, name := range scenarios {
+			exec := r.Bundle.Options.Scenarios[name].GetExec()
+			if _, ok := goja.AssertFunction(exports.Get(exec)); !ok {
+				addProblem(vuID, fmt.Sprintf("the function '%s' of the scenario '%s' isn't exported", exec, name))
+			}
+		}
+	}
+
+	var missingEnvMsg string
+	if len(missingEnv) > 0 {
+		keys := make([]string, 0, len(missingEnv))
+		for key := range missingEnv {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		missingEnvMsg = fmt.Sprintf("the init code read the __ENV keys '%s', which aren't set", strings.Join(keys, "', '"))
+	}
+	if len(problems) == 0 {
+		if missingEnvMsg != "" {
+			r.Logger.Warnf("The preflight check found that %s", missingEnvMsg)
+		}
+		return nil
+	}
+	if missingEnvMsg != "" {
+		problems = append(problems, missingEnvMsg)
+	}
+
+	err := fmt.Errorf("the preflight check found %d problem(s) in the init code:\n\t%s",
+		len(problems), strings.Join(problems, "\n\t"))
+	err = errext.WithHint(err, "the init code should work the same way for every VU, "+
+		"e.g. all of the files should be opened when __VU is 0")
+	return errext.WithExitCodeIfNone(err, exitcodes.ScriptException)
+}
+
 // nolint:funlen
 func (r *Runner) newVU(idLocal, idGlobal uint64, samplesOut chan<- metrics.SampleContainer) (*VU, error) {
 	// Instantiate a new bundle, make a VU out of it.
@@ -188,6 +272,7 @@ func (r *Runner) newVU(idLocal, idGlobal uint64, samplesOut chan<- metrics.Sampl
 		Blacklist:        r.Bundle.Options.BlacklistIPs,
 		BlockedHostnames: r.Bundle.Options.BlockedHostnames.Trie,
 		Hosts:            r.Bundle.Options.Hosts,
+		Latency:          r.Bundle.Options.Latency,
 	}
 	if r.Bundle.Options.LocalIPs.Valid {
 		var ipIndex uint64
@@ -257,6 +342,7 @@ func (r *Runner) newVU(idLocal, idGlobal uint64, samplesOut chan<- metrics.Sampl
 
 	vu.state = &lib.State{
 		Logger:         vu.Runner.Logger,
+		ScriptLogger:   vu.Runner.scriptLogger,
 		Options:        vu.Runner.Bundle.Options,
 		Transport:      vu.Transport,
 		Dialer:         vu.Dialer,
@@ -270,6 +356,16 @@ func (r *Runner) newVU(idLocal, idGlobal uint64, samplesOut chan<- metrics.Sampl
 		Tags:           lib.NewTagMap(vu.Runner.Bundle.Options.RunTags.CloneTags()),
 		Group:          r.defaultGroup,
 		BuiltinMetrics: r.builtinMetrics,
+
+		IterationCleanups: &lib.IterationCleanups{},
+		Activity:          lib.NewVUActivity(vu.ID),
+		CircuitBreakers:   r.Bundle.circuitBreakers,
+	}
+	if vu.Runner.Bundle.Options.IterationBodyBytesBudget.Int64 > 0 {
+		vu.state.BodyBytes = &lib.BodyBytesTracker{}
+	}
+	if vu.Runner.Bundle.Options.IterationBreakdown.Bool {
+		vu.state.IterationTimings = &lib.IterationTimings{}
 	}
 	vu.moduleVUImpl.state = vu.state
 	_ = vu.Runtime.Set("console", vu.Console)
@@ -368,7 +464,7 @@ func (r *Runner) IsExecutable(name string) bool {
 
 // HandleSummary calls the specified summary callback, if supplied.
 func (r *Runner) HandleSummary(ctx context.Context, summary *lib.Summary) (map[string]io.Reader, error) {
-	summaryDataForJS := summarizeMetricsToObject(summary, r.Bundle.Options, r.setupData)
+	summaryDataForJS, allMetrics := summarizeMetricsToObject(summary, r.Bundle.Options, r.setupData)
 
 	out := make(chan metrics.SampleContainer, 100)
 	defer close(out)
@@ -415,6 +511,7 @@ func (r *Runner) HandleSummary(ctx context.Context, summary *lib.Summary) (map[s
 		handleSummaryFn,
 		vu.Runtime.ToValue(r.Bundle.RuntimeOptions.SummaryExport.String),
 		vu.Runtime.ToValue(summaryDataForJS),
+		vu.Runtime.ToValue(allMetrics),
 	}
 	rawResult, _, _, err := vu.runFn(ctx, false, handleSummaryWrapper, nil, wrapperArgs...)
 
@@ -454,6 +551,15 @@ func (r *Runner) SetOptions(opts lib.Options) error {
 		r.console = c
 	}
 
+	if opts.ScriptLogOutput.Valid {
+		l, err := newFileScriptLogger(opts.ScriptLogOutput.String)
+		if err != nil {
+			return err
+		}
+
+		r.scriptLogger = l
+	}
+
 	// FIXME: Resolver probably shouldn't be reset here...
 	// It's done because the js.Runner is created before the full
 	// configuration has been processed, at which point we don't have
@@ -628,6 +734,11 @@ func (u *VU) GetID() uint64 {
 	return u.ID
 }
 
+// GetActivity returns what the VU is currently doing.
+func (u *VU) GetActivity() *lib.VUActivity {
+	return u.state.Activity
+}
+
 // Activate the VU so it will be able to run code.
 func (u *VU) Activate(params *lib.VUActivationParams) lib.ActiveVU {
 	u.Runtime.ClearInterrupt()
@@ -688,6 +799,7 @@ func (u *VU) Activate(params *lib.VUActivationParams) lib.ActiveVU {
 	u.state.GetScenarioGlobalVUIter = func() uint64 {
 		return avu.scIterGlobal
 	}
+	u.state.Activity.Activate(params.Scenario)
 
 	go func() {
 		// Wait for the run context to be over
@@ -697,6 +809,8 @@ func (u *VU) Activate(params *lib.VUActivationParams) lib.ActiveVU {
 		// Wait for the VU to stop running, if it was, and prevent it from
 		// running again for this activation
 		avu.busy <- struct{}{}
+		u.state.Activity.Deactivate()
+		u.state.CloseIdleConnections()
 
 		if params.DeactivateCallback != nil {
 			params.DeactivateCallback(u)
@@ -706,7 +820,8 @@ func (u *VU) Activate(params *lib.VUActivationParams) lib.ActiveVU {
 	return avu
 }
 
-// RunOnce runs the configured Exec function once.
+// RunOnce runs the configured Exec function, or the one returned by
+// GetNextExec, once.
 func (u *ActiveVU) RunOnce() error {
 	select {
 	case <-u.RunContext.Done():
@@ -732,10 +847,18 @@ func (u *ActiveVU) RunOnce() error {
 		}
 	}
 
-	fn, ok := u.exports[u.Exec]
+	if err := u.applyCircuitBreakers(); err != nil {
+		return err
+	}
+
+	execFn := u.Exec
+	if u.GetNextExec != nil {
+		execFn = u.GetNextExec()
+	}
+	fn, ok := u.exports[execFn]
 	if !ok {
 		// Shouldn't happen; this is validated in cmd.validateScenarioConfig()
-		panic(fmt.Sprintf("function '%s' not found in exports", u.Exec))
+		panic(fmt.Sprintf("function '%s' not found in exports", execFn))
 	}
 
 	u.incrIteration()
@@ -747,7 +870,9 @@ func (u *ActiveVU) RunOnce() error {
 	defer cancel()
 	u.moduleVUImpl.ctx = ctx
 	// Call the exported function.
+	u.state.Activity.StartIteration(u.iteration)
 	_, isFullIteration, totalTime, err := u.runFn(ctx, true, fn, cancel, u.setupData)
+	u.state.Activity.EndIteration()
 	if err != nil {
 		var x *goja.InterruptedError
 		if errors.As(err, &x) {
@@ -773,6 +898,19 @@ func (u *ActiveVU) RunOnce() error {
 	return err
 }
 
+// applyCircuitBreakers slows the scenario down before an iteration, if any
+// of the circuit breakers for it are open.
+func (u *ActiveVU) applyCircuitBreakers() error {
+	if delay := u.state.CircuitBreakers.SlowDownDelay(u.scenarioName); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-u.RunContext.Done():
+			return u.RunContext.Err()
+		}
+	}
+	return nil
+}
+
 // if isDefault is true, cancel also needs to be provided and it should cancel the provided context
 // TODO remove the need for the above through refactoring of this function and its callees
 func (u *VU) runFn(
@@ -790,6 +928,9 @@ func (u *VU) runFn(
 		u.state.Tags.Set("iter", strconv.FormatInt(u.state.Iteration, 10))
 	}
 
+	u.state.BodyBytes.Reset()
+	u.state.IterationTimings.Reset(0)
+	u.state.LastRequestURL = ""
 	startTime := time.Now()
 
 	if u.moduleVUImpl.eventLoop == nil {
@@ -813,6 +954,7 @@ func (u *VU) runFn(
 		cancel()
 		u.moduleVUImpl.eventLoop.WaitOnRegistered()
 	}
+	u.state.IterationCleanups.Run()
 	endTime := time.Now()
 	var exception *goja.Exception
 	if errors.As(err, &exception) {
@@ -821,15 +963,70 @@ func (u *VU) runFn(
 
 	if u.Runner.Bundle.Options.NoVUConnectionReuse.Bool {
 		u.Transport.CloseIdleConnections()
+		u.state.CloseIdleConnections()
 	}
 
+	u.checkBodyBytesBudget()
+
 	sampleTags := metrics.NewSampleTags(u.state.CloneTags())
 	u.state.Samples <- u.Dialer.GetTrail(
 		startTime, endTime, isFullIteration, isDefault, sampleTags, u.Runner.builtinMetrics)
+	if isFullIteration && opts.IterationBreakdown.Bool {
+		u.emitIterationTimings(endTime, endTime.Sub(startTime), sampleTags)
+	}
 
 	return v, isFullIteration, endTime.Sub(startTime), err
 }
 
+// emitIterationTimings emits the breakdown of the just finished iteration into
+// the time spent in protocol calls, in sleep() and in the script itself. It's
+// only done with the iterationBreakdown option, since it triples the samples
+// of every iteration.
+func (u *VU) emitIterationTimings(endTime time.Time, total time.Duration, tags *metrics.SampleTags) {
+	report := u.state.IterationTimings.Reset(total)
+	bm := u.Runner.builtinMetrics
+	u.state.Samples <- metrics.ConnectedSamples{
+		Samples: metrics.WithoutDisabled([]metrics.Sample{
+			{Time: endTime, Metric: bm.IterationProtocolDuration, Value: metrics.D(report.Protocol), Tags: tags},
+			{Time: endTime, Metric: bm.IterationScriptDuration, Value: metrics.D(report.Script), Tags: tags},
+			{Time: endTime, Metric: bm.IterationSleepDuration, Value: metrics.D(report.Sleep), Tags: tags},
+		}),
+		Tags: tags,
+		Time: endTime,
+	}
+}
+
+// checkBodyBytesBudget warns if the just finished iteration allocated more
+// response body bytes than the configured budget, pointing to the request
+// with the largest body, since that is most probably the culprit.
+func (u *VU) checkBodyBytesBudget() {
+	budget := u.Runner.Bundle.Options.IterationBodyBytesBudget.Int64
+	if budget <= 0 || u.state.BodyBytes == nil {
+		return
+	}
+	report := u.state.BodyBytes.Reset()
+	if report.Total <= budget {
+		return
+	}
+
+	fields := logrus.Fields{
+		"vu":            u.ID,
+		"iter":          u.state.Iteration,
+		"total_bytes":   report.Total,
+		"budget_bytes":  budget,
+		"largest_bytes": report.Largest,
+		"largest_url":   report.LargestURL,
+		"largest_group": report.LargestGroup,
+	}
+	if scenario, ok := u.state.Tags.Get("scenario"); ok {
+		fields["scenario"] = scenario
+	}
+	u.state.Logger.WithFields(fields).Warn(
+		"The iteration allocated more response body bytes than the configured budget, consider " +
+			"using responseType: 'none' or discardResponseBodies for responses whose bodies aren't needed",
+	)
+}
+
 func (u *ActiveVU) incrIteration() {
 	u.iteration++
 	u.state.Iteration = u.iteration
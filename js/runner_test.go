@@ -48,6 +48,8 @@ import (
 
 	"go.k6.io/k6/core"
 	"go.k6.io/k6/core/local"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules/k6"
 	k6http "go.k6.io/k6/js/modules/k6/http"
@@ -2487,3 +2489,355 @@ func TestExecutionInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestVUIntegrationBodyBytesBudget(t *testing.T) {
+	t.Parallel()
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	logger.Out = ioutil.Discard
+	hook := testutils.SimpleLogrusHook{HookedLevels: []logrus.Level{logrus.WarnLevel}}
+	logger.AddHook(&hook)
+
+	r, err := getSimpleRunner(t, "/script.js", tb.Replacer.Replace(`
+			var http = require("k6/http");
+			var group = require("k6").group;
+			exports.default = function() {
+				http.get("HTTPBIN_URL/bytes/100", { responseType: "binary" });
+				if (__ITER == 1) {
+					group("download", function() {
+						http.get("HTTPBIN_URL/bytes/2000", { responseType: "binary" });
+					});
+				}
+			}
+		`), logger)
+	require.NoError(t, err)
+	r.SetOptions(lib.Options{
+		IterationBodyBytesBudget: null.IntFrom(1000),
+		Hosts:                    tb.Dialer.Hosts,
+	})
+
+	initVU, err := r.NewVU(1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+
+	require.NoError(t, vu.RunOnce())
+	assert.Empty(t, hook.Drain())
+
+	require.NoError(t, vu.RunOnce())
+	entries := hook.Drain()
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(2100), entries[0].Data["total_bytes"])
+	assert.Equal(t, int64(2000), entries[0].Data["largest_bytes"])
+	assert.Equal(t, tb.Replacer.Replace("HTTPBIN_URL/bytes/2000"), entries[0].Data["largest_url"])
+	assert.Equal(t, "::download", entries[0].Data["largest_group"])
+}
+
+func TestExecutionInstanceVUs(t *testing.T) {
+	t.Parallel()
+
+	r, err := getSimpleRunner(t, "/script.js", `
+		var exec = require("k6/execution");
+		var group = require("k6").group;
+
+		exports.default = function() {
+			group("checkout", function() {
+				var vus = exec.instance.vus;
+				if (vus.length !== 1) throw new Error("unexpected VUs: " + JSON.stringify(vus));
+				var vu = vus[0];
+				if (vu.id !== 1 || vu.scenario !== "default" || vu.iteration !== 0 ||
+					vu.group !== "::checkout" || vu.url !== "" || vu.status !== "running" || !(vu.timeInStatus >= 0)) {
+					throw new Error("unexpected VU: " + JSON.stringify(vu));
+				}
+			});
+		}`)
+	require.NoError(t, err)
+
+	initVU, err := r.NewVU(1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, r.builtinMetrics, 1, 1)
+	es.AddInitializedVU(initVU)
+	assert.Equal(t, lib.VUStatusInactive, es.GetVUActivities()[0].Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = lib.WithExecutionState(ctx, es)
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx, Scenario: "default"})
+	require.NoError(t, vu.RunOnce())
+
+	activities := es.GetVUActivities()
+	require.Len(t, activities, 1)
+	assert.Equal(t, lib.VUStatusIdle, activities[0].Status)
+	assert.Equal(t, int64(0), activities[0].Iteration)
+	assert.Empty(t, activities[0].Group)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		return es.GetVUActivities()[0].Status == lib.VUStatusInactive
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestVUIntegrationIterationTimings(t *testing.T) {
+	t.Parallel()
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	r, err := getSimpleRunner(t, "/script.js", tb.Replacer.Replace(`
+			var http = require("k6/http");
+			var sleep = require("k6").sleep;
+			exports.default = function() {
+				http.get("HTTPBIN_URL/delay/0.2");
+				sleep(0.1);
+				var start = Date.now();
+				while (Date.now() - start < 50) {}
+			}
+		`))
+	require.NoError(t, err)
+	r.SetOptions(lib.Options{Hosts: tb.Dialer.Hosts, IterationBreakdown: null.BoolFrom(true)})
+
+	samples := make(chan metrics.SampleContainer, 100)
+	initVU, err := r.NewVU(1, 1, samples)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+	require.NoError(t, vu.RunOnce())
+	close(samples)
+
+	values := map[string]float64{}
+	for sc := range samples {
+		for _, s := range sc.GetSamples() {
+			values[s.Metric.Name] += s.Value
+		}
+	}
+	assert.GreaterOrEqual(t, values[metrics.IterationProtocolDurationName], 200.0)
+	assert.Less(t, values[metrics.IterationProtocolDurationName], 300.0)
+	assert.GreaterOrEqual(t, values[metrics.IterationSleepDurationName], 100.0)
+	assert.Less(t, values[metrics.IterationSleepDurationName], 200.0)
+	assert.GreaterOrEqual(t, values[metrics.IterationScriptDurationName], 40.0)
+	assert.InDelta(t, values[metrics.IterationDurationName], values[metrics.IterationProtocolDurationName]+
+		values[metrics.IterationSleepDurationName]+values[metrics.IterationScriptDurationName], 0.001)
+}
+
+func TestVUIntegrationIdentityPoolIterationScope(t *testing.T) {
+	t.Parallel()
+
+	r, err := getSimpleRunner(t, "/script.js", `
+			var data = require("k6/data");
+			var pool = new data.IdentityPool("users", function() { return [{username: "user0"}]; });
+			exports.default = function() {
+				if (pool.checkout().username !== "user0") {
+					throw new Error("unexpected identity");
+				}
+			}
+		`)
+	require.NoError(t, err)
+
+	samples := make(chan metrics.SampleContainer, 100)
+	initVU, err := r.NewVU(1, 1, samples)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+
+	// The only identity is returned at the end of each iteration, so the next
+	// one never has to wait for it
+	for i := 0; i < 3; i++ {
+		require.NoError(t, vu.RunOnce())
+	}
+	for _, sc := range metrics.GetBufferedSamples(samples) {
+		for _, s := range sc.GetSamples() {
+			assert.NotEqual(t, "identity_pool_starved", s.Metric.Name)
+		}
+	}
+}
+
+func TestVUIntegrationExecMix(t *testing.T) {
+	t.Parallel()
+
+	r, err := getSimpleRunner(t, "/script.js", `
+			var counters = { browse: 0, buy: 0 };
+			exports.browse = function() { counters.browse++; }
+			exports.buy = function() {
+				counters.buy++;
+				if (counters.browse !== 0) {
+					throw new Error("browse has a zero weight, but was called " + counters.browse + " times");
+				}
+			}
+			exports.default = function() { throw new Error("the default function shouldn't be called"); }
+		`)
+	require.NoError(t, err)
+
+	mix, err := lib.NewExecMix(map[string]float64{"browse": 0, "buy": 1})
+	require.NoError(t, err)
+
+	initVU, err := r.NewVU(1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx, GetNextExec: mix.Pick})
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, vu.RunOnce())
+	}
+}
+
+type fakeMetricsQuerier struct {
+	mu      sync.Mutex
+	values  map[string]float64
+	samples uint64
+}
+
+func (q *fakeMetricsQuerier) set(name string, value float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.values[name] = value
+}
+
+func (q *fakeMetricsQuerier) QueryMetric(name, _ string, _ time.Duration) (float64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.values[name], nil
+}
+
+func (q *fakeMetricsQuerier) QueryMetricRange(
+	_ context.Context, name, aggregation string, _, _ time.Time,
+) (float64, error) {
+	return q.QueryMetric(name, aggregation, 0)
+}
+
+func (q *fakeMetricsQuerier) CountSamples(string, time.Duration) (uint64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.samples, nil
+}
+
+func TestVUIntegrationCircuitBreakers(t *testing.T) {
+	t.Parallel()
+
+	r, err := getSimpleRunner(t, "/script.js", `
+		var metrics = require("k6/metrics");
+		var group = require("k6").group;
+
+		var skipLogin = metrics.circuitBreaker("skip-login", {
+			metric: "http_req_failed", threshold: "rate<0.1", action: "skipGroup", groups: ["login"],
+			interval: 1, minSamples: 5,
+		});
+		metrics.circuitBreaker("abort", {
+			metric: "iterations", threshold: "count<3", action: "abort", interval: 1, minSamples: 1,
+		});
+
+		exports.default = function() {
+			group("login", function() {
+				throw new Error("the login group wasn't skipped");
+			});
+			if (!skipLogin.isOpen()) throw new Error("the circuit breaker isn't open");
+		}`)
+	require.NoError(t, err)
+	cbs := r.GetCircuitBreakers()
+
+	querier := &fakeMetricsQuerier{values: map[string]float64{"http_req_failed": 0.5}, samples: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	type runResult struct {
+		abort *lib.CircuitBreaker
+		err   error
+	}
+	result := make(chan runResult, 1)
+	go func() {
+		abort, err := cbs.Run(ctx, querier, testutils.NewLogger(t))
+		result <- runResult{abort, err}
+	}()
+
+	initVU, err := r.NewVU(1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+
+	// the skip-login circuit breaker isn't evaluated until it has enough samples
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, cbs.ShouldSkipGroup("login"))
+	assert.ErrorContains(t, vu.RunOnce(), "the login group wasn't skipped")
+
+	querier.mu.Lock()
+	querier.samples = 5
+	querier.mu.Unlock()
+	require.Eventually(t, func() bool { return cbs.ShouldSkipGroup("login") }, time.Second, time.Millisecond)
+	require.NoError(t, vu.RunOnce())
+
+	querier.set("iterations", 3)
+	select {
+	case res := <-result:
+		require.NoError(t, res.err)
+		require.NotNil(t, res.abort)
+		assert.Equal(t, "abort", res.abort.Name)
+	case <-time.After(time.Second):
+		t.Fatal("the abort circuit breaker wasn't opened")
+	}
+}
+
+func TestRunnerPreflight(t *testing.T) {
+	t.Parallel()
+
+	baseFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(baseFS, "/data-0.csv", []byte("a,b"), 0o644))
+	require.NoError(t, afero.WriteFile(baseFS, "/data-3.csv", []byte("c,d"), 0o644))
+	fs := fsext.NewCacheOnReadFs(baseFS, afero.NewMemMapFs(), 0)
+	r, err := getSimpleRunner(t, "/script.js", `
+		var data = open("/data-" + (__VU % 3 == 0 ? __VU : 0) + ".csv");
+		if (__VU == 5) {
+			throw new Error("no data for VU 5");
+		}
+		exports.default = function() {};
+	`, fs)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Preflight(context.Background(), []uint64{1, 2}))
+
+	err = r.Preflight(context.Background(), []uint64{1, 3, 5})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found 2 problem(s)")
+	assert.Contains(t, err.Error(), "VU #3: ")
+	assert.Contains(t, err.Error(), "data-3.csv")
+	assert.Contains(t, err.Error(), "VU #5: Error: no data for VU 5")
+	assert.NotContains(t, err.Error(), "VU #1")
+
+	var ecerr errext.HasExitCode
+	require.ErrorAs(t, err, &ecerr)
+	assert.Equal(t, exitcodes.ScriptException, ecerr.ExitCode())
+}
+
+func TestRunnerPreflightScenariosAndEnv(t *testing.T) {
+	t.Parallel()
+
+	r, err := getSimpleRunner(t, "/script.js", `
+		var format = __ENV.FORMAT || "csv";
+		var host = __ENV.HOST;
+		if (__ENV.hasOwnProperty("OTHER") || JSON.stringify(__ENV) === "" || ("" + __ENV) === "" || __ENV[Symbol.iterator]) {
+			throw new Error("unexpected __ENV");
+		}
+		exports.options = {
+			scenarios: {
+				main: { executor: "shared-iterations", exec: "main" },
+				extra: { executor: "shared-iterations", exec: "extra" },
+			},
+		};
+		exports.main = function() {};
+		if (__VU != 2) {
+			exports.extra = function() {};
+		}
+	`)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Preflight(context.Background(), []uint64{1, 3}))
+
+	err = r.Preflight(context.Background(), []uint64{1, 2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found 2 problem(s)")
+	assert.Contains(t, err.Error(), "VU #2: the function 'extra' of the scenario 'extra' isn't exported")
+	assert.Contains(t, err.Error(), "the init code read the __ENV keys 'FORMAT', 'HOST', which aren't set")
+	assert.NotContains(t, err.Error(), "'main'")
+}
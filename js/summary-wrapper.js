@@ -32,11 +32,15 @@
         return group;
     };
 
-    var oldJSONSummary = function (data) {
+    var oldJSONSummary = function (data, allMetrics) {
         // Quick copy of the data, since it's easiest to modify it in place.
         var results = JSON.parse(JSON.stringify(data));
         delete results.options;
         delete results.state;
+        // The export always contains all of the sub-metrics, even if only the
+        // top ones are shown in the summary.
+        results.metrics = JSON.parse(JSON.stringify(allMetrics));
+        delete results.omitted_submetrics;
 
         forEach(results.metrics, function (metricName, metric) {
             var oldFormatMetric = metric.values;
@@ -59,7 +63,7 @@
         return JSON.stringify(results, null, 4);
     };
 
-    return function (exportedSummaryCallback, jsonSummaryPath, data) {
+    return function (exportedSummaryCallback, jsonSummaryPath, data, allMetrics) {
         var getDefaultSummary = function () {
             var enableColors = (!data.options.noColor && data.state.isStdOutTTY);
             return {
@@ -83,7 +87,7 @@
         // and if not, log an error and generate the default summary?
 
         if (jsonSummaryPath != '') {
-            result[jsonSummaryPath] = oldJSONSummary(data);
+            result[jsonSummaryPath] = oldJSONSummary(data, allMetrics);
         }
 
         return result;
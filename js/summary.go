@@ -25,6 +25,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/dop251/goja"
@@ -34,6 +36,7 @@ import (
 )
 
 // Copied from https://github.com/k6io/jslib.k6.io/tree/master/lib/k6-summary
+//
 //go:embed summary.js
 var jslibSummaryCode string //nolint:gochecknoglobals
 
@@ -79,9 +82,13 @@ func metricValueGetter(summaryTrendStats []string) func(metrics.Sink, time.Durat
 }
 
 // summarizeMetricsToObject transforms the summary objects in a way that's
-// suitable to pass to the JS runtime or export to JSON.
-func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData []byte) map[string]interface{} {
-	m := make(map[string]interface{})
+// suitable to pass to the JS runtime or export to JSON. If only the top
+// sub-metrics are shown, the summary contains just them and all of the metrics
+// are returned separately, so they can still be exported.
+func summarizeMetricsToObject(
+	data *lib.Summary, options lib.Options, setupData []byte,
+) (m map[string]interface{}, allMetrics map[string]interface{}) {
+	m = make(map[string]interface{})
 	m["root_group"] = exportGroup(data.RootGroup)
 	m["options"] = map[string]interface{}{
 		// TODO: improve when we can easily export all option values, including defaults?
@@ -117,12 +124,33 @@ func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData
 		metricsData[name] = metricData
 	}
 	m["metrics"] = metricsData
+	if len(data.Transactions) > 0 {
+		m["transactions"] = exportTransactions(data.Transactions)
+	}
+	if data.Abort != nil {
+		m["abort"] = map[string]interface{}{
+			"reason":    data.Abort.Reason,
+			"truncated": data.Abort.Truncated,
+		}
+	}
+	m["environment"] = exportRunEnvironment(data.Environment)
+	if len(data.ThroughputSearches) > 0 {
+		m["throughput_searches"] = exportThroughputSearches(data.ThroughputSearches)
+	}
+	metadata := options.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	m["metadata"] = metadata
+	if top := options.SummaryTopSubmetrics.Int64; top > 0 {
+		m["metrics"], m["omitted_submetrics"] = selectTopSubmetrics(data.Metrics, metricsData, int(top))
+	}
 
 	var setupDataI interface{}
 	if setupData != nil {
 		if err := json.Unmarshal(setupData, &setupDataI); err != nil {
 			// TODO: log the error
-			return m
+			return m, metricsData
 		}
 	} else {
 		setupDataI = goja.Undefined()
@@ -130,7 +158,182 @@ func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData
 
 	m["setup_data"] = setupDataI
 
-	return m
+	return m, metricsData
+}
+
+// exportRunEnvironment returns the environment with the same keys as in the
+// JSON output.
+func exportRunEnvironment(env lib.RunEnvironment) map[string]interface{} {
+	return map[string]interface{}{
+		"k6_version":             env.K6Version,
+		"os":                     env.OS,
+		"arch":                   env.Arch,
+		"hostname":               env.Hostname,
+		"cpus":                   env.CPUs,
+		"gomaxprocs":             env.GOMAXPROCS,
+		"open_files_limit":       env.OpenFilesLimit,
+		"container_cpu_limit":    env.ContainerCPULimit,
+		"container_memory_limit": env.ContainerMemoryLimit,
+	}
+}
+
+// exportThroughputSearches returns the results of the throughput-search
+// scenarios, with their time units in milliseconds. The max_rate is null if
+// none of the plateaus passed the criteria, and it's inconclusive if the search
+// ran out of plateaus before it converged.
+func exportThroughputSearches(searches []lib.ThroughputSearchResult) []interface{} {
+	result := make([]interface{}, 0, len(searches))
+	for _, search := range searches {
+		plateaus := make([]interface{}, 0, len(search.Plateaus))
+		for _, plateau := range search.Plateaus {
+			failures := plateau.Failures
+			if failures == nil {
+				failures = []string{}
+			}
+			plateaus = append(plateaus, map[string]interface{}{
+				"rate":     plateau.Rate,
+				"passed":   plateau.Passed,
+				"failures": failures,
+			})
+		}
+		var maxRate interface{}
+		if search.MaxRate > 0 {
+			maxRate = search.MaxRate
+		}
+		result = append(result, map[string]interface{}{
+			"scenario":     search.Scenario,
+			"time_unit":    metrics.D(search.TimeUnit),
+			"max_rate":     maxRate,
+			"inconclusive": search.Inconclusive,
+			"plateaus":     plateaus,
+		})
+	}
+	return result
+}
+
+// exportTransactions returns the rows of the SLA table in the summary. The
+// error rate is null for transactions without any HTTP requests, and the SLA
+// verdict is null for the ones without thresholds.
+func exportTransactions(transactions []lib.TransactionSummary) []interface{} {
+	result := make([]interface{}, 0, len(transactions))
+	for _, ts := range transactions {
+		var errorRate, sla interface{}
+		if ts.Failed.Total > 0 {
+			errorRate = float64(ts.Failed.Trues) / float64(ts.Failed.Total)
+		}
+		if ts.SLA.Valid {
+			sla = ts.SLA.Bool
+		}
+		result = append(result, map[string]interface{}{
+			"name":       strings.TrimPrefix(ts.Group, lib.GroupSeparator),
+			"group":      ts.Group,
+			"count":      ts.Duration.Count,
+			"error_rate": errorRate,
+			"p(90)":      ts.Duration.P(0.90),
+			"p(95)":      ts.Duration.P(0.95),
+			"p(99)":      ts.Duration.P(0.99),
+			"sla_ok":     sla,
+		})
+	}
+	return result
+}
+
+// submetricRank returns the value by which the sub-metrics of a metric are
+// ranked when only the top ones are shown, with the name of the summary value
+// it's from. The higher it is, the worse the sub-metric is assumed to be, e.g.
+// the URLs with the highest p(95) or with the most failed requests. The rates
+// are ranked by their true values if those are the failures, see
+// rateFailuresAreTrue(), and by their false values otherwise.
+func submetricRank(sink metrics.Sink, trueFailures bool) (string, float64) {
+	switch sink := sink.(type) {
+	case *metrics.TrendSink:
+		return "p(95)", sink.P(0.95)
+	case *metrics.RateSink:
+		if trueFailures {
+			return "passes", float64(sink.Trues)
+		}
+		return "fails", float64(sink.Total - sink.Trues)
+	case *metrics.CounterSink:
+		return "count", sink.Value
+	case *metrics.GaugeSink:
+		return "value", sink.Value
+	default:
+		return "", 0
+	}
+}
+
+// rateFailuresAreTrue returns whether the true values of the rate metric are
+// its failures, based on the rate thresholds of the metric and its
+// sub-metrics: an upper limit, e.g. rate<0.01, means that they are, while a
+// lower limit, e.g. rate>0.99, means that the false values are. Without such
+// thresholds, only the true values of http_req_failed are the failures, while
+// for the other rates, like checks, the false values are.
+func rateFailuresAreTrue(parent *metrics.Metric) bool {
+	thresholds := append([]*metrics.Threshold{}, parent.Thresholds.Thresholds...)
+	for _, sm := range parent.Submetrics {
+		thresholds = append(thresholds, sm.Metric.Thresholds.Thresholds...)
+	}
+	for _, threshold := range thresholds {
+		if aggregation, err := threshold.AggregationMethod(); err != nil || aggregation != "rate" {
+			continue
+		}
+		operator, err := threshold.Operator()
+		if err != nil {
+			continue
+		}
+		switch operator {
+		case "<", "<=":
+			return true
+		case ">", ">=":
+			return false
+		}
+	}
+	return parent.Name == metrics.HTTPReqFailedName
+}
+
+// selectTopSubmetrics returns the summary metrics with only the top sub-metrics
+// of every metric, and how many sub-metrics were omitted for each metric. The
+// sub-metrics with thresholds are always kept and not counted towards the top.
+func selectTopSubmetrics(
+	observed map[string]*metrics.Metric, metricsData map[string]interface{}, top int,
+) (shown map[string]interface{}, omitted map[string]interface{}) {
+	candidates := make(map[*metrics.Metric][]*metrics.Metric)
+	shown = make(map[string]interface{}, len(metricsData))
+	for name, data := range metricsData {
+		m := observed[name]
+		if m.Sub == nil || m.Sub.Parent == nil || len(m.Thresholds.Thresholds) > 0 {
+			shown[name] = data
+			continue
+		}
+		candidates[m.Sub.Parent] = append(candidates[m.Sub.Parent], m)
+	}
+
+	omitted = make(map[string]interface{})
+	for parent, submetrics := range candidates {
+		trueFailures := parent.Type == metrics.Rate && rateFailuresAreTrue(parent)
+		rankedBy, _ := submetricRank(submetrics[0].Sink, trueFailures)
+		sort.SliceStable(submetrics, func(i, j int) bool {
+			_, rankI := submetricRank(submetrics[i].Sink, trueFailures)
+			_, rankJ := submetricRank(submetrics[j].Sink, trueFailures)
+			if rankI != rankJ {
+				return rankI > rankJ
+			}
+			return submetrics[i].Name < submetrics[j].Name
+		})
+		for i, m := range submetrics {
+			if i >= top {
+				break
+			}
+			shown[m.Name] = metricsData[m.Name]
+		}
+		if len(submetrics) > top {
+			omitted[parent.Name] = map[string]interface{}{
+				"count":    len(submetrics) - top,
+				"rankedBy": rankedBy,
+			}
+		}
+	}
+	return shown, omitted
 }
 
 func exportGroup(group *lib.Group) map[string]interface{} {
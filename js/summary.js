@@ -148,6 +148,7 @@ var unitMap = {
   s: { unit: 's', coef: 0.001 },
   ms: { unit: 'ms', coef: 1 },
   us: { unit: 'µs', coef: 1000 },
+  ns: { unit: 'ns', coef: 1000000 },
 }
 
 function toFixedNoTrailingZeros(val, prec) {
@@ -345,7 +346,22 @@ function summarizeMetrics(options, data, decorate) {
     return fmtData
   }
 
+  var omitted = data.omitted_submetrics || {}
+  var addOmittedNote = function (parent) {
+    if (omitted.hasOwnProperty(parent)) {
+      var note = '↳ ' + omitted[parent].count + ' more sub-metrics not shown, ranked by ' + omitted[parent].rankedBy
+      result.push(indent + '    ' + decorate(note, palette.faint))
+    }
+  }
+
+  var lastParent = null
   for (var name of names) {
+    var parent = name.split('{', 1)[0]
+    if (lastParent !== null && parent !== lastParent) {
+      addOmittedNote(lastParent)
+    }
+    lastParent = parent
+
     var metric = data.metrics[name]
     var mark = ' '
     var markColor = function (text) {
@@ -378,7 +394,108 @@ function summarizeMetrics(options, data, decorate) {
 
     result.push(indent + fmtIndent + markColor(mark) + ' ' + fmtName + ' ' + getData(name))
   }
+  if (lastParent !== null) {
+    addOmittedNote(lastParent)
+  }
+
+  return result
+}
+
+// summarizeTransactions renders the SLA table of the transactions, i.e. of
+// the groups with thresholds on their group_duration, or of all groups with
+// the summaryTransactions option, marking the ones that passed their SLA.
+function summarizeTransactions(options, data, decorate) {
+  var transactions = data.transactions || []
+  if (transactions.length == 0) {
+    return []
+  }
+
+  var indent = options.indent + '  '
+  var timeMetric = { type: 'trend', contains: 'time' }
+  var rows = [['transaction', 'count', 'errors', 'p(90)', 'p(95)', 'p(99)']]
+  for (var tx of transactions) {
+    rows.push([
+      tx.name,
+      tx.count.toString(),
+      tx.error_rate === null ? '-' : humanizeValue(tx.error_rate, { type: 'rate' }, options.summaryTimeUnit),
+      humanizeValue(tx['p(90)'], timeMetric, options.summaryTimeUnit),
+      humanizeValue(tx['p(95)'], timeMetric, options.summaryTimeUnit),
+      humanizeValue(tx['p(99)'], timeMetric, options.summaryTimeUnit),
+    ])
+  }
+
+  var widths = new Array(rows[0].length).fill(0)
+  for (var row of rows) {
+    for (var i = 0; i < row.length; i++) {
+      widths[i] = Math.max(widths[i], strWidth(row[i]))
+    }
+  }
+  var formatRow = function (row, color) {
+    var cols = new Array(row.length)
+    for (var i = 0; i < row.length; i++) {
+      var padding = ' '.repeat(widths[i] - strWidth(row[i]))
+      // the names are aligned to the left and the values to the right
+      cols[i] = i == 0 ? decorate(row[i], color) + padding : padding + decorate(row[i], color)
+    }
+    return cols.join('  ')
+  }
 
+  var result = [indent + '  ' + formatRow(rows[0], palette.faint)]
+  for (var i = 0; i < transactions.length; i++) {
+    var mark = ' '
+    if (transactions[i].sla_ok === true) {
+      mark = decorate(succMark, palette.green)
+    } else if (transactions[i].sla_ok === false) {
+      mark = decorate(failMark, palette.red)
+    }
+    result.push(indent + mark + ' ' + formatRow(rows[i + 1], palette.cyan))
+  }
+  result.push('')
+  return result
+}
+
+// summarizeThroughputSearches shows the maximum rate that each of the
+// throughput-search scenarios found, with the results of their plateaus.
+function summarizeThroughputSearches(options, data, decorate) {
+  var searches = data.throughput_searches || []
+  var indent = options.indent + '    '
+  var result = []
+  for (var search of searches) {
+    var unit = ' iterations/' + humanizeGenericDuration(search.time_unit)
+    var maxRate = search.max_rate === null ? 'none of the plateaus passed' : search.max_rate + unit
+    if (search.inconclusive) {
+      maxRate += ' (inconclusive, ran out of plateaus)'
+    }
+    result.push(indent + 'throughput search ' + search.scenario + ': ' + decorate(maxRate, palette.cyan))
+    for (var plateau of search.plateaus) {
+      if (plateau.passed) {
+        result.push(indent + '  ' + decorate(succMark + ' ' + plateau.rate + unit, palette.green))
+      } else {
+        var failures = plateau.failures.length > 0 ? ': ' + plateau.failures.join(', ') : ''
+        result.push(indent + '  ' + decorate(failMark + ' ' + plateau.rate + unit + failures, palette.red))
+      }
+    }
+    result.push('')
+  }
+  return result
+}
+
+// summarizeAbort explains why the test run was aborted, warning that the
+// summary is incomplete if k6 was forcefully stopped.
+function summarizeAbort(options, data, decorate) {
+  if (!data.abort) {
+    return []
+  }
+
+  var indent = options.indent + '    '
+  var result = [indent + decorate(failMark + ' test run aborted: ' + data.abort.reason, palette.red)]
+  if (data.abort.truncated) {
+    result.push(
+      indent +
+        decorate(failMark + ' the summary is truncated, it contains only the metrics processed before the abort', palette.red)
+    )
+  }
+  result.push('')
   return result
 }
 
@@ -400,11 +517,14 @@ function generateTextSummary(data, options) {
     }
   }
 
+  Array.prototype.push.apply(lines, summarizeAbort(mergedOpts, data, decorate))
   Array.prototype.push.apply(
     lines,
     summarizeGroup(mergedOpts.indent + '    ', data.root_group, decorate)
   )
 
+  Array.prototype.push.apply(lines, summarizeTransactions(mergedOpts, data, decorate))
+  Array.prototype.push.apply(lines, summarizeThroughputSearches(mergedOpts, data, decorate))
   Array.prototype.push.apply(lines, summarizeMetrics(mergedOpts, data, decorate))
 
   return lines.join('\n')
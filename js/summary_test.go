@@ -24,6 +24,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"testing"
 	"time"
@@ -153,6 +154,257 @@ func TestTextSummaryWithSubMetrics(t *testing.T) {
 	assert.Equal(t, "\n"+expected+"\n", string(summaryOut))
 }
 
+func TestTextSummaryTopSubmetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	parentMetric, err := registry.NewMetric("my_trend", metrics.Trend)
+	require.NoError(t, err)
+	testMetrics := map[string]*metrics.Metric{parentMetric.Name: parentMetric}
+	for i, value := range []float64{20, 30, 10} {
+		subMetric, errSub := parentMetric.AddSubmetric(fmt.Sprintf("url:%d", i))
+		require.NoError(t, errSub)
+		subMetric.Metric.Sink.Add(metrics.Sample{Value: value})
+		parentMetric.Sink.Add(metrics.Sample{Value: value})
+		testMetrics[subMetric.Name] = subMetric.Metric
+	}
+
+	summary := &lib.Summary{
+		Metrics:         testMetrics,
+		RootGroup:       &lib.Group{},
+		TestRunDuration: time.Second,
+	}
+
+	runner, err := getSimpleRunner(
+		t,
+		"/script.js",
+		`
+		exports.options = {summaryTrendStats: ["max"], summaryTopSubmetrics: 2};
+		exports.default = function() {/* we don't run this, metrics are mocked */};
+		`,
+		lib.RuntimeOptions{
+			CompatibilityMode: null.NewString("base", true),
+			SummaryExport:     null.StringFrom("export.json"),
+		},
+	)
+	require.NoError(t, err)
+
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	summaryOut, err := ioutil.ReadAll(result["stdout"])
+	require.NoError(t, err)
+	expected := "     my_trend......: max=30\n" +
+		"       { url:0 }...: max=20\n" +
+		"       { url:1 }...: max=30\n" +
+		"       ↳ 1 more sub-metrics not shown, ranked by p(95)\n"
+	assert.Equal(t, "\n"+expected+"\n", string(summaryOut))
+
+	exportOut, err := ioutil.ReadAll(result["export.json"])
+	require.NoError(t, err)
+	var export struct {
+		Metrics map[string]interface{} `json:"metrics"`
+	}
+	require.NoError(t, json.Unmarshal(exportOut, &export))
+	assert.Len(t, export.Metrics, 4)
+	assert.Contains(t, export.Metrics, "my_trend{url:2}")
+}
+
+func TestSubmetricRank(t *testing.T) {
+	t.Parallel()
+
+	rate := &metrics.RateSink{}
+	for _, value := range []float64{1, 0, 0} {
+		rate.Add(metrics.Sample{Value: value})
+	}
+	rankedBy, rank := submetricRank(rate, false)
+	assert.Equal(t, "fails", rankedBy)
+	assert.Equal(t, 2.0, rank)
+	rankedBy, rank = submetricRank(rate, true)
+	assert.Equal(t, "passes", rankedBy)
+	assert.Equal(t, 1.0, rank)
+
+	rankedBy, rank = submetricRank(&metrics.CounterSink{Value: 5}, false)
+	assert.Equal(t, "count", rankedBy)
+	assert.Equal(t, 5.0, rank)
+}
+
+func TestRateFailuresAreTrue(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	assert.False(t, rateFailuresAreTrue(builtinMetrics.Checks))
+	assert.True(t, rateFailuresAreTrue(builtinMetrics.HTTPReqFailed))
+
+	errorRate, err := registry.NewMetric("errors", metrics.Rate)
+	require.NoError(t, err)
+	assert.False(t, rateFailuresAreTrue(errorRate))
+	sm, err := errorRate.AddSubmetric("endpoint:checkout")
+	require.NoError(t, err)
+	sm.Metric.Thresholds = metrics.NewThresholds([]string{"rate<0.01"})
+	assert.True(t, rateFailuresAreTrue(errorRate))
+
+	successes, err := registry.NewMetric("successes", metrics.Rate)
+	require.NoError(t, err)
+	successes.Thresholds = metrics.NewThresholds([]string{"count>10", "rate>=0.99"})
+	assert.False(t, rateFailuresAreTrue(successes))
+}
+
+func TestTextSummaryTransactions(t *testing.T) {
+	t.Parallel()
+
+	newTransaction := func(group string, durations []float64, failed []bool, sla null.Bool) lib.TransactionSummary {
+		ts := lib.TransactionSummary{Group: group, Duration: &metrics.TrendSink{}, Failed: &metrics.RateSink{}, SLA: sla}
+		for _, d := range durations {
+			ts.Duration.Add(metrics.Sample{Value: d})
+		}
+		for _, f := range failed {
+			value := 0.0
+			if f {
+				value = 1
+			}
+			ts.Failed.Add(metrics.Sample{Value: value})
+		}
+		return ts
+	}
+
+	summary := &lib.Summary{
+		Metrics:         map[string]*metrics.Metric{},
+		RootGroup:       &lib.Group{},
+		TestRunDuration: time.Second,
+		Transactions: []lib.TransactionSummary{
+			newTransaction("::checkout", []float64{100, 200, 300, 400}, []bool{false, true}, null.BoolFrom(false)),
+			newTransaction("::checkout::payment", []float64{50, 50}, nil, null.Bool{}),
+			newTransaction("::login", []float64{10}, []bool{false}, null.BoolFrom(true)),
+		},
+	}
+
+	getSummary := func(script string) map[string]io.Reader {
+		runner, err := getSimpleRunner(t, "/script.js", script, lib.RuntimeOptions{
+			CompatibilityMode: null.NewString("base", true),
+			SummaryExport:     null.StringFrom("export.json"),
+		})
+		require.NoError(t, err)
+		result, err := runner.HandleSummary(context.Background(), summary)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := getSummary(`
+		exports.options = {summaryTrendStats: ["max"]};
+		exports.default = function() {/* we don't run this, metrics are mocked */};
+	`)
+	summaryOut, err := ioutil.ReadAll(result["stdout"])
+	require.NoError(t, err)
+	expected := "     transaction        count  errors  p(90)  p(95)  p(99)\n" +
+		"   ✗ checkout               4  50.00%  370ms  385ms  397ms\n" +
+		"     checkout::payment      2       -   50ms   50ms   50ms\n" +
+		"   ✓ login                  1   0.00%   10ms   10ms   10ms\n"
+	assert.Equal(t, "\n"+expected+"\n\n", string(summaryOut))
+
+	// the transactions are exported with --summary-export as well
+	exportOut, err := ioutil.ReadAll(result["export.json"])
+	require.NoError(t, err)
+	var export struct {
+		Transactions []map[string]interface{} `json:"transactions"`
+	}
+	require.NoError(t, json.Unmarshal(exportOut, &export))
+	require.Len(t, export.Transactions, 3)
+	assert.Equal(t, "::checkout", export.Transactions[0]["group"])
+	assert.Equal(t, 0.5, export.Transactions[0]["error_rate"])
+
+	result = getSummary(`
+		exports.default = function() {/* we don't run this, metrics are mocked */};
+		exports.handleSummary = function(data) {
+			return {'transactions.json': JSON.stringify(data.transactions)};
+		};
+	`)
+	transactionsOut, err := ioutil.ReadAll(result["transactions.json"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name": "checkout", "group": "::checkout", "count": 4, "error_rate": 0.5,
+			"p(90)": 370, "p(95)": 385, "p(99)": 397, "sla_ok": false},
+		{"name": "checkout::payment", "group": "::checkout::payment", "count": 2, "error_rate": null,
+			"p(90)": 50, "p(95)": 50, "p(99)": 50, "sla_ok": null},
+		{"name": "login", "group": "::login", "count": 1, "error_rate": 0,
+			"p(90)": 10, "p(95)": 10, "p(99)": 10, "sla_ok": true}
+	]`, string(transactionsOut))
+}
+
+func TestTextSummaryThroughputSearches(t *testing.T) {
+	t.Parallel()
+
+	summary := &lib.Summary{
+		Metrics:         map[string]*metrics.Metric{},
+		RootGroup:       &lib.Group{},
+		TestRunDuration: time.Second,
+		ThroughputSearches: []lib.ThroughputSearchResult{{
+			Scenario: "search",
+			TimeUnit: time.Second,
+			MaxRate:  10,
+			Plateaus: []lib.ThroughputSearchPlateau{
+				{Rate: 10, Passed: true},
+				{Rate: 20, Failures: []string{"http_req_duration: p(95)<500 (612.5)"}},
+			},
+		}},
+	}
+
+	getSummary := func(script string) map[string]io.Reader {
+		runner, err := getSimpleRunner(t, "/script.js", script,
+			lib.RuntimeOptions{CompatibilityMode: null.NewString("base", true)})
+		require.NoError(t, err)
+		result, err := runner.HandleSummary(context.Background(), summary)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := getSummary(`
+		exports.default = function() {/* we don't run this, metrics are mocked */};
+	`)
+	summaryOut, err := ioutil.ReadAll(result["stdout"])
+	require.NoError(t, err)
+	expected := "     throughput search search: 10 iterations/1s\n" +
+		"       ✓ 10 iterations/1s\n" +
+		"       ✗ 20 iterations/1s: http_req_duration: p(95)<500 (612.5)\n"
+	assert.Equal(t, "\n"+expected+"\n\n", string(summaryOut))
+
+	result = getSummary(`
+		exports.default = function() {/* we don't run this, metrics are mocked */};
+		exports.handleSummary = function(data) {
+			return {'searches.json': JSON.stringify(data.throughput_searches)};
+		};
+	`)
+	searchesOut, err := ioutil.ReadAll(result["searches.json"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"scenario": "search", "time_unit": 1000, "max_rate": 10, "inconclusive": false, "plateaus": [
+		{"rate": 10, "passed": true, "failures": []},
+		{"rate": 20, "passed": false, "failures": ["http_req_duration: p(95)<500 (612.5)"]}
+	]}]`, string(searchesOut))
+}
+
+func TestTextSummaryAbort(t *testing.T) {
+	t.Parallel()
+
+	runner, err := getSimpleRunner(t, "/script.js", `
+		exports.default = function() {/* we don't run this, metrics are mocked */};
+	`, lib.RuntimeOptions{CompatibilityMode: null.NewString("base", true)})
+	require.NoError(t, err)
+	result, err := runner.HandleSummary(context.Background(), &lib.Summary{
+		Metrics:         map[string]*metrics.Metric{},
+		RootGroup:       &lib.Group{},
+		TestRunDuration: time.Second,
+		Abort:           &lib.SummaryAbort{Reason: "a second interrupt signal was received", Truncated: true},
+	})
+	require.NoError(t, err)
+	summaryOut, err := ioutil.ReadAll(result["stdout"])
+	require.NoError(t, err)
+	expected := "     ✗ test run aborted: a second interrupt signal was received\n" +
+		"     ✗ the summary is truncated, it contains only the metrics processed before the abort\n"
+	assert.Equal(t, "\n"+expected+"\n\n", string(summaryOut))
+}
+
 func createTestMetrics(t *testing.T) (map[string]*metrics.Metric, *lib.Group) {
 	registry := metrics.NewRegistry()
 	testMetrics := make(map[string]*metrics.Metric)
@@ -231,10 +483,26 @@ func createTestSummary(t *testing.T) *lib.Summary {
 		Metrics:         metrics,
 		RootGroup:       rootG,
 		TestRunDuration: time.Second,
+		Environment: lib.RunEnvironment{
+			K6Version: "0.38.1", OS: "linux", Arch: "amd64", Hostname: "generator-1", CPUs: 8, GOMAXPROCS: 4,
+			OpenFilesLimit: 1048576, ContainerCPULimit: 4, ContainerMemoryLimit: 8 << 30,
+		},
 	}
 }
 
 const expectedOldJSONExportResult = `{
+    "environment": {
+        "k6_version": "0.38.1",
+        "os": "linux",
+        "arch": "amd64",
+        "hostname": "generator-1",
+        "cpus": 8,
+        "gomaxprocs": 4,
+        "open_files_limit": 1048576,
+        "container_cpu_limit": 4,
+        "container_memory_limit": 8589934592
+    },
+    "metadata": {},
     "root_group": {
         "name": "",
         "path": "",
@@ -343,6 +611,18 @@ func TestOldJSONExport(t *testing.T) {
 
 const expectedHandleSummaryRawData = `
 {
+    "environment": {
+        "k6_version": "0.38.1",
+        "os": "linux",
+        "arch": "amd64",
+        "hostname": "generator-1",
+        "cpus": 8,
+        "gomaxprocs": 4,
+        "open_files_limit": 1048576,
+        "container_cpu_limit": 4,
+        "container_memory_limit": 8589934592
+    },
+    "metadata": {},
     "root_group": {
         "groups": [
             {
@@ -460,6 +740,18 @@ const expectedHandleSummaryRawData = `
 
 const expectedHandleSummaryDataWithSetup = `
 {
+    "environment": {
+        "k6_version": "0.38.1",
+        "os": "linux",
+        "arch": "amd64",
+        "hostname": "generator-1",
+        "cpus": 8,
+        "gomaxprocs": 4,
+        "open_files_limit": 1048576,
+        "container_cpu_limit": 4,
+        "container_memory_limit": 8589934592
+    },
+    "metadata": {},
     "root_group": {
         "groups": [
             {
@@ -23,6 +23,8 @@ package lib
 import (
 	"archive/tar"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -93,6 +95,10 @@ type Archive struct {
 
 	K6Version string `json:"k6version"`
 	Goos      string `json:"goos"`
+
+	// Digest of all of the contents of the archive, including the rest of the
+	// metadata, which is checked when it's read. Older archives don't have it.
+	Digest string `json:"digest,omitempty"`
 }
 
 func (arc *Archive) getFs(name string) afero.Fs {
@@ -133,6 +139,7 @@ func (arc *Archive) loadMetadataJSON(data []byte) (err error) {
 func ReadArchive(in io.Reader) (*Archive, error) {
 	r := tar.NewReader(in)
 	arc := &Archive{Filesystems: make(map[string]afero.Fs, 2)}
+	entries := make(map[string][]byte)
 	// initialize both fses
 	_ = arc.getFs("https")
 	_ = arc.getFs("file")
@@ -153,6 +160,8 @@ func ReadArchive(in io.Reader) (*Archive, error) {
 			return nil, err
 		}
 
+		entries[hdr.Name] = data
+
 		switch hdr.Name {
 		case "metadata.json":
 			if err = arc.loadMetadataJSON(data); err != nil {
@@ -197,6 +206,18 @@ func ReadArchive(in io.Reader) (*Archive, error) {
 			return nil, fmt.Errorf("unknown file prefix `%s` for file `%s`", pfx, normPath)
 		}
 	}
+	if arc.Digest != "" {
+		metadata, err := normalizeArchiveMetadata(entries["metadata.json"])
+		if err != nil {
+			return nil, err
+		}
+		entries["metadata.json"] = metadata
+		if digest := getArchiveDigest(entries); digest != arc.Digest {
+			return nil, fmt.Errorf("the archive has the digest '%s' instead of the expected '%s', "+
+				"it was probably modified or corrupted", digest, arc.Digest)
+		}
+	}
+
 	scheme, pathOnFs := getURLPathOnFs(arc.FilenameURL)
 	var err error
 	pathOnFs, err = url.PathUnescape(pathOnFs)
@@ -237,50 +258,58 @@ func getURLtoString(u *url.URL) string {
 	return u.String()
 }
 
-// Write serialises the archive to a writer.
-//
-// The format should be treated as opaque; currently it is simply a TAR rollup, but this may
-// change. If it does change, ReadArchive must be able to handle all previous formats as well as
-// the current one.
-func (arc *Archive) Write(out io.Writer) error {
-	w := tar.NewWriter(out)
-
-	now := time.Now()
-	metaArc := *arc
-	normalizeAndAnonymizeURL(metaArc.FilenameURL)
-	normalizeAndAnonymizeURL(metaArc.PwdURL)
-	metaArc.Filename = getURLtoString(metaArc.FilenameURL)
-	metaArc.Pwd = getURLtoString(metaArc.PwdURL)
-	actualDataPath, err := url.PathUnescape(path.Join(getURLPathOnFs(metaArc.FilenameURL)))
-	if err != nil {
-		return err
+// archiveModTime is the modification time of all of the archive entries, so
+// the same inputs always result in byte-identical archives.
+var archiveModTime = time.Unix(0, 0) //nolint:gochecknoglobals
+
+// getArchiveDigest returns the digest of the contents of the archive entries,
+// with the normalized metadata. The entries are sorted by their names, so the
+// digest doesn't depend on their order in the archive.
+func getArchiveDigest(entries map[string][]byte) string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
 	}
-	var madeLinkToData bool
-	metadata, err := metaArc.json()
-	if err != nil {
-		return err
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00", name, len(entries[name]))
+		_, _ = h.Write(entries[name])
 	}
-	_ = w.WriteHeader(&tar.Header{
-		Name:     "metadata.json",
-		Mode:     0o644,
-		Size:     int64(len(metadata)),
-		ModTime:  now,
-		Typeflag: tar.TypeReg,
-	})
-	if _, err = w.Write(metadata); err != nil {
-		return err
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeArchiveMetadata returns the metadata JSON without its digest and
+// with sorted keys and no whitespace, which is how it's included in the
+// digest of the archive.
+func normalizeArchiveMetadata(metadata []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &fields); err != nil {
+		return nil, err
 	}
+	delete(fields, "digest")
 
-	_ = w.WriteHeader(&tar.Header{
-		Name:     "data",
-		Mode:     0o644,
-		Size:     int64(len(arc.Data)),
-		ModTime:  now,
-		Typeflag: tar.TypeReg,
-	})
-	if _, err = w.Write(arc.Data); err != nil {
-		return err
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fields); err != nil {
+		return nil, err
 	}
+	return buffer.Bytes(), nil
+}
+
+// archiveFilesystem contains the sorted directories and files of one of
+// the filesystems of an archive.
+type archiveFilesystem struct {
+	name  string
+	dirs  []string
+	paths []string
+	files map[string][]byte
+}
+
+func (arc *Archive) getArchiveFilesystems() ([]archiveFilesystem, error) {
+	result := make([]archiveFilesystem, 0, 2)
 	for _, name := range [...]string{"file", "https"} {
 		filesystem, ok := arc.Filesystems[name]
 		if !ok {
@@ -298,9 +327,7 @@ func (arc *Archive) Write(out io.Writer) error {
 		// - We don't want to leak private information (eg. usernames) in archives, so make sure to
 		//   anonymize paths before stuffing them in a shareable archive.
 		foundDirs := make(map[string]bool)
-		paths := make([]string, 0, 10)
-		infos := make(map[string]os.FileInfo) // ... fix this ?
-		files := make(map[string][]byte)
+		afs := archiveFilesystem{name: name, files: make(map[string][]byte)}
 
 		walkFunc := filepath.WalkFunc(func(filePath string, info os.FileInfo, err error) error {
 			if err != nil {
@@ -308,64 +335,135 @@ func (arc *Archive) Write(out io.Writer) error {
 			}
 			normalizedPath := NormalizeAndAnonymizePath(filePath)
 
-			infos[normalizedPath] = info
 			if info.IsDir() {
 				foundDirs[normalizedPath] = true
 				return nil
 			}
 
-			paths = append(paths, normalizedPath)
-			files[normalizedPath], err = afero.ReadFile(filesystem, filePath)
+			afs.paths = append(afs.paths, normalizedPath)
+			afs.files[normalizedPath], err = afero.ReadFile(filesystem, filePath)
 			return err
 		})
 
-		if err = fsext.Walk(filesystem, afero.FilePathSeparator, walkFunc); err != nil {
-			return err
+		if err := fsext.Walk(filesystem, afero.FilePathSeparator, walkFunc); err != nil {
+			return nil, err
 		}
-		if len(files) == 0 {
+		if len(afs.files) == 0 {
 			continue // we don't need to write anything for this fs, if this is not done the root will be written
 		}
-		dirs := make([]string, 0, len(foundDirs))
 		for dirpath := range foundDirs {
-			dirs = append(dirs, dirpath)
+			afs.dirs = append(afs.dirs, dirpath)
 		}
-		sort.Strings(paths)
-		sort.Strings(dirs)
+		sort.Strings(afs.paths)
+		sort.Strings(afs.dirs)
+		result = append(result, afs)
+	}
+	return result, nil
+}
 
-		for _, dirPath := range dirs {
+// Write serialises the archive to a writer.
+//
+// The format should be treated as opaque; currently it is simply a TAR rollup, but this may
+// change. If it does change, ReadArchive must be able to handle all previous formats as well as
+// the current one. The same archive is always written in the same way, with the same
+// timestamps, and its metadata contains a digest of the rest of the contents.
+func (arc *Archive) Write(out io.Writer) error {
+	w := tar.NewWriter(out)
+
+	metaArc := *arc
+	normalizeAndAnonymizeURL(metaArc.FilenameURL)
+	normalizeAndAnonymizeURL(metaArc.PwdURL)
+	metaArc.Filename = getURLtoString(metaArc.FilenameURL)
+	metaArc.Pwd = getURLtoString(metaArc.PwdURL)
+	actualDataPath, err := url.PathUnescape(path.Join(getURLPathOnFs(metaArc.FilenameURL)))
+	if err != nil {
+		return err
+	}
+
+	filesystems, err := arc.getArchiveFilesystems()
+	if err != nil {
+		return err
+	}
+	entries := map[string][]byte{"data": arc.Data}
+	var madeLinkToData bool
+	for _, afs := range filesystems {
+		for _, filePath := range afs.paths {
+			fullFilePath := path.Clean(path.Join(afs.name, filePath))
+			if fullFilePath == actualDataPath {
+				madeLinkToData = true
+				continue
+			}
+			entries[fullFilePath] = afs.files[filePath]
+		}
+	}
+	if !madeLinkToData {
+		// This should never happen we should always link to `data` from inside the file/https directories
+		return fmt.Errorf("archive creation failed because the main script wasn't present in the cached filesystem")
+	}
+	metaArc.Digest = ""
+	metadata, err := metaArc.json()
+	if err != nil {
+		return err
+	}
+	if entries["metadata.json"], err = normalizeArchiveMetadata(metadata); err != nil {
+		return err
+	}
+	metaArc.Digest = getArchiveDigest(entries)
+	if metadata, err = metaArc.json(); err != nil {
+		return err
+	}
+	_ = w.WriteHeader(&tar.Header{
+		Name:     "metadata.json",
+		Mode:     0o644,
+		Size:     int64(len(metadata)),
+		ModTime:  archiveModTime,
+		Typeflag: tar.TypeReg,
+	})
+	if _, err = w.Write(metadata); err != nil {
+		return err
+	}
+
+	_ = w.WriteHeader(&tar.Header{
+		Name:     "data",
+		Mode:     0o644,
+		Size:     int64(len(arc.Data)),
+		ModTime:  archiveModTime,
+		Typeflag: tar.TypeReg,
+	})
+	if _, err = w.Write(arc.Data); err != nil {
+		return err
+	}
+	for _, afs := range filesystems {
+		for _, dirPath := range afs.dirs {
 			_ = w.WriteHeader(&tar.Header{
-				Name:       path.Clean(path.Join(name, dirPath)),
-				Mode:       0o755, // MemMapFs is buggy
-				AccessTime: now,   // MemMapFs is buggy
-				ChangeTime: now,   // MemMapFs is buggy
-				ModTime:    now,   // MemMapFs is buggy
-				Typeflag:   tar.TypeDir,
+				Name:     path.Clean(path.Join(afs.name, dirPath)),
+				Mode:     0o755, // MemMapFs is buggy
+				ModTime:  archiveModTime,
+				Typeflag: tar.TypeDir,
 			})
 		}
 
-		for _, filePath := range paths {
-			fullFilePath := path.Clean(path.Join(name, filePath))
+		for _, filePath := range afs.paths {
+			fullFilePath := path.Clean(path.Join(afs.name, filePath))
 			// we either have opaque
 			if fullFilePath == actualDataPath {
-				madeLinkToData = true
 				err = w.WriteHeader(&tar.Header{
 					Name:     fullFilePath,
 					Size:     0,
+					ModTime:  archiveModTime,
 					Typeflag: tar.TypeLink,
 					Linkname: "data",
 				})
 			} else {
 				err = w.WriteHeader(&tar.Header{
-					Name:       fullFilePath,
-					Mode:       0o644, // MemMapFs is buggy
-					Size:       int64(len(files[filePath])),
-					AccessTime: infos[filePath].ModTime(),
-					ChangeTime: infos[filePath].ModTime(),
-					ModTime:    infos[filePath].ModTime(),
-					Typeflag:   tar.TypeReg,
+					Name:     fullFilePath,
+					Mode:     0o644, // MemMapFs is buggy
+					Size:     int64(len(afs.files[filePath])),
+					ModTime:  archiveModTime,
+					Typeflag: tar.TypeReg,
 				})
 				if err == nil {
-					_, err = w.Write(files[filePath])
+					_, err = w.Write(afs.files[filePath])
 				}
 			}
 			if err != nil {
@@ -373,10 +471,6 @@ func (arc *Archive) Write(out io.Writer) error {
 			}
 		}
 	}
-	if !madeLinkToData {
-		// This should never happen we should always link to `data` from inside the file/https directories
-		return fmt.Errorf("archive creation failed because the main script wasn't present in the cached filesystem")
-	}
 
 	return w.Close()
 }
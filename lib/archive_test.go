@@ -21,14 +21,18 @@
 package lib
 
 import (
+	"archive/tar"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -178,6 +182,8 @@ func TestArchiveReadWrite(t *testing.T) {
 		arc2.Filesystems = nil
 		arc2.Filename = ""
 		arc2.Pwd = ""
+		assert.NotEmpty(t, arc2.Digest)
+		arc2.Digest = ""
 
 		assert.Equal(t, arc1, arc2)
 
@@ -251,6 +257,8 @@ func TestArchiveReadWrite(t *testing.T) {
 			assert.NoError(t, err)
 			arc2.Filename = ""
 			arc2.Pwd = ""
+			assert.NotEmpty(t, arc2.Digest)
+			arc2.Digest = ""
 
 			arc2Filesystems := arc2.Filesystems
 			arc2.Filesystems = nil
@@ -375,6 +383,8 @@ func TestStrangePaths(t *testing.T) {
 		arc2.Filesystems = nil
 		arc2.Filename = ""
 		arc2.Pwd = ""
+		assert.NotEmpty(t, arc2.Digest)
+		arc2.Digest = ""
 
 		assert.Equal(t, arc1, arc2, pathToChange)
 
@@ -410,3 +420,67 @@ func TestStdinArchive(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, string(data), "test")
 }
+
+func TestArchiveDeterministic(t *testing.T) {
+	t.Parallel()
+
+	newArchive := func(t *testing.T, modTime time.Time) *Archive {
+		files := map[string][]byte{
+			"/path/to/a.js":     []byte(`// a contents`),
+			"/path/to/b.js":     []byte(`// b contents`),
+			"/path/to/data.csv": []byte(`a,b`),
+		}
+		fs := makeMemMapFs(t, files)
+		for name := range files {
+			require.NoError(t, fs.Chtimes(name, modTime, modTime))
+		}
+		return &Archive{
+			Type:        "js",
+			K6Version:   consts.Version,
+			Options:     Options{VUs: null.IntFrom(10)},
+			FilenameURL: &url.URL{Scheme: "file", Path: "/path/to/a.js"},
+			Data:        []byte(`// a contents`),
+			PwdURL:      &url.URL{Scheme: "file", Path: "/path/to"},
+			Filesystems: map[string]afero.Fs{
+				"file":  fs,
+				"https": makeMemMapFs(t, map[string][]byte{"/example.com/lib.js": []byte(`// lib`)}),
+			},
+		}
+	}
+
+	buf1, buf2 := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	require.NoError(t, newArchive(t, time.Now()).Write(buf1))
+	require.NoError(t, newArchive(t, time.Now().Add(-time.Hour)).Write(buf2))
+	assert.Equal(t, buf1.Bytes(), buf2.Bytes())
+
+	t.Run("timestamps", func(t *testing.T) {
+		t.Parallel()
+		r := tar.NewReader(bytes.NewReader(buf1.Bytes()))
+		for {
+			hdr, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			assert.Equal(t, time.Unix(0, 0), hdr.ModTime, hdr.Name)
+		}
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		t.Parallel()
+		arc, err := ReadArchive(bytes.NewReader(buf1.Bytes()))
+		require.NoError(t, err)
+		assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, arc.Digest)
+
+		modified := bytes.Replace(buf1.Bytes(), []byte(`a,b`), []byte(`a,c`), 1)
+		_, err = ReadArchive(bytes.NewReader(modified))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "it was probably modified or corrupted")
+
+		require.Contains(t, buf1.String(), `"vus": 10`)
+		modified = bytes.Replace(buf1.Bytes(), []byte(`"vus": 10`), []byte(`"vus": 99`), 1)
+		_, err = ReadArchive(bytes.NewReader(modified))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "it was probably modified or corrupted")
+	})
+}
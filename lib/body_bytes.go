@@ -0,0 +1,61 @@
+package lib
+
+import "sync"
+
+// BodyBytesTracker keeps track of the response body bytes that were allocated
+// by a VU during a single iteration, so that iterations which hold on to
+// unexpectedly large bodies (e.g. a binary download that was read as text)
+// can be detected before they cause memory issues at scale.
+type BodyBytesTracker struct {
+	mu           sync.Mutex
+	total        int64
+	largest      int64
+	largestURL   string
+	largestGroup string
+}
+
+// BodyBytesReport is a snapshot of the body bytes that were allocated during
+// an iteration, together with the context of its largest body.
+type BodyBytesReport struct {
+	Total        int64
+	Largest      int64
+	LargestURL   string
+	LargestGroup string
+}
+
+// Add records that a body with the given size was allocated for a request to
+// the given URL, while the VU was in the given group.
+func (t *BodyBytesTracker) Add(size int64, group, url string) {
+	if t == nil || size <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.total += size
+	if size > t.largest {
+		t.largest = size
+		t.largestURL = url
+		t.largestGroup = group
+	}
+}
+
+// Reset returns the current report and clears the tracker, so it can be
+// used for the next iteration.
+func (t *BodyBytesTracker) Reset() BodyBytesReport {
+	if t == nil {
+		return BodyBytesReport{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := BodyBytesReport{
+		Total:        t.total,
+		Largest:      t.largest,
+		LargestURL:   t.largestURL,
+		LargestGroup: t.largestGroup,
+	}
+	t.total, t.largest, t.largestURL, t.largestGroup = 0, 0, "", ""
+
+	return report
+}
@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyBytesTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := &BodyBytesTracker{}
+	tracker.Add(10, "", "http://example.com/small")
+	tracker.Add(100, "::download", "http://example.com/big")
+	tracker.Add(0, "", "http://example.com/empty")
+
+	assert.Equal(t, BodyBytesReport{
+		Total:        110,
+		Largest:      100,
+		LargestURL:   "http://example.com/big",
+		LargestGroup: "::download",
+	}, tracker.Reset())
+	assert.Equal(t, BodyBytesReport{}, tracker.Reset())
+
+	var nilTracker *BodyBytesTracker
+	nilTracker.Add(10, "", "http://example.com/")
+	assert.Equal(t, BodyBytesReport{}, nilTracker.Reset())
+}
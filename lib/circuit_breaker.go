@@ -0,0 +1,328 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/metrics"
+)
+
+// CircuitBreakerAction is what is done while a circuit breaker is open.
+type CircuitBreakerAction string
+
+// The possible circuit breaker actions.
+const (
+	// CircuitBreakerSkipGroup skips the configured groups.
+	CircuitBreakerSkipGroup CircuitBreakerAction = "skipGroup"
+	// CircuitBreakerSlowDown pauses before every iteration of the configured
+	// scenarios, or of all of them if none are configured.
+	CircuitBreakerSlowDown CircuitBreakerAction = "slowDown"
+	// CircuitBreakerAbort aborts the whole test.
+	CircuitBreakerAbort CircuitBreakerAction = "abort"
+)
+
+// DefaultCircuitBreakerInterval is how often circuit breakers are evaluated,
+// if they don't have a custom interval.
+const DefaultCircuitBreakerInterval = time.Second
+
+// DefaultCircuitBreakerMinSamples is how many samples the metric needs to have
+// in the window before a circuit breaker is evaluated, if it doesn't have a
+// custom minimum.
+const DefaultCircuitBreakerMinSamples = 10
+
+// CircuitBreakerConfig is the configuration of a circuit breaker. It opens
+// when the threshold fails for the metric, aggregated over the window, and it
+// closes again when the threshold passes or when the window has less than
+// MinSamples samples.
+type CircuitBreakerConfig struct {
+	Metric     string
+	Threshold  string
+	Window     time.Duration
+	Interval   time.Duration
+	MinSamples uint64
+	Action     CircuitBreakerAction
+	Groups     []string
+	Scenarios  []string
+	Delay      time.Duration
+}
+
+// Validate checks the configuration and sets the default interval and
+// minimum number of samples.
+func (c *CircuitBreakerConfig) Validate() error {
+	if c.Metric == "" {
+		return errors.New("a metric is required")
+	}
+	if c.Threshold == "" {
+		return errors.New("a threshold is required")
+	}
+	if _, err := (&metrics.Threshold{Source: c.Threshold}).AggregationMethod(); err != nil {
+		return err
+	}
+	if c.Window < 0 || c.Interval < 0 || c.Delay < 0 {
+		return errors.New("the window, interval and delay can't be negative")
+	}
+	if c.Interval == 0 {
+		c.Interval = DefaultCircuitBreakerInterval
+	}
+	if c.MinSamples == 0 {
+		c.MinSamples = DefaultCircuitBreakerMinSamples
+	}
+
+	switch c.Action {
+	case CircuitBreakerSkipGroup:
+		if len(c.Groups) == 0 {
+			return fmt.Errorf("the '%s' action requires at least one group", c.Action)
+		}
+	case CircuitBreakerSlowDown:
+		if c.Delay == 0 {
+			return fmt.Errorf("the '%s' action requires a delay", c.Action)
+		}
+	case CircuitBreakerAbort:
+	default:
+		return fmt.Errorf("unknown action '%s', it should be one of '%s', '%s' or '%s'",
+			c.Action, CircuitBreakerSkipGroup, CircuitBreakerSlowDown, CircuitBreakerAbort)
+	}
+	return nil
+}
+
+// CircuitBreaker is a named circuit breaker, shared between all VUs.
+type CircuitBreaker struct {
+	Name   string
+	Config CircuitBreakerConfig
+
+	mu        sync.Mutex
+	open      bool
+	lastValue float64
+}
+
+// IsOpen returns whether the circuit breaker was open the last time it was
+// evaluated.
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.open
+}
+
+// prepare queries the metric once before the test starts running, so its
+// samples are tracked from the beginning and any sub-metric is created before
+// samples are emitted. It also catches unknown metrics and aggregation methods
+// that aren't supported by the metric before the first interval.
+func (cb *CircuitBreaker) prepare(querier MetricsQuerier) error {
+	if _, err := querier.CountSamples(cb.Config.Metric, cb.Config.Window); err != nil &&
+		!errors.Is(err, ErrNewQuerySubmetric) {
+		return err
+	}
+	aggregation, err := (&metrics.Threshold{Source: cb.Config.Threshold}).AggregationMethod()
+	if err != nil {
+		return err
+	}
+	_, err = querier.QueryMetric(cb.Config.Metric, aggregation, cb.Config.Window)
+	return err
+}
+
+// evaluate queries the metric and checks the threshold. Until the window
+// has enough samples, the threshold isn't checked and the circuit breaker is
+// closed. It returns whether the circuit breaker has just been opened or
+// closed.
+func (cb *CircuitBreaker) evaluate(querier MetricsQuerier) (changed bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	samples, err := querier.CountSamples(cb.Config.Metric, cb.Config.Window)
+	if err != nil {
+		return false, err
+	}
+	if samples < cb.Config.MinSamples {
+		changed = cb.open
+		cb.open = false
+		return changed, nil
+	}
+
+	threshold := &metrics.Threshold{Source: cb.Config.Threshold}
+	aggregation, err := threshold.AggregationMethod()
+	if err != nil {
+		return false, err
+	}
+	value, err := querier.QueryMetric(cb.Config.Metric, aggregation, cb.Config.Window)
+	if err != nil {
+		return false, err
+	}
+	passes, _, err := threshold.Evaluate(map[string]float64{aggregation: value})
+	if err != nil {
+		return false, err
+	}
+
+	changed = cb.open == passes
+	cb.open, cb.lastValue = !passes, value
+	return changed, nil
+}
+
+func (cb *CircuitBreaker) log(logger logrus.FieldLogger) {
+	cb.mu.Lock()
+	open, value := cb.open, cb.lastValue
+	cb.mu.Unlock()
+
+	entry := logger.WithFields(logrus.Fields{
+		"circuit_breaker": cb.Name,
+		"metric":          cb.Config.Metric,
+		"threshold":       cb.Config.Threshold,
+		"value":           value,
+	})
+	if open {
+		entry.Warnf("Circuit breaker '%s' opened, the '%s' action is in effect", cb.Name, cb.Config.Action)
+	} else {
+		entry.Infof("Circuit breaker '%s' closed", cb.Name)
+	}
+}
+
+// CircuitBreakers contains all of the circuit breakers of a test run. All of
+// its methods can be called on a nil CircuitBreakers.
+type CircuitBreakers struct {
+	mu       sync.RWMutex
+	breakers []*CircuitBreaker
+}
+
+// NewCircuitBreakers returns an empty set of circuit breakers.
+func NewCircuitBreakers() *CircuitBreakers {
+	return &CircuitBreakers{}
+}
+
+// Add validates the configuration and adds a new circuit breaker. Since the
+// init context is executed by every VU, the circuit breaker with the same
+// name is returned if it was already added.
+func (cbs *CircuitBreakers) Add(name string, config CircuitBreakerConfig) (*CircuitBreaker, error) {
+	if name == "" {
+		return nil, errors.New("the circuit breaker needs a name")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid circuit breaker '%s': %w", name, err)
+	}
+
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	for _, cb := range cbs.breakers {
+		if cb.Name == name {
+			return cb, nil
+		}
+	}
+	cb := &CircuitBreaker{Name: name, Config: config}
+	cbs.breakers = append(cbs.breakers, cb)
+	return cb, nil
+}
+
+func (cbs *CircuitBreakers) list() []*CircuitBreaker {
+	if cbs == nil {
+		return nil
+	}
+	cbs.mu.RLock()
+	defer cbs.mu.RUnlock()
+	return cbs.breakers
+}
+
+// Run evaluates every circuit breaker on its own interval and logs the ones
+// that were opened or closed, until the context is done or an abort circuit
+// breaker is opened, which is then returned. It should be called before the
+// test starts running. A circuit breaker that can't be evaluated is logged
+// and isn't evaluated again.
+func (cbs *CircuitBreakers) Run(
+	ctx context.Context, querier MetricsQuerier, logger logrus.FieldLogger,
+) (*CircuitBreaker, error) {
+	breakers := cbs.list()
+	if len(breakers) == 0 {
+		return nil, nil
+	}
+	if querier == nil {
+		return nil, errors.New("circuit breakers can't be evaluated, since the metrics aren't aggregated locally")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	aborts := make(chan *CircuitBreaker, len(breakers))
+	for _, cb := range breakers {
+		if err := cb.prepare(querier); err != nil {
+			logger.WithError(err).Errorf("Error evaluating circuit breaker '%s', it won't be evaluated", cb.Name)
+			aborts <- nil
+			continue
+		}
+		go func(cb *CircuitBreaker) {
+			aborts <- cb.run(ctx, querier, logger)
+		}(cb)
+	}
+
+	for range breakers {
+		if abort := <-aborts; abort != nil {
+			return abort, nil
+		}
+	}
+	return nil, nil
+}
+
+// run evaluates the circuit breaker on its interval, until the context is
+// done, it can't be evaluated or, if it's an abort one, it's opened, in which
+// case it's returned.
+func (cb *CircuitBreaker) run(ctx context.Context, querier MetricsQuerier, logger logrus.FieldLogger) *CircuitBreaker {
+	ticker := time.NewTicker(cb.Config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		changed, err := cb.evaluate(querier)
+		if err != nil {
+			logger.WithError(err).Errorf("Error evaluating circuit breaker '%s', it won't be evaluated again", cb.Name)
+			return nil
+		}
+		if changed {
+			cb.log(logger)
+		}
+		if cb.Config.Action == CircuitBreakerAbort && cb.IsOpen() {
+			return cb
+		}
+	}
+}
+
+// ShouldSkipGroup returns whether an open circuit breaker skips the group
+// with the given name.
+func (cbs *CircuitBreakers) ShouldSkipGroup(name string) bool {
+	for _, cb := range cbs.list() {
+		if cb.Config.Action != CircuitBreakerSkipGroup || !cb.IsOpen() {
+			continue
+		}
+		for _, group := range cb.Config.Groups {
+			if group == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SlowDownDelay returns for how long the iterations of the scenario should be
+// delayed by the open circuit breakers.
+func (cbs *CircuitBreakers) SlowDownDelay(scenario string) time.Duration {
+	var delay time.Duration
+	for _, cb := range cbs.list() {
+		if cb.Config.Action != CircuitBreakerSlowDown || !cb.IsOpen() || cb.Config.Delay <= delay {
+			continue
+		}
+		if len(cb.Config.Scenarios) == 0 {
+			delay = cb.Config.Delay
+			continue
+		}
+		for _, s := range cb.Config.Scenarios {
+			if s == scenario {
+				delay = cb.Config.Delay
+				break
+			}
+		}
+	}
+	return delay
+}
@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMetricsQuerier map[string]float64
+
+func (q fakeMetricsQuerier) QueryMetric(name, aggregation string, _ time.Duration) (float64, error) {
+	return q[name+" "+aggregation], nil
+}
+
+func (q fakeMetricsQuerier) QueryMetricRange(
+	_ context.Context, name, aggregation string, _, _ time.Time,
+) (float64, error) {
+	return q.QueryMetric(name, aggregation, 0)
+}
+
+func (q fakeMetricsQuerier) CountSamples(name string, _ time.Duration) (uint64, error) {
+	if name == "unknown" {
+		return 0, errors.New("metric 'unknown' does not exist in the script")
+	}
+	if samples, ok := q[name+" samples"]; ok {
+		return uint64(samples), nil
+	}
+	return DefaultCircuitBreakerMinSamples, nil
+}
+
+func evaluateCircuitBreakers(t *testing.T, cbs *CircuitBreakers, querier MetricsQuerier) {
+	t.Helper()
+	for _, cb := range cbs.list() {
+		_, err := cb.evaluate(querier)
+		require.NoError(t, err)
+	}
+}
+
+func TestCircuitBreakers(t *testing.T) {
+	t.Parallel()
+
+	cbs := NewCircuitBreakers()
+	skip, err := cbs.Add("skip", CircuitBreakerConfig{
+		Metric: "http_req_failed", Threshold: "rate<0.1", Action: CircuitBreakerSkipGroup, Groups: []string{"login"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCircuitBreakerInterval, skip.Config.Interval)
+	assert.Equal(t, uint64(DefaultCircuitBreakerMinSamples), skip.Config.MinSamples)
+	_, err = cbs.Add("slow", CircuitBreakerConfig{
+		Metric: "http_req_duration", Threshold: "p(95)<500", Interval: 10 * time.Second, MinSamples: 100,
+		Action: CircuitBreakerSlowDown, Delay: time.Second, Scenarios: []string{"main"},
+	})
+	require.NoError(t, err)
+	same, err := cbs.Add("skip", CircuitBreakerConfig{Metric: "other", Threshold: "count<1", Action: CircuitBreakerAbort})
+	require.NoError(t, err)
+	assert.Same(t, skip, same)
+
+	// the slow circuit breaker doesn't have enough samples yet
+	querier := fakeMetricsQuerier{
+		"http_req_failed rate": 0.5, "http_req_duration p(95)": 1000, "http_req_duration samples": 99,
+	}
+	evaluateCircuitBreakers(t, cbs, querier)
+	assert.True(t, cbs.ShouldSkipGroup("login"))
+	assert.False(t, cbs.ShouldSkipGroup("checkout"))
+	assert.Zero(t, cbs.SlowDownDelay("main"))
+
+	querier["http_req_duration samples"] = 100
+	evaluateCircuitBreakers(t, cbs, querier)
+	assert.Equal(t, time.Second, cbs.SlowDownDelay("main"))
+	assert.Zero(t, cbs.SlowDownDelay("other"))
+
+	// the circuit breakers are closed again when the threshold passes or
+	// there are too few samples to evaluate it
+	querier["http_req_failed rate"], querier["http_req_duration samples"] = 0, 0
+	evaluateCircuitBreakers(t, cbs, querier)
+	assert.False(t, cbs.ShouldSkipGroup("login"))
+	assert.Zero(t, cbs.SlowDownDelay("main"))
+
+	var nilCBs *CircuitBreakers
+	assert.False(t, nilCBs.ShouldSkipGroup("login"))
+	assert.Zero(t, nilCBs.SlowDownDelay("main"))
+}
+
+func TestCircuitBreakersRun(t *testing.T) {
+	t.Parallel()
+
+	logger, hook := logtest.NewNullLogger()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var nilCBs *CircuitBreakers
+	abort, err := nilCBs.Run(ctx, nil, logger)
+	assert.NoError(t, err)
+	assert.Nil(t, abort)
+
+	cbs := NewCircuitBreakers()
+	_, err = cbs.Add("abort", CircuitBreakerConfig{
+		Metric: "iterations", Threshold: "count<10", Interval: time.Millisecond, Action: CircuitBreakerAbort,
+	})
+	require.NoError(t, err)
+	_, err = cbs.Run(ctx, nil, logger)
+	assert.ErrorContains(t, err, "the metrics aren't aggregated locally")
+
+	// the circuit breakers with unknown metrics are skipped right away
+	_, err = cbs.Add("unknown", CircuitBreakerConfig{
+		Metric: "unknown", Threshold: "count<10", Interval: time.Hour, Action: CircuitBreakerAbort,
+	})
+	require.NoError(t, err)
+	abort, err = cbs.Run(ctx, fakeMetricsQuerier{"iterations count": 10}, logger)
+	require.NoError(t, err)
+	require.NotNil(t, abort)
+	assert.Equal(t, "abort", abort.Name)
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Contains(t, messages, "Error evaluating circuit breaker 'unknown', it won't be evaluated")
+
+	// nothing is returned when the context is done before the abort
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	abort, err = cbs.Run(ctx, fakeMetricsQuerier{"iterations count": 5}, logger)
+	require.NoError(t, err)
+	assert.Nil(t, abort)
+}
+
+func TestCircuitBreakerConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		config CircuitBreakerConfig
+		err    string
+	}{
+		{CircuitBreakerConfig{Threshold: "rate<0.1", Action: CircuitBreakerAbort}, "a metric is required"},
+		{CircuitBreakerConfig{Metric: "m", Action: CircuitBreakerAbort}, "a threshold is required"},
+		{CircuitBreakerConfig{Metric: "m", Threshold: "rate", Action: CircuitBreakerAbort}, "failed parsing threshold expression"},
+		{CircuitBreakerConfig{Metric: "m", Threshold: "rate<0.1", Action: "stop"}, "unknown action 'stop'"},
+		{CircuitBreakerConfig{Metric: "m", Threshold: "rate<0.1", Action: CircuitBreakerSkipGroup}, "requires at least one group"},
+		{CircuitBreakerConfig{Metric: "m", Threshold: "rate<0.1", Action: CircuitBreakerSlowDown}, "requires a delay"},
+		{
+			CircuitBreakerConfig{Metric: "m", Threshold: "rate<0.1", Action: CircuitBreakerAbort, Window: -time.Second},
+			"can't be negative",
+		},
+	}
+	for _, tc := range testCases {
+		assert.ErrorContains(t, tc.config.Validate(), tc.err)
+	}
+}
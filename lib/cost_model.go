@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"fmt"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// CostModel contains the prices which are used for estimating the cost of a
+// test run in real time, e.g. when testing metered third-party APIs. The
+// prices are in an arbitrary currency, they are only multiplied and summed.
+type CostModel struct {
+	// Price of a single HTTP or gRPC request.
+	PerRequest null.Float `json:"perRequest"`
+	// Price of a GB (10^9 bytes) of egress from the tested system, i.e. the
+	// data_received by k6.
+	PerGBEgress null.Float `json:"perGBEgress"`
+	// Price of a single VU running for a minute.
+	PerVUMinute null.Float `json:"perVUMinute"`
+}
+
+// IsEnabled returns true if at least one price is specified.
+func (c CostModel) IsEnabled() bool {
+	return c.PerRequest.Valid || c.PerGBEgress.Valid || c.PerVUMinute.Valid
+}
+
+// Validate makes sure that there are no negative prices.
+func (c CostModel) Validate() error {
+	for name, price := range map[string]null.Float{
+		"perRequest":  c.PerRequest,
+		"perGBEgress": c.PerGBEgress,
+		"perVUMinute": c.PerVUMinute,
+	} {
+		if price.Valid && price.Float64 < 0 {
+			return fmt.Errorf("the cost option %s can't be negative, but it's %g", name, price.Float64)
+		}
+	}
+	return nil
+}
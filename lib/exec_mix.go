@@ -0,0 +1,137 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// ExecMix is a thread-safe weighted set of exec functions, from which a
+// single executor can randomly pick the function for each iteration. The
+// weights can be changed while the test is running, but the set of function
+// names is fixed when the ExecMix is created, since all of them have to be
+// validated against the script exports beforehand.
+type ExecMix struct {
+	mx      sync.RWMutex
+	names   []string
+	weights []float64
+	total   float64
+}
+
+// ValidateExecMixWeights checks that all of the weights are non-negative and
+// that at least one of them is positive.
+func ValidateExecMixWeights(weights map[string]float64) error {
+	if len(weights) == 0 {
+		return errors.New("the exec mix should contain at least one function")
+	}
+	var total float64
+	for name, weight := range weights {
+		if name == "" {
+			return errors.New("the exec mix function names can't be empty")
+		}
+		if weight < 0 {
+			return fmt.Errorf("the weight of the exec mix function '%s' can't be negative", name)
+		}
+		total += weight
+	}
+	if total <= 0 {
+		return errors.New("at least one of the exec mix weights should be positive")
+	}
+	return nil
+}
+
+// NewExecMix returns a new ExecMix with the given function weights.
+func NewExecMix(weights map[string]float64) (*ExecMix, error) {
+	if err := ValidateExecMixWeights(weights); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		names = append(names, name)
+	}
+	sort.Strings(names) // for deterministic picks with the same random value
+
+	em := &ExecMix{names: names, weights: make([]float64, len(names))}
+	for i, name := range names {
+		em.weights[i] = weights[name]
+		em.total += weights[name]
+	}
+	return em, nil
+}
+
+// Names returns the sorted names of all of the functions in the mix.
+func (em *ExecMix) Names() []string {
+	result := make([]string, len(em.names))
+	copy(result, em.names)
+	return result
+}
+
+// Pick returns the function name for the next iteration.
+func (em *ExecMix) Pick() string {
+	return em.pick(rand.Float64()) //nolint:gosec
+}
+
+// pick returns the function name for the given random value in [0, 1).
+func (em *ExecMix) pick(r float64) string {
+	em.mx.RLock()
+	defer em.mx.RUnlock()
+
+	target := r * em.total
+	for i, weight := range em.weights {
+		if target < weight {
+			return em.names[i]
+		}
+		target -= weight
+	}
+	// Only possible because of floating point rounding, so return the last
+	// function with a non-zero weight
+	for i := len(em.weights) - 1; i >= 0; i-- {
+		if em.weights[i] > 0 {
+			return em.names[i]
+		}
+	}
+	return em.names[len(em.names)-1]
+}
+
+// GetWeights returns a copy of the current function weights.
+func (em *ExecMix) GetWeights() map[string]float64 {
+	em.mx.RLock()
+	defer em.mx.RUnlock()
+
+	result := make(map[string]float64, len(em.names))
+	for i, name := range em.names {
+		result[name] = em.weights[i]
+	}
+	return result
+}
+
+// SetWeights updates the weights of the specified functions, leaving the rest
+// of them unchanged. All of the names should already be part of the mix and
+// the resulting weights should still be valid, otherwise nothing is changed.
+func (em *ExecMix) SetWeights(weights map[string]float64) error {
+	em.mx.Lock()
+	defer em.mx.Unlock()
+
+	updated := make(map[string]float64, len(em.names))
+	for i, name := range em.names {
+		updated[name] = em.weights[i]
+	}
+	for name, weight := range weights {
+		if _, ok := updated[name]; !ok {
+			return fmt.Errorf("the function '%s' is not part of the exec mix", name)
+		}
+		updated[name] = weight
+	}
+	if err := ValidateExecMixWeights(updated); err != nil {
+		return err
+	}
+
+	em.total = 0
+	for i, name := range em.names {
+		em.weights[i] = updated[name]
+		em.total += updated[name]
+	}
+	return nil
+}
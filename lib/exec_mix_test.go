@@ -0,0 +1,44 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecMix(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewExecMix(nil)
+	require.Error(t, err)
+	_, err = NewExecMix(map[string]float64{"a": 0, "b": 0})
+	require.Error(t, err)
+	_, err = NewExecMix(map[string]float64{"a": 1, "b": -1})
+	require.Error(t, err)
+
+	mix, err := NewExecMix(map[string]float64{"browse": 6, "buy": 3, "search": 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"browse", "buy", "search"}, mix.Names())
+
+	assert.Equal(t, "browse", mix.pick(0))
+	assert.Equal(t, "browse", mix.pick(0.59))
+	assert.Equal(t, "buy", mix.pick(0.6))
+	assert.Equal(t, "buy", mix.pick(0.89))
+	assert.Equal(t, "search", mix.pick(0.9))
+	assert.Equal(t, "search", mix.pick(0.9999))
+
+	require.NoError(t, mix.SetWeights(map[string]float64{"browse": 0, "search": 7}))
+	assert.Equal(t, map[string]float64{"browse": 0, "buy": 3, "search": 7}, mix.GetWeights())
+	assert.Equal(t, "buy", mix.pick(0))
+	assert.Equal(t, "search", mix.pick(0.3))
+
+	assert.Error(t, mix.SetWeights(map[string]float64{"unknown": 1}))
+	assert.Error(t, mix.SetWeights(map[string]float64{"buy": 0, "search": 0}))
+	assert.Error(t, mix.SetWeights(map[string]float64{"buy": -1}))
+	assert.Equal(t, map[string]float64{"browse": 0, "buy": 3, "search": 7}, mix.GetWeights())
+
+	for i := 0; i < 100; i++ {
+		assert.NotEqual(t, "browse", mix.Pick())
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -102,6 +103,31 @@ const MaxTimeToWaitForPlannedVU = 400 * time.Millisecond
 // MaxTimeToWaitForPlannedVU before we actually return an error.
 const MaxRetriesGetPlannedVU = 5
 
+// MetricsQuerier returns the current aggregated values of metrics or
+// sub-metrics, like the ones thresholds are evaluated against. The name can
+// contain a sub-metric definition, e.g. `http_req_duration{scenario:main}`,
+// and the aggregation is one of the threshold aggregation methods, e.g.
+// `p(95)`. If window is not zero, only the samples from that last period of
+// time are aggregated. CountSamples similarly returns the number of samples
+// that would be aggregated. QueryMetricRange aggregates only the samples from
+// the from (inclusive) to the to (exclusive) time, after waiting for all of
+// the samples until then to be aggregated, so it's only accurate if the
+// metric is already queried with a window that covers that period.
+//
+// A sub-metric that isn't defined is created by its first query, which then
+// returns an error wrapping ErrNewQuerySubmetric, since the sub-metric
+// doesn't have any of the earlier samples of its parent metric.
+type MetricsQuerier interface {
+	QueryMetric(name, aggregation string, window time.Duration) (float64, error)
+	QueryMetricRange(ctx context.Context, name, aggregation string, from, to time.Time) (float64, error)
+	CountSamples(name string, window time.Duration) (uint64, error)
+}
+
+// ErrNewQuerySubmetric is wrapped by the errors of the MetricsQuerier for the
+// queries which created the queried sub-metric. The queries made only to set
+// up the sub-metrics before the test starts can ignore it.
+var ErrNewQuerySubmetric = errors.New("the sub-metric was created by the query")
+
 // ExecutionStatus is similar to RunStatus, but more fine grained and concerns
 // only local execution.
 //go:generate enumer -type=ExecutionStatus -trimprefix ExecutionStatus -output execution_status_gen.go
@@ -185,6 +211,10 @@ type ExecutionState struct {
 	vuIDSegIndexMx *sync.Mutex
 	vuIDSegIndex   *SegmentedIndex
 
+	// The activities of all initialized VUs that can report them, by VU ID.
+	vuActivities   map[uint64]*VUActivity
+	vuActivitiesMx sync.RWMutex
+
 	// TODO: add something similar, but for iterations? Currently, there isn't
 	// a straightforward way to get a unique sequential identifier per iteration
 	// in the context of a single k6 instance. Combining __VU and __ITER gives us
@@ -208,6 +238,10 @@ type ExecutionState struct {
 	// initializing unplanned VUs.
 	initVUFunc InitVUFunc
 
+	// Injected by the engine when the metrics are aggregated locally, used
+	// for answering the metric queries from the scripts.
+	metricsQuerier MetricsQuerier
+
 	// The number of VUs that are currently executing the test script. This also
 	// includes any VUs that are in the process of gracefully winding down,
 	// either at the end of the test, or when VUs are ramping down. It should
@@ -295,6 +329,7 @@ func NewExecutionState(
 		executionStatus:            new(uint32),
 		vuIDSegIndexMx:             new(sync.Mutex),
 		vuIDSegIndex:               segIdx,
+		vuActivities:               make(map[uint64]*VUActivity),
 		initializedVUs:             new(int64),
 		uninitializedUnplannedVUs:  &maxUnplannedUninitializedVUs,
 		activeVUs:                  new(int64),
@@ -571,6 +606,18 @@ func (es *ExecutionState) SetInitVUFunc(initVUFunc InitVUFunc) {
 	es.initVUFunc = initVUFunc
 }
 
+// SetMetricsQuerier is called by the engine before the test starts, if the
+// metric samples are aggregated locally.
+func (es *ExecutionState) SetMetricsQuerier(querier MetricsQuerier) {
+	es.metricsQuerier = querier
+}
+
+// GetMetricsQuerier returns the querier for the aggregated metrics, or nil if
+// they aren't available.
+func (es *ExecutionState) GetMetricsQuerier() MetricsQuerier {
+	return es.metricsQuerier
+}
+
 // GetUnplannedVU checks if any unplanned VUs remain to be initialized, and if
 // they do, it initializes one and returns it. If all unplanned VUs have already
 // been initialized, it returns one from the global vus buffer, but doesn't
@@ -604,6 +651,7 @@ func (es *ExecutionState) InitializeNewVU(ctx context.Context, logger *logrus.En
 		return nil, err
 	}
 	es.ModInitializedVUsCount(+1)
+	es.trackVUActivity(newVU)
 	return newVU, err
 }
 
@@ -612,6 +660,31 @@ func (es *ExecutionState) InitializeNewVU(ctx context.Context, logger *logrus.En
 func (es *ExecutionState) AddInitializedVU(vu InitializedVU) {
 	es.vus <- vu
 	es.ModInitializedVUsCount(+1)
+	es.trackVUActivity(vu)
+}
+
+func (es *ExecutionState) trackVUActivity(vu InitializedVU) {
+	reporter, ok := vu.(VUActivityReporter)
+	if !ok || reporter.GetActivity() == nil {
+		return
+	}
+	es.vuActivitiesMx.Lock()
+	es.vuActivities[vu.GetID()] = reporter.GetActivity()
+	es.vuActivitiesMx.Unlock()
+}
+
+// GetVUActivities returns what all of the initialized VUs are currently
+// doing, ordered by their IDs.
+func (es *ExecutionState) GetVUActivities() []VUActivitySnapshot {
+	es.vuActivitiesMx.RLock()
+	result := make([]VUActivitySnapshot, 0, len(es.vuActivities))
+	for _, activity := range es.vuActivities {
+		result = append(result, activity.Snapshot())
+	}
+	es.vuActivitiesMx.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
 }
 
 // ReturnVU is a helper function that puts VUs back into the buffer and
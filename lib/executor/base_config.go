@@ -23,11 +23,13 @@ package executor
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"gopkg.in/guregu/null.v3"
 
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/consts"
 	"go.k6.io/k6/lib/types"
 )
@@ -47,9 +49,14 @@ type BaseConfig struct {
 	StartTime    types.NullDuration `json:"startTime"`
 	GracefulStop types.NullDuration `json:"gracefulStop"`
 	Env          map[string]string  `json:"env"`
-	Exec         null.String        `json:"exec"` // function name, externally validated
+	Exec         null.String        `json:"exec"`    // function name, externally validated
+	ExecMix      map[string]float64 `json:"execMix"` // function name weights, externally validated
 	Tags         map[string]string  `json:"tags"`
 
+	// The default timeout of the requests in the scenario, the protocol
+	// modules use it unless it's overridden by a group or the request.
+	RequestTimeout types.NullDuration `json:"requestTimeout"`
+
 	// TODO: future extensions like distribution, others?
 }
 
@@ -75,6 +82,16 @@ func (bc BaseConfig) Validate() (errors []error) {
 	if bc.Exec.Valid && bc.Exec.String == "" {
 		errors = append(errors, fmt.Errorf("exec value cannot be empty"))
 	}
+	if len(bc.ExecMix) > 0 {
+		if bc.Exec.Valid {
+			errors = append(errors, fmt.Errorf("exec and execMix can't be specified at the same time"))
+		}
+		if err := lib.ValidateExecMixWeights(bc.ExecMix); err != nil {
+			errors = append(errors, err)
+		}
+	} else if bc.ExecMix != nil {
+		errors = append(errors, fmt.Errorf("execMix should contain at least one function"))
+	}
 	if bc.Type == "" {
 		errors = append(errors, fmt.Errorf("missing or empty type field"))
 	}
@@ -85,6 +102,9 @@ func (bc BaseConfig) Validate() (errors []error) {
 	if bc.GracefulStop.Duration < 0 {
 		errors = append(errors, fmt.Errorf("the gracefulStop timeout can't be negative"))
 	}
+	if bc.RequestTimeout.Valid && bc.RequestTimeout.Duration <= 0 {
+		errors = append(errors, fmt.Errorf("the requestTimeout should be more than 0"))
+	}
 	return errors
 }
 
@@ -129,6 +149,12 @@ func (bc BaseConfig) GetExec() string {
 	return exec
 }
 
+// GetExecMix returns the weights of the exec functions that should be
+// randomly picked for each iteration, if configured.
+func (bc BaseConfig) GetExecMix() map[string]float64 {
+	return bc.ExecMix
+}
+
 // GetTags returns any custom tags configured for the executor.
 func (bc BaseConfig) GetTags() map[string]string {
 	return bc.Tags
@@ -145,6 +171,18 @@ func (bc BaseConfig) getBaseInfo(facts ...string) string {
 	if bc.Exec.Valid {
 		facts = append(facts, fmt.Sprintf("exec: %s", bc.Exec.String))
 	}
+	if len(bc.ExecMix) > 0 {
+		names := make([]string, 0, len(bc.ExecMix))
+		for name := range bc.ExecMix {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		mix := make([]string, len(names))
+		for i, name := range names {
+			mix[i] = fmt.Sprintf("%s=%g", name, bc.ExecMix[name])
+		}
+		facts = append(facts, fmt.Sprintf("execMix: %s", strings.Join(mix, " ")))
+	}
 	if bc.StartTime.Duration > 0 {
 		facts = append(facts, fmt.Sprintf("startTime: %s", bc.StartTime.Duration))
 	}
@@ -41,6 +41,7 @@ type BaseExecutor struct {
 	executionState *lib.ExecutionState
 	iterSegIndexMx *sync.Mutex
 	iterSegIndex   *lib.SegmentedIndex
+	execMix        *lib.ExecMix
 	logger         *logrus.Entry
 	progress       *pb.ProgressBar
 }
@@ -48,12 +49,18 @@ type BaseExecutor struct {
 // NewBaseExecutor returns an initialized BaseExecutor
 func NewBaseExecutor(config lib.ExecutorConfig, es *lib.ExecutionState, logger *logrus.Entry) *BaseExecutor {
 	segIdx := lib.NewSegmentedIndex(es.ExecutionTuple)
+	var execMix *lib.ExecMix
+	if weights := config.GetExecMix(); len(weights) > 0 {
+		// The weights were already checked by Validate(), so this can't fail
+		execMix, _ = lib.NewExecMix(weights)
+	}
 	return &BaseExecutor{
 		config:         config,
 		executionState: es,
 		logger:         logger,
 		iterSegIndexMx: new(sync.Mutex),
 		iterSegIndex:   segIdx,
+		execMix:        execMix,
 		progress: pb.New(
 			pb.WithLeft(config.GetName),
 			pb.WithLogger(logger),
@@ -75,6 +82,12 @@ func (bs *BaseExecutor) Init(_ context.Context) error {
 	return nil
 }
 
+// GetExecMix returns the weighted mix of exec functions from which the
+// executor picks the function for each iteration, or nil if there isn't one.
+func (bs *BaseExecutor) GetExecMix() *lib.ExecMix {
+	return bs.execMix
+}
+
 // GetConfig returns the configuration with which this executor was launched.
 func (bs *BaseExecutor) GetConfig() lib.ExecutorConfig {
 	return bs.config
@@ -269,10 +269,11 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	go trackProgress(parentCtx, maxDurationCtx, regDurationCtx, &car, progressFn)
 
 	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
-		Name:       car.config.Name,
-		Executor:   car.config.Type,
-		StartTime:  startTime,
-		ProgressFn: progressFn,
+		Name:           car.config.Name,
+		Executor:       car.config.Type,
+		RequestTimeout: car.config.RequestTimeout,
+		StartTime:      startTime,
+		ProgressFn:     progressFn,
 	})
 
 	returnVU := func(u lib.InitializedVU) {
@@ -284,7 +285,7 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	activateVU := func(initVU lib.InitializedVU) lib.ActiveVU {
 		activeVUsWg.Add(1)
 		activeVU := initVU.Activate(getVUActivationParams(
-			maxDurationCtx, car.config.BaseConfig, returnVU,
+			maxDurationCtx, car.config.BaseConfig, car.execMix, returnVU,
 			car.nextIterationCounters,
 		))
 		car.executionState.ModCurrentlyActiveVUsCount(+1)
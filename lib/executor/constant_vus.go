@@ -177,10 +177,11 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- metrics.SampleC
 	runIteration := getIterationRunner(clv.executionState, clv.logger)
 
 	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
-		Name:       clv.config.Name,
-		Executor:   clv.config.Type,
-		StartTime:  startTime,
-		ProgressFn: progressFn,
+		Name:           clv.config.Name,
+		Executor:       clv.config.Type,
+		RequestTimeout: clv.config.RequestTimeout,
+		StartTime:      startTime,
+		ProgressFn:     progressFn,
 	})
 
 	returnVU := func(u lib.InitializedVU) {
@@ -193,7 +194,7 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- metrics.SampleC
 		defer cancel()
 
 		activeVU := initVU.Activate(
-			getVUActivationParams(ctx, clv.config.BaseConfig, returnVU,
+			getVUActivationParams(ctx, clv.config.BaseConfig, clv.execMix, returnVU,
 				clv.nextIterationCounters))
 
 		for {
@@ -363,7 +363,7 @@ func (rs *externallyControlledRunState) newManualVUHandle(
 	return &manualVUHandle{
 		vuHandle: newStoppedVUHandle(ctx, getVU, returnVU,
 			rs.executor.nextIterationCounters,
-			&rs.executor.config.BaseConfig, logger),
+			&rs.executor.config.BaseConfig, rs.executor.execMix, logger),
 		initVU:   initVU,
 		wg:       &wg,
 		cancelVU: cancel,
@@ -523,9 +523,10 @@ func (mex *ExternallyControlled) Run(parentCtx context.Context, out chan<- metri
 	startMaxVUs := mex.executionState.ExecutionTuple.ScaleInt64(mex.config.MaxVUs.Int64)
 
 	ss := &lib.ScenarioState{
-		Name:      mex.config.Name,
-		Executor:  mex.config.Type,
-		StartTime: time.Now(),
+		Name:           mex.config.Name,
+		Executor:       mex.config.Type,
+		RequestTimeout: mex.config.RequestTimeout,
+		StartTime:      time.Now(),
 	}
 	ctx = lib.WithScenarioState(ctx, ss)
 
@@ -98,9 +98,9 @@ func Context(ctx context.Context) context.Context {
 	return context.WithValue(ctx, cancelKey{}, &cancelExec{cancel: cancel})
 }
 
-// cancelExecutorContext cancels executor context found in ctx, ctx can be a
+// CancelExecutorContext cancels executor context found in ctx, ctx can be a
 // child of a context that was created with Context function.
-func cancelExecutorContext(ctx context.Context, err error) {
+func CancelExecutorContext(ctx context.Context, err error) {
 	if x := ctx.Value(cancelKey{}); x != nil {
 		if v, ok := x.(*cancelExec); ok {
 			v.reason = err
@@ -126,7 +126,7 @@ func CancelReason(ctx context.Context) error {
 func handleInterrupt(ctx context.Context, err error) bool {
 	if err != nil {
 		if common.IsInterruptError(err) {
-			cancelExecutorContext(ctx, err)
+			CancelExecutorContext(ctx, err)
 			return true
 		}
 	}
@@ -286,10 +286,10 @@ func getArrivalRatePerSec(scaledArrivalRate *big.Rat) *big.Rat {
 
 // TODO: Refactor this, maybe move all scenario things to an embedded struct?
 func getVUActivationParams(
-	ctx context.Context, conf BaseConfig, deactivateCallback func(lib.InitializedVU),
+	ctx context.Context, conf BaseConfig, execMix *lib.ExecMix, deactivateCallback func(lib.InitializedVU),
 	nextIterationCounters func() (uint64, uint64),
 ) *lib.VUActivationParams {
-	return &lib.VUActivationParams{
+	params := &lib.VUActivationParams{
 		RunContext:               ctx,
 		Scenario:                 conf.Name,
 		Exec:                     conf.GetExec(),
@@ -298,4 +298,8 @@ func getVUActivationParams(
 		DeactivateCallback:       deactivateCallback,
 		GetNextIterationCounters: nextIterationCounters,
 	}
+	if execMix != nil {
+		params.GetNextExec = execMix.Pick
+	}
+	return params
 }
@@ -0,0 +1,319 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const journeyType = "journey"
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		journeyType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewJourneyConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			return config, err
+		},
+	)
+}
+
+// JourneyState describes a single step of a user journey. The name of the
+// state is the name of the exported function that is executed in it, and the
+// transitions contain the probabilities of moving to every other state after
+// that. The remainder up to 1 is the probability of ending the journey.
+type JourneyState struct {
+	Transitions map[string]float64 `json:"transitions"`
+	ThinkTime   types.NullDuration `json:"thinkTime"`
+}
+
+// JourneyConfig stores the number of VUs, the duration and the Markov chain
+// which every VU follows, starting from the start state, for the whole
+// duration of the executor.
+type JourneyConfig struct {
+	BaseConfig
+	VUs      null.Int                `json:"vus"`
+	Duration types.NullDuration      `json:"duration"`
+	Start    null.String             `json:"start"`
+	States   map[string]JourneyState `json:"states"`
+}
+
+// NewJourneyConfig returns a JourneyConfig with default values
+func NewJourneyConfig(name string) JourneyConfig {
+	return JourneyConfig{
+		BaseConfig: NewBaseConfig(name, journeyType),
+		VUs:        null.NewInt(1, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &JourneyConfig{}
+
+// GetVUs returns the scaled VUs for the executor.
+func (jc JourneyConfig) GetVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(jc.VUs.Int64)
+}
+
+// GetStates returns the sorted names of all journey states, i.e. all of the
+// functions which the executor could run.
+func (jc JourneyConfig) GetStates() []string {
+	names := make([]string, 0, len(jc.States))
+	for name := range jc.States {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (jc JourneyConfig) GetDescription(et *lib.ExecutionTuple) string {
+	return fmt.Sprintf("%d VUs following a %d-state journey from %s for %s%s",
+		jc.GetVUs(et), len(jc.States), jc.Start.String, jc.Duration.Duration, jc.getBaseInfo())
+}
+
+// Validate makes sure all options are configured and valid
+func (jc JourneyConfig) Validate() []error {
+	errors := jc.BaseConfig.Validate()
+	if jc.Exec.Valid || len(jc.ExecMix) > 0 {
+		errors = append(errors, fmt.Errorf("exec and execMix can't be used, the journey states specify the functions"))
+	}
+	if jc.VUs.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the number of VUs must be more than 0"))
+	}
+
+	if !jc.Duration.Valid {
+		errors = append(errors, fmt.Errorf("the duration is unspecified"))
+	} else if jc.Duration.TimeDuration() < minDuration {
+		errors = append(errors, fmt.Errorf(
+			"the duration must be at least %s, but is %s", minDuration, jc.Duration,
+		))
+	}
+
+	if len(jc.States) == 0 {
+		errors = append(errors, fmt.Errorf("the journey should have at least one state"))
+	}
+	if !jc.Start.Valid {
+		errors = append(errors, fmt.Errorf("the start state is unspecified"))
+	} else if _, ok := jc.States[jc.Start.String]; !ok {
+		errors = append(errors, fmt.Errorf("the start state '%s' is not one of the journey states", jc.Start.String))
+	}
+
+	for _, name := range jc.GetStates() {
+		state := jc.States[name]
+		if state.ThinkTime.Duration < 0 {
+			errors = append(errors, fmt.Errorf("the think time of state '%s' can't be negative", name))
+		}
+		var total float64
+		for to, probability := range state.Transitions {
+			if _, ok := jc.States[to]; !ok {
+				errors = append(errors, fmt.Errorf("state '%s' has a transition to the unknown state '%s'", name, to))
+			}
+			if probability < 0 {
+				errors = append(errors, fmt.Errorf(
+					"the probability of the transition from '%s' to '%s' can't be negative", name, to,
+				))
+			}
+			total += probability
+		}
+		// Allow for some floating point imprecision, e.g. 0.7 + 0.2 + 0.1
+		if total > 1+1e-9 {
+			errors = append(errors, fmt.Errorf(
+				"the transition probabilities of state '%s' add up to %g, which is more than 1", name, total,
+			))
+		}
+	}
+
+	return errors
+}
+
+// nextState returns the state that should follow the given one for the given
+// random value in [0, 1), or false if the journey should end.
+func (jc JourneyConfig) nextState(current string, r float64) (string, bool) {
+	transitions := jc.States[current].Transitions
+	targets := make([]string, 0, len(transitions))
+	for to := range transitions {
+		targets = append(targets, to)
+	}
+	sort.Strings(targets) // for deterministic results with the same random value
+
+	for _, to := range targets {
+		if r < transitions[to] {
+			return to, true
+		}
+		r -= transitions[to]
+	}
+	return "", false
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration (disregarding any startTime), including the
+// maximum waiting time for any iterations to gracefully stop.
+func (jc JourneyConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return []lib.ExecutionStep{
+		{
+			TimeOffset: 0,
+			PlannedVUs: uint64(jc.GetVUs(et)),
+		},
+		{
+			TimeOffset: jc.Duration.TimeDuration() + jc.GracefulStop.TimeDuration(),
+			PlannedVUs: 0,
+		},
+	}
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (jc JourneyConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return jc.GetVUs(et) > 0
+}
+
+// NewExecutor creates a new Journey executor
+func (jc JourneyConfig) NewExecutor(es *lib.ExecutionState, logger *logrus.Entry) (lib.Executor, error) {
+	return Journey{
+		BaseExecutor: NewBaseExecutor(jc, es, logger),
+		config:       jc,
+	}, nil
+}
+
+// Journey maintains a constant number of looping VUs for the specified
+// duration, where every VU iteration executes the function of the current
+// journey state, and the next state is randomly picked according to the
+// transition probabilities.
+type Journey struct {
+	*BaseExecutor
+	config JourneyConfig
+}
+
+// Make sure we implement the lib.Executor interface.
+var _ lib.Executor = &Journey{}
+
+// Run constantly walks through the configured journey on a fixed number of VUs
+// for the specified duration.
+func (j Journey) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) (err error) {
+	numVUs := j.config.GetVUs(j.executionState.ExecutionTuple)
+	duration := j.config.Duration.TimeDuration()
+	gracefulStop := j.config.GetGracefulStop()
+
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	defer cancel()
+
+	// Make sure the log and the progress bar have accurate information
+	j.logger.WithFields(logrus.Fields{
+		"vus": numVUs, "duration": duration, "type": j.config.GetType(),
+		"states": strings.Join(j.config.GetStates(), ","),
+	}).Debug("Starting executor run...")
+
+	progressFn := func() (float64, []string) {
+		spent := time.Since(startTime)
+		right := []string{fmt.Sprintf("%d VUs", numVUs)}
+		if spent > duration {
+			right = append(right, duration.String())
+			return 1, right
+		}
+		right = append(right, fmt.Sprintf("%s/%s",
+			pb.GetFixedLengthDuration(spent, duration), duration))
+		return float64(spent) / float64(duration), right
+	}
+	j.progress.Modify(pb.WithProgress(progressFn))
+	go trackProgress(parentCtx, maxDurationCtx, regDurationCtx, j, progressFn)
+
+	// Actually schedule the VUs and iterations...
+	activeVUs := &sync.WaitGroup{}
+	defer activeVUs.Wait()
+
+	regDurationDone := regDurationCtx.Done()
+	runIteration := getIterationRunner(j.executionState, j.logger)
+	builtinMetrics := j.executionState.BuiltinMetrics
+
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:           j.config.Name,
+		Executor:       j.config.Type,
+		RequestTimeout: j.config.RequestTimeout,
+		StartTime:      startTime,
+		ProgressFn:     progressFn,
+	})
+
+	returnVU := func(u lib.InitializedVU) {
+		j.executionState.ReturnVU(u, true)
+		activeVUs.Done()
+	}
+
+	handleVU := func(initVU lib.InitializedVU) {
+		ctx, cancel := context.WithCancel(maxDurationCtx)
+		defer cancel()
+
+		current := j.config.Start.String
+		journeyStart := time.Now()
+		params := getVUActivationParams(ctx, j.config.BaseConfig, nil, returnVU, j.nextIterationCounters)
+		params.GetNextExec = func() string { return current }
+		activeVU := initVU.Activate(params)
+
+		for {
+			select {
+			case <-regDurationDone:
+				return // don't make more iterations
+			default:
+				// continue looping
+			}
+			if !runIteration(maxDurationCtx, activeVU) {
+				continue // interrupted, the journey state doesn't change
+			}
+
+			now := time.Now()
+			next, ok := j.config.nextState(current, rand.Float64()) //nolint:gosec
+			if ok {
+				tags := j.getMetricTags(nil).CloneTags()
+				tags["from"], tags["to"] = current, next
+				metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
+					Value: 1, Metric: builtinMetrics.JourneyTransitions,
+					Tags: metrics.IntoSampleTags(&tags), Time: now,
+				})
+			} else {
+				metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
+					Value: metrics.D(now.Sub(journeyStart)), Metric: builtinMetrics.JourneyDuration,
+					Tags: j.getMetricTags(nil), Time: now,
+				})
+			}
+
+			if thinkTime := j.config.States[current].ThinkTime.TimeDuration(); thinkTime > 0 {
+				timer := time.NewTimer(thinkTime)
+				select {
+				case <-regDurationDone:
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+
+			if ok {
+				current = next
+			} else {
+				current, journeyStart = j.config.Start.String, time.Now()
+			}
+		}
+	}
+
+	for i := int64(0); i < numVUs; i++ {
+		initVU, err := j.executionState.GetPlannedVU(j.logger, true)
+		if err != nil {
+			cancel()
+			return err
+		}
+		activeVUs.Add(1)
+		go handleVU(initVU)
+	}
+
+	return nil
+}
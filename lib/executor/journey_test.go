@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestJourneyConfig() JourneyConfig {
+	return JourneyConfig{
+		BaseConfig: BaseConfig{
+			Name: "journey", Type: journeyType,
+			GracefulStop: types.NullDurationFrom(500 * time.Millisecond),
+		},
+		VUs:      null.IntFrom(1),
+		Duration: types.NullDurationFrom(1 * time.Second),
+		Start:    null.StringFrom("home"),
+		States: map[string]JourneyState{
+			"home":     {Transitions: map[string]float64{"checkout": 1}, ThinkTime: types.NullDurationFrom(50 * time.Millisecond)},
+			"checkout": {},
+		},
+	}
+}
+
+func TestJourneyConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, getTestJourneyConfig().Validate())
+
+	config := getTestJourneyConfig()
+	config.Start = null.StringFrom("missing")
+	assert.Len(t, config.Validate(), 1)
+
+	config = getTestJourneyConfig()
+	config.States = map[string]JourneyState{
+		"home": {Transitions: map[string]float64{"home": 0.7, "missing": 0.2, "missing2": -0.1}},
+	}
+	assert.Len(t, config.Validate(), 3)
+
+	config = getTestJourneyConfig()
+	config.States["checkout"] = JourneyState{Transitions: map[string]float64{"home": 0.5, "checkout": 0.6}}
+	assert.Len(t, config.Validate(), 1)
+
+	config = getTestJourneyConfig()
+	config.States["checkout"] = JourneyState{
+		Transitions: map[string]float64{"home": 0.7, "checkout": 0.2, "other": 0.1},
+	}
+	config.States["other"] = JourneyState{ThinkTime: types.NullDurationFrom(-time.Second)}
+	assert.Len(t, config.Validate(), 1)
+
+	config = getTestJourneyConfig()
+	config.Exec = null.StringFrom("home")
+	assert.Len(t, config.Validate(), 1)
+}
+
+func TestJourneyConfigNextState(t *testing.T) {
+	t.Parallel()
+
+	config := getTestJourneyConfig()
+	config.States["home"] = JourneyState{Transitions: map[string]float64{"checkout": 0.25, "home": 0.5}}
+
+	for r, expected := range map[float64]string{0: "checkout", 0.24: "checkout", 0.25: "home", 0.74: "home"} {
+		next, ok := config.nextState("home", r)
+		assert.True(t, ok)
+		assert.Equal(t, expected, next, r)
+	}
+	_, ok := config.nextState("home", 0.75)
+	assert.False(t, ok)
+	_, ok = config.nextState("checkout", 0)
+	assert.False(t, ok)
+}
+
+func TestJourneyRun(t *testing.T) {
+	t.Parallel()
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	es := lib.NewExecutionState(lib.Options{}, et, builtinMetrics, 10, 50)
+
+	var iterations int64
+	ctx, cancel, executor, _ := setupExecutor(
+		t, getTestJourneyConfig(), es,
+		simpleRunner(func(ctx context.Context, _ *lib.State) error {
+			atomic.AddInt64(&iterations, 1)
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}),
+	)
+	defer cancel()
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, executor.Run(ctx, engineOut))
+
+	var transitions, journeys int
+	for _, sc := range metrics.GetBufferedSamples(engineOut) {
+		for _, s := range sc.GetSamples() {
+			switch s.Metric {
+			case builtinMetrics.JourneyTransitions:
+				transitions++
+				from, _ := s.Tags.Get("from")
+				to, _ := s.Tags.Get("to")
+				assert.Equal(t, "home", from)
+				assert.Equal(t, "checkout", to)
+			case builtinMetrics.JourneyDuration:
+				journeys++
+				assert.GreaterOrEqual(t, s.Value, float64(250))
+			}
+		}
+	}
+
+	// Every journey is home (100ms + 50ms think time) -> checkout (100ms)
+	assert.GreaterOrEqual(t, transitions, 3)
+	assert.LessOrEqual(t, transitions-journeys, 1)
+	assert.GreaterOrEqual(t, transitions-journeys, 0)
+	assert.Equal(t, int64(transitions+journeys), atomic.LoadInt64(&iterations))
+}
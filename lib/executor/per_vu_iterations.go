@@ -200,10 +200,11 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 	runIteration := getIterationRunner(pvi.executionState, pvi.logger)
 
 	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
-		Name:       pvi.config.Name,
-		Executor:   pvi.config.Type,
-		StartTime:  startTime,
-		ProgressFn: progressFn,
+		Name:           pvi.config.Name,
+		Executor:       pvi.config.Type,
+		RequestTimeout: pvi.config.RequestTimeout,
+		StartTime:      startTime,
+		ProgressFn:     progressFn,
 	})
 
 	returnVU := func(u lib.InitializedVU) {
@@ -219,7 +220,7 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 
 		vuID := initVU.GetID()
 		activeVU := initVU.Activate(
-			getVUActivationParams(ctx, pvi.config.BaseConfig, returnVU,
+			getVUActivationParams(ctx, pvi.config.BaseConfig, pvi.execMix, returnVU,
 				pvi.nextIterationCounters))
 
 		for i := int64(0); i < iterations; i++ {
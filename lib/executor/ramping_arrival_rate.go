@@ -391,10 +391,11 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	go trackProgress(parentCtx, maxDurationCtx, regDurationCtx, &varr, progressFn)
 
 	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
-		Name:       varr.config.Name,
-		Executor:   varr.config.Type,
-		StartTime:  startTime,
-		ProgressFn: progressFn,
+		Name:           varr.config.Name,
+		Executor:       varr.config.Type,
+		RequestTimeout: varr.config.RequestTimeout,
+		StartTime:      startTime,
+		ProgressFn:     progressFn,
 	})
 
 	returnVU := func(u lib.InitializedVU) {
@@ -408,7 +409,7 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 		activeVUsWg.Add(1)
 		activeVU := initVU.Activate(
 			getVUActivationParams(
-				maxDurationCtx, varr.config.BaseConfig, returnVU,
+				maxDurationCtx, varr.config.BaseConfig, varr.execMix, returnVU,
 				varr.nextIterationCounters))
 		varr.executionState.ModCurrentlyActiveVUsCount(+1)
 		atomic.AddUint64(&activeVUsCount, 1)
@@ -541,10 +541,11 @@ func (vlv *RampingVUs) Run(ctx context.Context, _ chan<- metrics.SampleContainer
 
 	progressFn := runState.makeProgressFn(regularDuration)
 	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
-		Name:       vlv.config.Name,
-		Executor:   vlv.config.Type,
-		StartTime:  runState.started,
-		ProgressFn: progressFn,
+		Name:           vlv.config.Name,
+		Executor:       vlv.config.Type,
+		RequestTimeout: vlv.config.RequestTimeout,
+		StartTime:      runState.started,
+		ProgressFn:     progressFn,
 	})
 	vlv.progress.Modify(pb.WithProgress(progressFn))
 	go trackProgress(ctx, maxDurationCtx, regularDurationCtx, vlv, progressFn)
@@ -623,7 +624,7 @@ func (rs *rampingVUsRunState) runLoopsIfPossible(ctx context.Context, cancel fun
 	for i := uint64(0); i < rs.maxVUs; i++ {
 		rs.vuHandles[i] = newStoppedVUHandle(
 			ctx, getVU, returnVU, rs.executor.nextIterationCounters,
-			&rs.executor.config.BaseConfig, rs.executor.logger.WithField("vuNum", i))
+			&rs.executor.config.BaseConfig, rs.executor.execMix, rs.executor.logger.WithField("vuNum", i))
 		go rs.vuHandles[i].runLoopsIfPossible(rs.runIteration)
 	}
 }
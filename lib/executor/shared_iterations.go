@@ -235,10 +235,11 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 	runIteration := getIterationRunner(si.executionState, si.logger)
 
 	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
-		Name:       si.config.Name,
-		Executor:   si.config.Type,
-		StartTime:  startTime,
-		ProgressFn: progressFn,
+		Name:           si.config.Name,
+		Executor:       si.config.Type,
+		RequestTimeout: si.config.RequestTimeout,
+		StartTime:      startTime,
+		ProgressFn:     progressFn,
 	})
 
 	returnVU := func(u lib.InitializedVU) {
@@ -251,7 +252,7 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 		defer cancel()
 
 		activeVU := initVU.Activate(getVUActivationParams(
-			ctx, si.config.BaseConfig, returnVU, si.nextIterationCounters))
+			ctx, si.config.BaseConfig, si.execMix, returnVU, si.nextIterationCounters))
 
 		for {
 			select {
@@ -0,0 +1,617 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const throughputSearchType = "throughput-search"
+
+// defaultThroughputSearchPrecision is the default precision of the search, in
+// iterations per time unit. The default maxPlateaus is derived from the rates
+// and the precision by getMaxPlateaus().
+const defaultThroughputSearchPrecision = 1
+
+// maxDroppedIterationsRatio is the ratio of dropped iterations, above which a
+// plateau fails, regardless of the criteria, since the rate wasn't reached.
+const maxDroppedIterationsRatio = 0.01
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		throughputSearchType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewThroughputSearchConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			return config, err
+		},
+	)
+}
+
+// ThroughputSearchConfig stores the configuration of the throughput-search
+// executor. It runs plateaus with a constant arrival rate, doubling the rate
+// from the start rate until a plateau fails the criteria or the maximum rate
+// is reached, and then it does a binary search between the last rate that
+// passed and the first one that failed, until they are within the precision.
+type ThroughputSearchConfig struct {
+	BaseConfig
+	StartRate       null.Int           `json:"startRate"`
+	MaxRate         null.Int           `json:"maxRate"`
+	TimeUnit        types.NullDuration `json:"timeUnit"`
+	PlateauDuration types.NullDuration `json:"plateauDuration"`
+	Precision       null.Int           `json:"precision"`
+	MaxPlateaus     null.Int           `json:"maxPlateaus"`
+
+	// Criteria are the thresholds, by metric or sub-metric name, that every
+	// plateau has to pass, e.g. {"http_req_duration": ["p(95)<500"]}. They
+	// are evaluated only against the samples of the executor's scenario.
+	Criteria map[string][]string `json:"criteria"`
+
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// NewThroughputSearchConfig returns a ThroughputSearchConfig with default values
+func NewThroughputSearchConfig(name string) *ThroughputSearchConfig {
+	return &ThroughputSearchConfig{
+		BaseConfig: NewBaseConfig(name, throughputSearchType),
+		StartRate:  null.NewInt(1, false),
+		TimeUnit:   types.NewNullDuration(1*time.Second, false),
+		Precision:  null.NewInt(defaultThroughputSearchPrecision, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &ThroughputSearchConfig{}
+
+// GetPreAllocatedVUs is just a helper method that returns the scaled pre-allocated VUs.
+func (tsc ThroughputSearchConfig) GetPreAllocatedVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(tsc.PreAllocatedVUs.Int64)
+}
+
+// GetMaxVUs is just a helper method that returns the scaled max VUs.
+func (tsc ThroughputSearchConfig) GetMaxVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(tsc.MaxVUs.Int64)
+}
+
+// getMaxPlateaus returns the maxPlateaus option, or if it isn't specified, the
+// number of plateaus that the search needs in the worst case to converge. That
+// is doubling the rate from the startRate up to the maxRate, and then halving
+// a range of up to maxRate-startRate until it's within the precision, e.g. 11
+// and 10 plateaus for the rates between 1 and 1000 with a precision of 1.
+func (tsc ThroughputSearchConfig) getMaxPlateaus() int64 {
+	if tsc.MaxPlateaus.Valid {
+		return tsc.MaxPlateaus.Int64
+	}
+	if tsc.StartRate.Int64 <= 0 || tsc.Precision.Int64 <= 0 {
+		return 1 // the config is invalid, Validate() returns the errors
+	}
+	plateaus := int64(1)
+	for rate := tsc.StartRate.Int64; rate < tsc.MaxRate.Int64; rate *= 2 {
+		plateaus++
+	}
+	for diff := tsc.MaxRate.Int64 - tsc.StartRate.Int64; diff > tsc.Precision.Int64; diff -= diff / 2 {
+		plateaus++
+	}
+	return plateaus
+}
+
+// GetMaxDuration returns the duration of the executor if it runs all of its
+// plateaus, the search usually finishes sooner than that.
+func (tsc ThroughputSearchConfig) GetMaxDuration() time.Duration {
+	return time.Duration(tsc.getMaxPlateaus()) * tsc.PlateauDuration.TimeDuration()
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (tsc ThroughputSearchConfig) GetDescription(et *lib.ExecutionTuple) string {
+	preAllocatedVUs, maxVUs := tsc.GetPreAllocatedVUs(et), tsc.GetMaxVUs(et)
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", preAllocatedVUs)
+	if maxVUs > preAllocatedVUs {
+		maxVUsRange += fmt.Sprintf("-%d", maxVUs)
+	}
+	return fmt.Sprintf("Up to %d %s plateaus, searching for the maximum rate between %d and %d iterations per %s%s",
+		tsc.getMaxPlateaus(), tsc.PlateauDuration.Duration, tsc.StartRate.Int64, tsc.MaxRate.Int64,
+		tsc.TimeUnit.Duration, tsc.getBaseInfo(maxVUsRange))
+}
+
+// Validate makes sure all options are configured and valid
+func (tsc *ThroughputSearchConfig) Validate() []error {
+	errors := tsc.BaseConfig.Validate()
+	if tsc.StartRate.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the startRate must be more than 0"))
+	}
+	if !tsc.MaxRate.Valid {
+		errors = append(errors, fmt.Errorf("the maxRate isn't specified"))
+	} else if tsc.MaxRate.Int64 < tsc.StartRate.Int64 {
+		errors = append(errors, fmt.Errorf("the maxRate can't be less than the startRate"))
+	}
+	if tsc.TimeUnit.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the timeUnit must be more than 0"))
+	}
+	if !tsc.PlateauDuration.Valid {
+		errors = append(errors, fmt.Errorf("the plateauDuration is unspecified"))
+	} else if tsc.PlateauDuration.TimeDuration() < minDuration {
+		errors = append(errors, fmt.Errorf(
+			"the plateauDuration must be at least %s, but is %s", minDuration, tsc.PlateauDuration,
+		))
+	}
+	if tsc.Precision.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the precision must be more than 0"))
+	}
+	if tsc.MaxPlateaus.Valid && tsc.MaxPlateaus.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the maxPlateaus must be more than 0"))
+	}
+
+	if len(tsc.Criteria) == 0 {
+		errors = append(errors, fmt.Errorf("at least one criterion is required"))
+	}
+	for _, name := range tsc.getCriteriaMetrics() {
+		for _, source := range tsc.Criteria[name] {
+			if _, err := (&metrics.Threshold{Source: source}).AggregationMethod(); err != nil {
+				errors = append(errors, fmt.Errorf("invalid criterion '%s' for '%s': %w", source, name, err))
+			}
+		}
+	}
+
+	if !tsc.PreAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if tsc.PreAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if !tsc.MaxVUs.Valid {
+		// TODO: don't change the config while validating
+		tsc.MaxVUs.Int64 = tsc.PreAllocatedVUs.Int64
+	} else if tsc.MaxVUs.Int64 < tsc.PreAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+
+	return errors
+}
+
+func (tsc ThroughputSearchConfig) getCriteriaMetrics() []string {
+	names := make([]string, 0, len(tsc.Criteria))
+	for name := range tsc.Criteria {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for all of its plateaus (disregarding any startTime), including
+// the maximum waiting time for any iterations to gracefully stop.
+func (tsc ThroughputSearchConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return []lib.ExecutionStep{
+		{
+			TimeOffset:      0,
+			PlannedVUs:      uint64(et.ScaleInt64(tsc.PreAllocatedVUs.Int64)),
+			MaxUnplannedVUs: uint64(et.ScaleInt64(tsc.MaxVUs.Int64) - et.ScaleInt64(tsc.PreAllocatedVUs.Int64)),
+		}, {
+			TimeOffset:      tsc.GetMaxDuration() + tsc.GracefulStop.TimeDuration(),
+			PlannedVUs:      0,
+			MaxUnplannedVUs: 0,
+		},
+	}
+}
+
+// NewExecutor creates a new ThroughputSearch executor
+func (tsc ThroughputSearchConfig) NewExecutor(
+	es *lib.ExecutionState, logger *logrus.Entry,
+) (lib.Executor, error) {
+	return &ThroughputSearch{
+		BaseExecutor: NewBaseExecutor(&tsc, es, logger),
+		config:       tsc,
+		resultLock:   new(sync.Mutex),
+		result:       &lib.ThroughputSearchResult{Scenario: tsc.Name, TimeUnit: tsc.TimeUnit.TimeDuration()},
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (tsc ThroughputSearchConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return tsc.GetMaxVUs(et) > 0
+}
+
+// throughputSearch keeps the state of the search for the maximum rate that
+// passes the criteria.
+type throughputSearch struct {
+	startRate, maxRate, precision int64
+
+	passed int64 // the highest rate that passed, 0 if none did yet
+	failed int64 // the lowest rate that failed, 0 if none did yet
+}
+
+// next returns the rate of the next plateau, or false if the search is done.
+func (s *throughputSearch) next() (int64, bool) {
+	switch {
+	case s.passed == 0 && s.failed == 0:
+		return s.startRate, true
+	case s.failed == 0:
+		if s.passed >= s.maxRate {
+			return 0, false
+		}
+		if rate := 2 * s.passed; rate < s.maxRate {
+			return rate, true
+		}
+		return s.maxRate, true
+	case s.passed == 0 || s.failed-s.passed <= s.precision:
+		// even the start rate failed, or the range is narrow enough
+		return 0, false
+	default:
+		return s.passed + (s.failed-s.passed)/2, true
+	}
+}
+
+// record records whether the plateau with the given rate passed.
+func (s *throughputSearch) record(rate int64, passed bool) {
+	if passed {
+		s.passed = rate
+	} else {
+		s.failed = rate
+	}
+}
+
+// ThroughputSearch runs plateaus with increasing constant arrival rates and
+// does a binary search for the maximum rate that passes the criteria.
+type ThroughputSearch struct {
+	*BaseExecutor
+	config ThroughputSearchConfig
+	et     *lib.ExecutionTuple
+
+	resultLock *sync.Mutex
+	result     *lib.ThroughputSearchResult
+}
+
+// Make sure we implement the lib.Executor and lib.ThroughputSearchExecutor interfaces.
+var (
+	_ lib.Executor                 = &ThroughputSearch{}
+	_ lib.ThroughputSearchExecutor = &ThroughputSearch{}
+)
+
+// GetThroughputSearchResult returns the plateaus that were run so far and the
+// maximum rate that passed the criteria.
+func (ts *ThroughputSearch) GetThroughputSearchResult() lib.ThroughputSearchResult {
+	ts.resultLock.Lock()
+	defer ts.resultLock.Unlock()
+	result := *ts.result
+	result.Plateaus = append([]lib.ThroughputSearchPlateau{}, ts.result.Plateaus...)
+	return result
+}
+
+// setInconclusive marks the result of the search as inconclusive.
+func (ts *ThroughputSearch) setInconclusive() {
+	ts.resultLock.Lock()
+	defer ts.resultLock.Unlock()
+	ts.result.Inconclusive = true
+}
+
+// recordPlateau adds the result of a plateau to the result of the search.
+func (ts *ThroughputSearch) recordPlateau(plateau lib.ThroughputSearchPlateau, maxRate int64) {
+	ts.resultLock.Lock()
+	defer ts.resultLock.Unlock()
+	ts.result.Plateaus = append(ts.result.Plateaus, plateau)
+	ts.result.MaxRate = maxRate
+}
+
+// Init values needed for the execution
+func (ts *ThroughputSearch) Init(ctx context.Context) error {
+	// err should always be nil, because Init() won't be called for executors
+	// with no work, as determined by their config's HasWork() method.
+	et, err := ts.BaseExecutor.executionState.ExecutionTuple.GetNewExecutionTupleFromValue(ts.config.MaxVUs.Int64)
+	if err != nil {
+		return err
+	}
+	ts.et = et
+	ts.iterSegIndex = lib.NewSegmentedIndex(et)
+
+	// The criteria sub-metrics are created and their samples are tracked
+	// from their first queries, so they are made before the test starts. The
+	// samples are kept for two plateaus, since every plateau is evaluated a
+	// little after its end, once all of its samples are aggregated.
+	querier := ts.executionState.GetMetricsQuerier()
+	if querier == nil {
+		return nil // Run() returns the error
+	}
+	window := 2 * ts.config.PlateauDuration.TimeDuration()
+	for _, name := range ts.config.getCriteriaMetrics() {
+		for _, source := range ts.config.Criteria[name] {
+			aggregation, err := (&metrics.Threshold{Source: source}).AggregationMethod()
+			if err == nil {
+				_, err = querier.QueryMetric(ts.getCriterionMetric(name), aggregation, window)
+			}
+			if err != nil && !errors.Is(err, lib.ErrNewQuerySubmetric) {
+				return fmt.Errorf("invalid criterion '%s' for '%s': %w", source, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getCriterionMetric returns the name of the criterion's metric, restricted to
+// the samples of the executor's scenario.
+func (ts *ThroughputSearch) getCriterionMetric(name string) string {
+	if !ts.executionState.Options.SystemTags.Has(metrics.TagScenario) {
+		return name
+	}
+	scenarioTag := metrics.TagScenario.String() + ":" + ts.config.Name
+	i := strings.IndexByte(name, '{')
+	switch {
+	case i < 0:
+		return name + "{" + scenarioTag + "}"
+	case strings.TrimSpace(name[i+1:]) == "}":
+		return name[:i+1] + scenarioTag + "}"
+	default:
+		return name[:i+1] + scenarioTag + "," + name[i+1:]
+	}
+}
+
+// evaluateCriteria checks the criteria against the samples of the plateau
+// between the start and end times and returns the failed ones, e.g.
+// "http_req_duration: p(95)<500 (612.5)".
+func (ts *ThroughputSearch) evaluateCriteria(
+	ctx context.Context, querier lib.MetricsQuerier, start, end time.Time,
+) ([]string, error) {
+	var failed []string
+	for _, name := range ts.config.getCriteriaMetrics() {
+		for _, source := range ts.config.Criteria[name] {
+			threshold := &metrics.Threshold{Source: source}
+			aggregation, err := threshold.AggregationMethod()
+			if err != nil {
+				return nil, err
+			}
+			value, err := querier.QueryMetricRange(ctx, ts.getCriterionMetric(name), aggregation, start, end)
+			if err != nil {
+				return nil, err
+			}
+			passes, _, err := threshold.Evaluate(map[string]float64{aggregation: value})
+			if err != nil {
+				return nil, err
+			}
+			if !passes {
+				failed = append(failed, fmt.Sprintf("%s: %s (%g)", name, source, value))
+			}
+		}
+	}
+	return failed, nil
+}
+
+// Run executes the plateaus of the search, until the maximum rate that passes
+// the criteria is found or the maximum number of plateaus is reached.
+//
+//nolint:funlen,cyclop
+func (ts ThroughputSearch) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) (err error) {
+	querier := ts.executionState.GetMetricsQuerier()
+	if querier == nil {
+		return errors.New("the throughput-search executor needs the metrics to be aggregated locally, " +
+			"so it can't be used when both the summary and the thresholds are disabled")
+	}
+
+	gracefulStop := ts.config.GetGracefulStop()
+	duration := ts.config.GetMaxDuration()
+	plateauDuration := ts.config.PlateauDuration.TimeDuration()
+	preAllocatedVUs := ts.config.GetPreAllocatedVUs(ts.executionState.ExecutionTuple)
+	maxVUs := ts.config.GetMaxVUs(ts.executionState.ExecutionTuple)
+	maxPlateaus := ts.config.getMaxPlateaus()
+
+	// Make sure the log and the progress bar have accurate information
+	ts.logger.WithFields(logrus.Fields{
+		"maxVUs": maxVUs, "preAllocatedVUs": preAllocatedVUs, "maxDuration": duration,
+		"type": ts.config.GetType(),
+	}).Debug("Starting executor run...")
+
+	activeVUsWg := &sync.WaitGroup{}
+
+	returnedVUs := make(chan struct{})
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+
+	vusPool := newActiveVUPool()
+	defer func() {
+		// Make sure all VUs aren't executing iterations anymore, for the cancel()
+		// below to deactivate them.
+		<-returnedVUs
+		// first close the vusPool so we wait for the gracefulShutdown
+		vusPool.Close()
+		cancel()
+		activeVUsWg.Wait()
+	}()
+	activeVUsCount := uint64(0)
+
+	search := &throughputSearch{
+		startRate: ts.config.StartRate.Int64,
+		maxRate:   ts.config.MaxRate.Int64,
+		precision: ts.config.Precision.Int64,
+	}
+	var plateau, currentRate, bestRate int64
+	vusFmt := pb.GetFixedLengthIntFormat(maxVUs)
+	plateausFmt := pb.GetFixedLengthIntFormat(maxPlateaus)
+	progressFn := func() (float64, []string) {
+		currPlateau := atomic.LoadInt64(&plateau)
+		progVUs := fmt.Sprintf(vusFmt+"/"+vusFmt+" VUs", vusPool.Running(), atomic.LoadUint64(&activeVUsCount))
+		progPlateaus := fmt.Sprintf("plateau "+plateausFmt+"/"+plateausFmt, currPlateau, maxPlateaus)
+		progRates := fmt.Sprintf("%d iters/%s, best %d",
+			atomic.LoadInt64(&currentRate), ts.config.TimeUnit.Duration, atomic.LoadInt64(&bestRate))
+		return float64(currPlateau) / float64(maxPlateaus), []string{progVUs, progPlateaus, progRates}
+	}
+	ts.progress.Modify(pb.WithProgress(progressFn))
+	go trackProgress(parentCtx, maxDurationCtx, regDurationCtx, &ts, progressFn)
+
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:           ts.config.Name,
+		Executor:       ts.config.Type,
+		RequestTimeout: ts.config.RequestTimeout,
+		StartTime:      startTime,
+		ProgressFn:     progressFn,
+	})
+
+	returnVU := func(u lib.InitializedVU) {
+		ts.executionState.ReturnVU(u, true)
+		activeVUsWg.Done()
+	}
+
+	runIterationBasic := getIterationRunner(ts.executionState, ts.logger)
+	activateVU := func(initVU lib.InitializedVU) lib.ActiveVU {
+		activeVUsWg.Add(1)
+		activeVU := initVU.Activate(getVUActivationParams(
+			maxDurationCtx, ts.config.BaseConfig, ts.execMix, returnVU,
+			ts.nextIterationCounters,
+		))
+		ts.executionState.ModCurrentlyActiveVUsCount(+1)
+		atomic.AddUint64(&activeVUsCount, 1)
+		vusPool.AddVU(maxDurationCtx, activeVU, runIterationBasic)
+		return activeVU
+	}
+
+	remainingUnplannedVUs := maxVUs - preAllocatedVUs
+	makeUnplannedVUCh := make(chan struct{})
+	defer close(makeUnplannedVUCh)
+	go func() {
+		defer close(returnedVUs)
+		for range makeUnplannedVUCh {
+			ts.logger.Debug("Starting initialization of an unplanned VU...")
+			initVU, err := ts.executionState.GetUnplannedVU(maxDurationCtx, ts.logger)
+			if err != nil {
+				// TODO figure out how to return it to the Run goroutine
+				ts.logger.WithError(err).Error("Error while allocating unplanned VU")
+			} else {
+				ts.logger.Debug("The unplanned VU finished initializing successfully!")
+				activateVU(initVU)
+			}
+		}
+	}()
+
+	// Get the pre-allocated VUs in the local buffer
+	for i := int64(0); i < preAllocatedVUs; i++ {
+		initVU, err := ts.executionState.GetPlannedVU(ts.logger, false)
+		if err != nil {
+			return err
+		}
+		activateVU(initVU)
+	}
+
+	droppedIterationMetric := ts.executionState.BuiltinMetrics.DroppedIterations
+	metricTags := ts.getMetricTags(nil)
+	timer := time.NewTimer(time.Hour * 24)
+	shownWarning := false
+
+	// runPlateau starts the iterations of a plateau with the given rate and
+	// returns how many of them were scheduled and dropped. The plateaus are
+	// aligned to the start of the executor, so they don't drift because of the
+	// time it takes to evaluate the criteria.
+	runPlateau := func(plateauStart, plateauEnd time.Time, rate int64) (scheduled, dropped int64) {
+		defer func() {
+			// wait for the end of the plateau, when the last iteration starts early
+			if wait := time.Until(plateauEnd); wait > 0 && regDurationCtx.Err() == nil {
+				timer.Reset(wait)
+				select {
+				case <-timer.C:
+				case <-regDurationCtx.Done():
+				}
+			}
+		}()
+
+		tickerPeriod := getTickerPeriod(getScaledArrivalRate(
+			ts.et.Segment, rate, ts.config.TimeUnit.TimeDuration())).TimeDuration()
+		if tickerPeriod <= 0 {
+			return 0, 0 // this execution segment has no iterations at this rate
+		}
+		for next := plateauStart; next.Before(plateauEnd); next = next.Add(tickerPeriod) {
+			timer.Reset(time.Until(next))
+			select {
+			case <-timer.C:
+			case <-regDurationCtx.Done():
+				return scheduled, dropped
+			}
+
+			scheduled++
+			if vusPool.TryRunIteration() {
+				continue
+			}
+			dropped++
+			metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
+				Value: 1, Metric: droppedIterationMetric,
+				Tags: metricTags, Time: time.Now(),
+			})
+
+			if remainingUnplannedVUs == 0 {
+				if !shownWarning {
+					ts.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", maxVUs)
+					shownWarning = true
+				}
+				continue
+			}
+			select {
+			case makeUnplannedVUCh <- struct{}{}: // great!
+				remainingUnplannedVUs--
+			default: // we're already allocating a new VU
+			}
+		}
+		return scheduled, dropped
+	}
+
+	for i := int64(0); i < maxPlateaus; i++ {
+		rate, ok := search.next()
+		if !ok {
+			break
+		}
+		atomic.StoreInt64(&currentRate, rate)
+		plateauStart := startTime.Add(time.Duration(i) * plateauDuration)
+		plateauEnd := plateauStart.Add(plateauDuration)
+		scheduled, dropped := runPlateau(plateauStart, plateauEnd, rate)
+		if parentCtx.Err() != nil || time.Now().Before(plateauEnd) {
+			break // the test was interrupted in the middle of the plateau
+		}
+		atomic.StoreInt64(&plateau, i+1)
+
+		// the last plateau ends with the regular duration, so only an
+		// interruption of the test stops the wait for its samples
+		failed, err := ts.evaluateCriteria(parentCtx, querier, plateauStart, plateauEnd)
+		if parentCtx.Err() != nil {
+			break // the test was interrupted while waiting for the samples
+		}
+		if err != nil {
+			return err
+		}
+		if scheduled > 0 && float64(dropped)/float64(scheduled) > maxDroppedIterationsRatio {
+			failed = append(failed, fmt.Sprintf("%d of %d iterations were dropped", dropped, scheduled))
+		}
+		search.record(rate, len(failed) == 0)
+		atomic.StoreInt64(&bestRate, search.passed)
+		ts.recordPlateau(lib.ThroughputSearchPlateau{Rate: rate, Passed: len(failed) == 0, Failures: failed}, search.passed)
+
+		logger := ts.logger.WithFields(logrus.Fields{"plateau": i + 1, "rate": rate})
+		if len(failed) == 0 {
+			logger.Infof("The plateau at %d iterations per %s passed", rate, ts.config.TimeUnit.Duration)
+		} else {
+			logger.Infof("The plateau at %d iterations per %s failed: %s",
+				rate, ts.config.TimeUnit.Duration, strings.Join(failed, ", "))
+		}
+	}
+
+	if _, ok := search.next(); ok && atomic.LoadInt64(&plateau) == maxPlateaus {
+		ts.setInconclusive()
+		ts.logger.Warnf("The search ran out of its %d plateaus before the passed and failed rates were within "+
+			"the precision of %d iterations per %s, so its result is inconclusive; increase the maxPlateaus",
+			maxPlateaus, search.precision, ts.config.TimeUnit.Duration)
+	}
+	if search.passed == 0 {
+		ts.logger.Warnf("None of the plateaus passed the criteria, not even the one at the start rate of %d "+
+			"iterations per %s", search.startRate, ts.config.TimeUnit.Duration)
+	} else {
+		ts.logger.Infof("The maximum rate that passed the criteria is %d iterations per %s",
+			search.passed, ts.config.TimeUnit.Duration)
+	}
+	return nil
+}
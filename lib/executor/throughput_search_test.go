@@ -0,0 +1,242 @@
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func TestThroughputSearchNext(t *testing.T) {
+	t.Parallel()
+
+	maxPassing := func(limit int64) func(int64) bool {
+		return func(rate int64) bool { return rate <= limit }
+	}
+	testCases := []struct {
+		name         string
+		passes       func(int64) bool
+		expectedRate []int64
+		expectedBest int64
+	}{
+		{name: "binary search", passes: maxPassing(37), expectedRate: []int64{10, 20, 40, 30, 35, 37, 38}, expectedBest: 37},
+		{name: "max rate passes", passes: maxPassing(100), expectedRate: []int64{10, 20, 40, 50}, expectedBest: 50},
+		{name: "start rate fails", passes: maxPassing(5), expectedRate: []int64{10}, expectedBest: 0},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			search := &throughputSearch{startRate: 10, maxRate: 50, precision: 1}
+			var rates []int64
+			for {
+				rate, ok := search.next()
+				if !ok {
+					break
+				}
+				rates = append(rates, rate)
+				search.record(rate, tc.passes(rate))
+			}
+			assert.Equal(t, tc.expectedRate, rates)
+			assert.Equal(t, tc.expectedBest, search.passed)
+		})
+	}
+}
+
+func TestThroughputSearchConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	config := NewThroughputSearchConfig("default")
+	errs := config.Validate()
+	require.Len(t, errs, 4)
+	assert.Contains(t, errs[0].Error(), "the maxRate isn't specified")
+	assert.Contains(t, errs[1].Error(), "the plateauDuration is unspecified")
+	assert.Contains(t, errs[2].Error(), "at least one criterion is required")
+	assert.Contains(t, errs[3].Error(), "the number of preAllocatedVUs isn't specified")
+
+	config.MaxRate = null.IntFrom(100)
+	config.PlateauDuration = types.NullDurationFrom(10 * time.Second)
+	config.PreAllocatedVUs = null.IntFrom(10)
+	config.Criteria = map[string][]string{"http_req_duration": {"p(95)<500", "foo(bar)"}}
+	errs = config.Validate()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "invalid criterion 'foo(bar)' for 'http_req_duration'")
+
+	config.Criteria["http_req_duration"] = []string{"p(95)<500"}
+	assert.Empty(t, config.Validate())
+	assert.Equal(t, 150*time.Second, config.GetMaxDuration())
+
+	config.MaxPlateaus = null.IntFrom(0)
+	errs = config.Validate()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "the maxPlateaus must be more than 0")
+}
+
+func TestThroughputSearchConfigMaxPlateaus(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		startRate, maxRate, precision, expected int64
+	}{
+		{startRate: 1, maxRate: 1000, precision: 1, expected: 21},
+		{startRate: 1, maxRate: 1000, precision: 10, expected: 18},
+		{startRate: 4, maxRate: 10, precision: 1, expected: 6},
+		{startRate: 10, maxRate: 10, precision: 1, expected: 1},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		config := NewThroughputSearchConfig("default")
+		config.StartRate = null.IntFrom(tc.startRate)
+		config.MaxRate = null.IntFrom(tc.maxRate)
+		config.Precision = null.IntFrom(tc.precision)
+		maxPlateaus := config.getMaxPlateaus()
+		assert.Equal(t, tc.expected, maxPlateaus, "%+v", tc)
+
+		// the search converges within the plateaus, regardless of the rate
+		// up to which the plateaus pass
+		for limit := int64(0); limit <= tc.maxRate; limit++ {
+			search := &throughputSearch{startRate: tc.startRate, maxRate: tc.maxRate, precision: tc.precision}
+			plateaus := int64(0)
+			for rate, ok := search.next(); ok; rate, ok = search.next() {
+				search.record(rate, rate <= limit)
+				plateaus++
+			}
+			assert.LessOrEqual(t, plateaus, maxPlateaus, "%+v, limit %d", tc, limit)
+		}
+	}
+
+	config := NewThroughputSearchConfig("default")
+	config.MaxPlateaus = null.IntFrom(5)
+	assert.Equal(t, int64(5), config.getMaxPlateaus())
+}
+
+// iterationsQuerier returns how many iterations were executed since the last
+// query as the value of every metric.
+type iterationsQuerier struct {
+	iterations int64
+	queries    []string
+	periods    []time.Duration // the windows or the durations of the ranges
+}
+
+func (iq *iterationsQuerier) QueryMetric(name, _ string, window time.Duration) (float64, error) {
+	iq.queries = append(iq.queries, name)
+	iq.periods = append(iq.periods, window)
+	return float64(atomic.SwapInt64(&iq.iterations, 0)), nil
+}
+
+func (iq *iterationsQuerier) QueryMetricRange(
+	_ context.Context, name, _ string, from, to time.Time,
+) (float64, error) {
+	iq.queries = append(iq.queries, name)
+	iq.periods = append(iq.periods, to.Sub(from))
+	return float64(atomic.SwapInt64(&iq.iterations, 0)), nil
+}
+
+func (iq *iterationsQuerier) CountSamples(string, time.Duration) (uint64, error) {
+	return 0, nil
+}
+
+func TestThroughputSearchRun(t *testing.T) {
+	t.Parallel()
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	es := lib.NewExecutionState(lib.Options{SystemTags: &metrics.DefaultSystemTagSet}, et, builtinMetrics, 10, 10)
+	querier := &iterationsQuerier{}
+	es.SetMetricsQuerier(querier)
+
+	config := NewThroughputSearchConfig("search")
+	config.StartRate = null.IntFrom(4)
+	config.MaxRate = null.IntFrom(10)
+	config.PlateauDuration = types.NullDurationFrom(time.Second)
+	config.PreAllocatedVUs = null.IntFrom(10)
+	config.Criteria = map[string][]string{"iterations": {"count<7"}}
+	require.Empty(t, config.Validate())
+
+	ctx, cancel, executor, logHook := setupExecutor(t, config, es,
+		simpleRunner(func(ctx context.Context, _ *lib.State) error {
+			atomic.AddInt64(&querier.iterations, 1)
+			return nil
+		}),
+	)
+	defer cancel()
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	start := time.Now()
+	require.NoError(t, executor.Run(ctx, engineOut))
+
+	// the plateaus are at 4 (passes), 8 (fails), 6 (passes) and 7 (fails)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 4*time.Second && elapsed < 5*time.Second, elapsed)
+	// the criteria are queried once by Init(), before the plateaus, so
+	// their samples are tracked for two plateaus, and then only for the
+	// time of every plateau
+	require.Len(t, querier.queries, 5)
+	assert.Equal(t, "iterations{scenario:search}", querier.queries[0])
+	assert.Equal(t, []time.Duration{2 * time.Second, time.Second, time.Second, time.Second, time.Second},
+		querier.periods)
+	assert.Empty(t, logHook.Drain())
+
+	result := executor.(lib.ThroughputSearchExecutor).GetThroughputSearchResult()
+	assert.Equal(t, "search", result.Scenario)
+	assert.Equal(t, time.Second, result.TimeUnit)
+	assert.Equal(t, int64(6), result.MaxRate)
+	assert.False(t, result.Inconclusive)
+	require.Len(t, result.Plateaus, 4)
+	expectedPlateaus := []lib.ThroughputSearchPlateau{{Rate: 4, Passed: true}, {Rate: 8}, {Rate: 6, Passed: true}, {Rate: 7}}
+	for i, expected := range expectedPlateaus {
+		assert.Equal(t, expected.Rate, result.Plateaus[i].Rate)
+		assert.Equal(t, expected.Passed, result.Plateaus[i].Passed)
+		assert.Equal(t, !expected.Passed, len(result.Plateaus[i].Failures) > 0)
+	}
+}
+
+func TestThroughputSearchRunInconclusive(t *testing.T) {
+	t.Parallel()
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	es := lib.NewExecutionState(lib.Options{SystemTags: &metrics.DefaultSystemTagSet}, et, builtinMetrics, 10, 10)
+	querier := &iterationsQuerier{}
+	es.SetMetricsQuerier(querier)
+
+	config := NewThroughputSearchConfig("search")
+	config.StartRate = null.IntFrom(4)
+	config.MaxRate = null.IntFrom(10)
+	config.PlateauDuration = types.NullDurationFrom(time.Second)
+	config.MaxPlateaus = null.IntFrom(2)
+	config.PreAllocatedVUs = null.IntFrom(10)
+	config.Criteria = map[string][]string{"iterations": {"count<7"}}
+	require.Empty(t, config.Validate())
+
+	ctx, cancel, executor, logHook := setupExecutor(t, config, es,
+		simpleRunner(func(ctx context.Context, _ *lib.State) error {
+			atomic.AddInt64(&querier.iterations, 1)
+			return nil
+		}),
+	)
+	defer cancel()
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, executor.Run(ctx, engineOut))
+
+	// the plateaus at 4 (passes) and 8 (fails) are all that it runs
+	result := executor.(lib.ThroughputSearchExecutor).GetThroughputSearchResult()
+	assert.Equal(t, int64(4), result.MaxRate)
+	assert.True(t, result.Inconclusive)
+	require.Len(t, result.Plateaus, 2)
+
+	entries := logHook.Drain()
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Message, "ran out of its 2 plateaus")
+}
@@ -94,6 +94,7 @@ type vuHandle struct {
 	returnVU              func(lib.InitializedVU)
 	nextIterationCounters func() (uint64, uint64)
 	config                *BaseConfig
+	execMix               *lib.ExecMix
 
 	initVU       lib.InitializedVU
 	activeVU     lib.ActiveVU
@@ -111,7 +112,7 @@ func newStoppedVUHandle(
 	parentCtx context.Context, getVU func() (lib.InitializedVU, error),
 	returnVU func(lib.InitializedVU),
 	nextIterationCounters func() (uint64, uint64),
-	config *BaseConfig, logger *logrus.Entry,
+	config *BaseConfig, execMix *lib.ExecMix, logger *logrus.Entry,
 ) *vuHandle {
 	ctx, cancel := context.WithCancel(parentCtx)
 
@@ -121,6 +122,7 @@ func newStoppedVUHandle(
 		getVU:                 getVU,
 		nextIterationCounters: nextIterationCounters,
 		config:                config,
+		execMix:               execMix,
 
 		canStartIter: make(chan struct{}),
 		state:        stopped,
@@ -151,7 +153,7 @@ func (vh *vuHandle) start() (err error) {
 		}
 
 		vh.activeVU = vh.initVU.Activate(getVUActivationParams(
-			vh.ctx, *vh.config, vh.returnVU, vh.nextIterationCounters))
+			vh.ctx, *vh.config, vh.execMix, vh.returnVU, vh.nextIterationCounters))
 		close(vh.canStartIter)
 		vh.changeState(starting)
 	}
@@ -31,6 +31,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/ui/pb"
 )
@@ -93,6 +94,9 @@ type ExecutorConfig interface {
 	//
 	// TODO: use interface{} so plain http requests can be specified?
 	GetExec() string
+	// Returns the weights of the exec functions that should be randomly
+	// picked for each iteration, if a mix was specified instead of exec.
+	GetExecMix() map[string]float64
 	GetTags() map[string]string
 
 	// Calculates the VU requirements in different stages of the executor's
@@ -115,6 +119,7 @@ type ScenarioState struct {
 	Name, Executor string
 	StartTime      time.Time
 	ProgressFn     func() (float64, []string)
+	RequestTimeout types.NullDuration // the default timeout of the scenario's requests
 }
 
 // InitVUFunc is just a shorthand so we don't have to type the function
@@ -145,6 +150,41 @@ type LiveUpdatableExecutor interface {
 	UpdateConfig(ctx context.Context, newConfig interface{}) error
 }
 
+// ExecMixExecutor should be implemented by the executors which can randomly
+// pick the exec function for each iteration from a weighted mix. GetExecMix()
+// returns nil if no mix was configured for the particular executor.
+type ExecMixExecutor interface {
+	GetExecMix() *ExecMix
+}
+
+// ThroughputSearchExecutor should be implemented by the executors which search
+// for the maximum rate that passes some criteria, so the result of the search
+// can be added to the end-of-test summary.
+type ThroughputSearchExecutor interface {
+	GetThroughputSearchResult() ThroughputSearchResult
+}
+
+// ThroughputSearchResult contains the plateaus that were run by a scenario
+// which searched for the maximum rate that passes its criteria.
+type ThroughputSearchResult struct {
+	Scenario string
+	TimeUnit time.Duration
+	MaxRate  int64 // the maximum rate that passed, 0 if none of the plateaus did
+	Plateaus []ThroughputSearchPlateau
+
+	// Inconclusive is true if the search ran out of plateaus before the
+	// passed and failed rates were within its precision.
+	Inconclusive bool
+}
+
+// ThroughputSearchPlateau is a single plateau of a throughput search, with the
+// failed criteria if it didn't pass, e.g. "http_req_duration: p(95)<500 (612.5)".
+type ThroughputSearchPlateau struct {
+	Rate     int64
+	Passed   bool
+	Failures []string
+}
+
 // ExecutorConfigConstructor is a simple function that returns a concrete
 // Config instance with the specified name and all default values correctly
 // initialized
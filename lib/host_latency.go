@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"go.k6.io/k6/lib/types"
+)
+
+// HostLatency is an artificial round-trip time that is added to the
+// connections to a host, to approximate clients from a different region.
+type HostLatency struct {
+	RTT    time.Duration
+	Jitter time.Duration
+	Region string
+}
+
+// ParseHostLatency parses latencies in the "80ms±10ms" format, where "+-" can
+// be used instead of "±" and the jitter is optional.
+func ParseHostLatency(s string) (HostLatency, error) {
+	s = strings.TrimSpace(strings.Replace(s, "+-", "±", 1))
+	parts := strings.SplitN(s, "±", 2)
+
+	var hl HostLatency
+	rtt, err := types.ParseExtendedDuration(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return hl, fmt.Errorf("invalid latency '%s': %w", s, err)
+	}
+	hl.RTT = rtt
+	if len(parts) == 2 {
+		if hl.Jitter, err = types.ParseExtendedDuration(strings.TrimSpace(parts[1])); err != nil {
+			return hl, fmt.Errorf("invalid latency jitter '%s': %w", s, err)
+		}
+	}
+	if hl.RTT < 0 || hl.Jitter < 0 {
+		return hl, fmt.Errorf("invalid latency '%s', it can't be negative", s)
+	}
+	return hl, nil
+}
+
+// Delay returns the RTT with a random jitter applied, which is never negative.
+func (hl HostLatency) Delay() time.Duration {
+	delay := hl.RTT
+	if hl.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(2*hl.Jitter)+1)) - hl.Jitter //nolint:gosec
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// String returns the latency in the format accepted by ParseHostLatency.
+func (hl HostLatency) String() string {
+	if hl.Jitter == 0 {
+		return types.Duration(hl.RTT).String()
+	}
+	return types.Duration(hl.RTT).String() + "±" + types.Duration(hl.Jitter).String()
+}
+
+// UnmarshalText parses the latency, so it can be specified in an environment
+// variable, e.g. K6_LATENCY="eu-api.example.com:80ms±10ms".
+func (hl *HostLatency) UnmarshalText(text []byte) error {
+	parsed, err := ParseHostLatency(string(text))
+	if err != nil {
+		return err
+	}
+	*hl = parsed
+	return nil
+}
+
+type hostLatencyJSON struct {
+	Latency string `json:"latency"`
+	Region  string `json:"region"`
+}
+
+// UnmarshalJSON accepts either a latency string, or an object with the
+// latency and the simulated region, e.g. {"latency": "80ms±10ms", "region": "eu"}.
+func (hl *HostLatency) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return hl.UnmarshalText([]byte(s))
+	}
+
+	var raw hostLatencyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Latency == "" {
+		return errors.New("the host latency should have a latency value")
+	}
+	if err := hl.UnmarshalText([]byte(raw.Latency)); err != nil {
+		return err
+	}
+	hl.Region = raw.Region
+	return nil
+}
+
+// MarshalJSON returns the latency string, or an object if there is a simulated
+// region as well.
+func (hl HostLatency) MarshalJSON() ([]byte, error) {
+	if hl.Region == "" {
+		return json.Marshal(hl.String())
+	}
+	return json.Marshal(hostLatencyJSON{Latency: hl.String(), Region: hl.Region})
+}
+
+// SimulatedRegionTag is the tag with the simulated region of the host, which
+// is added to the metric samples of the HTTP requests, WebSocket connections
+// and gRPC calls to hosts with a region.
+const SimulatedRegionTag = "simulated_region"
+
+// HostLatencies are the added latencies by hostname. A "*." prefix matches all
+// of the subdomains of a domain.
+type HostLatencies map[string]HostLatency
+
+// Get returns the latency for the hostname, preferring exact matches over the
+// wildcard ones and the longer wildcards over the shorter ones.
+func (hls HostLatencies) Get(hostname string) (HostLatency, bool) {
+	if len(hls) == 0 {
+		return HostLatency{}, false
+	}
+	hostname = strings.ToLower(hostname)
+	if hl, ok := hls[hostname]; ok {
+		return hl, true
+	}
+	for i := strings.IndexByte(hostname, '.'); i >= 0; {
+		if hl, ok := hls["*"+hostname[i:]]; ok {
+			return hl, true
+		}
+		next := strings.IndexByte(hostname[i+1:], '.')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return HostLatency{}, false
+}
+
+// Region returns the simulated region of the hostname, if it has one.
+func (hls HostLatencies) Region(hostname string) string {
+	hl, _ := hls.Get(hostname)
+	return hl.Region
+}
+
+// SetRegionTag sets the SimulatedRegionTag to the simulated region of the
+// hostname, if it has one and the tag wasn't already set.
+func (hls HostLatencies) SetRegionTag(tags map[string]string, hostname string) {
+	if _, ok := tags[SimulatedRegionTag]; ok {
+		return
+	}
+	if region := hls.Region(hostname); region != "" {
+		tags[SimulatedRegionTag] = region
+	}
+}
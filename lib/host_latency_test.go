@@ -0,0 +1,101 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostLatency(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]HostLatency{
+		"80ms±10ms":     {RTT: 80 * time.Millisecond, Jitter: 10 * time.Millisecond},
+		" 1s +- 200ms ": {RTT: time.Second, Jitter: 200 * time.Millisecond},
+		"50ms":          {RTT: 50 * time.Millisecond},
+		"120":           {RTT: 120 * time.Millisecond},
+	}
+	for input, expected := range testCases {
+		hl, err := ParseHostLatency(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, hl, input)
+	}
+
+	for _, input := range []string{"", "abc", "80ms±abc", "-10ms"} {
+		_, err := ParseHostLatency(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestHostLatencyJSON(t *testing.T) {
+	t.Parallel()
+
+	var hls HostLatencies
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"eu-api.example.com": "80ms±10ms",
+		"*.us.example.com": {"latency": "150ms", "region": "us-east"}
+	}`), &hls))
+	assert.Equal(t, HostLatencies{
+		"eu-api.example.com": {RTT: 80 * time.Millisecond, Jitter: 10 * time.Millisecond},
+		"*.us.example.com":   {RTT: 150 * time.Millisecond, Region: "us-east"},
+	}, hls)
+
+	data, err := json.Marshal(hls)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"eu-api.example.com": "80ms±10ms",
+		"*.us.example.com": {"latency": "150ms", "region": "us-east"}
+	}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"region": "eu"}`), &HostLatency{}))
+}
+
+func TestHostLatenciesGet(t *testing.T) {
+	t.Parallel()
+
+	hls := HostLatencies{
+		"api.example.com":        {RTT: 1, Region: "exact"},
+		"*.example.com":          {RTT: 2, Region: "short"},
+		"*.internal.example.com": {RTT: 3, Region: "long"},
+	}
+	testCases := map[string]string{
+		"api.example.com":          "exact",
+		"API.example.com":          "exact",
+		"www.example.com":          "short",
+		"a.b.internal.example.com": "long",
+		"internal.example.com":     "short",
+		"example.com":              "",
+		"example.org":              "",
+	}
+	for hostname, region := range testCases {
+		assert.Equal(t, region, hls.Region(hostname), hostname)
+	}
+
+	var empty HostLatencies
+	_, ok := empty.Get("example.com")
+	assert.False(t, ok)
+
+	tags := map[string]string{}
+	hls.SetRegionTag(tags, "example.org")
+	assert.Empty(t, tags)
+	hls.SetRegionTag(tags, "api.example.com")
+	assert.Equal(t, map[string]string{SimulatedRegionTag: "exact"}, tags)
+	tags[SimulatedRegionTag] = "custom"
+	hls.SetRegionTag(tags, "www.example.com")
+	assert.Equal(t, "custom", tags[SimulatedRegionTag], "the tag set by the user should be kept")
+}
+
+func TestHostLatencyDelay(t *testing.T) {
+	t.Parallel()
+
+	hl := HostLatency{RTT: 10 * time.Millisecond, Jitter: 20 * time.Millisecond}
+	for i := 0; i < 100; i++ {
+		delay := hl.Delay()
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 30*time.Millisecond)
+	}
+	assert.Equal(t, time.Second, HostLatency{RTT: time.Second}.Delay())
+}
@@ -0,0 +1,36 @@
+package lib
+
+import "sync"
+
+// IterationCleanups keeps the functions which release the resources a VU
+// acquired only for the duration of the current iteration, e.g. the
+// identities checked out from an identity pool. They are run synchronously
+// when the iteration ends, before the VU can start its next one.
+type IterationCleanups struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// Add registers a function that should be run at the end of the current
+// iteration.
+func (c *IterationCleanups) Add(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fns = append(c.fns, fn)
+}
+
+// Run runs and clears the registered functions, in the order in which they
+// were added.
+func (c *IterationCleanups) Run() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
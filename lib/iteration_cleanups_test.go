@@ -0,0 +1,24 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterationCleanups(t *testing.T) {
+	t.Parallel()
+
+	var calls []int
+	cleanups := &IterationCleanups{}
+	cleanups.Add(func() { calls = append(calls, 1) })
+	cleanups.Add(func() { calls = append(calls, 2) })
+	cleanups.Run()
+	assert.Equal(t, []int{1, 2}, calls)
+
+	cleanups.Run()
+	assert.Equal(t, []int{1, 2}, calls)
+
+	var nilCleanups *IterationCleanups
+	nilCleanups.Run()
+}
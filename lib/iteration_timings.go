@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// IterationTimings accumulates how much of the current iteration a VU spent
+// blocked in protocol calls (HTTP requests, gRPC calls, WebSocket sessions)
+// and in sleep(), so the remaining time can be attributed to the JS execution
+// of the script itself.
+type IterationTimings struct {
+	protocol, sleep int64 // nanoseconds, accessed atomically
+}
+
+// IterationTimingsReport is the breakdown of a finished iteration.
+type IterationTimingsReport struct {
+	Protocol, Sleep, Script time.Duration
+}
+
+// AddProtocolTime records the duration of a blocking protocol call.
+func (t *IterationTimings) AddProtocolTime(d time.Duration) {
+	if t != nil {
+		atomic.AddInt64(&t.protocol, int64(d))
+	}
+}
+
+// AddSleepTime records the duration of a sleep() call.
+func (t *IterationTimings) AddSleepTime(d time.Duration) {
+	if t != nil {
+		atomic.AddInt64(&t.sleep, int64(d))
+	}
+}
+
+// Reset returns the breakdown of an iteration with the given total duration
+// and clears the accumulated timings, so they can be used for the next one.
+func (t *IterationTimings) Reset(total time.Duration) IterationTimingsReport {
+	if t == nil {
+		return IterationTimingsReport{Script: total}
+	}
+	report := IterationTimingsReport{
+		Protocol: time.Duration(atomic.SwapInt64(&t.protocol, 0)),
+		Sleep:    time.Duration(atomic.SwapInt64(&t.sleep, 0)),
+	}
+	// Protocol calls from the event loop can overlap with everything else.
+	if report.Script = total - report.Protocol - report.Sleep; report.Script < 0 {
+		report.Script = 0
+	}
+	return report
+}
@@ -0,0 +1,32 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterationTimings(t *testing.T) {
+	t.Parallel()
+
+	timings := &IterationTimings{}
+	timings.AddProtocolTime(300 * time.Millisecond)
+	timings.AddProtocolTime(200 * time.Millisecond)
+	timings.AddSleepTime(time.Second)
+
+	assert.Equal(t, IterationTimingsReport{
+		Protocol: 500 * time.Millisecond,
+		Sleep:    time.Second,
+		Script:   500 * time.Millisecond,
+	}, timings.Reset(2*time.Second))
+	assert.Equal(t, IterationTimingsReport{Script: time.Second}, timings.Reset(time.Second))
+
+	timings.AddProtocolTime(2 * time.Second)
+	assert.Equal(t, IterationTimingsReport{Protocol: 2 * time.Second}, timings.Reset(time.Second))
+
+	var nilTimings *IterationTimings
+	nilTimings.AddProtocolTime(time.Second)
+	nilTimings.AddSleepTime(time.Second)
+	assert.Equal(t, IterationTimingsReport{Script: time.Second}, nilTimings.Reset(time.Second))
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -42,6 +43,7 @@ type Dialer struct {
 	Blacklist        []*lib.IPNet
 	BlockedHostnames *types.HostnameTrie
 	Hosts            map[string]*lib.HostAddress
+	Latency          lib.HostLatencies
 
 	BytesRead    int64
 	BytesWritten int64
@@ -49,6 +51,9 @@ type Dialer struct {
 
 // NewDialer constructs a new Dialer with the given DNS resolver.
 func NewDialer(dialer net.Dialer, resolver Resolver) *Dialer {
+	if dialer.Control == nil {
+		dialer.Control = socketControl
+	}
 	return &Dialer{
 		Dialer:   dialer,
 		Resolver: resolver,
@@ -81,14 +86,39 @@ func (d *Dialer) DialContext(ctx context.Context, proto, addr string) (net.Conn,
 	if err != nil {
 		return nil, err
 	}
+	latency, hasLatency := d.getLatency(addr)
+	if hasLatency {
+		// the TCP handshake takes a round trip as well
+		timer := time.NewTimer(latency.Delay())
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
 	conn, err := d.Dialer.DialContext(ctx, proto, dialAddr)
 	if err != nil {
 		return nil, err
 	}
-	conn = &Conn{conn, &d.BytesRead, &d.BytesWritten}
+	if hasLatency {
+		conn = &latencyConn{Conn: conn, latency: latency, closed: make(chan struct{})}
+	}
+	conn = newConn(conn, &d.BytesRead, &d.BytesWritten)
 	return conn, err
 }
 
+func (d *Dialer) getLatency(addr string) (lib.HostLatency, bool) {
+	if len(d.Latency) == 0 {
+		return lib.HostLatency{}, false
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return lib.HostLatency{}, false
+	}
+	return d.Latency.Get(host)
+}
+
 // GetTrail creates a new NetTrail instance with the Dialer
 // sent and received data metrics and the supplied times and tags.
 // TODO: Refactor this according to
@@ -137,7 +167,7 @@ func (d *Dialer) GetTrail(
 		StartTime:     startTime,
 		EndTime:       endTime,
 		Tags:          tags,
-		Samples:       samples,
+		Samples:       metrics.WithoutDisabled(samples),
 	}
 }
 
@@ -249,6 +279,44 @@ type Conn struct {
 	net.Conn
 
 	BytesRead, BytesWritten *int64
+
+	// ID is unique for all connections of the current k6 instance.
+	ID uint64
+}
+
+var lastConnID uint64 //nolint:gochecknoglobals
+
+func newConn(conn net.Conn, bytesRead, bytesWritten *int64) *Conn {
+	return &Conn{Conn: conn, BytesRead: bytesRead, BytesWritten: bytesWritten, ID: atomic.AddUint64(&lastConnID, 1)}
+}
+
+// GetConnID returns the ID of the connection, if it's a *Conn or it wraps
+// one, like a *tls.Conn does.
+func GetConnID(conn net.Conn) (uint64, bool) {
+	for conn != nil {
+		switch c := conn.(type) {
+		case *Conn:
+			return c.ID, true
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// SetConnTags sets the enabled local_port and conn_id system tags for the
+// connection with the given local address and ID, which is zero if unknown.
+func SetConnTags(tags map[string]string, systemTags *metrics.SystemTagSet, localAddr net.Addr, connID uint64) {
+	if systemTags.Has(metrics.TagLocalPort) && localAddr != nil {
+		if _, port, err := net.SplitHostPort(localAddr.String()); err == nil {
+			tags[metrics.TagLocalPort.String()] = port
+		}
+	}
+	if systemTags.Has(metrics.TagConnID) && connID != 0 {
+		tags[metrics.TagConnID.String()] = strconv.FormatUint(connID, 10)
+	}
 }
 
 func (c *Conn) Read(b []byte) (int, error) {
@@ -266,3 +334,42 @@ func (c *Conn) Write(b []byte) (int, error) {
 	}
 	return n, err
 }
+
+// latencyConn simulates a longer round-trip time by delaying the first data
+// that is received after something was sent, e.g. the TLS handshake messages
+// or the responses to requests. The delay is cut short when the connection is
+// closed, e.g. when the request is cancelled because the test is interrupted.
+type latencyConn struct {
+	net.Conn
+	latency   lib.HostLatency
+	sent      uint32
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *latencyConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 && atomic.CompareAndSwapUint32(&c.sent, 1, 0) {
+		timer := time.NewTimer(c.latency.Delay())
+		select {
+		case <-timer.C:
+		case <-c.closed:
+			timer.Stop()
+			return n, net.ErrClosed
+		}
+	}
+	return n, err
+}
+
+func (c *latencyConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.Conn.Close()
+}
+
+func (c *latencyConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		atomic.StoreUint32(&c.sent, 1)
+	}
+	return n, err
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package netext
+
+import "syscall"
+
+// socketControl isn't needed on other platforms, which share the ephemeral
+// ports between connections to different destinations by default.
+var socketControl func(network, address string, c syscall.RawConn) error //nolint:gochecknoglobals
@@ -21,14 +21,21 @@
 package netext
 
 import (
+	"context"
+	"crypto/tls"
+	"io"
 	"net"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils/mockresolver"
 	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
 )
 
 func TestDialerAddr(t *testing.T) {
@@ -138,3 +145,103 @@ func newResolver() *mockresolver.MockResolver {
 		}, nil,
 	)
 }
+
+func TestDialerLatency(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	dialer := NewDialer(net.Dialer{}, newResolver())
+	dialer.Hosts = map[string]*lib.HostAddress{"slow.example.com": {IP: net.ParseIP("127.0.0.1")}}
+	dialer.Latency = lib.HostLatencies{"*.example.com": {RTT: 100 * time.Millisecond}}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(context.Background(), "tcp", "slow.example.com:"+port)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "the TCP handshake should be delayed")
+
+	start = time.Now()
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, int64(4), dialer.BytesRead)
+	assert.Equal(t, int64(4), dialer.BytesWritten)
+}
+
+func TestLatencyConnClose(t *testing.T) {
+	t.Parallel()
+
+	client, server := net.Pipe()
+	go func() { _, _ = io.Copy(server, server) }()
+	conn := &latencyConn{Conn: client, latency: lib.HostLatency{RTT: time.Hour}, closed: make(chan struct{})}
+
+	// closing the connection interrupts the delay of a pending read
+	_, err := conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	time.AfterFunc(50*time.Millisecond, func() { _ = conn.Close() })
+	start := time.Now()
+	_, err = conn.Read(make([]byte, 4))
+	assert.ErrorIs(t, err, net.ErrClosed)
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.NoError(t, conn.Close())
+}
+
+func TestDialerConnTags(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	dialer := NewDialer(net.Dialer{}, newResolver())
+	conn1, err := dialer.DialContext(context.Background(), "tcp", listener.Addr().String())
+	require.NoError(t, err)
+	conn2, err := dialer.DialContext(context.Background(), "tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn2.Close() }()
+
+	defer func() { _ = conn1.Close() }()
+
+	id1, ok := GetConnID(conn1)
+	require.True(t, ok)
+	id2, ok := GetConnID(conn2)
+	require.True(t, ok)
+	assert.NotEqual(t, id1, id2)
+
+	// the ID can be found through the TLS connections that wrap the conns
+	tlsID, ok := GetConnID(tls.Client(conn2, &tls.Config{})) //nolint:gosec
+	require.True(t, ok)
+	assert.Equal(t, id2, tlsID)
+	_, ok = GetConnID(&net.TCPConn{})
+	assert.False(t, ok)
+
+	tags := map[string]string{}
+	systemTags := metrics.TagLocalPort | metrics.TagConnID
+	SetConnTags(tags, &systemTags, conn2.LocalAddr(), id2)
+	_, port, err := net.SplitHostPort(conn2.LocalAddr().String())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"local_port": port, "conn_id": strconv.FormatUint(id2, 10)}, tags)
+
+	tags = map[string]string{}
+	SetConnTags(tags, &systemTags, nil, 0)
+	assert.Empty(t, tags)
+	SetConnTags(tags, &metrics.DefaultSystemTagSet, conn2.LocalAddr(), id2)
+	assert.Empty(t, tags)
+}
@@ -0,0 +1,24 @@
+//go:build windows
+// +build windows
+
+package netext
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// soReuseUnicastPort is SO_REUSE_UNICASTPORT, which lets Windows (10 and
+// Server 2016 or newer) share the same ephemeral port between connections to
+// different destinations, instead of reserving a port for every connection
+// when it's created. This makes it much harder to run out of ephemeral ports
+// with many VUs.
+const soReuseUnicastPort = 0x3007
+
+func socketControl(_, _ string, c syscall.RawConn) error {
+	return c.Control(func(fd uintptr) {
+		// this fails on older Windows versions, which is fine
+		_ = windows.SetsockoptInt(windows.Handle(fd), windows.SOL_SOCKET, soReuseUnicastPort, 1)
+	})
+}
@@ -8,9 +8,12 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
 	"go.k6.io/k6/metrics"
 
 	protov1 "github.com/golang/protobuf/proto" //nolint:staticcheck,nolintlint // this is the old v1 version
@@ -32,6 +35,7 @@ type Request struct {
 	MethodDescriptor protoreflect.MethodDescriptor
 	Tags             map[string]string
 	Message          []byte
+	Timeout          lib.Timeout // only used for tagging, the context should have the deadline
 }
 
 // Response represents a gRPC response.
@@ -56,15 +60,26 @@ type Conn struct {
 // DefaultOptions generates an option set
 // with common options for requests from a VU.
 func DefaultOptions(vu modules.VU) []grpc.DialOption {
+	// The stats of the RPCs have only the addresses of the connection they
+	// were sent on, so the IDs of the connections of this client are kept by
+	// their local address.
+	connIDs := &sync.Map{}
 	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
-		return vu.State().Dialer.DialContext(ctx, "tcp", addr)
+		conn, err := vu.State().Dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		if id, ok := netext.GetConnID(conn); ok && conn.LocalAddr() != nil {
+			connIDs.Store(conn.LocalAddr().String(), id)
+		}
+		return conn, nil
 	}
 
 	return []grpc.DialOption{
 		grpc.WithBlock(),
 		grpc.FailOnNonTempDialError(true),
 		grpc.WithReturnConnectionError(),
-		grpc.WithStatsHandler(statsHandler{vu: vu}),
+		grpc.WithStatsHandler(statsHandler{vu: vu, connIDs: connIDs}),
 		grpc.WithContextDialer(dialer),
 	}
 }
@@ -111,6 +126,7 @@ func (c *Conn) Invoke(
 	}
 
 	ctx = withTags(ctx, req.Tags)
+	ctx = withTimeout(ctx, req.Timeout)
 
 	resp := dynamicpb.NewMessage(req.MethodDescriptor.Output())
 	header, trailer := metadata.New(nil), metadata.New(nil)
@@ -173,17 +189,28 @@ func (c *Conn) Close() error {
 }
 
 type statsHandler struct {
-	vu modules.VU
+	vu      modules.VU
+	connIDs *sync.Map
 }
 
+type connLocalAddrKey struct{}
+
 // TagConn implements the grpcstats.Handler interface
-func (statsHandler) TagConn(ctx context.Context, _ *grpcstats.ConnTagInfo) context.Context { // noop
-	return ctx
+func (statsHandler) TagConn(ctx context.Context, info *grpcstats.ConnTagInfo) context.Context {
+	if info.LocalAddr == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, connLocalAddrKey{}, info.LocalAddr.String())
 }
 
 // HandleConn implements the grpcstats.Handler interface
-func (statsHandler) HandleConn(context.Context, grpcstats.ConnStats) {
-	// noop
+func (h statsHandler) HandleConn(ctx context.Context, stat grpcstats.ConnStats) {
+	if _, ok := stat.(*grpcstats.ConnEnd); !ok {
+		return
+	}
+	if localAddr, ok := ctx.Value(connLocalAddrKey{}).(string); ok {
+		h.connIDs.Delete(localAddr)
+	}
 }
 
 // TagRPC implements the grpcstats.Handler interface
@@ -203,10 +230,28 @@ func (h statsHandler) HandleRPC(ctx context.Context, stat grpcstats.RPCStats) {
 				tags["ip"] = ip
 			}
 		}
+		// With client-side load balancing, the RPCs of a single connection
+		// can be spread between multiple backends, so the full resolved
+		// address each one was sent to can be tagged as well.
+		if state.Options.SystemTags.Has(metrics.TagBackend) && s.RemoteAddr != nil {
+			tags["backend"] = s.RemoteAddr.String()
+		}
+		var connID uint64
+		if s.LocalAddr != nil {
+			if id, ok := h.connIDs.Load(s.LocalAddr.String()); ok {
+				connID = id.(uint64) //nolint:forcetypeassert
+			}
+		}
+		netext.SetConnTags(tags, state.Options.SystemTags, s.LocalAddr, connID)
 	case *grpcstats.End:
 		if state.Options.SystemTags.Has(metrics.TagStatus) {
 			tags["status"] = strconv.Itoa(int(status.Code(s.Error)))
 		}
+		if timeout := getTimeout(ctx); status.Code(s.Error) == codes.DeadlineExceeded && timeout.Duration > 0 {
+			for k, v := range timeout.Tags() {
+				tags[k] = v
+			}
+		}
 
 		mTags := map[string]string(tags)
 		sampleTags := metrics.IntoSampleTags(&mTags)
@@ -313,3 +358,14 @@ func getTags(ctx context.Context) reqtags {
 	}
 	return v.(reqtags)
 }
+
+type ctxKeyTimeout struct{}
+
+func withTimeout(ctx context.Context, timeout lib.Timeout) context.Context {
+	return context.WithValue(ctx, ctxKeyTimeout{}, timeout)
+}
+
+func getTimeout(ctx context.Context) lib.Timeout {
+	timeout, _ := ctx.Value(ctxKeyTimeout{}).(lib.Timeout)
+	return timeout
+}
@@ -221,5 +221,16 @@ func readResponseBody(
 		respErr = fmt.Errorf("unknown responseType %s", respType)
 	}
 
+	if state.BodyBytes != nil && result != nil {
+		var groupPath, url string
+		if state.Group != nil {
+			groupPath = state.Group.Path
+		}
+		if resp.Request != nil && resp.Request.URL != nil {
+			url = resp.Request.URL.String()
+		}
+		state.BodyBytes.Add(int64(buf.Len()), groupPath, url)
+	}
+
 	return result, respErr
 }
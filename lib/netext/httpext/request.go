@@ -63,6 +63,7 @@ type ParsedHTTPRequest struct {
 	Body             *bytes.Buffer
 	Req              *http.Request
 	Timeout          time.Duration
+	TimeoutSource    lib.TimeoutSource
 	Auth             string
 	Throw            bool
 	ResponseType     ResponseType
@@ -72,6 +73,11 @@ type ParsedHTTPRequest struct {
 	ActiveJar        *cookiejar.Jar
 	Cookies          map[string]*HTTPRequestCookie
 	Tags             map[string]string
+	Route            Route
+	Transport        http.RoundTripper // the transport for the Route, instead of the one of the VU
+	// NoHTTPReqMetrics disables the http_reqs, http_req_* and http_req_failed samples of the request,
+	// for the requests whose durations are measured by their own metrics, like the long-polls.
+	NoHTTPReqMetrics bool
 }
 
 // Matches non-compliant io.Closer implementations (e.g. zstd.Decoder)
@@ -193,6 +199,10 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		tags[k] = v
 	}
 
+	preq.Route.addTags(tags, state.Options.SystemTags)
+
+	state.Options.Latency.SetRegionTag(tags, preq.Req.URL.Hostname())
+
 	// Only set the name system tag if the user didn't explicitly set it beforehand,
 	// and the Name was generated from a tagged template string (via http.url).
 	if _, ok := tags["name"]; !ok && state.Options.SystemTags.Has(metrics.TagName) &&
@@ -208,6 +218,11 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 	}
 
 	tracerTransport := newTransport(ctx, state, tags, preq.ResponseCallback)
+	tracerTransport.timeout = lib.Timeout{Duration: preq.Timeout, Source: preq.TimeoutSource}
+	tracerTransport.noHTTPReqMetrics = preq.NoHTTPReqMetrics
+	if preq.Transport != nil {
+		tracerTransport.roundTripper = preq.Transport
+	}
 	var transport http.RoundTripper = tracerTransport
 
 	// Combine tags with common log fields
@@ -353,7 +368,13 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		select {
 		case <-ctx.Done():
 		default:
-			state.Logger.WithField("error", resErr).Warn("Request Failed")
+			logger := state.Logger.WithField("error", resErr)
+			if code, _ := errorCodeForError(resErr); code == requestTimeoutErrorCode {
+				for k, v := range tracerTransport.timeout.Tags() {
+					logger = logger.WithField(k, v)
+				}
+			}
+			logger.Warn("Request Failed")
 		}
 	}
 
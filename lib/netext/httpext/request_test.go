@@ -287,6 +287,7 @@ func TestMakeRequestTimeoutInTheMiddle(t *testing.T) {
 		URL:              &URL{u: req.URL, URL: srv.URL},
 		Body:             new(bytes.Buffer),
 		Timeout:          50 * time.Millisecond,
+		TimeoutSource:    lib.TimeoutSourceGroup,
 		ResponseCallback: func(i int) bool { return i == 0 },
 	}
 
@@ -305,6 +306,9 @@ func TestMakeRequestTimeoutInTheMiddle(t *testing.T) {
 		"method":            "GET",
 		"url":               srv.URL,
 		"name":              srv.URL,
+
+		"timeout":           "50ms",
+		"timeout_source":    "group",
 	}
 	for _, s := range allSamples {
 		assert.Equal(t, expTags, s.Tags.CloneTags())
@@ -503,8 +507,48 @@ func TestMakeRequestTimeoutInTheBegining(t *testing.T) {
 		"method":            "GET",
 		"url":               srv.URL,
 		"name":              srv.URL,
+
+		"timeout":           "50ms",
 	}
 	for _, s := range allSamples {
 		assert.Equal(t, expTags, s.Tags.CloneTags())
 	}
 }
+
+func TestMakeRequestSimulatedRegion(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+	samples := make(chan metrics.SampleContainer, 10)
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Options: lib.Options{
+			RunTags:    &metrics.SampleTags{},
+			SystemTags: &metrics.DefaultSystemTagSet,
+			Latency:    lib.HostLatencies{"127.0.0.1": {Region: "eu-west"}},
+		},
+		Transport:      srv.Client().Transport,
+		Samples:        samples,
+		Logger:         logrus.New(),
+		BPool:          bpool.NewBufferPool(100),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewTagMap(nil),
+	}
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	preq := &ParsedHTTPRequest{
+		Req:  req,
+		URL:  &URL{u: req.URL, URL: srv.URL},
+		Body: new(bytes.Buffer),
+	}
+
+	_, err := MakeRequest(context.Background(), state, preq)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	for _, s := range (<-samples).GetSamples() {
+		region, ok := s.Tags.Get(lib.SimulatedRegionTag)
+		assert.True(t, ok)
+		assert.Equal(t, "eu-west", region)
+	}
+}
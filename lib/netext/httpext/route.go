@@ -0,0 +1,148 @@
+package httpext
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http2"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// Route overrides where a request is sent, independently of its URL and Host
+// header. This allows testing virtual hosts and services behind a service
+// mesh or a load balancer without changing the DNS or the hosts option.
+//
+// The redirects to other hosts aren't sent to ConnectTo, but they are sent
+// with the SNI of the original request, since the TLS server name can't be
+// set per host.
+type Route struct {
+	SNI       string // the TLS server name, instead of the URL host
+	ConnectTo string // a host:port or a host to connect to, instead of the URL host
+}
+
+// IsZero returns true if the Route doesn't override anything.
+func (r Route) IsZero() bool {
+	return r == Route{}
+}
+
+// addTags adds the enabled tags of the Route which weren't already set.
+func (r Route) addTags(tags map[string]string, enabledTags *metrics.SystemTagSet) {
+	for tag, value := range map[metrics.SystemTagSet]string{metrics.TagSNI: r.SNI, metrics.TagConnectTo: r.ConnectTo} {
+		if _, ok := tags[tag.String()]; !ok && value != "" && enabledTags.Has(tag) {
+			tags[tag.String()] = value
+		}
+	}
+}
+
+// urlAddress returns the host:port address of the URL, the same way as
+// http.Transport does when it dials the connections for it.
+func urlAddress(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// connectAddress returns the address to connect to instead of addr, which
+// keeps its port if ConnectTo doesn't have one.
+func (r Route) connectAddress(addr string) string {
+	if _, _, err := net.SplitHostPort(r.ConnectTo); err == nil {
+		return r.ConnectTo
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return r.ConnectTo
+	}
+	return net.JoinHostPort(r.ConnectTo, port)
+}
+
+// RoutedTransports creates and caches the transports for the requests of a VU
+// which have a Route. The idle connections of a transport are pooled only by
+// their URL host, so the requests with different routes can't share one.
+// Their idle connections are closed by the VU, like the ones of its transport.
+type RoutedTransports struct {
+	transports map[routeKey]*http.Transport
+}
+
+// routeKey identifies the transport of a route. The ConnectTo of a Route
+// applies only to the host of the original request, so the transports of the
+// routes with one are for a single origin.
+type routeKey struct {
+	route  Route
+	origin string
+}
+
+// Get returns the transport for the route of a request to the origin URL,
+// based on the transport of the VU. It should only be called from the
+// goroutine of the VU.
+func (rt *RoutedTransports) Get(state *lib.State, route Route, origin *url.URL) (*http.Transport, error) {
+	key := routeKey{route: route}
+	if route.ConnectTo != "" {
+		key.origin = urlAddress(origin)
+	}
+	if t, ok := rt.transports[key]; ok {
+		return t, nil
+	}
+
+	base, ok := state.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("the sni and connectTo options aren't supported with the %T transport", state.Transport)
+	}
+	t := base.Clone()
+	if route.SNI != "" {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{} //nolint:gosec
+		}
+		t.TLSClientConfig.ServerName = route.SNI
+	}
+	if route.ConnectTo != "" {
+		dial := base.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if addr == key.origin {
+				addr = route.connectAddress(addr)
+			}
+			return dial(ctx, network, addr)
+		}
+		// otherwise the connection to the proxy would be redirected
+		if proxy := base.Proxy; proxy != nil {
+			t.Proxy = func(req *http.Request) (*url.URL, error) {
+				if urlAddress(req.URL) == key.origin {
+					return nil, nil //nolint:nilnil
+				}
+				return proxy(req)
+			}
+		}
+	}
+	// The HTTP/2 connections are pooled by the http2.Transport the base one
+	// was configured with, so the clone needs its own.
+	if _, ok := base.TLSNextProto["h2"]; ok {
+		t.TLSNextProto = nil
+		if err := http2.ConfigureTransport(t); err != nil {
+			return nil, err
+		}
+	}
+
+	if rt.transports == nil {
+		rt.transports = make(map[routeKey]*http.Transport)
+		state.IdleConnectionClosers = append(state.IdleConnectionClosers, rt)
+	}
+	rt.transports[key] = t
+	return t, nil
+}
+
+// CloseIdleConnections closes the idle connections of all transports.
+func (rt *RoutedTransports) CloseIdleConnections() {
+	for _, t := range rt.transports {
+		t.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,127 @@
+package httpext
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestRouteConnectAddress(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "10.0.0.1:8443", Route{ConnectTo: "10.0.0.1:8443"}.connectAddress("example.com:443"))
+	assert.Equal(t, "10.0.0.1:443", Route{ConnectTo: "10.0.0.1"}.connectAddress("example.com:443"))
+	assert.Equal(t, "[::1]:443", Route{ConnectTo: "::1"}.connectAddress("example.com:443"))
+}
+
+func TestRouteTags(t *testing.T) {
+	t.Parallel()
+
+	sni, connectTo := metrics.TagSNI.String(), metrics.TagConnectTo.String()
+	route := Route{SNI: "example.com", ConnectTo: "10.0.0.1"}
+
+	tags := map[string]string{sni: "custom"}
+	route.addTags(tags, &metrics.DefaultSystemTagSet)
+	assert.Equal(t, map[string]string{sni: "custom", connectTo: "10.0.0.1"}, tags)
+
+	tags = map[string]string{}
+	route.addTags(tags, metrics.ToSystemTagSet([]string{sni}))
+	assert.Equal(t, map[string]string{sni: "example.com"}, tags)
+
+	tags = map[string]string{}
+	Route{}.addTags(tags, &metrics.DefaultSystemTagSet)
+	assert.Empty(t, tags)
+}
+
+func TestURLAddress(t *testing.T) {
+	t.Parallel()
+
+	for rawURL, addr := range map[string]string{
+		"http://example.com/path":   "example.com:80",
+		"https://example.com/path":  "example.com:443",
+		"https://example.com:8443/": "example.com:8443",
+		"http://[::1]/":             "[::1]:80",
+	} {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		assert.Equal(t, addr, urlAddress(u), rawURL)
+	}
+}
+
+func TestRoutedTransports(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+	require.NoError(t, http2.ConfigureTransport(base))
+	state := &lib.State{Transport: base}
+	routes := &RoutedTransports{}
+	origin, err := url.Parse("https://example.com/path")
+	require.NoError(t, err)
+	otherOrigin, err := url.Parse("https://example.com:8443/path")
+	require.NoError(t, err)
+
+	route := Route{SNI: "example.com", ConnectTo: "10.0.0.1"}
+	transport, err := routes.Get(state, route, origin)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", transport.TLSClientConfig.ServerName)
+	assert.Empty(t, base.TLSClientConfig.ServerName)
+	assert.Contains(t, transport.TLSNextProto, "h2")
+	assert.Equal(t, []lib.IdleConnectionCloser{routes}, state.IdleConnectionClosers)
+
+	cached, err := routes.Get(state, route, origin)
+	require.NoError(t, err)
+	assert.Same(t, transport, cached)
+	forOtherOrigin, err := routes.Get(state, route, otherOrigin)
+	require.NoError(t, err)
+	assert.NotSame(t, transport, forOtherOrigin)
+	sniOnly, err := routes.Get(state, Route{SNI: "example.com"}, origin)
+	require.NoError(t, err)
+	assert.NotSame(t, transport, sniOnly)
+	sniOnlyForOtherOrigin, err := routes.Get(state, Route{SNI: "example.com"}, otherOrigin)
+	require.NoError(t, err)
+	assert.Same(t, sniOnly, sniOnlyForOtherOrigin)
+	assert.Len(t, state.IdleConnectionClosers, 1)
+
+	_, err = (&RoutedTransports{}).Get(&lib.State{Transport: digestTransport{originalTransport: base}}, route, origin)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the sni and connectTo options aren't supported with the httpext.digestTransport transport")
+}
+
+func TestRoutedTransportsDialOnlyOrigin(t *testing.T) {
+	t.Parallel()
+
+	var dialed []string
+	base := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			return nil, net.ErrClosed
+		},
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse("http://proxy.test:3128")
+		},
+	}
+	origin, err := url.Parse("http://example.com/path")
+	require.NoError(t, err)
+	transport, err := (&RoutedTransports{}).Get(&lib.State{Transport: base}, Route{ConnectTo: "10.0.0.1"}, origin)
+	require.NoError(t, err)
+
+	_, _ = transport.DialContext(context.Background(), "tcp", "example.com:80")
+	_, _ = transport.DialContext(context.Background(), "tcp", "other.test:80")
+	assert.Equal(t, []string{"10.0.0.1:80", "other.test:80"}, dialed)
+
+	proxy, err := transport.Proxy(&http.Request{URL: origin})
+	require.NoError(t, err)
+	assert.Nil(t, proxy)
+	proxy, err = transport.Proxy(&http.Request{URL: &url.URL{Scheme: "http", Host: "other.test"}})
+	require.NoError(t, err)
+	assert.Equal(t, "proxy.test:3128", proxy.Host)
+}
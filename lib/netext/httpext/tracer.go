@@ -27,6 +27,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"go.k6.io/k6/lib/netext"
 	"go.k6.io/k6/metrics"
 	"gopkg.in/guregu/null.v3"
 )
@@ -52,6 +53,8 @@ type Trail struct {
 	// Detailed connection information.
 	ConnReused     bool
 	ConnRemoteAddr net.Addr
+	ConnLocalAddr  net.Addr
+	ConnID         uint64 // zero if unknown
 
 	Failed null.Bool
 	// Populated by SaveSamples()
@@ -73,6 +76,7 @@ func (tr *Trail) SaveSamples(builtinMetrics *metrics.BuiltinMetrics, tags *metri
 		{Metric: builtinMetrics.HTTPReqWaiting, Time: tr.EndTime, Tags: tags, Value: metrics.D(tr.Waiting)},
 		{Metric: builtinMetrics.HTTPReqReceiving, Time: tr.EndTime, Tags: tags, Value: metrics.D(tr.Receiving)},
 	}...)
+	tr.Samples = metrics.WithoutDisabled(tr.Samples)
 }
 
 // GetSamples implements the metrics.SampleContainer interface.
@@ -110,6 +114,8 @@ type Tracer struct {
 
 	connReused     bool
 	connRemoteAddr net.Addr
+	connLocalAddr  net.Addr
+	connID         uint64
 }
 
 // Trace returns a premade ClientTrace that calls all of the Tracer's hooks.
@@ -215,6 +221,8 @@ func (t *Tracer) GotConn(info httptrace.GotConnInfo) {
 	t.gotConn = now
 	t.connReused = info.Reused
 	t.connRemoteAddr = info.Conn.RemoteAddr()
+	t.connLocalAddr = info.Conn.LocalAddr()
+	t.connID, _ = netext.GetConnID(info.Conn)
 
 	// The Go stdlib's http module can start connecting to a remote server, only
 	// to abandon that connection even before it was fully established and reuse
@@ -272,6 +280,8 @@ func (t *Tracer) Done() *Trail {
 	trail := Trail{
 		ConnReused:     t.connReused,
 		ConnRemoteAddr: t.connRemoteAddr,
+		ConnLocalAddr:  t.connLocalAddr,
+		ConnID:         t.connID,
 	}
 
 	if t.gotConn != 0 && t.getConn != 0 && t.gotConn > t.getConn {
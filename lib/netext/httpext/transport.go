@@ -41,6 +41,9 @@ type transport struct {
 	state            *lib.State
 	tags             map[string]string
 	responseCallback func(int) bool
+	timeout          lib.Timeout       // the effective timeout, for tagging the timed out requests
+	roundTripper     http.RoundTripper // the VU's transport, unless the request has a Route
+	noHTTPReqMetrics bool              // whether the http_req_* samples shouldn't be emitted
 
 	lastRequest     *unfinishedRequest
 	lastRequestLock *sync.Mutex
@@ -85,6 +88,7 @@ func newTransport(
 		state:            state,
 		tags:             tags,
 		responseCallback: responseCallback,
+		roundTripper:     state.Transport,
 		lastRequestLock:  new(sync.Mutex),
 	}
 }
@@ -138,6 +142,12 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 		if enabledTags.Has(metrics.TagStatus) {
 			tags["status"] = "0"
 		}
+
+		if result.errorCode == requestTimeoutErrorCode && t.timeout.Duration > 0 {
+			for k, v := range t.timeout.Tags() {
+				tags[k] = v
+			}
+		}
 	} else {
 		if enabledTags.Has(metrics.TagStatus) {
 			tags["status"] = strconv.Itoa(unfReq.response.StatusCode)
@@ -160,6 +170,9 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 			if enabledTags.Has(metrics.TagOCSPStatus) {
 				tags["ocsp_status"] = oscp.Status
 			}
+			if enabledTags.Has(metrics.TagTLSSessionReused) {
+				tags[metrics.TagTLSSessionReused.String()] = strconv.FormatBool(unfReq.response.TLS.DidResume)
+			}
 			result.tlsInfo = tlsInfo
 		}
 	}
@@ -168,6 +181,7 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 			tags["ip"] = ip
 		}
 	}
+	netext.SetConnTags(tags, enabledTags, trail.ConnLocalAddr, trail.ConnID)
 	var failed float64
 	if t.responseCallback != nil {
 		var statusCode int
@@ -186,19 +200,27 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 
 	finalTags := metrics.IntoSampleTags(&tags)
 	builtinMetrics := t.state.BuiltinMetrics
-	trail.SaveSamples(builtinMetrics, finalTags)
+	if t.noHTTPReqMetrics {
+		trail.Tags = finalTags
+	} else {
+		trail.SaveSamples(builtinMetrics, finalTags)
+	}
 	if t.responseCallback != nil {
 		trail.Failed.Valid = true
 		if failed == 1 {
 			trail.Failed.Bool = true
 		}
-		trail.Samples = append(trail.Samples,
-			metrics.Sample{
-				Metric: builtinMetrics.HTTPReqFailed, Time: trail.EndTime, Tags: finalTags, Value: failed,
-			},
-		)
+		if !builtinMetrics.HTTPReqFailed.Disabled && !t.noHTTPReqMetrics {
+			trail.Samples = append(trail.Samples,
+				metrics.Sample{
+					Metric: builtinMetrics.HTTPReqFailed, Time: trail.EndTime, Tags: finalTags, Value: failed,
+				},
+			)
+		}
+	}
+	if !t.noHTTPReqMetrics {
+		metrics.PushIfNotDone(t.ctx, t.state.Samples, trail)
 	}
-	metrics.PushIfNotDone(t.ctx, t.state.Samples, trail)
 
 	return result
 }
@@ -242,7 +264,7 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
 	tracer := &Tracer{}
 	reqWithTracer := req.WithContext(httptrace.WithClientTrace(ctx, tracer.Trace()))
-	resp, err := t.state.Transport.RoundTrip(reqWithTracer)
+	resp, err := t.roundTripper.RoundTrip(reqWithTracer)
 
 	var netError net.Error
 	if errors.As(err, &netError) && netError.Timeout() {
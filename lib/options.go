@@ -29,6 +29,7 @@ import (
 	"net"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
@@ -314,6 +315,28 @@ func ParseCIDR(s string) (*IPNet, error) {
 	return &parsedIPNet, nil
 }
 
+// Metadata contains the key-value pairs describing a test run.
+type Metadata map[string]string
+
+// Decode parses the comma-separated key=value pairs of the K6_METADATA
+// environment variable, like the ones of the --metadata flag. It's used by
+// envconfig instead of its own key:value format for maps.
+func (m *Metadata) Decode(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	result := make(Metadata)
+	for _, pair := range strings.Split(value, ",") {
+		idx := strings.IndexRune(pair, '=')
+		if idx <= 0 || idx == len(pair)-1 {
+			return fmt.Errorf("invalid metadata '%s', it should be in the key=value format", pair)
+		}
+		result[pair[:idx]] = pair[idx+1:]
+	}
+	*m = result
+	return nil
+}
+
 type Options struct {
 	// Should the test start in a paused state?
 	Paused null.Bool `json:"paused" envconfig:"K6_PAUSED"`
@@ -341,6 +364,10 @@ type Options struct {
 	NoTeardown      null.Bool          `json:"noTeardown" envconfig:"K6_NO_TEARDOWN"`
 	TeardownTimeout types.NullDuration `json:"teardownTimeout" envconfig:"K6_TEARDOWN_TIMEOUT"`
 
+	// Check that the init code of the first and last VUs can be executed,
+	// before initializing any of the VUs.
+	Preflight null.Bool `json:"preflight" envconfig:"K6_PREFLIGHT"`
+
 	// Limit HTTP requests per second.
 	RPS null.Int `json:"rps" envconfig:"K6_RPS"`
 
@@ -385,6 +412,10 @@ type Options struct {
 	// Hosts overrides dns entries for given hosts
 	Hosts map[string]*HostAddress `json:"hosts" envconfig:"K6_HOSTS"`
 
+	// Artificial round-trip times added to the connections to the given hosts,
+	// optionally tagging their requests with a simulated region.
+	Latency HostLatencies `json:"latency" envconfig:"K6_LATENCY"`
+
 	// Disable keep-alive connections
 	NoConnectionReuse null.Bool `json:"noConnectionReuse" envconfig:"K6_NO_CONNECTION_REUSE"`
 
@@ -397,6 +428,9 @@ type Options struct {
 	// iteration is shorter than the specified value.
 	MinIterationDuration types.NullDuration `json:"minIterationDuration" envconfig:"K6_MIN_ITERATION_DURATION"`
 
+	// Prices for estimating the cost of the test run; can't be set through env vars.
+	Cost *CostModel `json:"cost" ignored:"true"`
+
 	// These values are for third party collectors' benefit.
 	// Can't be set through env vars.
 	External map[string]json.RawMessage `json:"ext" ignored:"true"`
@@ -407,6 +441,15 @@ type Options struct {
 	// Summary time unit for summary metrics (response times) in CLI output
 	SummaryTimeUnit null.String `json:"summaryTimeUnit" envconfig:"K6_SUMMARY_TIME_UNIT"`
 
+	// Show only this many of the worst sub-metrics of every metric in the
+	// summary. The rates are ranked by the values their rate thresholds limit,
+	// e.g. the true values for rate<0.01, and by the false values without any
+	// thresholds, except for http_req_failed, whose true values are failures.
+	SummaryTopSubmetrics null.Int `json:"summaryTopSubmetrics" envconfig:"K6_SUMMARY_TOP_SUBMETRICS"`
+
+	// Show all groups in the SLA table of the summary, not only the ones with thresholds
+	SummaryTransactions null.Bool `json:"summaryTransactions" envconfig:"K6_SUMMARY_TRANSACTIONS"`
+
 	// Which system tags to include with metrics ("method", "vu" etc.)
 	// Use pointer for identifying whether user provide any tag or not.
 	SystemTags *metrics.SystemTagSet `json:"systemTags" envconfig:"K6_SYSTEM_TAGS"`
@@ -414,6 +457,13 @@ type Options struct {
 	// Tags to be applied to all samples for this running
 	RunTags *metrics.SampleTags `json:"tags" envconfig:"K6_TAGS"`
 
+	// Arbitrary key-value pairs describing the test run, e.g. the tested
+	// build, which are included in the summary and the outputs
+	Metadata Metadata `json:"metadata" envconfig:"K6_METADATA"`
+
+	// Built-in metrics that shouldn't emit any samples at all
+	DisabledMetrics []string `json:"disabledMetrics" envconfig:"K6_DISABLED_METRICS"`
+
 	// Buffer size of the channel for metric samples; 0 means unbuffered
 	MetricSamplesBufferSize null.Int `json:"metricSamplesBufferSize" envconfig:"K6_METRIC_SAMPLES_BUFFER_SIZE"`
 
@@ -423,9 +473,18 @@ type Options struct {
 	// Discard Http Responses Body
 	DiscardResponseBodies null.Bool `json:"discardResponseBodies" envconfig:"K6_DISCARD_RESPONSE_BODIES"`
 
+	// Warn about iterations that allocated more than this number of response body bytes; 0 disables it
+	IterationBodyBytesBudget null.Int `json:"iterationBodyBytesBudget" envconfig:"K6_ITERATION_BODY_BYTES_BUDGET"`
+
+	// Emit the breakdown of every iteration into protocol, sleep and script time
+	IterationBreakdown null.Bool `json:"iterationBreakdown" envconfig:"K6_ITERATION_BREAKDOWN"`
+
 	// Redirect console logging to a file
 	ConsoleOutput null.String `json:"-" envconfig:"K6_CONSOLE_OUTPUT"`
 
+	// Write the structured script logs of k6/execution to a separate file
+	ScriptLogOutput null.String `json:"-" envconfig:"K6_SCRIPT_LOG_OUTPUT"`
+
 	// Specify client IP ranges and/or CIDR from which VUs will make requests
 	LocalIPs types.NullIPPool `json:"-" envconfig:"K6_LOCAL_IPS"`
 }
@@ -498,6 +557,9 @@ func (o Options) Apply(opts Options) Options {
 	if opts.TeardownTimeout.Valid {
 		o.TeardownTimeout = opts.TeardownTimeout
 	}
+	if opts.Preflight.Valid {
+		o.Preflight = opts.Preflight
+	}
 	if opts.RPS.Valid {
 		o.RPS = opts.RPS
 	}
@@ -543,6 +605,9 @@ func (o Options) Apply(opts Options) Options {
 	if opts.Hosts != nil {
 		o.Hosts = opts.Hosts
 	}
+	if opts.Latency != nil {
+		o.Latency = opts.Latency
+	}
 	if opts.NoConnectionReuse.Valid {
 		o.NoConnectionReuse = opts.NoConnectionReuse
 	}
@@ -564,9 +629,21 @@ func (o Options) Apply(opts Options) Options {
 	if opts.SummaryTimeUnit.Valid {
 		o.SummaryTimeUnit = opts.SummaryTimeUnit
 	}
+	if opts.SummaryTopSubmetrics.Valid {
+		o.SummaryTopSubmetrics = opts.SummaryTopSubmetrics
+	}
+	if opts.SummaryTransactions.Valid {
+		o.SummaryTransactions = opts.SummaryTransactions
+	}
 	if opts.SystemTags != nil {
 		o.SystemTags = opts.SystemTags
 	}
+	if opts.Metadata != nil {
+		o.Metadata = opts.Metadata
+	}
+	if opts.DisabledMetrics != nil {
+		o.DisabledMetrics = opts.DisabledMetrics
+	}
 	if !opts.RunTags.IsEmpty() {
 		o.RunTags = opts.RunTags
 	}
@@ -576,12 +653,24 @@ func (o Options) Apply(opts Options) Options {
 	if opts.DiscardResponseBodies.Valid {
 		o.DiscardResponseBodies = opts.DiscardResponseBodies
 	}
+	if opts.IterationBodyBytesBudget.Valid {
+		o.IterationBodyBytesBudget = opts.IterationBodyBytesBudget
+	}
+	if opts.IterationBreakdown.Valid {
+		o.IterationBreakdown = opts.IterationBreakdown
+	}
 	if opts.ConsoleOutput.Valid {
 		o.ConsoleOutput = opts.ConsoleOutput
 	}
+	if opts.ScriptLogOutput.Valid {
+		o.ScriptLogOutput = opts.ScriptLogOutput
+	}
 	if opts.LocalIPs.Valid {
 		o.LocalIPs = opts.LocalIPs
 	}
+	if opts.Cost != nil {
+		o.Cost = opts.Cost
+	}
 	if opts.DNS.TTL.Valid {
 		o.DNS.TTL = opts.DNS.TTL
 	}
@@ -614,6 +703,14 @@ func (o Options) Validate() []error {
 					o.ExecutionSegment, o.ExecutionSegmentSequence))
 		}
 	}
+	if o.Cost != nil {
+		if err := o.Cost.Validate(); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if o.SummaryTopSubmetrics.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("summaryTopSubmetrics can't be negative"))
+	}
 	return append(errors, o.Scenarios.Validate()...)
 }
 
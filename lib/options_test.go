@@ -487,6 +487,15 @@ func TestOptions(t *testing.T) {
 		assert.Equal(t, "192.0.2.1:80", opts.Hosts["test.loadimpact.com"].String())
 	})
 
+	t.Run("Latency", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{Latency: HostLatencies{
+			"eu-api.example.com": {RTT: 80 * time.Millisecond, Jitter: 10 * time.Millisecond, Region: "eu"},
+		}})
+		assert.Equal(t, "80ms±10ms", opts.Latency["eu-api.example.com"].String())
+		assert.Equal(t, "eu", opts.Latency.Region("eu-api.example.com"))
+	})
+
 	t.Run("Throws", func(t *testing.T) {
 		t.Parallel()
 		opts := Options{}.Apply(Options{Throw: null.BoolFrom(true)})
@@ -572,6 +581,33 @@ func TestOptions(t *testing.T) {
 		assert.True(t, opts.DiscardResponseBodies.Valid)
 		assert.True(t, opts.DiscardResponseBodies.Bool)
 	})
+	t.Run("IterationBodyBytesBudget", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{IterationBodyBytesBudget: null.IntFrom(1024)})
+		assert.True(t, opts.IterationBodyBytesBudget.Valid)
+		assert.Equal(t, int64(1024), opts.IterationBodyBytesBudget.Int64)
+	})
+	t.Run("IterationBreakdown", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{IterationBreakdown: null.BoolFrom(true)})
+		assert.True(t, opts.IterationBreakdown.Valid)
+		assert.True(t, opts.IterationBreakdown.Bool)
+	})
+	t.Run("Cost", func(t *testing.T) {
+		t.Parallel()
+		var opts Options
+		require.NoError(t, json.Unmarshal([]byte(`{"cost": {"perRequest": 0.001, "perVUMinute": 0.05}}`), &opts))
+		opts = Options{}.Apply(opts)
+		require.NotNil(t, opts.Cost)
+		assert.True(t, opts.Cost.IsEnabled())
+		assert.Equal(t, CostModel{PerRequest: null.FloatFrom(0.001), PerVUMinute: null.FloatFrom(0.05)}, *opts.Cost)
+		assert.Empty(t, opts.Validate())
+
+		opts.Cost.PerGBEgress = null.FloatFrom(-1)
+		errs := opts.Validate()
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "perGBEgress can't be negative")
+	})
 	t.Run("ClientIPRanges", func(t *testing.T) {
 		t.Parallel()
 		clientIPRanges := types.NullIPPool{}
@@ -675,6 +711,12 @@ func TestOptionsEnv(t *testing.T) {
 			"true":  null.BoolFrom(true),
 			"false": null.BoolFrom(false),
 		},
+		{"Metadata", "K6_METADATA"}: {
+			"":                      Metadata(nil),
+			"build=1234":            Metadata{"build": "1234"},
+			"build=1234,team=a=b":   Metadata{"build": "1234", "team": "a=b"},
+			"url=http://test.k6.io": Metadata{"url": "http://test.k6.io"},
+		},
 		// Thresholds
 		// External
 	}
@@ -700,6 +742,18 @@ func TestOptionsEnv(t *testing.T) {
 	}
 }
 
+func TestMetadataDecode(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"build", "=1234", "build=", "build=1234,,team=perf"} {
+		var metadata Metadata
+		err := metadata.Decode(value)
+		require.Error(t, err, value)
+		assert.Contains(t, err.Error(), "it should be in the key=value format")
+		assert.Nil(t, metadata)
+	}
+}
+
 func TestCIDRUnmarshal(t *testing.T) {
 	t.Parallel()
 	testData := []struct {
@@ -0,0 +1,67 @@
+package preflight
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// vusPerCPU is the number of running VUs per CPU over which they are likely to
+// wait for a CPU long enough to noticeably skew the measured latencies.
+const vusPerCPU = 1000
+
+// ParseCPUList parses a list of CPU numbers and ranges in the format used by
+// Linux, e.g. `0-7,16-23`. The returned CPUs are sorted and unique.
+func ParseCPUList(s string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		first, last := part, part
+		if idx := strings.Index(part, "-"); idx >= 0 {
+			first, last = part[:idx], part[idx+1:]
+		}
+		from, err := strconv.ParseUint(first, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU '%s' in '%s'", first, s)
+		}
+		to, err := strconv.ParseUint(last, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU '%s' in '%s'", last, s)
+		}
+		if to < from {
+			return nil, fmt.Errorf("invalid CPU range '%s' in '%s'", part, s)
+		}
+		for cpu := from; cpu <= to; cpu++ {
+			seen[int(cpu)] = true
+		}
+	}
+
+	cpus := make([]int, 0, len(seen))
+	for cpu := range seen {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// UsableCPUs returns how many CPUs can execute the VUs at the same time, which
+// is the GOMAXPROCS value, unless the CPU quota of the container is lower.
+func UsableCPUs(gomaxprocs int, containerCPUs float64) int {
+	if quota := int(math.Ceil(containerCPUs)); quota > 0 && quota < gomaxprocs {
+		return quota
+	}
+	return gomaxprocs
+}
+
+// CheckCPUs compares the number of CPUs the VUs can run on with the given
+// number of VUs. If maxVUs is 0, the CPUs are only reported.
+func CheckCPUs(cpus int, maxVUs uint64) Diagnostic {
+	d := Diagnostic{Name: "number of usable CPUs", Value: strconv.Itoa(cpus)}
+	if cpus > 0 && maxVUs > uint64(cpus)*vusPerCPU {
+		d.Warning = fmt.Sprintf("%d VUs may often wait for a CPU, which makes the measured latencies "+
+			"less precise, use more CPUs with --gomaxprocs or split the test with --execution-segment", maxVUs)
+	}
+	return d
+}
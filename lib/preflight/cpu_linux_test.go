@@ -0,0 +1,22 @@
+package preflight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUAffinity(t *testing.T) { //nolint:paralleltest // it changes the affinity of the whole process
+	cpus, err := GetCPUAffinity()
+	require.NoError(t, err)
+	require.NotEmpty(t, cpus)
+	defer func() {
+		require.NoError(t, SetCPUAffinity(cpus))
+	}()
+
+	require.NoError(t, SetCPUAffinity(cpus[:1]))
+	restricted, err := GetCPUAffinity()
+	require.NoError(t, err)
+	assert.Equal(t, cpus[:1], restricted)
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+package preflight
+
+import "errors"
+
+var errCPUAffinityUnsupported = errors.New("setting the CPU affinity is supported only on Linux")
+
+// GetCPUAffinity isn't supported outside of Linux.
+func GetCPUAffinity() ([]int, error) {
+	return nil, errCPUAffinityUnsupported
+}
+
+// SetCPUAffinity isn't supported outside of Linux.
+func SetCPUAffinity([]int) error {
+	return errCPUAffinityUnsupported
+}
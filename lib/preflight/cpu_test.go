@@ -0,0 +1,37 @@
+package preflight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPUList(t *testing.T) {
+	t.Parallel()
+
+	cpus, err := ParseCPUList("8-11, 0,2-3,10")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 3, 8, 9, 10, 11}, cpus)
+
+	for _, invalid := range []string{"", "a", "1-", "3-1", "0,,1"} {
+		_, err := ParseCPUList(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestUsableCPUs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 8, UsableCPUs(8, 0))
+	assert.Equal(t, 3, UsableCPUs(8, 2.5))
+	assert.Equal(t, 4, UsableCPUs(4, 16))
+}
+
+func TestCheckCPUs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Diagnostic{Name: "number of usable CPUs", Value: "4"}, CheckCPUs(4, 0))
+	assert.Empty(t, CheckCPUs(4, 4000).Warning)
+	assert.Contains(t, CheckCPUs(4, 4001).Warning, "4001 VUs may often wait for a CPU")
+}
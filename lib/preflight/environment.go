@@ -0,0 +1,156 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// estimatedMemoryPerVU is a rough estimate of the memory a VU with a
+	// simple script needs, bigger scripts and SharedArray-less data can
+	// easily need a lot more.
+	estimatedMemoryPerVU = 2 << 20 // 2 MiB
+
+	// slowConnectionThreshold is the connection time over which the latency
+	// to an output or a target is reported as a problem.
+	slowConnectionThreshold = 500 * time.Millisecond
+)
+
+// ClockSync is the synchronization status of the system clock.
+type ClockSync uint8
+
+// The possible ClockSync values.
+const (
+	ClockSyncUnknown ClockSync = iota
+	ClockSynchronized
+	ClockUnsynchronized
+)
+
+// Environment contains the OS limits, as well as the other properties of the
+// environment, which are relevant for running tests with many VUs. The zero
+// values mean that something is unknown or not applicable for the OS.
+type Environment struct {
+	Limits
+
+	ConntrackCount, ConntrackMax uint64 // the Linux netfilter connection tracking table
+	AvailableMemory              uint64 // in bytes
+	ClockSync                    ClockSync
+
+	// The limits of the cgroup which k6 runs in, e.g. of its container.
+	ContainerCPUs   float64
+	ContainerMemory uint64 // in bytes
+}
+
+// GetEnvironment returns the current state of the environment.
+func GetEnvironment() (Environment, error) {
+	limits, err := GetLimits()
+	if err != nil {
+		return Environment{}, err
+	}
+	env := Environment{Limits: limits, ClockSync: getClockSync()}
+	if env.ConntrackCount, env.ConntrackMax, err = getConntrack(); err != nil {
+		return Environment{}, err
+	}
+	if env.AvailableMemory, err = getAvailableMemory(); err != nil {
+		return Environment{}, err
+	}
+	env.ContainerCPUs, env.ContainerMemory = GetContainerLimits()
+	return env, nil
+}
+
+// GetContainerLimits returns the CPU quota, in CPUs, and the memory limit, in
+// bytes, of the cgroup of k6. The cgroup setups vary a lot, so the limits are
+// just unknown (0) if they can't be read, instead of preventing the checks
+// that don't need them.
+func GetContainerLimits() (cpus float64, memory uint64) {
+	cpus, memory, err := getContainerLimits()
+	if err != nil {
+		return 0, 0
+	}
+	return cpus, memory
+}
+
+// CheckEnvironment compares the environment with what the given number of VUs
+// would probably need, in addition to the checks of CheckLimits.
+func CheckEnvironment(env Environment, maxVUs uint64) []Diagnostic {
+	result := CheckLimits(env.Limits, maxVUs)
+	neededConns := maxVUs * connectionsPerVU
+
+	if env.ConntrackMax > 0 {
+		conntrack := Diagnostic{
+			Name:  "connection tracking table",
+			Value: fmt.Sprintf("%d of %d entries used", env.ConntrackCount, env.ConntrackMax),
+		}
+		if maxVUs > 0 && env.ConntrackCount+neededConns > env.ConntrackMax {
+			conntrack.Warning = fmt.Sprintf("%d VUs may need around %d more entries, and new connections are "+
+				"dropped when the table is full, raise it with `sysctl -w net.netfilter.nf_conntrack_max=%d`",
+				maxVUs, neededConns, env.ConntrackCount+2*neededConns)
+		}
+		result = append(result, conntrack)
+	}
+
+	memory := Diagnostic{Name: "available memory", Value: "unknown"}
+	if env.AvailableMemory > 0 {
+		memory.Value = fmt.Sprintf("%d MiB", env.AvailableMemory>>20)
+		if needed := maxVUs * estimatedMemoryPerVU; maxVUs > 0 && env.AvailableMemory < needed {
+			memory.Warning = fmt.Sprintf("%d VUs may need at least %d MiB, even with simple scripts, consider "+
+				"splitting the test between multiple machines with --execution-segment", maxVUs, needed>>20)
+		}
+	}
+	result = append(result, memory)
+
+	clock := Diagnostic{Name: "system clock", Value: "unknown synchronization status"}
+	switch env.ClockSync {
+	case ClockSynchronized:
+		clock.Value = "synchronized"
+	case ClockUnsynchronized:
+		clock.Value = "not synchronized"
+		clock.Warning = "the metric timestamps may be skewed compared to other machines and the outputs, " +
+			"enable NTP synchronization"
+	case ClockSyncUnknown:
+	}
+	result = append(result, clock)
+
+	return result
+}
+
+// CheckConnectivity measures the time needed to open a TCP connection to each
+// of the given addresses, in the host:port format, concurrently.
+func CheckConnectivity(ctx context.Context, addresses []string, timeout time.Duration) []Diagnostic {
+	result := make([]Diagnostic, len(addresses))
+	var wg sync.WaitGroup
+	for i, address := range addresses {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			result[i] = checkConnection(ctx, address, timeout)
+		}(i, address)
+	}
+	wg.Wait()
+	return result
+}
+
+func checkConnection(ctx context.Context, address string, timeout time.Duration) Diagnostic {
+	d := Diagnostic{Name: "connection to " + address}
+
+	dialer := net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		d.Value = "failed"
+		d.Warning = err.Error()
+		return d
+	}
+	latency := time.Since(start)
+	_ = conn.Close()
+
+	d.Value = latency.Round(time.Millisecond).String()
+	if latency > slowConnectionThreshold {
+		d.Warning = fmt.Sprintf("connecting took more than %s, the measured response times will include a "+
+			"lot of network latency", slowConnectionThreshold)
+	}
+	return d
+}
@@ -0,0 +1,165 @@
+package preflight
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// getConntrack returns the current and maximum number of entries in the
+// netfilter connection tracking table, or zeros if it isn't used.
+func getConntrack() (count, max uint64, err error) {
+	if max, err = readUintFile("/proc/sys/net/netfilter/nf_conntrack_max"); err != nil || max == 0 {
+		return 0, 0, err
+	}
+	if count, err = readUintFile("/proc/sys/net/netfilter/nf_conntrack_count"); err != nil {
+		return 0, 0, err
+	}
+	return count, max, nil
+}
+
+func readUintFile(filename string) (uint64, error) {
+	data, err := ioutil.ReadFile(filename) //nolint:gosec
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func getAvailableMemory() (uint64, error) {
+	data, err := ioutil.ReadFile("/proc/meminfo")
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseMemAvailable(data)
+}
+
+// parseMemAvailable returns the MemAvailable value from /proc/meminfo, which
+// estimates how much memory is available without swapping.
+func parseMemAvailable(meminfo []byte) (uint64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(meminfo))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "MemAvailable:" || fields[2] != "kB" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemAvailable value: %w", err)
+		}
+		return kb << 10, nil
+	}
+	return 0, scanner.Err() // old kernels don't have MemAvailable
+}
+
+// getContainerLimits returns the CPU quota, in CPUs, and the memory limit of
+// the cgroup of k6, or zeros if there aren't any.
+func getContainerLimits() (cpus float64, memory uint64, err error) {
+	return readContainerLimits("/sys/fs/cgroup")
+}
+
+// readContainerLimits is like getContainerLimits, for the cgroup filesystem
+// mounted at root.
+func readContainerLimits(root string) (cpus float64, memory uint64, err error) {
+	// cgroup v2
+	cpuMax, err := ioutil.ReadFile(filepath.Join(root, "cpu.max")) //nolint:gosec
+	if err == nil {
+		if cpus, err = parseCgroupCPUMax(string(cpuMax)); err != nil {
+			return 0, 0, err
+		}
+		memory, err = readCgroupLimit(filepath.Join(root, "memory.max"))
+		return cpus, memory, err
+	}
+	if !os.IsNotExist(err) {
+		return 0, 0, err
+	}
+
+	// cgroup v1, or no cgroup limits at all
+	quota, err := readCgroupLimit(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, 0, err
+	}
+	period, err := readCgroupLimit(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if quota > 0 && period > 0 {
+		cpus = float64(quota) / float64(period)
+	}
+	memory, err = readCgroupLimit(filepath.Join(root, "memory", "memory.limit_in_bytes"))
+	return cpus, memory, err
+}
+
+// parseCgroupCPUMax parses the `$MAX $PERIOD` format of the cgroup v2
+// cpu.max file, where $MAX is "max" if there isn't a quota.
+func parseCgroupCPUMax(cpuMax string) (float64, error) {
+	fields := strings.Fields(cpuMax)
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("unexpected cpu.max value %q", cpuMax)
+	}
+	if fields[0] == "max" {
+		return 0, nil
+	}
+	quota, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cpu.max quota: %w", err)
+	}
+	period, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil || period == 0 {
+		return 0, fmt.Errorf("invalid cpu.max period %q", fields[1])
+	}
+	return float64(quota) / float64(period), nil
+}
+
+// unlimitedCgroupValue is the value over which a cgroup v1 limit means that
+// there isn't one, it's set to the page-aligned max int64 by default.
+const unlimitedCgroupValue = 1 << 62
+
+// readCgroupLimit returns the value of a cgroup limit file, or 0 if it doesn't
+// exist or there isn't a limit.
+func readCgroupLimit(filename string) (uint64, error) {
+	data, err := ioutil.ReadFile(filename) //nolint:gosec
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "max" || strings.HasPrefix(value, "-") {
+		return 0, nil
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s: %w", value, filename, err)
+	}
+	if limit >= unlimitedCgroupValue {
+		return 0, nil
+	}
+	return limit, nil
+}
+
+func getClockSync() ClockSync {
+	var timex unix.Timex
+	state, err := unix.Adjtimex(&timex)
+	if err != nil {
+		return ClockSyncUnknown
+	}
+	if state == unix.TIME_ERROR {
+		return ClockUnsynchronized
+	}
+	return ClockSynchronized
+}
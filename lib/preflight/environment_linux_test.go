@@ -0,0 +1,104 @@
+package preflight
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMemAvailable(t *testing.T) {
+	t.Parallel()
+
+	memory, err := parseMemAvailable([]byte("MemTotal:       16318480 kB\n" +
+		"MemFree:         1077004 kB\nMemAvailable:    8657196 kB\nBuffers:          528420 kB\n"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8657196*1024), memory)
+
+	memory, err = parseMemAvailable([]byte("MemTotal:       16318480 kB\nMemFree:         1077004 kB\n"))
+	require.NoError(t, err)
+	assert.Zero(t, memory)
+
+	_, err = parseMemAvailable([]byte("MemAvailable:    lots kB\n"))
+	assert.Error(t, err)
+}
+
+func TestParseCgroupCPUMax(t *testing.T) {
+	t.Parallel()
+
+	cpus, err := parseCgroupCPUMax("150000 100000\n")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, cpus)
+
+	cpus, err = parseCgroupCPUMax("max 100000\n")
+	require.NoError(t, err)
+	assert.Zero(t, cpus)
+
+	_, err = parseCgroupCPUMax("150000 0")
+	assert.Error(t, err)
+}
+
+func TestReadCgroupLimit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for value, expected := range map[string]uint64{
+		"536870912\n":           512 << 20,
+		"max\n":                 0,
+		"-1\n":                  0,
+		"9223372036854771712\n": 0,
+	} {
+		filename := filepath.Join(dir, "limit")
+		require.NoError(t, ioutil.WriteFile(filename, []byte(value), 0o600))
+		limit, err := readCgroupLimit(filename)
+		require.NoError(t, err)
+		assert.Equal(t, expected, limit, value)
+	}
+
+	limit, err := readCgroupLimit(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Zero(t, limit)
+}
+
+func TestReadContainerLimits(t *testing.T) {
+	t.Parallel()
+
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for name, value := range files {
+			filename := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o700))
+			require.NoError(t, ioutil.WriteFile(filename, []byte(value), 0o600))
+		}
+		return dir
+	}
+
+	cpus, memory, err := readContainerLimits(writeFiles(t, map[string]string{
+		"cpu.max":    "200000 100000\n",
+		"memory.max": "1073741824\n",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, cpus)
+	assert.Equal(t, uint64(1<<30), memory)
+
+	cpus, memory, err = readContainerLimits(writeFiles(t, map[string]string{
+		"cpu/cpu.cfs_quota_us":             "50000\n",
+		"cpu/cpu.cfs_period_us":            "100000\n",
+		"memory/memory.limit_in_bytes":     "9223372036854771712\n",
+		"memory/memory.max_usage_in_bytes": "1024\n",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, cpus)
+	assert.Zero(t, memory)
+
+	cpus, memory, err = readContainerLimits(t.TempDir())
+	require.NoError(t, err)
+	assert.Zero(t, cpus)
+	assert.Zero(t, memory)
+
+	_, _, err = readContainerLimits(writeFiles(t, map[string]string{"cpu.max": "lots\n"}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package preflight
+
+// There are no cgroups like on Linux.
+func getContainerLimits() (cpus float64, memory uint64, err error) {
+	return 0, 0, nil
+}
+
+// There is no connection tracking like Linux's netfilter one.
+func getConntrack() (count, max uint64, err error) {
+	return 0, 0, nil
+}
+
+func getAvailableMemory() (uint64, error) {
+	return 0, nil
+}
+
+func getClockSync() ClockSync {
+	return ClockSyncUnknown
+}
@@ -0,0 +1,66 @@
+package preflight
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckEnvironment(t *testing.T) {
+	t.Parallel()
+
+	env := Environment{
+		Limits:          Limits{OpenFiles: 1 << 20, OpenFilesMax: 1 << 20},
+		ConntrackCount:  1000,
+		ConntrackMax:    65536,
+		AvailableMemory: 4 << 30,
+		ClockSync:       ClockSynchronized,
+	}
+	diagnostics := CheckEnvironment(env, 1000)
+	require.Len(t, diagnostics, 5)
+	assert.Equal(t, Diagnostic{Name: "connection tracking table", Value: "1000 of 65536 entries used"}, diagnostics[2])
+	assert.Equal(t, Diagnostic{Name: "available memory", Value: "4096 MiB"}, diagnostics[3])
+	assert.Equal(t, Diagnostic{Name: "system clock", Value: "synchronized"}, diagnostics[4])
+
+	env.ClockSync = ClockUnsynchronized
+	diagnostics = CheckEnvironment(env, 40000)
+	assert.Contains(t, diagnostics[2].Warning, "40000 VUs may need around 80000 more entries")
+	assert.Contains(t, diagnostics[3].Warning, "40000 VUs may need at least 80000 MiB")
+	assert.Contains(t, diagnostics[4].Warning, "enable NTP synchronization")
+
+	// the count and the maximum are read separately, so the former can be larger
+	env.ConntrackCount = 70000
+	diagnostics = CheckEnvironment(env, 1)
+	assert.Equal(t, "70000 of 65536 entries used", diagnostics[2].Value)
+	assert.Contains(t, diagnostics[2].Warning, "1 VUs may need around 2 more entries")
+
+	diagnostics = CheckEnvironment(Environment{}, 40000)
+	require.Len(t, diagnostics, 4, "the connection tracking table shouldn't be reported without netfilter")
+	assert.Equal(t, Diagnostic{Name: "available memory", Value: "unknown"}, diagnostics[2])
+	assert.Equal(t, Diagnostic{Name: "system clock", Value: "unknown synchronization status"}, diagnostics[3])
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closedListener.Addr().String()
+	require.NoError(t, closedListener.Close())
+	defer func() { _ = listener.Close() }()
+
+	diagnostics := CheckConnectivity(context.Background(),
+		[]string{listener.Addr().String(), closedAddress}, time.Second)
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, "connection to "+listener.Addr().String(), diagnostics[0].Name)
+	assert.Empty(t, diagnostics[0].Warning)
+	assert.Equal(t, "connection to "+closedAddress, diagnostics[1].Name)
+	assert.Equal(t, "failed", diagnostics[1].Value)
+	assert.NotEmpty(t, diagnostics[1].Warning)
+}
@@ -0,0 +1,47 @@
+//go:build windows
+// +build windows
+
+package preflight
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// There are no cgroups like on Linux.
+func getContainerLimits() (cpus float64, memory uint64, err error) {
+	return 0, 0, nil
+}
+
+// There is no connection tracking like Linux's netfilter one.
+func getConntrack() (count, max uint64, err error) {
+	return 0, 0, nil
+}
+
+// memoryStatusEx is the MEMORYSTATUSEX structure of GlobalMemoryStatusEx.
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+func getAvailableMemory() (uint64, error) {
+	proc := windows.NewLazySystemDLL("kernel32.dll").NewProc("GlobalMemoryStatusEx")
+	status := memoryStatusEx{}
+	status.Length = uint32(unsafe.Sizeof(status))
+	if r, _, err := proc.Call(uintptr(unsafe.Pointer(&status))); r == 0 {
+		return 0, err
+	}
+	return status.AvailPhys, nil
+}
+
+func getClockSync() ClockSync {
+	return ClockSyncUnknown
+}
@@ -0,0 +1,20 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package preflight
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func getEphemeralPorts() (EphemeralPorts, error) {
+	first, err := unix.SysctlUint32("net.inet.ip.portrange.first")
+	if err != nil {
+		return EphemeralPorts{}, nil //nolint:nilerr // the sysctl names differ between the BSDs
+	}
+	last, err := unix.SysctlUint32("net.inet.ip.portrange.last")
+	if err != nil {
+		return EphemeralPorts{}, nil //nolint:nilerr
+	}
+	return EphemeralPorts{First: uint16(first), Last: uint16(last)}, nil
+}
@@ -0,0 +1,36 @@
+package preflight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func getEphemeralPorts() (EphemeralPorts, error) {
+	data, err := ioutil.ReadFile("/proc/sys/net/ipv4/ip_local_port_range")
+	if os.IsNotExist(err) {
+		return EphemeralPorts{}, nil // e.g. in some containers
+	}
+	if err != nil {
+		return EphemeralPorts{}, err
+	}
+	return parseLinuxPortRange(string(data))
+}
+
+func parseLinuxPortRange(data string) (EphemeralPorts, error) {
+	fields := strings.Fields(data)
+	if len(fields) != 2 {
+		return EphemeralPorts{}, fmt.Errorf("unexpected ip_local_port_range value: %q", data)
+	}
+	first, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return EphemeralPorts{}, err
+	}
+	last, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return EphemeralPorts{}, err
+	}
+	return EphemeralPorts{First: uint16(first), Last: uint16(last)}, nil
+}
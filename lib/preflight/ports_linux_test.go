@@ -0,0 +1,21 @@
+package preflight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinuxPortRange(t *testing.T) {
+	t.Parallel()
+
+	ports, err := parseLinuxPortRange("32768\t60999\n")
+	require.NoError(t, err)
+	assert.Equal(t, EphemeralPorts{First: 32768, Last: 60999}, ports)
+
+	_, err = parseLinuxPortRange("32768")
+	assert.Error(t, err)
+	_, err = parseLinuxPortRange("32768 70000")
+	assert.Error(t, err)
+}
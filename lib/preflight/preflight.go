@@ -0,0 +1,105 @@
+// Package preflight contains checks of the OS limits and settings which are
+// relevant for running tests with many VUs, as well as tuning of the ones
+// that k6 can change by itself.
+package preflight
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// connectionsPerVU is a rough estimate of the number of connections a
+	// single VU keeps open at the same time, e.g. because of http.batch() or
+	// connections to multiple hosts.
+	connectionsPerVU = 2
+
+	// reservedOpenFiles is the number of file descriptors k6 needs for itself,
+	// e.g. for the script files, outputs and the REST API.
+	reservedOpenFiles = 100
+)
+
+// EphemeralPorts is the local port range from which the OS assigns ports to
+// new outgoing connections.
+type EphemeralPorts struct {
+	First, Last uint16
+}
+
+// Count returns the number of ports in the range, or 0 if it's unknown.
+func (p EphemeralPorts) Count() uint64 {
+	if p.First == 0 || p.Last < p.First {
+		return 0
+	}
+	return uint64(p.Last) - uint64(p.First) + 1
+}
+
+// Limits contains the OS limits which are relevant for running many VUs. A
+// zero value means the limit is unknown or, for the open files, that there
+// isn't one.
+type Limits struct {
+	OpenFiles      uint64 // the current (soft) limit of open file descriptors
+	OpenFilesMax   uint64 // the hard limit, up to which OpenFiles can be raised
+	EphemeralPorts EphemeralPorts
+}
+
+// Diagnostic is the result of a single preflight check.
+type Diagnostic struct {
+	Name    string
+	Value   string
+	Warning string // empty if nothing seems wrong
+}
+
+// CheckLimits compares the OS limits with the ones the given number of VUs
+// would probably need. If maxVUs is 0, the limits are only reported.
+func CheckLimits(limits Limits, maxVUs uint64) []Diagnostic {
+	neededConns := maxVUs * connectionsPerVU
+
+	openFiles := Diagnostic{Name: "open files limit", Value: "unlimited"}
+	if limits.OpenFiles > 0 {
+		openFiles.Value = strconv.FormatUint(limits.OpenFiles, 10)
+		if limits.OpenFilesMax > 0 {
+			openFiles.Value += fmt.Sprintf(" (hard limit %d)", limits.OpenFilesMax)
+		}
+		if needed := neededConns + reservedOpenFiles; maxVUs > 0 && limits.OpenFiles < needed {
+			openFiles.Warning = fmt.Sprintf("%d VUs may need around %d open files, "+
+				"raise the limit with `ulimit -n %d` or in /etc/security/limits.conf",
+				maxVUs, needed, needed)
+		}
+	}
+
+	ports := Diagnostic{Name: "ephemeral ports", Value: "unknown"}
+	if count := limits.EphemeralPorts.Count(); count > 0 {
+		ports.Value = fmt.Sprintf("%d (%d-%d)", count, limits.EphemeralPorts.First, limits.EphemeralPorts.Last)
+		if maxVUs > 0 && count < neededConns {
+			ports.Warning = fmt.Sprintf("%d VUs may need around %d connections to the same target, "+
+				"which can exhaust the ephemeral ports, widen the local port range or use more local IPs "+
+				"with --local-ips", maxVUs, neededConns)
+		}
+	}
+
+	return []Diagnostic{openFiles, ports}
+}
+
+// parseNetshDynamicPorts parses the output of
+// `netsh int ipv4 show dynamicport tcp` on Windows. The labels are localized,
+// so only the order of the values is relied upon - the start port is followed
+// by the number of ports.
+func parseNetshDynamicPorts(out string) (EphemeralPorts, error) {
+	var values []uint64
+	for _, line := range strings.Split(out, "\n") {
+		idx := strings.LastIndex(line, ":")
+		if idx < 0 {
+			continue
+		}
+		v, err := strconv.ParseUint(strings.TrimSpace(line[idx+1:]), 10, 32)
+		if err != nil {
+			continue
+		}
+		values = append(values, v)
+	}
+	if len(values) != 2 || values[0] == 0 || values[1] == 0 || values[0]+values[1]-1 > 65535 {
+		return EphemeralPorts{}, fmt.Errorf("unexpected netsh output: %q", out)
+	}
+	return EphemeralPorts{First: uint16(values[0]), Last: uint16(values[0] + values[1] - 1)}, nil
+}
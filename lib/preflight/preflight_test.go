@@ -0,0 +1,45 @@
+package preflight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLimits(t *testing.T) {
+	t.Parallel()
+
+	limits := Limits{OpenFiles: 1024, OpenFilesMax: 4096, EphemeralPorts: EphemeralPorts{First: 32768, Last: 60999}}
+	diagnostics := CheckLimits(limits, 0)
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, Diagnostic{Name: "open files limit", Value: "1024 (hard limit 4096)"}, diagnostics[0])
+	assert.Equal(t, Diagnostic{Name: "ephemeral ports", Value: "28232 (32768-60999)"}, diagnostics[1])
+
+	diagnostics = CheckLimits(limits, 400)
+	assert.Empty(t, diagnostics[0].Warning)
+	assert.Empty(t, diagnostics[1].Warning)
+
+	diagnostics = CheckLimits(limits, 20000)
+	assert.Contains(t, diagnostics[0].Warning, "20000 VUs may need around 40100 open files")
+	assert.Contains(t, diagnostics[1].Warning, "20000 VUs may need around 40000 connections")
+
+	diagnostics = CheckLimits(Limits{}, 20000)
+	assert.Equal(t, Diagnostic{Name: "open files limit", Value: "unlimited"}, diagnostics[0])
+	assert.Equal(t, Diagnostic{Name: "ephemeral ports", Value: "unknown"}, diagnostics[1])
+}
+
+func TestParseNetshDynamicPorts(t *testing.T) {
+	t.Parallel()
+
+	ports, err := parseNetshDynamicPorts("\r\nProtocol tcp Dynamic Port Range\r\n" +
+		"---------------------------------\r\nStart Port      : 49152\r\nNumber of Ports : 16384\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, EphemeralPorts{First: 49152, Last: 65535}, ports)
+	assert.Equal(t, uint64(16384), ports.Count())
+
+	_, err = parseNetshDynamicPorts("Start Port : 60000\nNumber of Ports : 16384\n")
+	assert.Error(t, err)
+	_, err = parseNetshDynamicPorts("something unexpected")
+	assert.Error(t, err)
+}
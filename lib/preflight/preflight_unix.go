@@ -0,0 +1,49 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package preflight
+
+import (
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetLimits returns the current OS limits.
+func GetLimits() (Limits, error) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return Limits{}, err
+	}
+	ports, err := getEphemeralPorts()
+	if err != nil {
+		return Limits{}, err
+	}
+	return Limits{
+		OpenFiles:      uint64(rlimit.Cur), //nolint:unconvert
+		OpenFilesMax:   uint64(rlimit.Max), //nolint:unconvert
+		EphemeralPorts: ports,
+	}, nil
+}
+
+// Tune raises the soft limit of open files up to the hard limit, since every
+// connection of every VU needs a file descriptor. The returned function
+// reverts any changes that shouldn't outlive the test run.
+func Tune(logger logrus.FieldLogger) func() {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		logger.WithError(err).Debug("Couldn't get the open files limit")
+		return func() {}
+	}
+	if rlimit.Cur < rlimit.Max {
+		previous := rlimit.Cur
+		rlimit.Cur = rlimit.Max
+		if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+			// e.g. on macOS, where the hard limit can be higher than the kernel allows
+			logger.WithError(err).Debug("Couldn't raise the open files limit")
+		} else {
+			logger.Debugf("Raised the open files limit from %d to %d", previous, rlimit.Cur)
+		}
+	}
+	return func() {}
+}
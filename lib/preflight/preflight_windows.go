@@ -0,0 +1,45 @@
+//go:build windows
+// +build windows
+
+package preflight
+
+import (
+	"os/exec"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+)
+
+// GetLimits returns the current OS limits. There is no practical limit of the
+// open sockets on Windows, so only the ephemeral ports are checked.
+func GetLimits() (Limits, error) {
+	out, err := exec.Command("netsh", "int", "ipv4", "show", "dynamicport", "tcp").Output() //nolint:gosec
+	if err != nil {
+		return Limits{}, err
+	}
+	ports, err := parseNetshDynamicPorts(string(out))
+	if err != nil {
+		return Limits{}, err
+	}
+	return Limits{EphemeralPorts: ports}, nil
+}
+
+// Tune increases the resolution of the system timer to 1ms for the duration
+// of the test run. The default 15.6ms resolution makes sleep(), think times
+// and the arrival-rate executors very imprecise with many VUs. The returned
+// function restores the previous resolution.
+func Tune(logger logrus.FieldLogger) func() {
+	winmm := windows.NewLazySystemDLL("winmm.dll")
+	timeBeginPeriod, timeEndPeriod := winmm.NewProc("timeBeginPeriod"), winmm.NewProc("timeEndPeriod")
+	if err := timeBeginPeriod.Find(); err != nil {
+		logger.WithError(err).Debug("Couldn't increase the timer resolution")
+		return func() {}
+	}
+	if r, _, _ := timeBeginPeriod.Call(1); r != 0 {
+		logger.Debug("Couldn't increase the timer resolution")
+		return func() {}
+	}
+	return func() {
+		_, _, _ = timeEndPeriod.Call(1)
+	}
+}
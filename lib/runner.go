@@ -25,6 +25,8 @@ import (
 	"io"
 	"time"
 
+	"gopkg.in/guregu/null.v3"
+
 	"go.k6.io/k6/metrics"
 )
 
@@ -55,6 +57,7 @@ type VUActivationParams struct {
 	DeactivateCallback       func(InitializedVU)
 	Env, Tags                map[string]string
 	Exec, Scenario           string
+	GetNextExec              func() string // if not nil, overrides Exec for every iteration
 	GetNextIterationCounters func() (uint64, uint64)
 }
 
@@ -104,6 +107,20 @@ type Runner interface {
 	HandleSummary(context.Context, *Summary) (map[string]io.Reader, error)
 }
 
+// PreflightRunner is implemented by the runners that can check whether the
+// init code of the VUs with the given IDs can be executed, without actually
+// initializing those VUs. All of the found problems should be returned, not
+// just the first one.
+type PreflightRunner interface {
+	Preflight(ctx context.Context, vuIDs []uint64) error
+}
+
+// CircuitBreakerRunner is implemented by the runners whose scripts can define
+// circuit breakers, which have to be evaluated while the test is running.
+type CircuitBreakerRunner interface {
+	GetCircuitBreakers() *CircuitBreakers
+}
+
 // UIState describes the state of the UI, which might influence what
 // handleSummary() returns.
 type UIState struct {
@@ -118,4 +135,47 @@ type Summary struct {
 	TestRunDuration time.Duration // TODO: use lib.ExecutionState-based interface instead?
 	NoColor         bool          // TODO: drop this when noColor is part of the (runtime) options
 	UIState         UIState
+	Transactions    []TransactionSummary
+	Abort           *SummaryAbort // nil if the test run wasn't aborted
+	Environment     RunEnvironment
+	// ThroughputSearches contains the results of the scenarios that searched
+	// for the maximum rate which passes their criteria.
+	ThroughputSearches []ThroughputSearchResult
+}
+
+// RunEnvironment describes the k6 build and the machine which executed a test
+// run, so its results can be compared with the right context later. The zero
+// values mean that something is unknown or, for the limits, that there isn't
+// a limit.
+type RunEnvironment struct {
+	K6Version            string  `json:"k6_version"`
+	OS                   string  `json:"os"`
+	Arch                 string  `json:"arch"`
+	Hostname             string  `json:"hostname"`
+	CPUs                 int     `json:"cpus"`
+	GOMAXPROCS           int     `json:"gomaxprocs"`
+	OpenFilesLimit       uint64  `json:"open_files_limit"`
+	ContainerCPULimit    float64 `json:"container_cpu_limit"`
+	ContainerMemoryLimit uint64  `json:"container_memory_limit"` // in bytes
+}
+
+// SummaryAbort describes why a test run was aborted before it finished.
+type SummaryAbort struct {
+	Reason string
+	// Truncated is true when k6 was forcefully stopped, so the summary
+	// contains only the metrics that were processed up until that point.
+	Truncated bool
+}
+
+// TransactionSummary contains the aggregated metrics of all executions of a
+// group, which are shown as a transaction in the SLA table of the summary.
+type TransactionSummary struct {
+	Group    string // the group path, e.g. "::checkout::payment"
+	Duration *metrics.TrendSink
+	// Failed contains the http_req_failed samples of the requests made
+	// directly in the group.
+	Failed *metrics.RateSink
+	// SLA is whether all thresholds of the group_duration{group:<path>}
+	// sub-metric passed, or null if it doesn't have any thresholds.
+	SLA null.Bool
 }
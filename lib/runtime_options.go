@@ -57,6 +57,9 @@ type RuntimeOptions struct {
 	// Environment variables passed onto the runner
 	Env map[string]string `json:"env"`
 
+	// The experimental JS modules which scripts are allowed to import
+	EnableExperimental []string `json:"enableExperimental"`
+
 	NoThresholds  null.Bool   `json:"noThresholds"`
 	NoSummary     null.Bool   `json:"noSummary"`
 	SummaryExport null.String `json:"summaryExport"`
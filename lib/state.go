@@ -32,6 +32,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -49,9 +50,16 @@ type State struct {
 	// TODO change to logrus.FieldLogger when there is time to fix all the tests
 	Logger *logrus.Logger
 
+	// Logger for the structured script logs of k6/execution, it may write to
+	// a different output than Logger.
+	ScriptLogger logrus.FieldLogger
+
 	// Current group; all emitted metrics are tagged with this.
 	Group *Group
 
+	// The request timeout override of the current group, if any.
+	GroupTimeout types.NullDuration
+
 	// Networking equipment.
 	Dialer DialContexter
 
@@ -61,6 +69,12 @@ type State struct {
 	CookieJar *cookiejar.Jar
 	TLSConfig *tls.Config
 
+	// The other transports of the VU, e.g. the ones for the HTTP requests with
+	// custom routes, whose idle connections are closed together with the ones
+	// of the Transport, at the end of the iterations with noVUConnectionReuse,
+	// and when the VU is stopped.
+	IdleConnectionClosers []IdleConnectionCloser
+
 	// Rate limits.
 	RPSLimit *rate.Limiter
 
@@ -87,6 +101,26 @@ type State struct {
 	GetScenarioGlobalVUIter func() uint64
 
 	BuiltinMetrics *metrics.BuiltinMetrics
+
+	// Keeps track of the response body bytes allocated in the current iteration.
+	BodyBytes *BodyBytesTracker
+
+	// Keeps track of the protocol and sleep time in the current iteration.
+	IterationTimings *IterationTimings
+
+	// The functions releasing the resources acquired for the current
+	// iteration, which are run when it ends.
+	IterationCleanups *IterationCleanups
+
+	// Keeps track of what the VU is currently doing, for observing it from
+	// outside of the VU.
+	Activity *VUActivity
+
+	// The circuit breakers of the test run, shared between all VUs.
+	CircuitBreakers *CircuitBreakers
+
+	// The URL of the last HTTP request made in the current iteration.
+	LastRequestURL string
 }
 
 // CloneTags makes a copy of the tags map and returns it.
@@ -154,3 +188,16 @@ func (tg *TagMap) Clone() map[string]string {
 	}
 	return tags
 }
+
+// IdleConnectionCloser is implemented by the transports which pool the idle
+// connections, like http.Transport.
+type IdleConnectionCloser interface {
+	CloseIdleConnections()
+}
+
+// CloseIdleConnections closes the idle connections of the IdleConnectionClosers.
+func (s *State) CloseIdleConnections() {
+	for _, c := range s.IdleConnectionClosers {
+		c.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,56 @@
+package lib
+
+import (
+	"context"
+	"time"
+
+	"go.k6.io/k6/lib/types"
+)
+
+// TimeoutSource is the level of the timeout hierarchy the effective timeout of
+// a request comes from.
+type TimeoutSource string
+
+// The levels of the timeout hierarchy, the more specific ones take precedence:
+// request > group > scenario > the default of the protocol module.
+const (
+	TimeoutSourceRequest  TimeoutSource = "request"
+	TimeoutSourceGroup    TimeoutSource = "group"
+	TimeoutSourceScenario TimeoutSource = "scenario"
+	TimeoutSourceDefault  TimeoutSource = "default"
+)
+
+// Timeout is the effective timeout of a request, together with its source,
+// so it's possible to tell which one fired when debugging timeout errors.
+type Timeout struct {
+	Duration time.Duration
+	Source   TimeoutSource
+}
+
+// Tags returns the tags which are added to the metric samples of requests that
+// failed because of the timeout.
+func (t Timeout) Tags() map[string]string {
+	tags := map[string]string{"timeout": t.Duration.String()}
+	if t.Source != "" {
+		tags["timeout_source"] = string(t.Source)
+	}
+	return tags
+}
+
+// GetTimeout returns the effective timeout of a request, using the first one
+// which is specified from the request itself, the current group of the VU and
+// the scenario of the context, or the given default of the protocol module.
+func GetTimeout(
+	ctx context.Context, state *State, requestTimeout types.NullDuration, moduleDefault time.Duration,
+) Timeout {
+	if requestTimeout.Valid {
+		return Timeout{Duration: time.Duration(requestTimeout.Duration), Source: TimeoutSourceRequest}
+	}
+	if state != nil && state.GroupTimeout.Valid {
+		return Timeout{Duration: time.Duration(state.GroupTimeout.Duration), Source: TimeoutSourceGroup}
+	}
+	if ss := GetScenarioState(ctx); ss != nil && ss.RequestTimeout.Valid {
+		return Timeout{Duration: time.Duration(ss.RequestTimeout.Duration), Source: TimeoutSourceScenario}
+	}
+	return Timeout{Duration: moduleDefault, Source: TimeoutSourceDefault}
+}
@@ -0,0 +1,34 @@
+package lib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.k6.io/k6/lib/types"
+)
+
+func TestGetTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := WithScenarioState(context.Background(), &ScenarioState{
+		RequestTimeout: types.NullDurationFrom(30 * time.Second),
+	})
+	state := &State{GroupTimeout: types.NullDurationFrom(10 * time.Second)}
+	requestTimeout := types.NullDurationFrom(time.Second)
+
+	assert.Equal(t, Timeout{Duration: time.Second, Source: TimeoutSourceRequest},
+		GetTimeout(ctx, state, requestTimeout, time.Minute))
+	assert.Equal(t, Timeout{Duration: 10 * time.Second, Source: TimeoutSourceGroup},
+		GetTimeout(ctx, state, types.NullDuration{}, time.Minute))
+	assert.Equal(t, Timeout{Duration: 30 * time.Second, Source: TimeoutSourceScenario},
+		GetTimeout(ctx, &State{}, types.NullDuration{}, time.Minute))
+	assert.Equal(t, Timeout{Duration: time.Minute, Source: TimeoutSourceDefault},
+		GetTimeout(context.Background(), nil, types.NullDuration{}, time.Minute))
+
+	assert.Equal(t, map[string]string{"timeout": "10s", "timeout_source": "group"},
+		Timeout{Duration: 10 * time.Second, Source: TimeoutSourceGroup}.Tags())
+	assert.Equal(t, map[string]string{"timeout": "1m0s"}, Timeout{Duration: time.Minute}.Tags())
+}
@@ -0,0 +1,148 @@
+package lib
+
+import (
+	"sync"
+	"time"
+)
+
+// VUStatus describes what a VU is currently doing.
+type VUStatus string
+
+// The possible VU statuses.
+const (
+	// VUStatusInactive is the status of initialized VUs that currently
+	// aren't used by any executor.
+	VUStatusInactive VUStatus = "inactive"
+	// VUStatusIdle is the status of activated VUs between iterations.
+	VUStatusIdle VUStatus = "idle"
+	// VUStatusRunning is the status of VUs in the middle of an iteration.
+	VUStatusRunning VUStatus = "running"
+)
+
+// VUActivity keeps track of what a VU is currently doing, so that it can be
+// safely observed from other goroutines, e.g. for detecting stuck VUs via the
+// REST API. All of its methods can be called on a nil VUActivity.
+type VUActivity struct {
+	mu       sync.RWMutex
+	id       uint64
+	scenario string
+	iter     int64
+	group    string
+	url      string
+	status   VUStatus
+	since    time.Time
+}
+
+// VUActivitySnapshot is the state of a VU at a specific point in time.
+type VUActivitySnapshot struct {
+	ID        uint64
+	Scenario  string
+	Iteration int64
+	Group     string
+	URL       string
+	Status    VUStatus
+	Since     time.Time
+}
+
+// TimeInStatus returns for how long the VU has had its current status.
+func (s VUActivitySnapshot) TimeInStatus(now time.Time) time.Duration {
+	return now.Sub(s.Since)
+}
+
+// VUActivityReporter is implemented by the VUs which can report what they are
+// currently doing.
+type VUActivityReporter interface {
+	GetActivity() *VUActivity
+}
+
+// NewVUActivity returns the activity of a newly initialized VU.
+func NewVUActivity(id uint64) *VUActivity {
+	return &VUActivity{id: id, iter: -1, status: VUStatusInactive, since: time.Now()}
+}
+
+func (a *VUActivity) setStatus(status VUStatus) {
+	if a.status != status {
+		a.status = status
+		a.since = time.Now()
+	}
+}
+
+// Activate marks the VU as activated for the given scenario.
+func (a *VUActivity) Activate(scenario string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scenario = scenario
+	a.setStatus(VUStatusIdle)
+}
+
+// Deactivate marks the VU as returned by the scenario that was using it.
+func (a *VUActivity) Deactivate() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scenario, a.group, a.url = "", "", ""
+	a.setStatus(VUStatusInactive)
+}
+
+// StartIteration marks the start of the iteration with the given number.
+func (a *VUActivity) StartIteration(iter int64) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.iter = iter
+	a.group, a.url = "", ""
+	a.setStatus(VUStatusRunning)
+}
+
+// EndIteration marks the end of the current iteration.
+func (a *VUActivity) EndIteration() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.setStatus(VUStatusIdle)
+}
+
+// SetGroup sets the path of the group the VU is currently in.
+func (a *VUActivity) SetGroup(path string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.group = path
+}
+
+// SetURL sets the URL of the request the VU is currently making, or has made
+// last in the current iteration.
+func (a *VUActivity) SetURL(url string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.url = url
+}
+
+// Snapshot returns the current state of the VU.
+func (a *VUActivity) Snapshot() VUActivitySnapshot {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return VUActivitySnapshot{
+		ID:        a.id,
+		Scenario:  a.scenario,
+		Iteration: a.iter,
+		Group:     a.group,
+		URL:       a.url,
+		Status:    a.status,
+		Since:     a.since,
+	}
+}
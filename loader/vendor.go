@@ -0,0 +1,159 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"go.k6.io/k6/lib/fsext"
+)
+
+const (
+	// VendorDir is the directory next to the script, in which the remote
+	// modules are vendored.
+	VendorDir = "vendor"
+	// VendorManifestFile is the file in the vendor directory that lists
+	// the vendored modules with their integrity hashes.
+	VendorManifestFile = "k6-deps.json"
+)
+
+// VendoredModule is a remote module that was vendored.
+type VendoredModule struct {
+	// Integrity is the SHA-256 hash of the module in the same format as
+	// the subresource integrity attributes, e.g. "sha256-<base64 hash>".
+	Integrity string `json:"integrity"`
+}
+
+// VendorManifest contains all of the vendored modules, by their path in the
+// vendor directory, which is their URL without the scheme, e.g.
+// "jslib.k6.io/k6-utils/1.4.0/index.js".
+type VendorManifest struct {
+	Modules map[string]VendoredModule `json:"modules"`
+}
+
+// Paths returns the sorted paths of the vendored modules.
+func (vm *VendorManifest) Paths() []string {
+	paths := make([]string, 0, len(vm.Modules))
+	for p := range vm.Modules {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func getIntegrity(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// getVendorDir returns the vendor directory for the script, or an empty
+// string if the script isn't a local file, and the OS filesystem to use.
+func getVendorDir(osfs afero.Fs, scriptURL *url.URL) (afero.Fs, string) {
+	if scriptURL == nil || scriptURL.Scheme != "file" {
+		return osfs, ""
+	}
+	if runtime.GOOS == "windows" {
+		// the same as in CreateFilesystems(), since the paths are in the same format
+		osfs = fsext.NewTrimFilePathSeparatorFs(osfs)
+	}
+	return osfs, filepath.FromSlash(path.Join(path.Dir(scriptURL.Path), VendorDir))
+}
+
+// VendorModules writes all of the remote modules that were loaded in the
+// https filesystem to the vendor directory of the script, together with a
+// manifest with their integrity hashes.
+func VendorModules(osfs afero.Fs, scriptURL *url.URL, httpsFS afero.Fs) (*VendorManifest, error) {
+	osfs, dir := getVendorDir(osfs, scriptURL)
+	if dir == "" {
+		return nil, fmt.Errorf("only the remote modules of local scripts can be vendored, not of '%s'", scriptURL)
+	}
+
+	manifest := &VendorManifest{Modules: make(map[string]VendoredModule)}
+	err := fsext.Walk(httpsFS, afero.FilePathSeparator, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := afero.ReadFile(httpsFS, filePath)
+		if err != nil {
+			return err
+		}
+		modulePath := strings.TrimPrefix(filepath.ToSlash(filePath), "/")
+		if err = osfs.MkdirAll(filepath.Join(dir, filepath.Dir(filepath.FromSlash(modulePath))), 0o755); err != nil {
+			return err
+		}
+		if err = afero.WriteFile(osfs, filepath.Join(dir, filepath.FromSlash(modulePath)), data, 0o644); err != nil {
+			return err
+		}
+		manifest.Modules[modulePath] = VendoredModule{Integrity: getIntegrity(data)}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = osfs.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err = afero.WriteFile(osfs, filepath.Join(dir, VendorManifestFile), append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// LoadVendoredModules adds the vendored modules of the script to the https
+// filesystem, after checking their integrity hashes, so they are used instead
+// of fetching the remote modules. Nothing is done if the script doesn't have
+// a vendor manifest.
+func LoadVendoredModules(
+	logger logrus.FieldLogger, osfs afero.Fs, scriptURL *url.URL, httpsFS afero.Fs,
+) (*VendorManifest, error) {
+	osfs, dir := getVendorDir(osfs, scriptURL)
+	if dir == "" {
+		return nil, nil
+	}
+	manifestPath := filepath.Join(dir, VendorManifestFile)
+	data, err := afero.ReadFile(osfs, manifestPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	manifest := &VendorManifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("couldn't parse the vendor manifest '%s': %w", manifestPath, err)
+	}
+
+	for _, modulePath := range manifest.Paths() {
+		// the paths are cleaned, so the vendored modules can't be outside of the vendor directory
+		cleanPath := filepath.FromSlash(path.Clean("/" + modulePath))
+		moduleData, err := afero.ReadFile(osfs, filepath.Join(dir, cleanPath))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read the vendored module '%s': %w", modulePath, err)
+		}
+		if integrity := getIntegrity(moduleData); integrity != manifest.Modules[modulePath].Integrity {
+			return nil, fmt.Errorf("the vendored module '%s' has the integrity hash '%s' instead of the expected '%s'",
+				modulePath, integrity, manifest.Modules[modulePath].Integrity)
+		}
+		if err = afero.WriteFile(httpsFS, cleanPath, moduleData, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	logger.Debugf("Loaded %d vendored modules from '%s'", len(manifest.Modules), dir)
+	return manifest, nil
+}
@@ -0,0 +1,78 @@
+package loader
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestVendorModules(t *testing.T) {
+	t.Parallel()
+
+	const utils, other = "export function a() {}", "export default 1"
+	scriptURL := &url.URL{Scheme: "file", Path: "/path/to/script.js"}
+	newHTTPSFS := func(t *testing.T) afero.Fs {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "/jslib.k6.io/k6-utils/1.4.0/index.js", []byte(utils), 0o644))
+		require.NoError(t, afero.WriteFile(fs, "/example.com/other.js", []byte(other), 0o644))
+		return fs
+	}
+
+	t.Run("roundtrip", func(t *testing.T) {
+		t.Parallel()
+		osfs := afero.NewMemMapFs()
+		manifest, err := VendorModules(osfs, scriptURL, newHTTPSFS(t))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example.com/other.js", "jslib.k6.io/k6-utils/1.4.0/index.js"}, manifest.Paths())
+		assert.Equal(t, "sha256-VbqzN0bqANjdwOCDmHsiLHly44mqkijWkoSi4CWl6Ek=",
+			manifest.Modules["jslib.k6.io/k6-utils/1.4.0/index.js"].Integrity)
+
+		data, err := afero.ReadFile(osfs, filepath.FromSlash("/path/to/vendor/jslib.k6.io/k6-utils/1.4.0/index.js"))
+		require.NoError(t, err)
+		assert.Equal(t, utils, string(data))
+
+		httpsFS := afero.NewMemMapFs()
+		loaded, err := LoadVendoredModules(testutils.NewLogger(t), osfs, scriptURL, httpsFS)
+		require.NoError(t, err)
+		assert.Equal(t, manifest, loaded)
+
+		src, err := Load(testutils.NewLogger(t), map[string]afero.Fs{"https": httpsFS},
+			&url.URL{Scheme: "https", Host: "jslib.k6.io", Path: "/k6-utils/1.4.0/index.js"},
+			"https://jslib.k6.io/k6-utils/1.4.0/index.js")
+		require.NoError(t, err)
+		assert.Equal(t, utils, string(src.Data))
+	})
+
+	t.Run("modified module", func(t *testing.T) {
+		t.Parallel()
+		osfs := afero.NewMemMapFs()
+		_, err := VendorModules(osfs, scriptURL, newHTTPSFS(t))
+		require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(osfs, filepath.FromSlash("/path/to/vendor/example.com/other.js"),
+			[]byte("export default 2"), 0o644))
+
+		_, err = LoadVendoredModules(testutils.NewLogger(t), osfs, scriptURL, afero.NewMemMapFs())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the vendored module 'example.com/other.js' has the integrity hash")
+	})
+
+	t.Run("no manifest", func(t *testing.T) {
+		t.Parallel()
+		manifest, err := LoadVendoredModules(testutils.NewLogger(t), afero.NewMemMapFs(), scriptURL, afero.NewMemMapFs())
+		require.NoError(t, err)
+		assert.Nil(t, manifest)
+	})
+
+	t.Run("remote script", func(t *testing.T) {
+		t.Parallel()
+		_, err := VendorModules(afero.NewMemMapFs(), &url.URL{Scheme: "https", Host: "example.com", Path: "/script.js"},
+			newHTTPSFS(t))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// Aggregate calculates the value of an aggregation method from the sink. The
+// aggregation methods are the same as the ones used in threshold expressions,
+// e.g. "count", "rate", "value", "avg" or "p(95)", and the duration is used to
+// calculate the rate of counters.
+func Aggregate(sink Sink, aggregation string, duration time.Duration) (float64, error) {
+	method, methodValue, err := parseThresholdAggregationMethod(aggregation)
+	if err != nil {
+		return 0, fmt.Errorf("invalid aggregation method '%s'", aggregation)
+	}
+
+	unsupported := func(sinkType string) (float64, error) {
+		return 0, fmt.Errorf("the aggregation method '%s' isn't supported by %s metrics", aggregation, sinkType)
+	}
+
+	switch sinkImpl := sink.(type) {
+	case *CounterSink:
+		switch method {
+		case tokenCount:
+			return sinkImpl.Value, nil
+		case tokenRate:
+			if duration <= 0 {
+				return 0, nil
+			}
+			return sinkImpl.Value / duration.Seconds(), nil
+		}
+		return unsupported("counter")
+	case *GaugeSink:
+		if method == tokenValue {
+			return sinkImpl.Value, nil
+		}
+		return unsupported("gauge")
+	case *TrendSink:
+		switch method {
+		case tokenMin:
+			return sinkImpl.Min, nil
+		case tokenMax:
+			return sinkImpl.Max, nil
+		case tokenAvg:
+			return sinkImpl.Avg, nil
+		case tokenMed:
+			sinkImpl.Calc()
+			return sinkImpl.Med, nil
+		case tokenPercentile:
+			return sinkImpl.P(methodValue.Float64 / 100), nil
+		}
+		return unsupported("trend")
+	case *RateSink:
+		if method == tokenRate {
+			if sinkImpl.Total == 0 {
+				return 0, nil
+			}
+			return float64(sinkImpl.Trues) / float64(sinkImpl.Total), nil
+		}
+		return unsupported("rate")
+	default:
+		return 0, fmt.Errorf("unable to aggregate; reason: unknown sink type")
+	}
+}
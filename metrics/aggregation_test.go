@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	counter, gauge, trend, rate := &CounterSink{}, &GaugeSink{}, &TrendSink{}, &RateSink{}
+	for _, v := range []float64{1, 2, 3, 4, 0} {
+		counter.Add(Sample{Value: v})
+		gauge.Add(Sample{Value: v})
+		trend.Add(Sample{Value: v})
+		rate.Add(Sample{Value: v})
+	}
+
+	testCases := []struct {
+		sink        Sink
+		aggregation string
+		expected    float64
+	}{
+		{counter, "count", 10},
+		{counter, "rate", 5},
+		{gauge, "value", 0},
+		{trend, "min", 0},
+		{trend, "max", 4},
+		{trend, "avg", 2},
+		{trend, "med", 2},
+		{trend, "p(75)", 3},
+		{rate, "rate", 0.8},
+	}
+	for _, tc := range testCases {
+		value, err := Aggregate(tc.sink, tc.aggregation, 2*time.Second)
+		require.NoError(t, err, tc.aggregation)
+		assert.Equal(t, tc.expected, value, tc.aggregation)
+	}
+
+	_, err := Aggregate(trend, "count", time.Second)
+	assert.ErrorContains(t, err, "the aggregation method 'count' isn't supported by trend metrics")
+	_, err = Aggregate(gauge, "p(abc)", time.Second)
+	assert.ErrorContains(t, err, "invalid aggregation method 'p(abc)'")
+
+	value, err := Aggregate(&RateSink{}, "rate", time.Second)
+	require.NoError(t, err)
+	assert.Zero(t, value)
+}
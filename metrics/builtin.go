@@ -20,6 +20,10 @@
 
 package metrics
 
+import (
+	"fmt"
+)
+
 const (
 	VUsName               = "vus" //nolint:revive
 	VUsMaxName            = "vus_max"
@@ -27,6 +31,13 @@ const (
 	IterationDurationName = "iteration_duration"
 	DroppedIterationsName = "dropped_iterations"
 
+	IterationProtocolDurationName = "iteration_protocol_duration"
+	IterationScriptDurationName   = "iteration_script_duration"
+	IterationSleepDurationName    = "iteration_sleep_duration"
+
+	JourneyTransitionsName = "journey_transitions"
+	JourneyDurationName    = "journey_duration"
+
 	ChecksName        = "checks"
 	GroupDurationName = "group_duration"
 
@@ -40,6 +51,9 @@ const (
 	HTTPReqWaitingName        = "http_req_waiting"
 	HTTPReqReceivingName      = "http_req_receiving"
 
+	HTTPLongPollWaitName   = "http_longpoll_wait"
+	HTTPLongPollCyclesName = "http_longpoll_cycles"
+
 	WSSessionsName         = "ws_sessions"
 	WSMessagesSentName     = "ws_msgs_sent"
 	WSMessagesReceivedName = "ws_msgs_received"
@@ -51,6 +65,8 @@ const (
 
 	DataSentName     = "data_sent"
 	DataReceivedName = "data_received"
+
+	EstimatedCostName = "estimated_cost"
 )
 
 // BuiltinMetrics represent all the builtin metrics of k6
@@ -61,6 +77,15 @@ type BuiltinMetrics struct {
 	IterationDuration *Metric
 	DroppedIterations *Metric
 
+	// The breakdown of iteration_duration, emitted by the runner.
+	IterationProtocolDuration *Metric
+	IterationScriptDuration   *Metric
+	IterationSleepDuration    *Metric
+
+	// Emitted by the journey executor.
+	JourneyTransitions *Metric
+	JourneyDuration    *Metric
+
 	// Runner-emitted.
 	Checks        *Metric
 	GroupDuration *Metric
@@ -76,6 +101,10 @@ type BuiltinMetrics struct {
 	HTTPReqWaiting        *Metric
 	HTTPReqReceiving      *Metric
 
+	// Emitted by the http.longPoll() helper.
+	HTTPLongPollWait   *Metric
+	HTTPLongPollCycles *Metric
+
 	// Websocket-related
 	WSSessions         *Metric
 	WSMessagesSent     *Metric
@@ -90,6 +119,9 @@ type BuiltinMetrics struct {
 	// Network-related; used for future protocols as well.
 	DataSent     *Metric
 	DataReceived *Metric
+
+	// Emitted by the engine, when a cost model is configured.
+	EstimatedCost *Metric
 }
 
 // RegisterBuiltinMetrics register and returns the builtin metrics in the provided registry
@@ -101,6 +133,13 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 		IterationDuration: registry.MustNewMetric(IterationDurationName, Trend, Time),
 		DroppedIterations: registry.MustNewMetric(DroppedIterationsName, Counter),
 
+		IterationProtocolDuration: registry.MustNewMetric(IterationProtocolDurationName, Trend, Time),
+		IterationScriptDuration:   registry.MustNewMetric(IterationScriptDurationName, Trend, Time),
+		IterationSleepDuration:    registry.MustNewMetric(IterationSleepDurationName, Trend, Time),
+
+		JourneyTransitions: registry.MustNewMetric(JourneyTransitionsName, Counter),
+		JourneyDuration:    registry.MustNewMetric(JourneyDurationName, Trend, Time),
+
 		Checks:        registry.MustNewMetric(ChecksName, Rate),
 		GroupDuration: registry.MustNewMetric(GroupDurationName, Trend, Time),
 
@@ -114,6 +153,9 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 		HTTPReqWaiting:        registry.MustNewMetric(HTTPReqWaitingName, Trend, Time),
 		HTTPReqReceiving:      registry.MustNewMetric(HTTPReqReceivingName, Trend, Time),
 
+		HTTPLongPollWait:   registry.MustNewMetric(HTTPLongPollWaitName, Trend, Time),
+		HTTPLongPollCycles: registry.MustNewMetric(HTTPLongPollCyclesName, Counter),
+
 		WSSessions:         registry.MustNewMetric(WSSessionsName, Counter),
 		WSMessagesSent:     registry.MustNewMetric(WSMessagesSentName, Counter),
 		WSMessagesReceived: registry.MustNewMetric(WSMessagesReceivedName, Counter),
@@ -125,5 +167,69 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 
 		DataSent:     registry.MustNewMetric(DataSentName, Counter, Data),
 		DataReceived: registry.MustNewMetric(DataReceivedName, Counter, Data),
+
+		EstimatedCost: registry.MustNewMetric(EstimatedCostName, Counter),
+	}
+}
+
+// byName returns the built-in metrics by their names.
+func (bm *BuiltinMetrics) byName() map[string]*Metric {
+	return map[string]*Metric{
+		VUsName:               bm.VUs,
+		VUsMaxName:            bm.VUsMax,
+		IterationsName:        bm.Iterations,
+		IterationDurationName: bm.IterationDuration,
+		DroppedIterationsName: bm.DroppedIterations,
+
+		IterationProtocolDurationName: bm.IterationProtocolDuration,
+		IterationScriptDurationName:   bm.IterationScriptDuration,
+		IterationSleepDurationName:    bm.IterationSleepDuration,
+
+		JourneyTransitionsName: bm.JourneyTransitions,
+		JourneyDurationName:    bm.JourneyDuration,
+
+		ChecksName:        bm.Checks,
+		GroupDurationName: bm.GroupDuration,
+
+		HTTPReqsName:              bm.HTTPReqs,
+		HTTPReqFailedName:         bm.HTTPReqFailed,
+		HTTPReqDurationName:       bm.HTTPReqDuration,
+		HTTPReqBlockedName:        bm.HTTPReqBlocked,
+		HTTPReqConnectingName:     bm.HTTPReqConnecting,
+		HTTPReqTLSHandshakingName: bm.HTTPReqTLSHandshaking,
+		HTTPReqSendingName:        bm.HTTPReqSending,
+		HTTPReqWaitingName:        bm.HTTPReqWaiting,
+		HTTPReqReceivingName:      bm.HTTPReqReceiving,
+
+		HTTPLongPollWaitName:   bm.HTTPLongPollWait,
+		HTTPLongPollCyclesName: bm.HTTPLongPollCycles,
+
+		WSSessionsName:         bm.WSSessions,
+		WSMessagesSentName:     bm.WSMessagesSent,
+		WSMessagesReceivedName: bm.WSMessagesReceived,
+		WSPingName:             bm.WSPing,
+		WSSessionDurationName:  bm.WSSessionDuration,
+		WSConnectingName:       bm.WSConnecting,
+
+		GRPCReqDurationName: bm.GRPCReqDuration,
+
+		DataSentName:     bm.DataSent,
+		DataReceivedName: bm.DataReceived,
+
+		EstimatedCostName: bm.EstimatedCost,
+	}
+}
+
+// Disable marks the given built-in metrics as disabled, so no samples are
+// emitted for them. It should be called before the test starts running.
+func (bm *BuiltinMetrics) Disable(names ...string) error {
+	byName := bm.byName()
+	for _, name := range names {
+		m, ok := byName[name]
+		if !ok || m == nil {
+			return fmt.Errorf("'%s' isn't a built-in metric, only those can be disabled", name)
+		}
+		m.Disabled = true
 	}
+	return nil
 }
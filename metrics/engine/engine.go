@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"go.k6.io/k6/lib"
@@ -34,6 +35,28 @@ type MetricsEngine struct {
 	//     the metrics are decoupled from their types
 	MetricsLock     sync.Mutex
 	ObservedMetrics map[string]*metrics.Metric
+
+	// The recent samples of the metrics with windowed queries, guarded by
+	// the MetricsLock as well.
+	windowedSamples map[*metrics.Metric]*windowedSamples
+
+	// The sub-metrics that were created only for queries, by their parent
+	// metrics, also guarded by the MetricsLock. They aren't part of the
+	// summary or the outputs.
+	querySubmetrics      map[*metrics.Metric][]*metrics.Submetric
+	querySubmetricsCount int
+
+	// The start time of the last flush of the ingester and a channel that is
+	// closed after every flush, also guarded by the MetricsLock, so queries
+	// can wait for the samples until some time to be aggregated.
+	lastFlush time.Time
+	flushed   chan struct{}
+
+	// The per-group sinks for the transactions in the summary, also guarded
+	// by the MetricsLock. It's nil if there is no summary.
+	groupDurationMetric *metrics.Metric
+	httpReqFailedMetric *metrics.Metric
+	transactions        map[string]*transactionSinks
 }
 
 // NewMetricsEngine creates a new metrics Engine with the given parameters.
@@ -49,6 +72,18 @@ func NewMetricsEngine(
 		logger:         logger.WithField("component", "metrics-engine"),
 
 		ObservedMetrics: make(map[string]*metrics.Metric),
+		windowedSamples: make(map[*metrics.Metric]*windowedSamples),
+		querySubmetrics: make(map[*metrics.Metric][]*metrics.Submetric),
+		flushed:         make(chan struct{}),
+	}
+	registry.SetSubmetricsLock(&me.MetricsLock)
+
+	if !me.runtimeOptions.NoSummary.Bool {
+		me.groupDurationMetric = registry.Get(metrics.GroupDurationName)
+		me.httpReqFailedMetric = registry.Get(metrics.HTTPReqFailedName)
+		if me.groupDurationMetric != nil {
+			me.transactions = make(map[string]*transactionSinks)
+		}
 	}
 
 	if !(me.runtimeOptions.NoSummary.Bool && me.runtimeOptions.NoThresholds.Bool) {
@@ -71,6 +106,20 @@ func (me *MetricsEngine) GetIngester() output.Output {
 }
 
 func (me *MetricsEngine) getThresholdMetricOrSubmetric(name string) (*metrics.Metric, error) {
+	// The sub-metric may already exist if it was defined by the script with
+	// metrics.addSubmetric(), but it can't have thresholds more than once.
+	return me.getMetricOrSubmetric(name, func(m *metrics.Metric, keyValues string) (*metrics.Submetric, error) {
+		sm, err := m.GetOrAddSubmetric(keyValues)
+		if err == nil && len(sm.Metric.Thresholds.Thresholds) > 0 {
+			return nil, fmt.Errorf("sub-metric with params '%s' already exists for metric %s: %s", keyValues, m.Name, sm.Name)
+		}
+		return sm, err
+	})
+}
+
+func (me *MetricsEngine) getMetricOrSubmetric(
+	name string, getSubmetric func(*metrics.Metric, string) (*metrics.Submetric, error),
+) (*metrics.Metric, error) {
 	// TODO: replace with strings.Cut after Go 1.18
 	nameParts := strings.SplitN(name, "{", 2)
 
@@ -83,10 +132,10 @@ func (me *MetricsEngine) getThresholdMetricOrSubmetric(name string) (*metrics.Me
 	}
 
 	submetricDefinition := nameParts[1]
-	if submetricDefinition[len(submetricDefinition)-1] != '}' {
+	if submetricDefinition == "" || submetricDefinition[len(submetricDefinition)-1] != '}' {
 		return nil, fmt.Errorf("missing ending bracket, sub-metric format needs to be 'metric{key:value}'")
 	}
-	sm, err := metric.AddSubmetric(submetricDefinition[:len(submetricDefinition)-1])
+	sm, err := getSubmetric(metric, submetricDefinition[:len(submetricDefinition)-1])
 	if err != nil {
 		return nil, err
 	}
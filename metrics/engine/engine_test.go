@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+func TestThresholdsOnDefinedSubmetric(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, builtinMetrics, 0, 0)
+
+	// the script defined the sub-metric with metrics.addSubmetric()
+	sm, err := registry.GetOrAddSubmetric(metrics.HTTPReqDurationName, "endpoint:checkout")
+	require.NoError(t, err)
+
+	newThresholds := func() metrics.Thresholds {
+		thresholds := metrics.NewThresholds([]string{"p(95)<100"})
+		require.NoError(t, thresholds.Parse())
+		return thresholds
+	}
+	opts := lib.Options{Thresholds: map[string]metrics.Thresholds{
+		"http_req_duration{endpoint:checkout}": newThresholds(),
+	}}
+	_, err = NewMetricsEngine(registry, es, opts, lib.RuntimeOptions{}, testutils.NewLogger(t))
+	require.NoError(t, err)
+	assert.Len(t, builtinMetrics.HTTPReqDuration.Submetrics, 1)
+	assert.Len(t, sm.Metric.Thresholds.Thresholds, 1)
+
+	// but equivalent sub-metrics still can't have thresholds more than once
+	registry = metrics.NewRegistry()
+	metrics.RegisterBuiltinMetrics(registry)
+	opts.Thresholds = map[string]metrics.Thresholds{
+		"http_req_duration{endpoint:checkout}":  newThresholds(),
+		"http_req_duration{endpoint:checkout,}": newThresholds(),
+	}
+	_, err = NewMetricsEngine(registry, es, opts, lib.RuntimeOptions{}, testutils.NewLogger(t))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists for metric http_req_duration")
+}
+
+func TestSubmetricsAddedWhileIngesting(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, builtinMetrics, 0, 0)
+	me, err := NewMetricsEngine(registry, es, lib.Options{}, lib.RuntimeOptions{}, testutils.NewLogger(t))
+	require.NoError(t, err)
+	ingester, ok := me.GetIngester().(*outputIngester)
+	require.True(t, ok)
+
+	const count = 100
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		tags := metrics.IntoSampleTags(&map[string]string{"endpoint": "1"})
+		for i := 0; i < count; i++ {
+			ingester.AddMetricSamples([]metrics.SampleContainer{
+				builtinMetrics.HTTPReqDuration.Sample(time.Now(), tags, 100),
+			})
+			ingester.flushMetrics()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		// e.g. the VUs that are initialized in the middle of the test
+		for i := 0; i < count; i++ {
+			_, err := registry.GetOrAddSubmetric(metrics.HTTPReqDurationName, fmt.Sprintf("endpoint:%d", i))
+			assert.NoError(t, err)
+		}
+	}()
+	wg.Wait()
+
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+	assert.Len(t, builtinMetrics.HTTPReqDuration.Submetrics, count)
+
+	// e.g. the VU that is initialized for the end-of-test summary
+	sm, err := registry.GetOrAddSubmetric(metrics.HTTPReqDurationName, "endpoint: 42")
+	require.NoError(t, err)
+	assert.Equal(t, "http_req_duration{endpoint:42}", sm.Name)
+}
@@ -9,7 +9,7 @@ import (
 
 const collectRate = 50 * time.Millisecond
 
-var _ output.Output = &outputIngester{}
+var _ output.WithoutRuntimeControl = &outputIngester{}
 
 // outputIngester implements the output.Output interface and can be used to
 // "feed" the MetricsEngine data from a `k6 run` test run.
@@ -21,6 +21,10 @@ type outputIngester struct {
 	periodicFlusher *output.PeriodicFlusher
 }
 
+// DisallowRuntimeControl makes sure the ingester can't be paused or sampled,
+// since the thresholds and the end-of-test summary depend on all samples.
+func (oi *outputIngester) DisallowRuntimeControl() {}
+
 // Description returns a human-readable description of the output.
 func (oi *outputIngester) Description() string {
 	return "engine"
@@ -50,13 +54,15 @@ func (oi *outputIngester) Stop() error {
 
 // flushMetrics Writes samples to the MetricsEngine
 func (oi *outputIngester) flushMetrics() {
+	start := time.Now()
 	sampleContainers := oi.GetBufferedSamples()
-	if len(sampleContainers) == 0 {
-		return
-	}
 
 	oi.metricsEngine.MetricsLock.Lock()
 	defer oi.metricsEngine.MetricsLock.Unlock()
+	defer oi.metricsEngine.markFlushed(start)
+	if len(sampleContainers) == 0 {
+		return
+	}
 
 	// TODO: split metric samples in buckets with a *metrics.Metric key; this will
 	// allow us to have a per-bucket lock, instead of one global one, and it
@@ -78,6 +84,8 @@ func (oi *outputIngester) flushMetrics() {
 			m := sample.Metric               // this should have come from the Registry, no need to look it up
 			oi.metricsEngine.markObserved(m) // mark it as observed so it shows in the end-of-test summary
 			m.Sink.Add(sample)               // finally, add its value to its own sink
+			oi.metricsEngine.addWindowedSample(m, sample)
+			oi.metricsEngine.addTransactionSample(sample)
 
 			// and also to the same for any submetrics that match the metric sample
 			for _, sm := range m.Submetrics {
@@ -86,7 +94,11 @@ func (oi *outputIngester) flushMetrics() {
 				}
 				oi.metricsEngine.markObserved(sm.Metric)
 				sm.Metric.Sink.Add(sample)
+				oi.metricsEngine.addWindowedSample(sm.Metric, sample)
 			}
+			oi.metricsEngine.addQuerySubmetricSamples(m, sample)
 		}
 	}
+
+	oi.metricsEngine.pruneWindowedSamples(time.Now())
 }
@@ -0,0 +1,309 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+var _ lib.MetricsQuerier = &MetricsEngine{}
+
+// ingestDelay is how long it can take for a sample to reach the ingester,
+// since the samples are buffered by the core engine for its own collect
+// period first, with a margin for the processing of the samples.
+const ingestDelay = 2 * collectRate
+
+// maxQuerySubmetrics limits how many sub-metrics can be created only for
+// queries, since every one of them is matched against all later samples of
+// its parent metric.
+const maxQuerySubmetrics = 1000
+
+// windowedSamples contains the recent samples of a metric, so queries over
+// a window of time can be answered. The samples are tracked only after the
+// first windowed query for the metric, for the longest queried window. The
+// total number of samples is counted after the first query of any kind.
+type windowedSamples struct {
+	since   time.Time
+	window  time.Duration
+	samples []metrics.Sample
+	total   uint64
+}
+
+// QueryMetric returns the current value of the aggregation method for the
+// metric or sub-metric with the given name. Sub-metrics that aren't used in
+// thresholds or defined by the script are created by the first query for
+// them, which returns an ErrNewQuerySubmetric error, since, just like
+// windowed queries, they only aggregate the samples from that moment on.
+func (me *MetricsEngine) QueryMetric(name, aggregation string, window time.Duration) (float64, error) {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	if err := me.checkAggregation(name, aggregation); err != nil {
+		return 0, err
+	}
+	metric, newErr := me.getQueryMetric(name)
+	if metric == nil {
+		return 0, newErr
+	}
+	if window <= 0 {
+		value, err := metrics.Aggregate(metric.Sink, aggregation, me.executionState.GetCurrentTestRunDuration())
+		if err != nil {
+			return 0, err
+		}
+		return value, newErr
+	}
+
+	now := time.Now()
+	ws := me.getWindowedSamples(metric, window, now)
+	sink := metrics.NewSink(metric.Type)
+	cutoff := now.Add(-window)
+	for _, sample := range ws.samples {
+		if !sample.Time.Before(cutoff) {
+			sink.Add(sample)
+		}
+	}
+
+	duration := window
+	if tracked := now.Sub(ws.since); tracked < duration {
+		duration = tracked
+	}
+	value, err := metrics.Aggregate(sink, aggregation, duration)
+	if err != nil {
+		return 0, err
+	}
+	return value, newErr
+}
+
+// QueryMetricRange returns the value of the aggregation method for the samples
+// of the metric or sub-metric with the given name from the from time until,
+// but excluding, the to time. It first waits for the ingester to aggregate all
+// of the samples until the to time, so only the samples that are still
+// tracked for the windowed queries of the metric are aggregated.
+func (me *MetricsEngine) QueryMetricRange(
+	ctx context.Context, name, aggregation string, from, to time.Time,
+) (float64, error) {
+	if err := me.waitForFlush(ctx, to.Add(ingestDelay)); err != nil {
+		return 0, err
+	}
+
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	if err := me.checkAggregation(name, aggregation); err != nil {
+		return 0, err
+	}
+	metric, newErr := me.getQueryMetric(name)
+	if metric == nil {
+		return 0, newErr
+	}
+
+	now := time.Now()
+	ws := me.getWindowedSamples(metric, now.Sub(from), now)
+	sink := metrics.NewSink(metric.Type)
+	for _, sample := range ws.samples {
+		if !sample.Time.Before(from) && sample.Time.Before(to) {
+			sink.Add(sample)
+		}
+	}
+
+	if ws.since.After(from) {
+		from = ws.since
+	}
+	duration := to.Sub(from)
+	if duration < 0 {
+		duration = 0
+	}
+	value, err := metrics.Aggregate(sink, aggregation, duration)
+	if err != nil {
+		return 0, err
+	}
+	return value, newErr
+}
+
+// CountSamples returns the number of samples of the metric or sub-metric with
+// the given name in the last window, or since the first query for it if the
+// window is zero, since the samples are counted only from that moment on.
+func (me *MetricsEngine) CountSamples(name string, window time.Duration) (uint64, error) {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	metric, newErr := me.getQueryMetric(name)
+	if metric == nil {
+		return 0, newErr
+	}
+
+	now := time.Now()
+	ws := me.getWindowedSamples(metric, window, now)
+	if window <= 0 {
+		return ws.total, newErr
+	}
+	var count uint64
+	cutoff := now.Add(-window)
+	for _, sample := range ws.samples {
+		if !sample.Time.Before(cutoff) {
+			count++
+		}
+	}
+	return count, newErr
+}
+
+// getQueryMetric returns the metric or sub-metric with the given name. If the
+// sub-metric was created by this query, it's returned together with an error
+// wrapping lib.ErrNewQuerySubmetric. It should be called with the MetricsLock
+// held.
+func (me *MetricsEngine) getQueryMetric(name string) (*metrics.Metric, error) {
+	var created *metrics.Submetric
+	metric, err := me.getMetricOrSubmetric(name, func(m *metrics.Metric, keyValues string) (*metrics.Submetric, error) {
+		sm, isNew, err := me.getQuerySubmetric(m, keyValues)
+		if isNew {
+			created = sm
+		}
+		return sm, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created != nil {
+		return metric, fmt.Errorf(
+			"%w, so it doesn't have the earlier samples of '%s'; define it in the init context "+
+				"with metrics.addSubmetric('%s', '%s') to query it",
+			lib.ErrNewQuerySubmetric, created.Parent.Name, created.Parent.Name, created.Suffix,
+		)
+	}
+	return metric, nil
+}
+
+// checkAggregation checks the aggregation method before the metric is looked
+// up, so an invalid query doesn't leave a sub-metric or windowed samples
+// behind. It should be called with the MetricsLock held.
+func (me *MetricsEngine) checkAggregation(name, aggregation string) error {
+	// TODO: replace with strings.Cut after Go 1.18
+	parent := me.registry.Get(strings.SplitN(name, "{", 2)[0])
+	if parent == nil {
+		return nil // the lookup of the metric returns the error
+	}
+	_, err := metrics.Aggregate(metrics.NewSink(parent.Type), aggregation, 0)
+	return err
+}
+
+// markFlushed records that a flush of the ingester, which started at the
+// given time, finished and wakes up the queries waiting for it. It should be
+// called with the MetricsLock held.
+func (me *MetricsEngine) markFlushed(start time.Time) {
+	me.lastFlush = start
+	close(me.flushed)
+	me.flushed = make(chan struct{})
+}
+
+// waitForFlush waits until a flush of the ingester that started at or after
+// the given time is finished.
+func (me *MetricsEngine) waitForFlush(ctx context.Context, t time.Time) error {
+	for {
+		me.MetricsLock.Lock()
+		done, flushed := !me.lastFlush.Before(t), me.flushed
+		me.MetricsLock.Unlock()
+		if done {
+			return nil
+		}
+		select {
+		case <-flushed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// getQuerySubmetric returns the existing sub-metric with the same tags, or
+// the one created by an earlier query. Otherwise, it creates a new one that
+// is private to the engine, so the metric's list of sub-metrics, which the
+// outputs read without any locks, isn't changed while the test is running,
+// and reports that it's new. It should be called with the MetricsLock held.
+func (me *MetricsEngine) getQuerySubmetric(
+	metric *metrics.Metric, keyValues string,
+) (sm *metrics.Submetric, isNew bool, err error) {
+	sm, err = metric.NewSubmetric(keyValues)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, existing := range metric.Submetrics {
+		if existing.Tags.IsEqual(sm.Tags) {
+			return existing, false, nil
+		}
+	}
+	for _, existing := range me.querySubmetrics[metric] {
+		if existing.Tags.IsEqual(sm.Tags) {
+			return existing, false, nil
+		}
+	}
+	if me.querySubmetricsCount >= maxQuerySubmetrics {
+		return nil, false, fmt.Errorf(
+			"can't query sub-metric %s, there are already %d queried sub-metrics without thresholds",
+			sm.Name, maxQuerySubmetrics)
+	}
+	me.querySubmetrics[metric] = append(me.querySubmetrics[metric], sm)
+	me.querySubmetricsCount++
+	return sm, true, nil
+}
+
+// addQuerySubmetricSamples adds the sample to the matching sub-metrics that
+// were created by queries. It should be called with the MetricsLock held.
+func (me *MetricsEngine) addQuerySubmetricSamples(metric *metrics.Metric, sample metrics.Sample) {
+	for _, sm := range me.querySubmetrics[metric] {
+		if !sample.Tags.Contains(sm.Tags) {
+			continue
+		}
+		sm.Metric.Sink.Add(sample)
+		me.addWindowedSample(sm.Metric, sample)
+	}
+}
+
+// getWindowedSamples starts tracking the samples of the metric, if they
+// weren't already, for at least the given window. It should be called with
+// the MetricsLock held.
+func (me *MetricsEngine) getWindowedSamples(
+	metric *metrics.Metric, window time.Duration, now time.Time,
+) *windowedSamples {
+	ws, ok := me.windowedSamples[metric]
+	if !ok {
+		ws = &windowedSamples{since: now}
+		me.windowedSamples[metric] = ws
+	}
+	if window > ws.window {
+		ws.window = window
+	}
+	return ws
+}
+
+// addWindowedSample keeps the sample if the metric has windowed queries. It
+// should be called with the MetricsLock held.
+func (me *MetricsEngine) addWindowedSample(metric *metrics.Metric, sample metrics.Sample) {
+	ws, ok := me.windowedSamples[metric]
+	if !ok {
+		return
+	}
+	ws.total++
+	if ws.window <= 0 {
+		return
+	}
+	// only the time and the value are needed, so the tags aren't retained
+	ws.samples = append(ws.samples, metrics.Sample{Time: sample.Time, Value: sample.Value})
+}
+
+// pruneWindowedSamples drops the samples that are too old to be part of any
+// of the queried windows. It should be called with the MetricsLock held.
+func (me *MetricsEngine) pruneWindowedSamples(now time.Time) {
+	for _, ws := range me.windowedSamples {
+		cutoff := now.Add(-ws.window)
+		i := 0
+		for i < len(ws.samples) && ws.samples[i].Time.Before(cutoff) {
+			i++
+		}
+		if i > 0 {
+			ws.samples = append(ws.samples[:0], ws.samples[i:]...)
+		}
+	}
+}
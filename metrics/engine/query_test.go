@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+func TestQueryMetric(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, builtinMetrics, 0, 0)
+	me, err := NewMetricsEngine(registry, es, lib.Options{}, lib.RuntimeOptions{}, testutils.NewLogger(t))
+	require.NoError(t, err)
+	ingester, ok := me.GetIngester().(*outputIngester)
+	require.True(t, ok)
+
+	now := time.Now()
+	push := func(offset time.Duration, value float64, scenario string) {
+		tags := metrics.IntoSampleTags(&map[string]string{"scenario": scenario})
+		ingester.AddMetricSamples([]metrics.SampleContainer{
+			builtinMetrics.HTTPReqDuration.Sample(now.Add(offset), tags, value),
+		})
+		ingester.flushMetrics()
+	}
+
+	// the window tracking starts with the first windowed query, which reports
+	// that the sub-metric didn't exist before it
+	value, err := me.QueryMetric("http_req_duration{scenario:main}", "max", time.Minute)
+	require.ErrorIs(t, err, lib.ErrNewQuerySubmetric)
+	assert.ErrorContains(t, err, "metrics.addSubmetric('http_req_duration', 'scenario:main')")
+	assert.Zero(t, value)
+
+	push(-time.Hour, 1000, "main")
+	push(0, 100, "main")
+	push(0, 200, "main")
+	push(0, 5000, "other")
+
+	value, err = me.QueryMetric("http_req_duration{scenario:main}", "max", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, value)
+	value, err = me.QueryMetric("http_req_duration{ scenario: main }", "avg", 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 433.33, value, 0.01)
+	value, err = me.QueryMetric("http_req_duration", "max", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 5000.0, value)
+	// the queried sub-metrics are private to the engine
+	assert.Empty(t, registry.Get("http_req_duration").Submetrics)
+	assert.Empty(t, me.ObservedMetrics["http_req_duration{scenario:main}"])
+	querySubmetrics := me.querySubmetrics[registry.Get("http_req_duration")]
+	require.Len(t, querySubmetrics, 1)
+	assert.Len(t, me.windowedSamples[querySubmetrics[0].Metric].samples, 2)
+
+	count, err := me.CountSamples("http_req_duration{scenario:main}", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), count)
+	count, err = me.CountSamples("http_req_duration{scenario:main}", 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), count)
+	// the samples without a window are counted only after the first query
+	count, err = me.CountSamples("http_req_duration", 0)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	push(0, 300, "other")
+	count, err = me.CountSamples("http_req_duration", 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+	assert.Empty(t, me.windowedSamples[registry.Get("http_req_duration")].samples)
+
+	_, err = me.QueryMetric("unknown", "max", 0)
+	assert.ErrorContains(t, err, "metric 'unknown' does not exist in the script")
+	_, err = me.QueryMetric("http_req_duration{", "max", 0)
+	assert.ErrorContains(t, err, "missing ending bracket")
+	_, err = me.QueryMetric("http_req_duration", "count", 0)
+	assert.ErrorContains(t, err, "isn't supported by trend metrics")
+	_, err = me.QueryMetric("http_req_duration{scenario:other}", "count", time.Minute)
+	assert.ErrorContains(t, err, "isn't supported by trend metrics")
+	assert.Len(t, me.querySubmetrics[registry.Get("http_req_duration")], 1)
+	_, err = me.CountSamples("http_req_duration{scenario:other}", 0)
+	assert.ErrorIs(t, err, lib.ErrNewQuerySubmetric)
+	_, err = me.CountSamples("http_req_duration{scenario:other}", 0)
+	assert.NoError(t, err)
+}
+
+func TestQueryMetricExistingSubmetric(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, builtinMetrics, 0, 0)
+	thresholds := map[string]metrics.Thresholds{
+		"http_req_duration{scenario:main}": {Thresholds: []*metrics.Threshold{{Source: "p(95)<100"}}},
+	}
+	me, err := NewMetricsEngine(registry, es, lib.Options{Thresholds: thresholds}, lib.RuntimeOptions{},
+		testutils.NewLogger(t))
+	require.NoError(t, err)
+	ingester, ok := me.GetIngester().(*outputIngester)
+	require.True(t, ok)
+
+	tags := metrics.IntoSampleTags(&map[string]string{"scenario": "main"})
+	ingester.AddMetricSamples([]metrics.SampleContainer{
+		builtinMetrics.HTTPReqDuration.Sample(time.Now(), tags, 100),
+	})
+	ingester.flushMetrics()
+
+	// the sub-metric of the threshold is used, with all of its samples
+	count, err := me.CountSamples("http_req_duration{ scenario: main }", 0)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	value, err := me.QueryMetric("http_req_duration{scenario:main}", "max", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, value)
+	assert.Len(t, registry.Get("http_req_duration").Submetrics, 1)
+	assert.Empty(t, me.querySubmetrics)
+}
+
+func TestQueryMetricRange(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, builtinMetrics, 0, 0)
+	me, err := NewMetricsEngine(registry, es, lib.Options{}, lib.RuntimeOptions{}, testutils.NewLogger(t))
+	require.NoError(t, err)
+	ingester, ok := me.GetIngester().(*outputIngester)
+	require.True(t, ok)
+
+	_, err = me.QueryMetric("http_req_duration{scenario:main}", "max", time.Hour)
+	require.ErrorIs(t, err, lib.ErrNewQuerySubmetric)
+
+	start := time.Now().Add(-time.Minute)
+	tags := metrics.IntoSampleTags(&map[string]string{"scenario": "main"})
+	for i, value := range []float64{100, 200, 300, 400} {
+		ingester.AddMetricSamples([]metrics.SampleContainer{
+			builtinMetrics.HTTPReqDuration.Sample(start.Add(time.Duration(i-1)*time.Second), tags, value),
+		})
+	}
+
+	// the query waits for a flush that started after the end of the range,
+	// and only the samples from the start until the end are aggregated
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		value, err := me.QueryMetricRange(context.Background(), "http_req_duration{scenario:main}", "max",
+			start, start.Add(2*time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, 300.0, value)
+	}()
+	ingester.flushMetrics()
+	<-done
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = me.QueryMetricRange(ctx, "http_req_duration{scenario:main}", "max", start, time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = me.QueryMetricRange(context.Background(), "http_req_duration", "count", start, start)
+	assert.ErrorContains(t, err, "isn't supported by trend metrics")
+	_, err = me.QueryMetricRange(context.Background(), "http_req_duration{scenario:other}", "max", start, start)
+	assert.ErrorIs(t, err, lib.ErrNewQuerySubmetric)
+}
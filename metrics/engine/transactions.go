@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"sort"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// transactionSinks aggregate the durations of a group and the failed requests
+// in it, so they can be shown as a transaction in the end-of-test summary.
+type transactionSinks struct {
+	// The sink of the group_duration{group:<path>} sub-metric, if there is
+	// one, otherwise the durations are added to it by addTransactionSample.
+	duration  *metrics.TrendSink
+	submetric *metrics.Submetric
+	failed    *metrics.RateSink
+}
+
+// addTransactionSample adds the group_duration and http_req_failed samples to
+// the sinks of their group. Only the groups with a group_duration{group:<path>}
+// sub-metric, e.g. because of its thresholds, are transactions, unless the
+// summaryTransactions option is enabled. The durations of the former are
+// already aggregated by their sub-metrics, so they aren't kept twice. It
+// should be called with the MetricsLock held.
+func (me *MetricsEngine) addTransactionSample(sample metrics.Sample) {
+	if me.transactions == nil || (sample.Metric != me.groupDurationMetric && sample.Metric != me.httpReqFailedMetric) {
+		return
+	}
+	group, ok := sample.Tags.Get(metrics.TagGroup.String())
+	if !ok || group == "" {
+		return // the root group isn't a transaction
+	}
+
+	ts, ok := me.transactions[group]
+	if !ok {
+		ts = me.newTransactionSinks(group)
+		me.transactions[group] = ts // nil for the groups that aren't transactions
+	}
+	switch {
+	case ts == nil:
+		return
+	case sample.Metric == me.httpReqFailedMetric:
+		ts.failed.Add(sample)
+	case ts.submetric == nil:
+		ts.duration.Add(sample)
+	}
+}
+
+// newTransactionSinks returns the sinks for a transaction, reusing the sink of
+// the group_duration{group:<path>} sub-metric if it exists. It returns nil if
+// the group isn't a transaction.
+func (me *MetricsEngine) newTransactionSinks(group string) *transactionSinks {
+	for _, sm := range me.groupDurationMetric.Submetrics {
+		if !isGroupSubmetric(sm, group) {
+			continue
+		}
+		if duration, ok := sm.Metric.Sink.(*metrics.TrendSink); ok {
+			return &transactionSinks{duration: duration, submetric: sm, failed: &metrics.RateSink{}}
+		}
+	}
+	if !me.options.SummaryTransactions.Bool {
+		return nil
+	}
+	return &transactionSinks{duration: &metrics.TrendSink{}, failed: &metrics.RateSink{}}
+}
+
+// isGroupSubmetric returns whether the sub-metric is filtered only by the
+// given group.
+func isGroupSubmetric(sm *metrics.Submetric, group string) bool {
+	tags := sm.Tags.CloneTags()
+	return len(tags) == 1 && tags[metrics.TagGroup.String()] == group
+}
+
+// GetTransactions returns the summaries of all transactions that were executed,
+// sorted by their paths. The SLA of a group is determined by the thresholds
+// of its group_duration{group:<path>} sub-metric, if it has any. It should be
+// called with the MetricsLock held.
+func (me *MetricsEngine) GetTransactions() []lib.TransactionSummary {
+	if len(me.transactions) == 0 {
+		return nil
+	}
+
+	result := make([]lib.TransactionSummary, 0, len(me.transactions))
+	for group, ts := range me.transactions {
+		if ts == nil || ts.duration.Count == 0 {
+			continue // not a transaction or only the requests in the group finished
+		}
+		var sla null.Bool
+		if ts.submetric != nil && len(ts.submetric.Metric.Thresholds.Thresholds) > 0 {
+			passed := true
+			for _, threshold := range ts.submetric.Metric.Thresholds.Thresholds {
+				passed = passed && !threshold.LastFailed
+			}
+			sla = null.BoolFrom(passed)
+		}
+		result = append(result, lib.TransactionSummary{
+			Group:    group,
+			Duration: ts.duration,
+			Failed:   ts.failed,
+			SLA:      sla,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Group < result[j].Group })
+	return result
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+func TestGetTransactions(t *testing.T) {
+	t.Parallel()
+
+	thresholds := metrics.NewThresholds([]string{"p(95)<100"})
+	require.NoError(t, thresholds.Parse())
+	getTransactions := func(summaryTransactions bool) ([]lib.TransactionSummary, *metrics.Registry) {
+		registry := metrics.NewRegistry()
+		builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+		et, err := lib.NewExecutionTuple(nil, nil)
+		require.NoError(t, err)
+		es := lib.NewExecutionState(lib.Options{}, et, builtinMetrics, 0, 0)
+		opts := lib.Options{
+			Thresholds:          map[string]metrics.Thresholds{"group_duration{group:::login}": thresholds},
+			SummaryTransactions: null.BoolFrom(summaryTransactions),
+		}
+		me, err := NewMetricsEngine(registry, es, opts, lib.RuntimeOptions{}, testutils.NewLogger(t))
+		require.NoError(t, err)
+		ingester, ok := me.GetIngester().(*outputIngester)
+		require.True(t, ok)
+
+		now := time.Now()
+		push := func(metric *metrics.Metric, group string, value float64) {
+			tags := metrics.IntoSampleTags(&map[string]string{"group": group})
+			ingester.AddMetricSamples([]metrics.SampleContainer{metric.Sample(now, tags, value)})
+		}
+		push(builtinMetrics.GroupDuration, "::login", 50)
+		push(builtinMetrics.GroupDuration, "::login", 70)
+		push(builtinMetrics.HTTPReqFailed, "::login", 1)
+		push(builtinMetrics.HTTPReqFailed, "::login", 0)
+		push(builtinMetrics.GroupDuration, "::browse", 500)
+		push(builtinMetrics.HTTPReqFailed, "::unfinished", 0)
+		push(builtinMetrics.HTTPReqFailed, "", 1)
+		ingester.flushMetrics()
+		me.EvaluateThresholds()
+		return me.GetTransactions(), registry
+	}
+
+	// only the groups with thresholds are shown by default, and their
+	// durations are taken from the sub-metrics of the thresholds
+	transactions, registry := getTransactions(false)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "::login", transactions[0].Group)
+	assert.Equal(t, uint64(2), transactions[0].Duration.Count)
+	assert.Same(t, registry.Get("group_duration").Submetrics[0].Metric.Sink, transactions[0].Duration)
+	assert.Equal(t, int64(2), transactions[0].Failed.Total)
+	assert.Equal(t, int64(1), transactions[0].Failed.Trues)
+	assert.Equal(t, null.BoolFrom(true), transactions[0].SLA)
+
+	transactions, _ = getTransactions(true)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "::browse", transactions[0].Group)
+	assert.Equal(t, uint64(1), transactions[0].Duration.Count)
+	assert.Zero(t, transactions[0].Failed.Total)
+	assert.False(t, transactions[0].SLA.Valid)
+	assert.Equal(t, "::login", transactions[1].Group)
+	assert.Equal(t, uint64(2), transactions[1].Duration.Count)
+}
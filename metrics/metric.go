@@ -3,6 +3,7 @@ package metrics
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -23,6 +24,10 @@ type Metric struct {
 	Sub        *Submetric   `json:"-"`
 	Sink       Sink         `json:"-"`
 	Observed   bool         `json:"-"`
+
+	// Disabled built-in metrics don't emit any samples, see the
+	// disabledMetrics option. It's set before the test starts running.
+	Disabled bool `json:"-"`
 }
 
 // Sample samples the metric at the given time, with the provided tags and value
@@ -35,6 +40,23 @@ func (m *Metric) Sample(t time.Time, tags *SampleTags, value float64) Sample {
 	}
 }
 
+// NewSink returns a new empty sink for the given metric type, or nil if the
+// type is unknown.
+func NewSink(mt MetricType) Sink {
+	switch mt {
+	case Counter:
+		return &CounterSink{}
+	case Gauge:
+		return &GaugeSink{}
+	case Trend:
+		return &TrendSink{}
+	case Rate:
+		return &RateSink{}
+	default:
+		return nil
+	}
+}
+
 // newMetric instantiates a new Metric
 func newMetric(name string, mt MetricType, vt ...ValueType) *Metric {
 	valueType := Default
@@ -42,17 +64,8 @@ func newMetric(name string, mt MetricType, vt ...ValueType) *Metric {
 		valueType = vt[0]
 	}
 
-	var sink Sink
-	switch mt {
-	case Counter:
-		sink = &CounterSink{}
-	case Gauge:
-		sink = &GaugeSink{}
-	case Trend:
-		sink = &TrendSink{}
-	case Rate:
-		sink = &RateSink{}
-	default:
+	sink := NewSink(mt)
+	if sink == nil {
 		return nil
 	}
 
@@ -78,6 +91,54 @@ type Submetric struct {
 // and adds it to the metric's submetrics list.
 func (m *Metric) AddSubmetric(keyValues string) (*Submetric, error) {
 	keyValues = strings.TrimSpace(keyValues)
+	tags, err := m.parseSubmetricTags(keyValues)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sm := range m.Submetrics {
+		if sm.Tags.IsEqual(tags) {
+			return nil, fmt.Errorf(
+				"sub-metric with params '%s' already exists for metric %s: %s",
+				keyValues, m.Name, sm.Name,
+			)
+		}
+	}
+
+	return m.addSubmetric(keyValues, tags), nil
+}
+
+// GetOrAddSubmetric returns the submetric matching the key:value definition,
+// creating it only if the metric doesn't already have one with the same tags.
+func (m *Metric) GetOrAddSubmetric(keyValues string) (*Submetric, error) {
+	keyValues = strings.TrimSpace(keyValues)
+	tags, err := m.parseSubmetricTags(keyValues)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sm := range m.Submetrics {
+		if sm.Tags.IsEqual(tags) {
+			return sm, nil
+		}
+	}
+
+	return m.addSubmetric(keyValues, tags), nil
+}
+
+// NewSubmetric creates a new submetric from the key:value definition without
+// adding it to the metric's submetrics list, so it only gets the samples that
+// are explicitly added to its sink.
+func (m *Metric) NewSubmetric(keyValues string) (*Submetric, error) {
+	keyValues = strings.TrimSpace(keyValues)
+	tags, err := m.parseSubmetricTags(keyValues)
+	if err != nil {
+		return nil, err
+	}
+	return m.newSubmetric(keyValues, tags), nil
+}
+
+func (m *Metric) parseSubmetricTags(keyValues string) (*SampleTags, error) {
 	if len(keyValues) == 0 {
 		return nil, fmt.Errorf("submetric criteria for metric '%s' cannot be empty", m.Name)
 	}
@@ -99,17 +160,16 @@ func (m *Metric) AddSubmetric(keyValues string) (*Submetric, error) {
 		rawTags[key] = value
 	}
 
-	tags := IntoSampleTags(&rawTags)
+	return IntoSampleTags(&rawTags), nil
+}
 
-	for _, sm := range m.Submetrics {
-		if sm.Tags.IsEqual(tags) {
-			return nil, fmt.Errorf(
-				"sub-metric with params '%s' already exists for metric %s: %s",
-				keyValues, m.Name, sm.Name,
-			)
-		}
-	}
+func (m *Metric) addSubmetric(keyValues string, tags *SampleTags) *Submetric {
+	subMetric := m.newSubmetric(keyValues, tags)
+	m.Submetrics = append(m.Submetrics, subMetric)
+	return subMetric
+}
 
+func (m *Metric) newSubmetric(keyValues string, tags *SampleTags) *Submetric {
 	subMetric := &Submetric{
 		Name:   m.Name + "{" + keyValues + "}",
 		Suffix: keyValues,
@@ -120,9 +180,7 @@ func (m *Metric) AddSubmetric(keyValues string) (*Submetric, error) {
 	subMetricMetric.Sub = subMetric // sigh
 	subMetric.Metric = subMetricMetric
 
-	m.Submetrics = append(m.Submetrics, subMetric)
-
-	return subMetric, nil
+	return subMetric
 }
 
 // ErrMetricNameParsing indicates parsing a metric name failed
@@ -188,3 +246,51 @@ func ParseMetricName(name string) (string, []string, error) {
 
 	return name[0:openingTokenPos], tags, nil
 }
+
+// ErrMetricNameMacro indicates that an environment variable macro in a metric
+// name expression could not be resolved.
+var ErrMetricNameMacro = errors.New("unresolved metric name macro")
+
+var metricNameMacroRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandMetricNameMacros resolves the `${VAR_NAME}` macros in the tag section
+// of a metric name expression of the form metric_name{tag_key:${VAR_NAME}} by
+// using the supplied lookup function. The metric name itself is left as-is. An
+// error containing ErrMetricNameMacro in its chain is returned if a referenced
+// variable can't be found, or if its value contains a ',' or a '}', since it
+// would be substituted as-is and would change the tags of the expression.
+func ExpandMetricNameMacros(name string, lookup func(key string) (string, bool)) (string, error) {
+	openingTokenPos := strings.IndexByte(name, '{')
+	if openingTokenPos == -1 || !strings.Contains(name[openingTokenPos:], "${") {
+		return name, nil
+	}
+
+	var missing, invalid []string
+	expanded := metricNameMacroRegex.ReplaceAllStringFunc(name[openingTokenPos:], func(macro string) string {
+		key := metricNameMacroRegex.FindStringSubmatch(macro)[1]
+		value, ok := lookup(key)
+		if !ok {
+			missing = append(missing, key)
+			return macro
+		}
+		if strings.ContainsAny(value, ",}") {
+			invalid = append(invalid, key)
+			return macro
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf(
+			"%w, metric %q references undefined environment variable(s) %s",
+			ErrMetricNameMacro, name, strings.Join(missing, ", "),
+		)
+	}
+	if len(invalid) > 0 {
+		return "", fmt.Errorf(
+			"%w, the value of the environment variable(s) %s referenced by metric %q can't contain ',' or '}'",
+			ErrMetricNameMacro, strings.Join(invalid, ", "), name,
+		)
+	}
+
+	return name[:openingTokenPos] + expanded, nil
+}
@@ -67,6 +67,22 @@ func TestAddSubmetric(t *testing.T) {
 	}
 }
 
+func TestNewSubmetric(t *testing.T) {
+	t.Parallel()
+
+	m := newMetric("metric", Trend)
+	sm, err := m.NewSubmetric(" a : 1 ")
+	require.NoError(t, err)
+	assert.Equal(t, "metric{a : 1}", sm.Name)
+	assert.Equal(t, m, sm.Parent)
+	assert.Equal(t, sm, sm.Metric.Sub)
+	assert.EqualValues(t, map[string]string{"a": "1"}, sm.Tags.tags)
+	assert.Empty(t, m.Submetrics)
+
+	_, err = m.NewSubmetric("")
+	assert.Error(t, err)
+}
+
 func TestParseMetricName(t *testing.T) {
 	t.Parallel()
 
@@ -198,3 +214,53 @@ func TestParseMetricName(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandMetricNameMacros(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"ENVIRONMENT": "staging",
+		"REGION":      "eu",
+		"WITH_COMMA":  "eu,env:prod",
+		"WITH_BRACE":  "eu}",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "no tags", input: "test_metric", want: "test_metric"},
+		{name: "no macros", input: "test_metric{env:prod}", want: "test_metric{env:prod}"},
+		{name: "single macro", input: "test_metric{env:${ENVIRONMENT}}", want: "test_metric{env:staging}"},
+		{
+			name:  "multiple macros",
+			input: "test_metric{env:${ENVIRONMENT},region:${REGION}-1}",
+			want:  "test_metric{env:staging,region:eu-1}",
+		},
+		{name: "macro outside of tags", input: "${ENVIRONMENT}{env:prod}", want: "${ENVIRONMENT}{env:prod}"},
+		{name: "undefined variable", input: "test_metric{env:${MISSING}}", wantErr: true},
+		{name: "value with a comma", input: "test_metric{region:${WITH_COMMA}}", wantErr: true},
+		{name: "value with a closing brace", input: "test_metric{region:${WITH_BRACE}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ExpandMetricNameMacros(tt.input, lookup)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrMetricNameMacro)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -30,12 +30,23 @@ import (
 type Registry struct {
 	metrics map[string]*Metric
 	l       sync.RWMutex
+
+	// submetricsLock is held while GetOrAddSubmetric adds sub-metrics. It's
+	// replaced with the lock under which they are read during the test.
+	submetricsLock sync.Locker
+
+	// The sub-metrics returned by GetOrAddSubmetric, guarded by l, so they
+	// can be returned again without the submetricsLock, e.g. to the VU that
+	// is initialized for the end-of-test summary while the lock is held.
+	submetrics map[*Metric][]*Submetric
 }
 
 // NewRegistry returns a new registry
 func NewRegistry() *Registry {
 	return &Registry{
-		metrics: make(map[string]*Metric),
+		metrics:        make(map[string]*Metric),
+		submetricsLock: new(sync.Mutex),
+		submetrics:     make(map[*Metric][]*Submetric),
 	}
 }
 
@@ -89,3 +100,67 @@ func (r *Registry) MustNewMetric(name string, typ MetricType, t ...ValueType) *M
 func (r *Registry) Get(name string) *Metric {
 	return r.metrics[name]
 }
+
+// SetSubmetricsLock sets the lock that GetOrAddSubmetric holds while it adds
+// the sub-metrics, e.g. the lock of the metrics engine, which iterates over
+// them while it ingests the samples. It should be called before the VUs are
+// initialized.
+func (r *Registry) SetSubmetricsLock(l sync.Locker) {
+	r.l.Lock()
+	defer r.l.Unlock()
+	r.submetricsLock = l
+}
+
+// GetOrAddSubmetric returns the sub-metric of the given metric that matches
+// the key:value definition, creating it if it doesn't exist yet. It's safe to
+// call concurrently, even while the test is running, e.g. when the VUs are
+// initialized in the middle of it.
+func (r *Registry) GetOrAddSubmetric(metricName, keyValues string) (*Submetric, error) {
+	r.l.RLock()
+	m, ok := r.metrics[metricName]
+	submetricsLock := r.submetricsLock
+	r.l.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("metric '%s' does not exist in the script", metricName)
+	}
+
+	candidate, err := m.NewSubmetric(keyValues)
+	if err != nil {
+		return nil, err
+	}
+	if sm := r.getSubmetric(m, candidate.Tags); sm != nil {
+		return sm, nil
+	}
+
+	// the registry lock isn't held here, since the metrics engine gets the
+	// metrics from the registry while it holds its own lock
+	submetricsLock.Lock()
+	sm, err := m.GetOrAddSubmetric(keyValues)
+	submetricsLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	r.l.Lock()
+	defer r.l.Unlock()
+	for _, existing := range r.submetrics[m] {
+		if existing == sm {
+			return sm, nil
+		}
+	}
+	r.submetrics[m] = append(r.submetrics[m], sm)
+	return sm, nil
+}
+
+// getSubmetric returns the sub-metric with the given tags that was already
+// returned by GetOrAddSubmetric, if there is one.
+func (r *Registry) getSubmetric(m *Metric, tags *SampleTags) *Submetric {
+	r.l.RLock()
+	defer r.l.RUnlock()
+	for _, sm := range r.submetrics[m] {
+		if sm.Tags.IsEqual(tags) {
+			return sm
+		}
+	}
+	return nil
+}
@@ -17,6 +17,12 @@ type Sample struct {
 	Time   time.Time
 	Tags   *SampleTags
 	Value  float64
+
+	// Seq is the sequence number of the sample, which is assigned when it's
+	// passed to the outputs. It's unique and monotonically increasing for
+	// every k6 instance, so gaps mean that samples were dropped. It's written
+	// by the json, csv and proto outputs.
+	Seq uint64
 }
 
 // SampleContainer is a simple abstraction that allows sample
@@ -114,15 +120,45 @@ func GetBufferedSamples(input <-chan SampleContainer) (result []SampleContainer)
 }
 
 // PushIfNotDone first checks if the supplied context is done and doesn't push
-// the sample container if it is.
+// the sample container if it is. The samples of disabled metrics are dropped.
 func PushIfNotDone(ctx context.Context, output chan<- SampleContainer, sample SampleContainer) bool {
 	if ctx.Err() != nil {
 		return false
 	}
+	switch s := sample.(type) {
+	case Sample:
+		if s.Metric.Disabled {
+			return true
+		}
+	case Samples:
+		sample = Samples(WithoutDisabled(s))
+	case ConnectedSamples:
+		s.Samples = WithoutDisabled(s.Samples)
+		sample = s
+	}
 	output <- sample
 	return true
 }
 
+// WithoutDisabled returns the given samples without the ones of disabled
+// metrics. The slice is returned as it is if none of them are disabled.
+func WithoutDisabled(samples []Sample) []Sample {
+	for i, s := range samples {
+		if !s.Metric.Disabled {
+			continue
+		}
+		result := make([]Sample, i, len(samples)-1)
+		copy(result, samples[:i])
+		for _, s := range samples[i+1:] {
+			if !s.Metric.Disabled {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return samples
+}
+
 // GetResolversForTrendColumns checks if passed trend columns are valid for use in
 // the summary output and then returns a map of the corresponding resolvers.
 func GetResolversForTrendColumns(trendColumns []string) (map[string]func(s *TrendSink) float64, error) {
@@ -1,12 +1,15 @@
 package metrics
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSampleTags(t *testing.T) {
@@ -95,6 +98,48 @@ func TestSampleImplementations(t *testing.T) {
 	assert.Equal(t, sample.GetTags(), sample.GetTags())
 }
 
+func TestPushIfNotDoneDisabledMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	builtinMetrics := RegisterBuiltinMetrics(registry)
+	require.NoError(t, builtinMetrics.Disable(VUsMaxName, HTTPReqBlockedName))
+	err := builtinMetrics.Disable("my_custom_metric")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'my_custom_metric' isn't a built-in metric")
+
+	now := time.Now()
+	vus := Sample{Metric: builtinMetrics.VUs, Time: now, Value: 1}
+	vusMax := Sample{Metric: builtinMetrics.VUsMax, Time: now, Value: 2}
+	out := make(chan SampleContainer, 10)
+	ctx := context.Background()
+	assert.True(t, PushIfNotDone(ctx, out, vusMax))
+	assert.True(t, PushIfNotDone(ctx, out, vus))
+	assert.True(t, PushIfNotDone(ctx, out, ConnectedSamples{Samples: []Sample{vus, vusMax}, Time: now}))
+	assert.True(t, PushIfNotDone(ctx, out, Samples{vusMax, vus}))
+	close(out)
+
+	var pushed [][]Sample
+	for sc := range out {
+		pushed = append(pushed, sc.GetSamples())
+	}
+	assert.Equal(t, [][]Sample{{vus}, {vus}, {vus}}, pushed)
+}
+
+func TestBuiltinMetricsByName(t *testing.T) {
+	t.Parallel()
+
+	builtinMetrics := RegisterBuiltinMetrics(NewRegistry())
+	byName := builtinMetrics.byName()
+	fields := reflect.ValueOf(builtinMetrics).Elem()
+	assert.Len(t, byName, fields.NumField())
+	for i := 0; i < fields.NumField(); i++ {
+		m, ok := fields.Field(i).Interface().(*Metric)
+		require.True(t, ok)
+		assert.Equal(t, m, byName[m.Name], fields.Type().Field(i).Name)
+	}
+}
+
 func TestGetResolversForTrendColumnsValidation(t *testing.T) {
 	validateTests := []struct {
 		stats  []string
@@ -104,13 +104,24 @@ const (
 	TagVU
 	TagOCSPStatus
 	TagIP
+	TagLocalPort
+	TagConnID
+	TagTLSSessionReused
+	TagBackend
+
+	// System tags enabled by default, which are set only for the HTTP requests
+	// with the sni and connectTo params.
+	TagSNI
+	TagConnectTo
 )
 
 // DefaultSystemTagSet includes all of the system tags emitted with metrics by default.
-// Other tags that are not enabled by default include: iter, vu, ocsp_status, ip
+// Other tags that are not enabled by default include: iter, vu, ocsp_status, ip,
+// local_port, conn_id, tls_session_reused, backend
 //nolint:gochecknoglobals
 var DefaultSystemTagSet = TagProto | TagSubproto | TagStatus | TagMethod | TagURL | TagName | TagGroup |
-	TagCheck | TagError | TagErrorCode | TagTLSVersion | TagScenario | TagService | TagExpectedResponse
+	TagCheck | TagError | TagErrorCode | TagTLSVersion | TagScenario | TagService | TagExpectedResponse |
+	TagSNI | TagConnectTo
 
 // Add adds a tag to tag set.
 func (i *SystemTagSet) Add(tag SystemTagSet) {
@@ -105,6 +105,56 @@ func (t *Threshold) run(sinks map[string]float64) (bool, error) {
 	return passes, err
 }
 
+// Evaluate checks the threshold against the supplied aggregated metric values,
+// e.g. the ones from the end-of-test summary of a previous test run, without
+// changing its state. Besides the result, it returns the aggregated value the
+// threshold expression was checked against.
+func (t *Threshold) Evaluate(values map[string]float64) (passes bool, value float64, err error) {
+	parsed := t.parsed
+	if parsed == nil {
+		if parsed, err = parseThresholdExpression(t.Source); err != nil {
+			return false, 0, err
+		}
+	}
+
+	value, ok := values[parsed.SinkKey()]
+	if !ok {
+		return false, 0, fmt.Errorf("no %s value found for threshold %s", parsed.SinkKey(), t.Source)
+	}
+	passes, err = (&Threshold{Source: t.Source, parsed: parsed}).runNoTaint(values)
+
+	return passes, value, err
+}
+
+// AggregationMethod returns the aggregation method the threshold expression
+// is checked against, e.g. "p(95)" for "p(95)<200".
+func (t *Threshold) AggregationMethod() (string, error) {
+	parsed, err := t.getParsed()
+	if err != nil {
+		return "", err
+	}
+	return parsed.SinkKey(), nil
+}
+
+// Operator returns the comparison operator of the threshold expression, e.g.
+// "<" for "p(95)<200".
+func (t *Threshold) Operator() (string, error) {
+	parsed, err := t.getParsed()
+	if err != nil {
+		return "", err
+	}
+	return parsed.Operator, nil
+}
+
+// getParsed returns the parsed threshold expression, parsing it if the
+// threshold wasn't parsed yet.
+func (t *Threshold) getParsed() (*thresholdExpression, error) {
+	if t.parsed != nil {
+		return t.parsed, nil
+	}
+	return parseThresholdExpression(t.Source)
+}
+
 type thresholdConfig struct {
 	Threshold        string             `json:"threshold"`
 	AbortOnFail      bool               `json:"abortOnFail"`
@@ -254,6 +254,31 @@ func TestThresholdRun(t *testing.T) {
 	})
 }
 
+func TestThresholdEvaluate(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]float64{"avg": 150, "p(95)": 420}
+
+	passes, value, err := newThreshold("p(95)<500", false, types.NullDuration{}).Evaluate(values)
+	require.NoError(t, err)
+	assert.True(t, passes)
+	assert.Equal(t, 420.0, value)
+
+	threshold := newThreshold("avg<100", false, types.NullDuration{})
+	passes, value, err = threshold.Evaluate(values)
+	require.NoError(t, err)
+	assert.False(t, passes)
+	assert.Equal(t, 150.0, value)
+	assert.False(t, threshold.LastFailed, "the threshold state shouldn't change")
+	assert.Nil(t, threshold.parsed)
+
+	_, _, err = newThreshold("p(99)<500", false, types.NullDuration{}).Evaluate(values)
+	assert.Error(t, err)
+
+	_, _, err = newThreshold("foo<500", false, types.NullDuration{}).Evaluate(values)
+	assert.Error(t, err)
+}
+
 func TestThresholdsParse(t *testing.T) {
 	t.Parallel()
 
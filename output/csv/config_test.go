@@ -30,6 +30,7 @@ import (
 
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/types"
@@ -39,6 +40,23 @@ func TestNewConfig(t *testing.T) {
 	config := NewConfig()
 	assert.Equal(t, "file.csv", config.FileName.String)
 	assert.Equal(t, "1s", config.SaveInterval.String())
+	assert.Equal(t, "unix", config.TimeFormat.String)
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	for _, f := range []string{"unix", "unix_milli", "unix_micro", "unix_nano", "rfc3339", "rfc3339_nano"} {
+		timeFormat, err := ParseTimeFormat(f)
+		assert.NoError(t, err)
+		assert.Equal(t, TimeFormat(f), timeFormat)
+	}
+	_, err := ParseTimeFormat("unix_pico")
+	assert.ErrorContains(t, err, "unknown CSV time format 'unix_pico'")
+
+	ts := time.Date(2019, 7, 5, 11, 4, 4, 123456789, time.UTC)
+	assert.Equal(t, "1562324644123", UnixMilli.Format(ts))
+	assert.Equal(t, "1562324644123456789", UnixNano.Format(ts))
+	assert.Equal(t, "2019-07-05T11:04:04Z", RFC3339.Format(ts))
+	assert.Equal(t, "2019-07-05T11:04:04.123456789Z", RFC3339Nano.Format(ts))
 }
 
 func TestApply(t *testing.T) {
@@ -123,6 +141,12 @@ func TestParseArg(t *testing.T) {
 				"CSV output argument 'save_interval' is deprecated, please use 'saveInterval' instead.",
 			},
 		},
+		"fileName=test.csv,timeFormat=unix_nano": {
+			config: Config{
+				FileName:   null.StringFrom("test.csv"),
+				TimeFormat: null.StringFrom("unix_nano"),
+			},
+		},
 		"filename=test.csv,save_interval=5s": {
 			expectedErr: true,
 		},
@@ -145,6 +169,7 @@ func TestParseArg(t *testing.T) {
 			}
 			assert.Equal(t, testCase.config.FileName.String, config.FileName.String)
 			assert.Equal(t, testCase.config.SaveInterval.String(), config.SaveInterval.String())
+			assert.Equal(t, testCase.config.TimeFormat, config.TimeFormat)
 
 			var entries []string
 			for _, v := range hook.AllEntries() {
@@ -155,3 +180,18 @@ func TestParseArg(t *testing.T) {
 		})
 	}
 }
+
+func TestGetConsolidatedConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := GetConsolidatedConfig(
+		[]byte(`{"file_name":"json.csv","save_interval":"3s","time_format":"rfc3339"}`),
+		map[string]string{"K6_CSV_SAVE_INTERVAL": "4s"},
+		"fileName=arg.csv",
+		testutils.NewLogger(t),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, null.StringFrom("arg.csv"), config.FileName)
+	assert.Equal(t, types.NullDurationFrom(4*time.Second), config.SaveInterval)
+	assert.Equal(t, null.StringFrom("rfc3339"), config.TimeFormat)
+}
@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -38,6 +39,10 @@ import (
 )
 
 // Output implements the lib.Output interface for saving to CSV files.
+//
+// Unlike the json and proto outputs, it doesn't write the environment and the
+// metadata of the test run, since every line of a CSV file has to be a row
+// with the same columns, and most CSV readers don't support comment lines.
 type Output struct {
 	output.SampleBuffer
 
@@ -54,6 +59,7 @@ type Output struct {
 	ignoredTags  []string
 	row          []string
 	saveInterval time.Duration
+	timeFormat   TimeFormat
 }
 
 // New Creates new instance of CSV output
@@ -87,6 +93,10 @@ func newOutput(params output.Params) (*Output, error) {
 
 	saveInterval := config.SaveInterval.TimeDuration()
 	fname := config.FileName.String
+	timeFormat, err := ParseTimeFormat(config.TimeFormat.String)
+	if err != nil {
+		return nil, err
+	}
 
 	if fname == "" || fname == "-" {
 		stdoutWriter := csv.NewWriter(os.Stdout)
@@ -95,8 +105,9 @@ func newOutput(params output.Params) (*Output, error) {
 			resTags:      resTags,
 			ignoredTags:  ignoredTags,
 			csvWriter:    stdoutWriter,
-			row:          make([]string, 3+len(resTags)+1),
+			row:          make([]string, 3+len(resTags)+2),
 			saveInterval: saveInterval,
+			timeFormat:   timeFormat,
 			closeFn:      func() error { return nil },
 			logger:       logger,
 			params:       params,
@@ -112,8 +123,9 @@ func newOutput(params output.Params) (*Output, error) {
 		fname:        fname,
 		resTags:      resTags,
 		ignoredTags:  ignoredTags,
-		row:          make([]string, 3+len(resTags)+1),
+		row:          make([]string, 3+len(resTags)+2),
 		saveInterval: saveInterval,
+		timeFormat:   timeFormat,
 		logger:       logger,
 		params:       params,
 	}
@@ -182,7 +194,7 @@ func (o *Output) flushMetrics() {
 		for _, sc := range samples {
 			for _, sample := range sc.GetSamples() {
 				sample := sample
-				row := SampleToRow(&sample, o.resTags, o.ignoredTags, o.row)
+				row := SampleToRow(&sample, o.resTags, o.ignoredTags, o.row, o.timeFormat)
 				err := o.csvWriter.Write(row)
 				if err != nil {
 					o.logger.WithField("filename", o.fname).Error("CSV: Error writing to file")
@@ -195,14 +207,16 @@ func (o *Output) flushMetrics() {
 
 // MakeHeader creates list of column names for csv file
 func MakeHeader(tags []string) []string {
-	tags = append(tags, "extra_tags")
+	tags = append(tags, "extra_tags", "seq")
 	return append([]string{"metric_name", "timestamp", "metric_value"}, tags...)
 }
 
 // SampleToRow converts sample into array of strings
-func SampleToRow(sample *metrics.Sample, resTags []string, ignoredTags []string, row []string) []string {
+func SampleToRow(
+	sample *metrics.Sample, resTags []string, ignoredTags []string, row []string, timeFormat TimeFormat,
+) []string {
 	row[0] = sample.Metric.Name
-	row[1] = fmt.Sprintf("%d", sample.Time.Unix())
+	row[1] = timeFormat.Format(sample.Time)
 	row[2] = fmt.Sprintf("%f", sample.Value)
 	sampleTags := sample.Tags.CloneTags()
 
@@ -234,7 +248,8 @@ func SampleToRow(sample *metrics.Sample, resTags []string, ignoredTags []string,
 			prev = true
 		}
 	}
-	row[len(row)-1] = extraTags.String()
+	row[len(row)-2] = extraTags.String()
+	row[len(row)-1] = strconv.FormatUint(sample.Seq, 10)
 
 	return row
 }
@@ -55,11 +55,12 @@ func TestMakeHeader(t *testing.T) {
 		testname, tags := testname, tags
 		t.Run(testname, func(t *testing.T) {
 			header := MakeHeader(tags)
-			assert.Equal(t, len(tags)+4, len(header))
+			assert.Equal(t, len(tags)+5, len(header))
 			assert.Equal(t, "metric_name", header[0])
 			assert.Equal(t, "timestamp", header[1])
 			assert.Equal(t, "metric_value", header[2])
-			assert.Equal(t, "extra_tags", header[len(header)-1])
+			assert.Equal(t, "extra_tags", header[len(header)-2])
+			assert.Equal(t, "seq", header[len(header)-1])
 		})
 	}
 }
@@ -73,6 +74,7 @@ func TestSampleToRow(t *testing.T) {
 		sample      *metrics.Sample
 		resTags     []string
 		ignoredTags []string
+		timeFormat  TimeFormat
 	}{
 		{
 			testname: "One res tag, one ignored tag, one extra tag",
@@ -124,6 +126,18 @@ func TestSampleToRow(t *testing.T) {
 			resTags:     []string{"tag1", "tag3"},
 			ignoredTags: []string{"tag4", "tag6"},
 		},
+		{
+			testname: "Microsecond timestamp",
+			sample: &metrics.Sample{
+				Time:   time.Unix(1562324644, 123456789),
+				Metric: testMetric,
+				Value:  0.123456,
+				Tags:   metrics.NewSampleTags(map[string]string{"tag1": "val1"}),
+			},
+			resTags:     []string{"tag1"},
+			ignoredTags: []string{},
+			timeFormat:  UnixMicro,
+		},
 	}
 
 	expected := []struct {
@@ -168,20 +182,29 @@ func TestSampleToRow(t *testing.T) {
 				"tag5=val5",
 			},
 		},
+		{
+			baseRow: []string{
+				"my_metric",
+				"1562324644123456",
+				"0.123456",
+				"val1",
+			},
+		},
 	}
 
 	for i := range testData {
 		testname, sample := testData[i].testname, testData[i].sample
 		resTags, ignoredTags := testData[i].resTags, testData[i].ignoredTags
+		timeFormat := testData[i].timeFormat
 		expectedRow := expected[i]
 
 		t.Run(testname, func(t *testing.T) {
-			row := SampleToRow(sample, resTags, ignoredTags, make([]string, 3+len(resTags)+1))
+			row := SampleToRow(sample, resTags, ignoredTags, make([]string, 3+len(resTags)+2), timeFormat)
 			for ind, cell := range expectedRow.baseRow {
 				assert.Equal(t, cell, row[ind])
 			}
 			for _, cell := range expectedRow.extraRow {
-				assert.Contains(t, row[len(row)-1], cell)
+				assert.Contains(t, row[len(row)-2], cell)
 			}
 		})
 	}
@@ -249,11 +272,12 @@ func TestRun(t *testing.T) {
 						"error": "val3",
 						"tag4":  "val4",
 					}),
+					Seq: 42,
 				},
 			},
 			fileName:       "test",
 			fileReaderFunc: readUnCompressedFile,
-			outputContent:  "metric_name,timestamp,metric_value,check,error,extra_tags\n" + "my_metric,1562324643,1.000000,val1,val3,url=val2\n" + "my_metric,1562324644,1.000000,val1,val3,tag4=val4&url=val2\n",
+			outputContent:  "metric_name,timestamp,metric_value,check,error,extra_tags,seq\n" + "my_metric,1562324643,1.000000,val1,val3,url=val2,0\n" + "my_metric,1562324644,1.000000,val1,val3,tag4=val4&url=val2,42\n",
 		},
 		{
 			samples: []metrics.SampleContainer{
@@ -281,7 +305,7 @@ func TestRun(t *testing.T) {
 			},
 			fileName:       "test.gz",
 			fileReaderFunc: readCompressedFile,
-			outputContent:  "metric_name,timestamp,metric_value,check,error,extra_tags\n" + "my_metric,1562324643,1.000000,val1,val3,url=val2\n" + "my_metric,1562324644,1.000000,val1,val3,name=val4&url=val2\n",
+			outputContent:  "metric_name,timestamp,metric_value,check,error,extra_tags,seq\n" + "my_metric,1562324643,1.000000,val1,val3,url=val2,0\n" + "my_metric,1562324644,1.000000,val1,val3,name=val4&url=val2,0\n",
 		},
 	}
 
@@ -314,9 +338,9 @@ func sortExtraTagsForTest(t *testing.T, input string) string {
 	lines, err := r.ReadAll()
 	require.NoError(t, err)
 	for i, line := range lines[1:] {
-		extraTags := strings.Split(line[len(line)-1], "&")
+		extraTags := strings.Split(line[len(line)-2], "&")
 		sort.Strings(extraTags)
-		lines[i+1][len(line)-1] = strings.Join(extraTags, "&")
+		lines[i+1][len(line)-2] = strings.Join(extraTags, "&")
 	}
 	var b bytes.Buffer
 	w := csv.NewWriter(&b)
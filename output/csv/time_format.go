@@ -0,0 +1,51 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TimeFormat is the format of the sample timestamps in the CSV file.
+type TimeFormat string
+
+// The supported time formats.
+const (
+	Unix        TimeFormat = "unix"
+	UnixMilli   TimeFormat = "unix_milli"
+	UnixMicro   TimeFormat = "unix_micro"
+	UnixNano    TimeFormat = "unix_nano"
+	RFC3339     TimeFormat = "rfc3339"
+	RFC3339Nano TimeFormat = "rfc3339_nano"
+)
+
+// ParseTimeFormat validates the given time format.
+func ParseTimeFormat(s string) (TimeFormat, error) {
+	switch f := TimeFormat(s); f {
+	case Unix, UnixMilli, UnixMicro, UnixNano, RFC3339, RFC3339Nano:
+		return f, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown CSV time format '%s', it should be one of '%s', '%s', '%s', '%s', '%s' or '%s'",
+			s, Unix, UnixMilli, UnixMicro, UnixNano, RFC3339, RFC3339Nano,
+		)
+	}
+}
+
+// Format returns the timestamp in the time format.
+func (f TimeFormat) Format(t time.Time) string {
+	switch f {
+	case UnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case UnixMicro:
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case UnixNano:
+		return strconv.FormatInt(t.UnixNano(), 10)
+	case RFC3339:
+		return t.Format(time.RFC3339)
+	case RFC3339Nano:
+		return t.Format(time.RFC3339Nano)
+	default:
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+}
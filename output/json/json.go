@@ -33,6 +33,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output"
 )
@@ -47,12 +48,12 @@ type Output struct {
 	params          output.Params
 	periodicFlusher *output.PeriodicFlusher
 
-	logger      logrus.FieldLogger
-	filename    string
-	out         io.Writer
-	closeFn     func() error
-	seenMetrics map[string]struct{}
-	thresholds  map[string][]*metrics.Threshold
+	logger     logrus.FieldLogger
+	filename   string
+	out        io.Writer
+	closeFn    func() error
+	encoder    *Encoder
+	thresholds map[string][]*metrics.Threshold
 }
 
 // New returns a new JSON output.
@@ -64,7 +65,6 @@ func New(params output.Params) (output.Output, error) {
 			"output":   "json",
 			"filename": params.ConfigArgument,
 		}),
-		seenMetrics: make(map[string]struct{}),
 	}, nil
 }
 
@@ -112,6 +112,11 @@ func (o *Output) Start() error {
 		}
 	}
 
+	o.encoder = NewEncoder(o.out)
+	if err := o.writeMetadata(); err != nil {
+		return err
+	}
+
 	pf, err := output.NewPeriodicFlusher(flushPeriod, o.flushMetrics)
 	if err != nil {
 		return err
@@ -122,6 +127,15 @@ func (o *Output) Start() error {
 	return nil
 }
 
+// writeMetadata writes the environment and the metadata of the test run as
+// the first line. They are only known when the output is created by k6 run.
+func (o *Output) writeMetadata() error {
+	if o.params.RunEnvironment.K6Version == "" {
+		return nil
+	}
+	return o.encoder.EncodeMetadata(o.params.RunEnvironment, o.params.ScriptOptions.Metadata)
+}
+
 // Stop flushes any remaining metrics and stops the goroutine.
 func (o *Output) Stop() error {
 	o.logger.Debug("Stopping...")
@@ -150,9 +164,7 @@ func (o *Output) flushMetrics() {
 		for _, sample := range samples {
 			sample := sample
 			sample.Metric.Thresholds.Thresholds = o.thresholds[sample.Metric.Name]
-			o.handleMetric(sample.Metric, jw)
-			wrapSample(sample).MarshalEasyJSON(jw)
-			jw.RawByte('\n')
+			o.encoder.encode(jw, sample)
 		}
 	}
 
@@ -165,12 +177,45 @@ func (o *Output) flushMetrics() {
 	}
 }
 
-func (o *Output) handleMetric(m *metrics.Metric, jw *jwriter.Writer) {
-	if _, ok := o.seenMetrics[m.Name]; ok {
-		return
+// Encoder writes samples as JSON lines, in the format of the JSON output,
+// preceded by the definition of their metric the first time it's used.
+type Encoder struct {
+	w           io.Writer
+	seenMetrics map[string]struct{}
+}
+
+// NewEncoder returns an encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, seenMetrics: make(map[string]struct{})}
+}
+
+// EncodeMetadata writes the environment and the metadata of the test run as a
+// single line. It should be called before any samples are encoded.
+func (e *Encoder) EncodeMetadata(env lib.RunEnvironment, metadata map[string]string) error {
+	jw := new(jwriter.Writer)
+	wrapMetadata(env, metadata).MarshalEasyJSON(jw)
+	jw.RawByte('\n')
+	_, err := jw.DumpTo(e.w)
+	return err
+}
+
+// Encode writes the given samples.
+func (e *Encoder) Encode(samples []metrics.Sample) error {
+	jw := new(jwriter.Writer)
+	for _, sample := range samples {
+		e.encode(jw, sample)
+	}
+	_, err := jw.DumpTo(e.w)
+	return err
+}
+
+func (e *Encoder) encode(jw *jwriter.Writer, sample metrics.Sample) {
+	if _, ok := e.seenMetrics[sample.Metric.Name]; !ok {
+		e.seenMetrics[sample.Metric.Name] = struct{}{}
+		wrapMetric(sample.Metric).MarshalEasyJSON(jw)
+		jw.RawByte('\n')
 	}
-	o.seenMetrics[m.Name] = struct{}{}
 
-	wrapMetric(m).MarshalEasyJSON(jw)
+	wrapSample(sample).MarshalEasyJSON(jw)
 	jw.RawByte('\n')
 }
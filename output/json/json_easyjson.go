@@ -7,6 +7,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	lib "go.k6.io/k6/lib"
 	metrics "go.k6.io/k6/metrics"
 	time "time"
 )
@@ -89,6 +90,7 @@ func easyjson42239ddeDecode(in *jlexer.Lexer, out *struct {
 	Time  time.Time           `json:"time"`
 	Value float64             `json:"value"`
 	Tags  *metrics.SampleTags `json:"tags"`
+	Seq   uint64              `json:"seq,omitempty"`
 }) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
@@ -126,6 +128,8 @@ func easyjson42239ddeDecode(in *jlexer.Lexer, out *struct {
 					in.AddError((*out.Tags).UnmarshalJSON(data))
 				}
 			}
+		case "seq":
+			out.Seq = uint64(in.Uint64())
 		default:
 			in.SkipRecursive()
 		}
@@ -140,6 +144,7 @@ func easyjson42239ddeEncode(out *jwriter.Writer, in struct {
 	Time  time.Time           `json:"time"`
 	Value float64             `json:"value"`
 	Tags  *metrics.SampleTags `json:"tags"`
+	Seq   uint64              `json:"seq,omitempty"`
 }) {
 	out.RawByte('{')
 	first := true
@@ -163,6 +168,11 @@ func easyjson42239ddeEncode(out *jwriter.Writer, in struct {
 			(*in.Tags).MarshalEasyJSON(out)
 		}
 	}
+	if in.Seq != 0 {
+		const prefix string = ",\"seq\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Seq))
+	}
 	out.RawByte('}')
 }
 func easyjson42239ddeDecodeGoK6IoK6OutputJson1(in *jlexer.Lexer, out *metricEnvelope) {
@@ -442,3 +452,245 @@ func easyjson42239ddeEncodeGoK6IoK6Metrics1(out *jwriter.Writer, in metrics.Subm
 	}
 	out.RawByte('}')
 }
+func easyjson42239ddeDecodeGoK6IoK6OutputJson2(in *jlexer.Lexer, out *metadataEnvelope) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "type":
+			out.Type = string(in.String())
+		case "data":
+			easyjson42239ddeDecode1(in, &out.Data)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson42239ddeEncodeGoK6IoK6OutputJson2(out *jwriter.Writer, in metadataEnvelope) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"data\":"
+		out.RawString(prefix)
+		easyjson42239ddeEncode1(out, in.Data)
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v metadataEnvelope) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson42239ddeEncodeGoK6IoK6OutputJson2(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *metadataEnvelope) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson42239ddeDecodeGoK6IoK6OutputJson2(l, v)
+}
+func easyjson42239ddeDecode1(in *jlexer.Lexer, out *struct {
+	Environment lib.RunEnvironment `json:"environment"`
+	Metadata    map[string]string  `json:"metadata"`
+}) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "environment":
+			easyjson42239ddeDecodeGoK6IoK6Lib(in, &out.Environment)
+		case "metadata":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Metadata = make(map[string]string)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					v4 = string(in.String())
+					(out.Metadata)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson42239ddeEncode1(out *jwriter.Writer, in struct {
+	Environment lib.RunEnvironment `json:"environment"`
+	Metadata    map[string]string  `json:"metadata"`
+}) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"environment\":"
+		out.RawString(prefix[1:])
+		easyjson42239ddeEncodeGoK6IoK6Lib(out, in.Environment)
+	}
+	{
+		const prefix string = ",\"metadata\":"
+		out.RawString(prefix)
+		if in.Metadata == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v5First := true
+			for v5Name, v5Value := range in.Metadata {
+				if v5First {
+					v5First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v5Name))
+				out.RawByte(':')
+				out.String(string(v5Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+func easyjson42239ddeDecodeGoK6IoK6Lib(in *jlexer.Lexer, out *lib.RunEnvironment) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "k6_version":
+			out.K6Version = string(in.String())
+		case "os":
+			out.OS = string(in.String())
+		case "arch":
+			out.Arch = string(in.String())
+		case "hostname":
+			out.Hostname = string(in.String())
+		case "cpus":
+			out.CPUs = int(in.Int())
+		case "gomaxprocs":
+			out.GOMAXPROCS = int(in.Int())
+		case "open_files_limit":
+			out.OpenFilesLimit = uint64(in.Uint64())
+		case "container_cpu_limit":
+			out.ContainerCPULimit = float64(in.Float64())
+		case "container_memory_limit":
+			out.ContainerMemoryLimit = uint64(in.Uint64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson42239ddeEncodeGoK6IoK6Lib(out *jwriter.Writer, in lib.RunEnvironment) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"k6_version\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.K6Version))
+	}
+	{
+		const prefix string = ",\"os\":"
+		out.RawString(prefix)
+		out.String(string(in.OS))
+	}
+	{
+		const prefix string = ",\"arch\":"
+		out.RawString(prefix)
+		out.String(string(in.Arch))
+	}
+	{
+		const prefix string = ",\"hostname\":"
+		out.RawString(prefix)
+		out.String(string(in.Hostname))
+	}
+	{
+		const prefix string = ",\"cpus\":"
+		out.RawString(prefix)
+		out.Int(int(in.CPUs))
+	}
+	{
+		const prefix string = ",\"gomaxprocs\":"
+		out.RawString(prefix)
+		out.Int(int(in.GOMAXPROCS))
+	}
+	{
+		const prefix string = ",\"open_files_limit\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.OpenFilesLimit))
+	}
+	{
+		const prefix string = ",\"container_cpu_limit\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.ContainerCPULimit))
+	}
+	{
+		const prefix string = ",\"container_memory_limit\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.ContainerMemoryLimit))
+	}
+	out.RawByte('}')
+}
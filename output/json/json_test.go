@@ -28,10 +28,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mailru/easyjson"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output"
@@ -111,6 +113,27 @@ func TestJsonOutputStdout(t *testing.T) {
 	validateResults(stdout)
 }
 
+func TestJsonOutputMetadata(t *testing.T) {
+	t.Parallel()
+
+	stdout := new(bytes.Buffer)
+	out, err := New(output.Params{
+		Logger:         testutils.NewLogger(t),
+		StdOut:         stdout,
+		ScriptOptions:  lib.Options{Metadata: map[string]string{"build": "1234"}},
+		RunEnvironment: lib.RunEnvironment{K6Version: "0.38.1", OS: "linux", Arch: "amd64", CPUs: 8, GOMAXPROCS: 8},
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.Start())
+	require.NoError(t, out.Stop())
+
+	getValidator(t, []string{
+		`{"type":"Metadata","data":{"environment":{"k6_version":"0.38.1","os":"linux","arch":"amd64","hostname":"",` +
+			`"cpus":8,"gomaxprocs":8,"open_files_limit":0,"container_cpu_limit":0,"container_memory_limit":0},` +
+			`"metadata":{"build":"1234"}}}`,
+	})(stdout)
+}
+
 func TestJsonOutputFileError(t *testing.T) {
 	t.Parallel()
 
@@ -192,6 +215,20 @@ func TestWrapSampleWithSamplePointer(t *testing.T) {
 	assert.NotEqual(t, out, (*sampleEnvelope)(nil))
 }
 
+func TestWrapSampleSequenceNumber(t *testing.T) {
+	t.Parallel()
+	sample := metrics.Sample{
+		Metric: &metrics.Metric{Name: "my_metric"},
+		Time:   time.Unix(1614173830, 0).UTC(),
+		Value:  1,
+		Seq:    42,
+	}
+	data, err := easyjson.Marshal(wrapSample(sample))
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"Point","data":{"time":"2021-02-24T13:37:10Z","value":1,"tags":null,"seq":42},"metric":"my_metric"}`,
+		string(data))
+}
+
 func TestWrapMetricWithMetricPointer(t *testing.T) {
 	t.Parallel()
 	out := wrapMetric(&metrics.Metric{})
@@ -23,6 +23,7 @@ package json
 import (
 	"time"
 
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/metrics"
 )
 
@@ -35,6 +36,7 @@ type sampleEnvelope struct {
 		Time  time.Time         `json:"time"`
 		Value float64           `json:"value"`
 		Tags  *metrics.SampleTags `json:"tags"`
+		Seq   uint64            `json:"seq,omitempty"`
 	} `json:"data"`
 	Metric string `json:"metric"`
 }
@@ -49,6 +51,7 @@ func wrapSample(sample metrics.Sample) sampleEnvelope {
 	s.Data.Time = sample.Time
 	s.Data.Value = sample.Value
 	s.Data.Tags = sample.Tags
+	s.Data.Seq = sample.Seq
 	return s
 }
 
@@ -66,3 +69,24 @@ func wrapMetric(metric *metrics.Metric) metricEnvelope {
 		Data:   metric,
 	}
 }
+
+//easyjson:json
+type metadataEnvelope struct {
+	Type string `json:"type"`
+	Data struct {
+		Environment lib.RunEnvironment `json:"environment"`
+		Metadata    map[string]string  `json:"metadata"`
+	} `json:"data"`
+}
+
+// wrapMetadata is used to package the environment of the test run and the
+// user-provided metadata, which are written before all of the metrics.
+func wrapMetadata(env lib.RunEnvironment, metadata map[string]string) metadataEnvelope {
+	m := metadataEnvelope{Type: "Metadata"}
+	m.Data.Environment = env
+	m.Data.Metadata = metadata
+	if m.Data.Metadata == nil {
+		m.Data.Metadata = map[string]string{}
+	}
+	return m
+}
@@ -1,28 +1,137 @@
 package output
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/metrics"
 )
 
 // Manager can be used to manage multiple outputs at the same time.
 type Manager struct {
-	outputs []Output
-	logger  logrus.FieldLogger
+	outputs  []Output
+	controls []*control // nil for the outputs that implement WithoutRuntimeControl
+	logger   logrus.FieldLogger
 
 	testStopCallback func(error)
+
+	lastSeq uint64 // the sequence number of the last sample passed to the outputs
 }
 
 // NewManager returns a new manager for the given outputs.
 func NewManager(outputs []Output, logger logrus.FieldLogger, testStopCallback func(error)) *Manager {
+	controls := make([]*control, len(outputs))
+	for i, out := range outputs {
+		if _, ok := out.(WithoutRuntimeControl); !ok {
+			controls[i] = &control{samplingRatio: 1}
+		}
+	}
 	return &Manager{
 		outputs:          outputs,
+		controls:         controls,
 		logger:           logger.WithField("component", "output-manager"),
 		testStopCallback: testStopCallback,
 	}
 }
 
+// Status is the run-time state of an output, which can be paused and resumed,
+// or can receive only a part of the metric samples during the test run.
+type Status struct {
+	ID            int
+	Description   string
+	Paused        bool
+	SamplingRatio float64
+}
+
+// control keeps track of the run-time state of an output. The sampling is
+// deterministic - every sample container adds the sampling ratio to the
+// credit, and is passed to the output once the credit reaches 1.
+type control struct {
+	mx            sync.Mutex
+	paused        bool
+	samplingRatio float64
+	credit        float64
+}
+
+func (c *control) sample(sampleContainers []metrics.SampleContainer) []metrics.SampleContainer {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.paused {
+		return nil
+	}
+	if c.samplingRatio >= 1 {
+		return sampleContainers
+	}
+	result := make([]metrics.SampleContainer, 0, int(float64(len(sampleContainers))*c.samplingRatio)+1)
+	for _, sc := range sampleContainers {
+		c.credit += c.samplingRatio
+		if c.credit >= 1 {
+			c.credit--
+			result = append(result, sc)
+		}
+	}
+	return result
+}
+
+// GetStatuses returns the run-time state of all outputs that can be
+// controlled during the test run.
+func (om *Manager) GetStatuses() []Status {
+	result := make([]Status, 0, len(om.outputs))
+	for id := range om.outputs {
+		if status, err := om.GetStatus(id); err == nil {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+// GetStatus returns the run-time state of the output with the given ID.
+func (om *Manager) GetStatus(id int) (Status, error) {
+	if id < 0 || id >= len(om.outputs) || om.controls[id] == nil {
+		return Status{}, fmt.Errorf("there is no output with ID %d that can be controlled", id)
+	}
+	c := om.controls[id]
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return Status{
+		ID:            id,
+		Description:   om.outputs[id].Description(),
+		Paused:        c.paused,
+		SamplingRatio: c.samplingRatio,
+	}, nil
+}
+
+// UpdateStatus pauses or resumes the output with the given ID and changes its
+// sampling ratio, if they are specified. The ratio has to be in (0, 1].
+func (om *Manager) UpdateStatus(id int, paused null.Bool, samplingRatio null.Float) (Status, error) {
+	if _, err := om.GetStatus(id); err != nil {
+		return Status{}, err
+	}
+	if samplingRatio.Valid && (samplingRatio.Float64 <= 0 || samplingRatio.Float64 > 1) {
+		return Status{}, fmt.Errorf("the sampling ratio should be more than 0 and at most 1, but is %g",
+			samplingRatio.Float64)
+	}
+
+	c := om.controls[id]
+	c.mx.Lock()
+	if paused.Valid && paused.Bool != c.paused {
+		c.paused = paused.Bool
+		om.logger.WithField("output", om.outputs[id].Description()).Infof("Output paused: %t", c.paused)
+	}
+	if samplingRatio.Valid {
+		c.samplingRatio, c.credit = samplingRatio.Float64, 0
+	}
+	c.mx.Unlock()
+
+	return om.GetStatus(id)
+}
+
 // StartOutputs spins up all configured outputs. If some output fails to start,
 // it stops the already started ones. This may take some time, since some
 // outputs make initial network requests to set up whatever remote services are
@@ -69,13 +178,43 @@ func (om *Manager) SetRunStatus(status lib.RunStatus) {
 // AddMetricSamples is a temporary method to make the Manager usable in the
 // current Engine. It needs to be replaced with the full metric pump.
 //
+// The samples are given sequence numbers before they are passed to the
+// outputs, in the same order to each of them, so the outputs that are paused
+// or sampled will see gaps in the sequence.
+//
 // TODO: refactor
 func (om *Manager) AddMetricSamples(sampleContainers []metrics.SampleContainer) {
 	if len(sampleContainers) == 0 {
 		return
 	}
+	om.assignSequenceNumbers(sampleContainers)
 
-	for _, out := range om.outputs {
-		out.AddMetricSamples(sampleContainers)
+	for i, out := range om.outputs {
+		sampled := sampleContainers
+		if om.controls[i] != nil {
+			if sampled = om.controls[i].sample(sampleContainers); len(sampled) == 0 {
+				continue
+			}
+		}
+		out.AddMetricSamples(sampled)
+	}
+}
+
+// assignSequenceNumbers numbers the samples in the given containers in order.
+// It relies on GetSamples() returning the samples of the container itself,
+// except for single samples, which are replaced with their numbered copies.
+func (om *Manager) assignSequenceNumbers(sampleContainers []metrics.SampleContainer) {
+	for i, sc := range sampleContainers {
+		if sample, ok := sc.(metrics.Sample); ok {
+			om.lastSeq++
+			sample.Seq = om.lastSeq
+			sampleContainers[i] = sample
+			continue
+		}
+		samples := sc.GetSamples()
+		for j := range samples {
+			om.lastSeq++
+			samples[j].Seq = om.lastSeq
+		}
 	}
 }
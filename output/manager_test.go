@@ -0,0 +1,121 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/metrics"
+)
+
+type countingOutput struct {
+	received int
+}
+
+func (o *countingOutput) Description() string { return "counting" }
+func (o *countingOutput) Start() error        { return nil }
+func (o *countingOutput) Stop() error         { return nil }
+
+func (o *countingOutput) AddMetricSamples(scs []metrics.SampleContainer) {
+	o.received += len(scs)
+}
+
+type uncontrolledOutput struct {
+	countingOutput
+}
+
+func (o *uncontrolledOutput) DisallowRuntimeControl() {}
+
+func TestManagerRuntimeControl(t *testing.T) {
+	t.Parallel()
+
+	controlled, uncontrolled := &countingOutput{}, &uncontrolledOutput{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	om := NewManager([]Output{controlled, uncontrolled}, logger, nil)
+
+	assert.Equal(t, []Status{{ID: 0, Description: "counting", Paused: false, SamplingRatio: 1}}, om.GetStatuses())
+	_, err := om.GetStatus(1)
+	assert.Error(t, err)
+	_, err = om.UpdateStatus(2, null.BoolFrom(true), null.Float{})
+	assert.Error(t, err)
+
+	samples := make([]metrics.SampleContainer, 10)
+	for i := range samples {
+		samples[i] = metrics.Sample{}
+	}
+	send := func() {
+		om.AddMetricSamples(samples)
+	}
+
+	send()
+	assert.Equal(t, 10, controlled.received)
+	assert.Equal(t, 10, uncontrolled.received)
+
+	status, err := om.UpdateStatus(0, null.BoolFrom(true), null.Float{})
+	require.NoError(t, err)
+	assert.True(t, status.Paused)
+	send()
+	assert.Equal(t, 10, controlled.received)
+	assert.Equal(t, 20, uncontrolled.received)
+
+	status, err = om.UpdateStatus(0, null.BoolFrom(false), null.FloatFrom(0.25))
+	require.NoError(t, err)
+	assert.Equal(t, Status{ID: 0, Description: "counting", Paused: false, SamplingRatio: 0.25}, status)
+	send()
+	send()
+	assert.Equal(t, 15, controlled.received)
+	assert.Equal(t, 40, uncontrolled.received)
+
+	for _, ratio := range []float64{0, -1, 1.5} {
+		_, err = om.UpdateStatus(0, null.Bool{}, null.FloatFrom(ratio))
+		assert.Error(t, err, ratio)
+	}
+	status, err = om.GetStatus(0)
+	require.NoError(t, err)
+	assert.Equal(t, 0.25, status.SamplingRatio)
+}
+
+type recordingOutput struct {
+	countingOutput
+	seqs []uint64
+}
+
+func (o *recordingOutput) AddMetricSamples(scs []metrics.SampleContainer) {
+	for _, sc := range scs {
+		for _, sample := range sc.GetSamples() {
+			o.seqs = append(o.seqs, sample.Seq)
+		}
+	}
+}
+
+func TestManagerSequenceNumbers(t *testing.T) {
+	t.Parallel()
+
+	first, second := &recordingOutput{}, &recordingOutput{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	om := NewManager([]Output{first, second}, logger, nil)
+
+	send := func() {
+		om.AddMetricSamples([]metrics.SampleContainer{
+			metrics.Sample{},
+			metrics.Samples{{}, {}},
+			metrics.ConnectedSamples{Samples: []metrics.Sample{{}}},
+		})
+	}
+	send()
+	_, err := om.UpdateStatus(1, null.BoolFrom(true), null.Float{})
+	require.NoError(t, err)
+	send()
+	_, err = om.UpdateStatus(1, null.BoolFrom(false), null.Float{})
+	require.NoError(t, err)
+	send()
+
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, first.seqs)
+	// the samples sent while the second output was paused are a gap
+	assert.Equal(t, []uint64{1, 2, 3, 4, 9, 10, 11, 12}, second.seqs)
+}
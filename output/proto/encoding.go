@@ -0,0 +1,581 @@
+package proto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/metrics"
+)
+
+// maxRecordSize protects the decoder from allocating huge buffers for
+// corrupted files.
+const maxRecordSize = 64 << 20
+
+// maxCachedTagSetPtrs bounds the cache of the tag set IDs by their pointers,
+// so the encoder doesn't keep every tag set of the test run alive.
+const maxCachedTagSetPtrs = 1 << 14
+
+// Header is the first record of every results file.
+type Header struct {
+	FormatVersion uint32
+	K6Version     string
+	// Schema is the serialized google.protobuf.FileDescriptorSet of the
+	// records, i.e. samples.proto.
+	Schema []byte
+}
+
+// Metadata describes the test run, it's written after the header when the
+// results file is written by k6 run.
+type Metadata struct {
+	Environment lib.RunEnvironment
+	// Metadata is the metadata option of the script.
+	Metadata map[string]string
+}
+
+// Encoder writes samples as length-delimited protobuf records, emitting the
+// definitions of their metrics and tag sets the first time they are used.
+type Encoder struct {
+	w        io.Writer
+	buf, msg []byte
+	metrics  map[string]uint32
+	tagSets  map[string]uint32
+	// tagSetPtrs caches the IDs of the tag sets by their pointers, since
+	// the same *metrics.SampleTags is usually shared by many samples.
+	tagSetPtrs map[*metrics.SampleTags]uint32
+}
+
+// NewEncoder returns an encoder writing to w, after it writes the header
+// with the schema of the records.
+func NewEncoder(w io.Writer) (*Encoder, error) {
+	schema, err := marshalSchema()
+	if err != nil {
+		return nil, err
+	}
+	e := &Encoder{
+		w:          w,
+		metrics:    make(map[string]uint32),
+		tagSets:    make(map[string]uint32),
+		tagSetPtrs: make(map[*metrics.SampleTags]uint32),
+	}
+
+	var msg []byte
+	msg = protowire.AppendTag(msg, headerFormatVersionField, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, formatVersion)
+	msg = protowire.AppendTag(msg, headerK6VersionField, protowire.BytesType)
+	msg = protowire.AppendString(msg, consts.Version)
+	msg = protowire.AppendTag(msg, headerSchemaField, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, schema)
+	e.appendRecord(recordHeaderField, msg)
+
+	return e, e.flush()
+}
+
+// EncodeMetadata writes the environment and the metadata of the test run. It
+// should be called before any samples are encoded.
+func (e *Encoder) EncodeMetadata(md Metadata) error {
+	env := md.Environment
+	var msg []byte
+	for _, f := range []struct {
+		num   protowire.Number
+		value string
+	}{
+		{metadataK6VersionField, env.K6Version},
+		{metadataOSField, env.OS},
+		{metadataArchField, env.Arch},
+		{metadataHostnameField, env.Hostname},
+	} {
+		msg = protowire.AppendTag(msg, f.num, protowire.BytesType)
+		msg = protowire.AppendString(msg, f.value)
+	}
+	for _, f := range []struct {
+		num   protowire.Number
+		value uint64
+	}{
+		{metadataCPUsField, uint64(env.CPUs)},
+		{metadataGOMAXPROCSField, uint64(env.GOMAXPROCS)},
+		{metadataOpenFilesLimitField, env.OpenFilesLimit},
+		{metadataContainerMemoryLimitField, env.ContainerMemoryLimit},
+	} {
+		msg = protowire.AppendTag(msg, f.num, protowire.VarintType)
+		msg = protowire.AppendVarint(msg, f.value)
+	}
+	msg = protowire.AppendTag(msg, metadataContainerCPULimitField, protowire.Fixed64Type)
+	msg = protowire.AppendFixed64(msg, math.Float64bits(env.ContainerCPULimit))
+
+	keys := make([]string, 0, len(md.Metadata))
+	for k := range md.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var entry []byte
+		entry = protowire.AppendTag(entry, mapEntryKeyField, protowire.BytesType)
+		entry = protowire.AppendString(entry, k)
+		entry = protowire.AppendTag(entry, mapEntryValueField, protowire.BytesType)
+		entry = protowire.AppendString(entry, md.Metadata[k])
+		msg = protowire.AppendTag(msg, metadataMetadataField, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, entry)
+	}
+	e.appendRecord(recordMetadataField, msg)
+	return e.flush()
+}
+
+// Encode writes the given samples.
+func (e *Encoder) Encode(samples []metrics.Sample) error {
+	for _, s := range samples {
+		e.appendSample(s)
+	}
+	return e.flush()
+}
+
+func (e *Encoder) flush() error {
+	_, err := e.w.Write(e.buf)
+	e.buf = e.buf[:0]
+	return err
+}
+
+func (e *Encoder) appendRecord(recordField protowire.Number, msg []byte) {
+	size := protowire.SizeTag(recordField) + protowire.SizeBytes(len(msg))
+	e.buf = protowire.AppendVarint(e.buf, uint64(size))
+	e.buf = protowire.AppendTag(e.buf, recordField, protowire.BytesType)
+	e.buf = protowire.AppendBytes(e.buf, msg)
+}
+
+func (e *Encoder) metricID(m *metrics.Metric) uint32 {
+	if id, ok := e.metrics[m.Name]; ok {
+		return id
+	}
+	id := uint32(len(e.metrics) + 1)
+	e.metrics[m.Name] = id
+
+	msg := e.msg[:0]
+	msg = protowire.AppendTag(msg, metricIDField, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(id))
+	msg = protowire.AppendTag(msg, metricNameField, protowire.BytesType)
+	msg = protowire.AppendString(msg, m.Name)
+	msg = protowire.AppendTag(msg, metricTypeField, protowire.BytesType)
+	msg = protowire.AppendString(msg, m.Type.String())
+	msg = protowire.AppendTag(msg, metricContainsField, protowire.BytesType)
+	msg = protowire.AppendString(msg, m.Contains.String())
+	e.appendRecord(recordMetricField, msg)
+	e.msg = msg
+
+	return id
+}
+
+// tagSetID returns the ID of the given tags, or 0 if there are none.
+func (e *Encoder) tagSetID(tags *metrics.SampleTags) uint32 {
+	if tags.IsEmpty() {
+		return 0
+	}
+	if id, ok := e.tagSetPtrs[tags]; ok {
+		return id
+	}
+	id := e.tagSetIDByKey(tags)
+	if len(e.tagSetPtrs) >= maxCachedTagSetPtrs {
+		e.tagSetPtrs = make(map[*metrics.SampleTags]uint32)
+	}
+	e.tagSetPtrs[tags] = id
+	return id
+}
+
+// tagSetIDByKey returns the ID of the given tags by their contents, writing
+// the definition of the tag set if it's new.
+func (e *Encoder) tagSetIDByKey(tags *metrics.SampleTags) uint32 {
+	tagsMap := tags.CloneTags()
+	keys := make([]string, 0, len(tagsMap))
+	for k := range tagsMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(tagsMap[k])
+		b.WriteByte(0)
+	}
+	if id, ok := e.tagSets[b.String()]; ok {
+		return id
+	}
+	id := uint32(len(e.tagSets) + 1)
+	e.tagSets[b.String()] = id
+
+	msg := e.msg[:0]
+	msg = protowire.AppendTag(msg, tagSetIDField, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(id))
+	for _, k := range keys {
+		var entry []byte
+		entry = protowire.AppendTag(entry, mapEntryKeyField, protowire.BytesType)
+		entry = protowire.AppendString(entry, k)
+		entry = protowire.AppendTag(entry, mapEntryValueField, protowire.BytesType)
+		entry = protowire.AppendString(entry, tagsMap[k])
+		msg = protowire.AppendTag(msg, tagSetTagsField, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, entry)
+	}
+	e.appendRecord(recordTagSetField, msg)
+	e.msg = msg
+
+	return id
+}
+
+func (e *Encoder) appendSample(s metrics.Sample) {
+	metricID, tagSetID := e.metricID(s.Metric), e.tagSetID(s.Tags)
+
+	msg := e.msg[:0]
+	msg = protowire.AppendTag(msg, sampleMetricIDField, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(metricID))
+	if tagSetID != 0 {
+		msg = protowire.AppendTag(msg, sampleTagSetIDField, protowire.VarintType)
+		msg = protowire.AppendVarint(msg, uint64(tagSetID))
+	}
+	msg = protowire.AppendTag(msg, sampleTimeUnixNanoField, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(s.Time.UnixNano()))
+	msg = protowire.AppendTag(msg, sampleValueField, protowire.Fixed64Type)
+	msg = protowire.AppendFixed64(msg, math.Float64bits(s.Value))
+	if s.Seq != 0 {
+		msg = protowire.AppendTag(msg, sampleSeqField, protowire.VarintType)
+		msg = protowire.AppendVarint(msg, s.Seq)
+	}
+	e.appendRecord(recordSampleField, msg)
+	e.msg = msg
+}
+
+// Decoder reads the samples from a results file written by an Encoder.
+type Decoder struct {
+	r        *bufio.Reader
+	header   Header
+	metadata *Metadata
+	rec      []byte
+	metrics  map[uint32]*metrics.Metric
+	tagSets  map[uint32]*metrics.SampleTags
+
+	// pending is the record after the header, if it isn't the metadata.
+	pending *pendingRecord
+}
+
+type pendingRecord struct {
+	num protowire.Number
+	msg []byte
+	err error
+}
+
+// NewDecoder returns a decoder reading from r, after it reads and validates
+// the header of the results file.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	d := &Decoder{
+		r:       bufio.NewReader(r),
+		metrics: make(map[uint32]*metrics.Metric),
+		tagSets: make(map[uint32]*metrics.SampleTags),
+	}
+	num, msg, err := d.readRecord()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the results file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if num != recordHeaderField {
+		return nil, errors.New("the results file doesn't start with a header, it's probably not a k6 results file")
+	}
+	if err = d.decodeHeader(msg); err != nil {
+		return nil, err
+	}
+	if d.header.FormatVersion != formatVersion {
+		return nil, fmt.Errorf("unsupported results file format version %d", d.header.FormatVersion)
+	}
+
+	// the metadata follows the header, so it's available before the samples
+	num, msg, err = d.readRecord()
+	if err != nil || num != recordMetadataField {
+		d.pending = &pendingRecord{num: num, msg: append([]byte(nil), msg...), err: err}
+		return d, nil
+	}
+	if err = d.decodeMetadata(msg); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Header returns the header of the results file.
+func (d *Decoder) Header() Header {
+	return d.header
+}
+
+// Metadata returns the metadata of the test run, or false if the results file
+// doesn't contain it, e.g. because it wasn't written by k6 run.
+func (d *Decoder) Metadata() (Metadata, bool) {
+	if d.metadata == nil {
+		return Metadata{}, false
+	}
+	return *d.metadata, true
+}
+
+// Next returns the next sample in the results file, or io.EOF if there are
+// no more samples.
+func (d *Decoder) Next() (metrics.Sample, error) {
+	for {
+		num, msg, err := d.nextRecord()
+		if err != nil {
+			return metrics.Sample{}, err
+		}
+		switch num {
+		case recordMetricField:
+			err = d.decodeMetric(msg)
+		case recordTagSetField:
+			err = d.decodeTagSet(msg)
+		case recordSampleField:
+			return d.decodeSample(msg)
+		default:
+			// ignore unknown records, they may be from newer k6 versions
+		}
+		if err != nil {
+			return metrics.Sample{}, err
+		}
+	}
+}
+
+// nextRecord returns the pending record, if there is one, or it reads the
+// next record.
+func (d *Decoder) nextRecord() (protowire.Number, []byte, error) {
+	if p := d.pending; p != nil {
+		d.pending = nil
+		return p.num, p.msg, p.err
+	}
+	return d.readRecord()
+}
+
+// readRecord reads the next record and returns the number and the contents
+// of its only field.
+func (d *Decoder) readRecord() (protowire.Number, []byte, error) {
+	size, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, nil, io.EOF
+		}
+		return 0, nil, fmt.Errorf("couldn't read the size of a record: %w", err)
+	}
+	if size > maxRecordSize {
+		return 0, nil, fmt.Errorf("invalid record size %d", size)
+	}
+	if cap(d.rec) < int(size) {
+		d.rec = make([]byte, size)
+	}
+	d.rec = d.rec[:size]
+	if _, err = io.ReadFull(d.r, d.rec); err != nil {
+		return 0, nil, fmt.Errorf("couldn't read a record: %w", err)
+	}
+
+	var (
+		num protowire.Number
+		msg []byte
+	)
+	err = rangeFields(d.rec, func(n protowire.Number, t protowire.Type, b []byte) error {
+		if t != protowire.BytesType {
+			return nil
+		}
+		num, msg = n, b
+		return nil
+	})
+	return num, msg, err
+}
+
+// rangeFields calls fn for every field in the message. For bytes fields, b
+// contains the value, for varint and fixed fields it contains the encoded
+// value, which can be parsed with the protowire.Consume functions.
+func rangeFields(msg []byte, fn func(num protowire.Number, typ protowire.Type, b []byte) error) error {
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return fmt.Errorf("invalid record: %w", protowire.ParseError(n))
+		}
+		msg = msg[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			value, n = protowire.ConsumeBytes(msg)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, msg)
+			if n >= 0 {
+				value = msg[:n]
+			}
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid record: %w", protowire.ParseError(n))
+		}
+		msg = msg[n:]
+
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func consumeVarint(b []byte) uint64 {
+	v, _ := protowire.ConsumeVarint(b)
+	return v
+}
+
+func (d *Decoder) decodeHeader(msg []byte) error {
+	return rangeFields(msg, func(num protowire.Number, _ protowire.Type, b []byte) error {
+		switch num {
+		case headerFormatVersionField:
+			d.header.FormatVersion = uint32(consumeVarint(b))
+		case headerK6VersionField:
+			d.header.K6Version = string(b)
+		case headerSchemaField:
+			d.header.Schema = append([]byte(nil), b...)
+		}
+		return nil
+	})
+}
+
+func (d *Decoder) decodeMetadata(msg []byte) error {
+	md := &Metadata{Metadata: make(map[string]string)}
+	env := &md.Environment
+	err := rangeFields(msg, func(num protowire.Number, _ protowire.Type, b []byte) error {
+		switch num {
+		case metadataK6VersionField:
+			env.K6Version = string(b)
+		case metadataOSField:
+			env.OS = string(b)
+		case metadataArchField:
+			env.Arch = string(b)
+		case metadataHostnameField:
+			env.Hostname = string(b)
+		case metadataCPUsField:
+			env.CPUs = int(consumeVarint(b))
+		case metadataGOMAXPROCSField:
+			env.GOMAXPROCS = int(consumeVarint(b))
+		case metadataOpenFilesLimitField:
+			env.OpenFilesLimit = consumeVarint(b)
+		case metadataContainerCPULimitField:
+			v, _ := protowire.ConsumeFixed64(b)
+			env.ContainerCPULimit = math.Float64frombits(v)
+		case metadataContainerMemoryLimitField:
+			env.ContainerMemoryLimit = consumeVarint(b)
+		case metadataMetadataField:
+			var key, value string
+			err := rangeFields(b, func(num protowire.Number, _ protowire.Type, b []byte) error {
+				switch num {
+				case mapEntryKeyField:
+					key = string(b)
+				case mapEntryValueField:
+					value = string(b)
+				}
+				return nil
+			})
+			md.Metadata[key] = value
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	d.metadata = md
+	return nil
+}
+
+func (d *Decoder) decodeMetric(msg []byte) error {
+	var id uint32
+	m := &metrics.Metric{}
+	err := rangeFields(msg, func(num protowire.Number, _ protowire.Type, b []byte) error {
+		switch num {
+		case metricIDField:
+			id = uint32(consumeVarint(b))
+		case metricNameField:
+			m.Name = string(b)
+		case metricTypeField:
+			return m.Type.UnmarshalText(b)
+		case metricContainsField:
+			return m.Contains.UnmarshalText(b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	d.metrics[id] = m
+	return nil
+}
+
+func (d *Decoder) decodeTagSet(msg []byte) error {
+	var id uint32
+	tags := make(map[string]string)
+	err := rangeFields(msg, func(num protowire.Number, _ protowire.Type, b []byte) error {
+		switch num {
+		case tagSetIDField:
+			id = uint32(consumeVarint(b))
+		case tagSetTagsField:
+			var key, value string
+			err := rangeFields(b, func(num protowire.Number, _ protowire.Type, b []byte) error {
+				switch num {
+				case mapEntryKeyField:
+					key = string(b)
+				case mapEntryValueField:
+					value = string(b)
+				}
+				return nil
+			})
+			tags[key] = value
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	d.tagSets[id] = metrics.IntoSampleTags(&tags)
+	return nil
+}
+
+func (d *Decoder) decodeSample(msg []byte) (metrics.Sample, error) {
+	var (
+		s                  metrics.Sample
+		metricID, tagSetID uint32
+	)
+	err := rangeFields(msg, func(num protowire.Number, _ protowire.Type, b []byte) error {
+		switch num {
+		case sampleMetricIDField:
+			metricID = uint32(consumeVarint(b))
+		case sampleTagSetIDField:
+			tagSetID = uint32(consumeVarint(b))
+		case sampleTimeUnixNanoField:
+			s.Time = time.Unix(0, int64(consumeVarint(b)))
+		case sampleValueField:
+			v, _ := protowire.ConsumeFixed64(b)
+			s.Value = math.Float64frombits(v)
+		case sampleSeqField:
+			s.Seq = consumeVarint(b)
+		}
+		return nil
+	})
+	if err != nil {
+		return s, err
+	}
+
+	var ok bool
+	if s.Metric, ok = d.metrics[metricID]; !ok {
+		return s, fmt.Errorf("invalid record: unknown metric ID %d", metricID)
+	}
+	if tagSetID != 0 {
+		if s.Tags, ok = d.tagSets[tagSetID]; !ok {
+			return s, fmt.Errorf("invalid record: unknown tag set ID %d", tagSetID)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,129 @@
+// Package proto implements an output writing the raw samples as
+// length-delimited protocol buffer records, described by samples.proto.
+package proto
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/output"
+)
+
+const flushPeriod = 200 * time.Millisecond
+
+// Output writes all passed samples to an (optionally gzipped) binary file.
+type Output struct {
+	output.SampleBuffer
+
+	params          output.Params
+	periodicFlusher *output.PeriodicFlusher
+
+	logger   logrus.FieldLogger
+	filename string
+	encoder  *Encoder
+	closeFn  func() error
+}
+
+// New returns a new proto output.
+func New(params output.Params) (output.Output, error) {
+	return &Output{
+		params:   params,
+		filename: params.ConfigArgument,
+		logger: params.Logger.WithFields(logrus.Fields{
+			"output":   "proto",
+			"filename": params.ConfigArgument,
+		}),
+	}, nil
+}
+
+// Description returns a human-readable description of the output.
+func (o *Output) Description() string {
+	if o.filename == "" || o.filename == "-" {
+		return "proto (stdout)"
+	}
+	return fmt.Sprintf("proto (%s)", o.filename)
+}
+
+// Start opens the file, writes the header with the schema and starts the
+// goroutine for flushing the samples.
+func (o *Output) Start() error {
+	o.logger.Debug("Starting...")
+
+	var out io.Writer
+	if o.filename == "" || o.filename == "-" {
+		w := bufio.NewWriter(o.params.StdOut)
+		o.closeFn = w.Flush
+		out = w
+	} else {
+		file, err := o.params.FS.Create(o.filename)
+		if err != nil {
+			return err
+		}
+		w := bufio.NewWriter(file)
+		out = w
+		var gzipWriter *gzip.Writer
+		if strings.HasSuffix(o.filename, ".gz") {
+			gzipWriter = gzip.NewWriter(w)
+			out = gzipWriter
+		}
+		o.closeFn = func() error {
+			if gzipWriter != nil {
+				_ = gzipWriter.Close()
+			}
+			_ = w.Flush()
+			return file.Close()
+		}
+	}
+
+	encoder, err := NewEncoder(out)
+	if err != nil {
+		return err
+	}
+	o.encoder = encoder
+	// the environment is only known when the output is created by k6 run
+	if env := o.params.RunEnvironment; env.K6Version != "" {
+		if err = encoder.EncodeMetadata(Metadata{Environment: env, Metadata: o.params.ScriptOptions.Metadata}); err != nil {
+			return err
+		}
+	}
+
+	pf, err := output.NewPeriodicFlusher(flushPeriod, o.flushMetrics)
+	if err != nil {
+		return err
+	}
+	o.logger.Debug("Started!")
+	o.periodicFlusher = pf
+
+	return nil
+}
+
+// Stop flushes any remaining samples and closes the file.
+func (o *Output) Stop() error {
+	o.logger.Debug("Stopping...")
+	defer o.logger.Debug("Stopped!")
+	o.periodicFlusher.Stop()
+	return o.closeFn()
+}
+
+func (o *Output) flushMetrics() {
+	containers := o.GetBufferedSamples()
+	start := time.Now()
+	var count int
+	for _, sc := range containers {
+		samples := sc.GetSamples()
+		count += len(samples)
+		if err := o.encoder.Encode(samples); err != nil {
+			o.logger.WithError(err).Error("Couldn't write the samples")
+			return
+		}
+	}
+	if count > 0 {
+		o.logger.WithField("t", time.Since(start)).WithField("count", count).Debug("Wrote the samples")
+	}
+}
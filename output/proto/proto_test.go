@@ -0,0 +1,301 @@
+package proto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+	"go.k6.io/k6/output/json"
+)
+
+func generateSamples(t testing.TB, count int) []metrics.Sample {
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+
+	start := time.Date(2022, time.May, 4, 13, 37, 0, 123456789, time.UTC)
+	samples := make([]metrics.Sample, 0, count)
+	for i := 0; i < count; i++ {
+		tags := metrics.NewSampleTags(map[string]string{
+			"expected_response": "true",
+			"group":             "",
+			"method":            "GET",
+			"name":              fmt.Sprintf("https://test.k6.io/items/%d", i%10),
+			"proto":             "HTTP/1.1",
+			"scenario":          "default",
+			"status":            "200",
+			"tls_version":       "tls1.3",
+			"url":               fmt.Sprintf("https://test.k6.io/items/%d", i%10),
+		})
+		samples = append(samples, metrics.Sample{
+			Metric: builtinMetrics.HTTPReqDuration,
+			Time:   start.Add(time.Duration(i) * time.Millisecond),
+			Tags:   tags,
+			Value:  float64(i) * 1.337,
+			Seq:    uint64(i + 1),
+		})
+	}
+	samples = append(samples, metrics.Sample{Metric: builtinMetrics.VUs, Time: start, Value: 10})
+	return samples
+}
+
+func readAll(t testing.TB, r io.Reader) (Header, []metrics.Sample) {
+	decoder, err := NewDecoder(r)
+	require.NoError(t, err)
+	var samples []metrics.Sample
+	for {
+		s, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return decoder.Header(), samples
+		}
+		require.NoError(t, err)
+		samples = append(samples, s)
+	}
+}
+
+func assertSamplesEqual(t testing.TB, expected, actual []metrics.Sample) {
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].Metric.Name, actual[i].Metric.Name)
+		assert.Equal(t, expected[i].Metric.Type, actual[i].Metric.Type)
+		assert.Equal(t, expected[i].Metric.Contains, actual[i].Metric.Contains)
+		assert.Equal(t, expected[i].Time.UnixNano(), actual[i].Time.UnixNano())
+		assert.Equal(t, expected[i].Value, actual[i].Value)
+		assert.Equal(t, expected[i].Seq, actual[i].Seq)
+		assert.Equal(t, expected[i].Tags.CloneTags(), actual[i].Tags.CloneTags())
+	}
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	t.Parallel()
+
+	samples := generateSamples(t, 100)
+	buf := new(bytes.Buffer)
+	encoder, err := NewEncoder(buf)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(samples[:50]))
+	require.NoError(t, encoder.Encode(samples[50:]))
+
+	header, decoded := readAll(t, buf)
+	assert.Equal(t, uint32(formatVersion), header.FormatVersion)
+	assert.NotEmpty(t, header.K6Version)
+	assertSamplesEqual(t, samples, decoded)
+}
+
+func TestEncoderTagSetCache(t *testing.T) {
+	t.Parallel()
+
+	samples := generateSamples(t, 100)
+	encoder, err := NewEncoder(new(bytes.Buffer))
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(samples))
+	// the generated samples have 10 distinct tag sets, each in its own
+	// *SampleTags, and the vus sample has none
+	assert.Len(t, encoder.tagSets, 10)
+	assert.Len(t, encoder.tagSetPtrs, 100)
+
+	// equal tag sets in different pointers still get the same ID
+	assert.Equal(t, encoder.tagSetID(samples[0].Tags), encoder.tagSetID(samples[10].Tags))
+	assert.Len(t, encoder.tagSets, 10)
+}
+
+func TestEncoderSize(t *testing.T) {
+	t.Parallel()
+
+	samples := generateSamples(t, 1000)
+	protoBuf := new(bytes.Buffer)
+	encoder, err := NewEncoder(protoBuf)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(samples))
+
+	jsonBuf := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(jsonBuf).Encode(samples))
+
+	ratio := float64(jsonBuf.Len()) / float64(protoBuf.Len())
+	assert.Greater(t, ratio, 5.0, "JSON is %d bytes, proto is %d bytes", jsonBuf.Len(), protoBuf.Len())
+}
+
+// TestEmbeddedSchema checks that all records can be decoded with a generic
+// protobuf decoder, using only the schema embedded in the header.
+func TestEmbeddedSchema(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	encoder, err := NewEncoder(buf)
+	require.NoError(t, err)
+	require.NoError(t, encoder.EncodeMetadata(testMetadata))
+	require.NoError(t, encoder.Encode(generateSamples(t, 3)))
+
+	decoder, err := NewDecoder(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	fds := &descriptorpb.FileDescriptorSet{}
+	require.NoError(t, protobuf.Unmarshal(decoder.Header().Schema, fds))
+	files, err := protodesc.NewFiles(fds)
+	require.NoError(t, err)
+	desc, err := files.FindDescriptorByName("k6.output.v1.Record")
+	require.NoError(t, err)
+	recordDesc := desc.(protoreflect.MessageDescriptor) //nolint:forcetypeassert
+
+	var records []*dynamicpb.Message
+	data := buf.Bytes()
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+		require.Greater(t, n, 0)
+		data = data[n:]
+		record := dynamicpb.NewMessage(recordDesc)
+		require.NoError(t, protobuf.Unmarshal(data[:size], record))
+		require.Empty(t, record.GetUnknown())
+		records = append(records, record)
+		data = data[size:]
+	}
+
+	// header, metadata, metric, tag set, sample, tag set, sample, tag set, sample, metric, sample
+	require.Len(t, records, 11)
+	which := func(m *dynamicpb.Message) string {
+		return string(m.WhichOneof(recordDesc.Oneofs().ByName("record")).Name())
+	}
+	assert.Equal(t, "header", which(records[0]))
+	assert.Equal(t, "metadata", which(records[1]))
+	metadata := records[1].Get(recordDesc.Fields().ByName("metadata")).Message()
+	assert.Equal(t, "generator-1", metadata.Get(metadata.Descriptor().Fields().ByName("hostname")).String())
+	assert.Equal(t, 2.5, metadata.Get(metadata.Descriptor().Fields().ByName("container_cpu_limit")).Float())
+	assert.Equal(t, "1234", metadata.Get(metadata.Descriptor().Fields().ByName("metadata")).Map().
+		Get(protoreflect.ValueOfString("build").MapKey()).String())
+	records = append(records[:1], records[2:]...)
+	assert.Equal(t, "metric", which(records[1]))
+	metric := records[1].Get(recordDesc.Fields().ByName("metric")).Message()
+	assert.Equal(t, "http_req_duration", metric.Get(metric.Descriptor().Fields().ByName("name")).String())
+	assert.Equal(t, "trend", metric.Get(metric.Descriptor().Fields().ByName("type")).String())
+	assert.Equal(t, "tag_set", which(records[2]))
+	tagSet := records[2].Get(recordDesc.Fields().ByName("tag_set")).Message()
+	assert.Equal(t, 9, tagSet.Get(tagSet.Descriptor().Fields().ByName("tags")).Map().Len())
+	assert.Equal(t, "sample", which(records[3]))
+	sample := records[3].Get(recordDesc.Fields().ByName("sample")).Message()
+	assert.Equal(t, int64(1651671420123456789),
+		sample.Get(sample.Descriptor().Fields().ByName("time_unix_nano")).Int())
+	assert.Equal(t, uint64(1), sample.Get(sample.Descriptor().Fields().ByName("seq")).Uint())
+	assert.Equal(t, "sample", which(records[9]))
+}
+
+var testMetadata = Metadata{ //nolint:gochecknoglobals
+	Environment: lib.RunEnvironment{
+		K6Version: "0.38.1", OS: "linux", Arch: "amd64", Hostname: "generator-1", CPUs: 8, GOMAXPROCS: 4,
+		OpenFilesLimit: 1048576, ContainerCPULimit: 2.5, ContainerMemoryLimit: 4 << 30,
+	},
+	Metadata: map[string]string{"build": "1234", "branch": "main"},
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	encoder, err := NewEncoder(buf)
+	require.NoError(t, err)
+	require.NoError(t, encoder.EncodeMetadata(testMetadata))
+	samples := generateSamples(t, 2)
+	require.NoError(t, encoder.Encode(samples))
+
+	decoder, err := NewDecoder(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	metadata, ok := decoder.Metadata()
+	require.True(t, ok)
+	assert.Equal(t, testMetadata, metadata)
+	sample, err := decoder.Next()
+	require.NoError(t, err)
+	assertSamplesEqual(t, samples[:1], []metrics.Sample{sample})
+
+	// the files written by other commands than k6 run don't have any metadata
+	buf.Reset()
+	encoder, err = NewEncoder(buf)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(samples))
+	_, decoded := readAll(t, bytes.NewReader(buf.Bytes()))
+	assertSamplesEqual(t, samples, decoded)
+	decoder, err = NewDecoder(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	_, ok = decoder.Metadata()
+	assert.False(t, ok)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewDecoder(bytes.NewReader(nil))
+	assert.ErrorContains(t, err, "the results file is empty")
+
+	_, err = NewDecoder(bytes.NewReader([]byte(`{"type":"Metric"}`)))
+	assert.Error(t, err)
+
+	buf := new(bytes.Buffer)
+	encoder, err := NewEncoder(buf)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(generateSamples(t, 1)))
+	decoder, err := NewDecoder(bytes.NewReader(buf.Bytes()[:buf.Len()-3]))
+	require.NoError(t, err)
+	_, err = decoder.Next()
+	require.NoError(t, err)
+	_, err = decoder.Next()
+	assert.ErrorContains(t, err, "couldn't read a record")
+}
+
+func TestOutput(t *testing.T) {
+	t.Parallel()
+
+	for _, filename := range []string{"/results.pb", "/results.pb.gz"} {
+		filename := filename
+		t.Run(filename, func(t *testing.T) {
+			t.Parallel()
+
+			fs := afero.NewMemMapFs()
+			out, err := New(output.Params{
+				Logger:         testutils.NewLogger(t),
+				FS:             fs,
+				ConfigArgument: filename,
+				RunEnvironment: testMetadata.Environment,
+				ScriptOptions:  lib.Options{Metadata: testMetadata.Metadata},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "proto ("+filename+")", out.Description())
+			require.NoError(t, out.Start())
+
+			samples := generateSamples(t, 10)
+			out.AddMetricSamples([]metrics.SampleContainer{metrics.Samples(samples[:5]), metrics.Samples(samples[5:])})
+			require.NoError(t, out.Stop())
+
+			f, err := fs.Open(filename)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+			var r io.Reader = f
+			if filename == "/results.pb.gz" {
+				r, err = gzip.NewReader(f)
+				require.NoError(t, err)
+			}
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			decoder, err := NewDecoder(bytes.NewReader(data))
+			require.NoError(t, err)
+			metadata, ok := decoder.Metadata()
+			require.True(t, ok)
+			assert.Equal(t, testMetadata, metadata)
+			_, decoded := readAll(t, bytes.NewReader(data))
+			assertSamplesEqual(t, samples, decoded)
+		})
+	}
+}
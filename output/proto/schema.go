@@ -0,0 +1,182 @@
+package proto
+
+import (
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// The field numbers of the messages in samples.proto.
+const (
+	recordHeaderField   = 1
+	recordMetricField   = 2
+	recordTagSetField   = 3
+	recordSampleField   = 4
+	recordMetadataField = 5
+
+	headerFormatVersionField = 1
+	headerK6VersionField     = 2
+	headerSchemaField        = 3
+
+	metadataK6VersionField            = 1
+	metadataOSField                   = 2
+	metadataArchField                 = 3
+	metadataHostnameField             = 4
+	metadataCPUsField                 = 5
+	metadataGOMAXPROCSField           = 6
+	metadataOpenFilesLimitField       = 7
+	metadataContainerCPULimitField    = 8
+	metadataContainerMemoryLimitField = 9
+	metadataMetadataField             = 10
+
+	metricIDField       = 1
+	metricNameField     = 2
+	metricTypeField     = 3
+	metricContainsField = 4
+
+	tagSetIDField   = 1
+	tagSetTagsField = 2
+
+	mapEntryKeyField   = 1
+	mapEntryValueField = 2
+
+	sampleMetricIDField     = 1
+	sampleTagSetIDField     = 2
+	sampleTimeUnixNanoField = 3
+	sampleValueField        = 4
+	sampleSeqField          = 5
+)
+
+// formatVersion is the version of the file format, written in the header.
+const formatVersion = 1
+
+// schemaPackage is the protobuf package of the messages in samples.proto.
+const schemaPackage = "k6.output.v1"
+
+func field(
+	name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string,
+) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:   protobuf.String(name),
+		Number: protobuf.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = protobuf.String("." + schemaPackage + "." + typeName)
+	}
+	return f
+}
+
+func oneofField(name string, number int32, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := field(name, number, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName)
+	f.OneofIndex = protobuf.Int32(0)
+	return f
+}
+
+// schemaDescriptor returns the descriptor of samples.proto, which is embedded
+// in the header of every results file.
+func schemaDescriptor() *descriptorpb.FileDescriptorProto {
+	const (
+		typeUint32  = descriptorpb.FieldDescriptorProto_TYPE_UINT32
+		typeUint64  = descriptorpb.FieldDescriptorProto_TYPE_UINT64
+		typeInt64   = descriptorpb.FieldDescriptorProto_TYPE_INT64
+		typeDouble  = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+		typeString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		typeBytes   = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+		typeMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	tags := field("tags", tagSetTagsField, typeMessage, "TagSet.TagsEntry")
+	tags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	metadata := field("metadata", metadataMetadataField, typeMessage, "Metadata.MetadataEntry")
+	metadata.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	mapEntry := func(name string) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name: protobuf.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("key", mapEntryKeyField, typeString, ""),
+				field("value", mapEntryValueField, typeString, ""),
+			},
+			Options: &descriptorpb.MessageOptions{MapEntry: protobuf.Bool(true)},
+		}
+	}
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:    protobuf.String("samples.proto"),
+		Package: protobuf.String(schemaPackage),
+		Syntax:  protobuf.String("proto3"),
+		Options: &descriptorpb.FileOptions{GoPackage: protobuf.String("go.k6.io/k6/output/proto")},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: protobuf.String("Record"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					oneofField("header", recordHeaderField, "Header"),
+					oneofField("metric", recordMetricField, "Metric"),
+					oneofField("tag_set", recordTagSetField, "TagSet"),
+					oneofField("sample", recordSampleField, "Sample"),
+					oneofField("metadata", recordMetadataField, "Metadata"),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: protobuf.String("record")}},
+			},
+			{
+				Name: protobuf.String("Header"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("format_version", headerFormatVersionField, typeUint32, ""),
+					field("k6_version", headerK6VersionField, typeString, ""),
+					field("schema", headerSchemaField, typeBytes, ""),
+				},
+			},
+			{
+				Name: protobuf.String("Metadata"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("k6_version", metadataK6VersionField, typeString, ""),
+					field("os", metadataOSField, typeString, ""),
+					field("arch", metadataArchField, typeString, ""),
+					field("hostname", metadataHostnameField, typeString, ""),
+					field("cpus", metadataCPUsField, typeUint32, ""),
+					field("gomaxprocs", metadataGOMAXPROCSField, typeUint32, ""),
+					field("open_files_limit", metadataOpenFilesLimitField, typeUint64, ""),
+					field("container_cpu_limit", metadataContainerCPULimitField, typeDouble, ""),
+					field("container_memory_limit", metadataContainerMemoryLimitField, typeUint64, ""),
+					metadata,
+				},
+				NestedType: []*descriptorpb.DescriptorProto{mapEntry("MetadataEntry")},
+			},
+			{
+				Name: protobuf.String("Metric"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", metricIDField, typeUint32, ""),
+					field("name", metricNameField, typeString, ""),
+					field("type", metricTypeField, typeString, ""),
+					field("contains", metricContainsField, typeString, ""),
+				},
+			},
+			{
+				Name: protobuf.String("TagSet"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", tagSetIDField, typeUint32, ""),
+					tags,
+				},
+				NestedType: []*descriptorpb.DescriptorProto{mapEntry("TagsEntry")},
+			},
+			{
+				Name: protobuf.String("Sample"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("metric_id", sampleMetricIDField, typeUint32, ""),
+					field("tag_set_id", sampleTagSetIDField, typeUint32, ""),
+					field("time_unix_nano", sampleTimeUnixNanoField, typeInt64, ""),
+					field("value", sampleValueField, typeDouble, ""),
+					field("seq", sampleSeqField, typeUint64, ""),
+				},
+			},
+		},
+	}
+}
+
+// marshalSchema returns the serialized google.protobuf.FileDescriptorSet with
+// the schema of the results file.
+func marshalSchema() ([]byte, error) {
+	return protobuf.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{schemaDescriptor()},
+	})
+}
@@ -50,6 +50,7 @@ type Params struct {
 	ScriptOptions  lib.Options
 	RuntimeOptions lib.RuntimeOptions
 	ExecutionPlan  []lib.ExecutionStep
+	RunEnvironment lib.RunEnvironment
 }
 
 // TODO: make v2 with buffered channels?
@@ -72,7 +73,8 @@ type Output interface {
 	// A method to receive the latest metric samples from the Engine. This
 	// method is never called concurrently, so do not do anything blocking here
 	// that might take a long time. Preferably, just use the SampleBuffer or
-	// something like it to buffer metrics until they are flushed.
+	// something like it to buffer metrics until they are flushed. The samples
+	// are always received in the order of their sequence numbers.
 	AddMetricSamples(samples []metrics.SampleContainer)
 
 	// Flush all remaining metrics and finalize the test run.
@@ -106,3 +108,11 @@ type WithBuiltinMetrics interface {
 	Output
 	SetBuiltinMetrics(builtinMetrics *metrics.BuiltinMetrics)
 }
+
+// WithoutRuntimeControl means the output always has to receive all metric
+// samples, so it can't be paused or sampled during the test run, e.g. because
+// the thresholds or the end-of-test summary depend on it.
+type WithoutRuntimeControl interface {
+	Output
+	DisallowRuntimeControl()
+}